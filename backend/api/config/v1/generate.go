@@ -0,0 +1,5 @@
+package configv1
+
+// Regenerates config.pb.go and config_grpc.pb.go from config.proto with the
+// plugins pinned in buf.gen.yaml.
+//go:generate go run github.com/bufbuild/buf/cmd/buf@v1.73.0 generate ../../.. --template ../../../buf.gen.yaml --output ../../..
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"config-service/backend/pkg/client"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchPollTimeout is how long each watch request waits for changes.
+const watchPollTimeout = 30 * time.Second
+
+// importFormats maps file extensions to the document formats of the API.
+var importFormats = map[string]string{
+	".json":       "json",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".env":        "dotenv",
+	".properties": "properties",
+}
+
+func runGet(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("get", "<env> <key>")
+	args, err := a.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	config, err := c.GetConfig(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return a.printConfig(config)
+}
+
+// runSet updates the key, or creates it when it does not exist. With
+// -version it only updates the key at that version.
+func runSet(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("set", "<env> <key> <value>")
+	valueType := fs.String("type", "", "value `type`: string, int, float, bool, duration, url, json or enum; keeps the current type when empty")
+	enum := fs.String("enum", "", "comma-separated `values` allowed for the enum type")
+	secret := fs.Bool("secret", false, "store the value encrypted and mask it in responses")
+	version := fs.Int64("version", 0, "only update the key at this `version`")
+	args, err := a.parse(fs, args, 3)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	environment, key, value := args[0], args[1], args[2]
+	spec := client.ValueSpec{Type: *valueType, Secret: *secret}
+	if *enum != "" {
+		spec.Enum = strings.Split(*enum, ",")
+	}
+
+	err = c.UpdateConfig(ctx, environment, key, value, spec, *version)
+	if errors.Is(err, client.ErrNotFound) && *version == 0 {
+		if err := c.CreateConfig(ctx, environment, key, value, spec); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "created %s in %s\n", key, environment)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "updated %s in %s\n", key, environment)
+	return nil
+}
+
+func runDelete(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("delete", "<env> <key>")
+	tree := fs.Bool("tree", false, "also delete the keys nested below the key")
+	version := fs.Int64("version", 0, "only delete the key at this `version`")
+	args, err := a.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if *tree && *version != 0 {
+		return &usageError{"-tree cannot be combined with -version"}
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	environment, key := args[0], args[1]
+	if !*tree {
+		if err := c.DeleteConfig(ctx, environment, key, *version); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "deleted %s in %s\n", key, environment)
+		return nil
+	}
+	revisions, err := c.DeleteTree(ctx, environment, key)
+	if err != nil {
+		return err
+	}
+	for _, revision := range revisions {
+		fmt.Fprintf(a.stdout, "deleted %s in %s\n", revision.Key, environment)
+	}
+	return nil
+}
+
+func runList(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("list", "<env>")
+	var opts client.ListOptions
+	fs.StringVar(&opts.Prefix, "prefix", "", "only keys that start with `prefix`")
+	fs.StringVar(&opts.Tree, "tree", "", "only `key` and the keys nested below it")
+	fs.StringVar(&opts.Search, "search", "", "only keys or values that contain `text`, ignoring case")
+	args, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	configs, err := c.ListConfigs(ctx, args[0], opts)
+	if err != nil {
+		return err
+	}
+	return a.printConfigs(configs)
+}
+
+func runDiff(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("diff", "<left> <right>")
+	args, err := a.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if err := a.rejectEnvOutput(); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	diff, err := c.Compare(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return a.printDiff(diff)
+}
+
+// runExport prints the document the server encodes; -o does not apply.
+func runExport(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("export", "<env>")
+	format := fs.String("format", "json", "document `format`: json, yaml, dotenv or properties")
+	tree := fs.String("tree", "", "only `key` and the keys nested below it")
+	file := fs.String("file", "", "write the document to `path` instead of stdout")
+	args, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	document, err := c.Export(ctx, args[0], *format, *tree)
+	if err != nil {
+		return err
+	}
+	if *file != "" {
+		return os.WriteFile(*file, document, 0o600)
+	}
+	_, err = a.stdout.Write(document)
+	return err
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("import", "<env> <file>")
+	opts := client.ImportOptions{}
+	fs.StringVar(&opts.Format, "format", "", "document `format`: json, yaml, dotenv or properties; guessed from the file extension")
+	fs.StringVar(&opts.Mode, "mode", "merge", "`mode`: merge, replace (also delete missing keys) or skip-existing")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report the changes without writing them")
+	args, err := a.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if err := a.rejectEnvOutput(); err != nil {
+		return err
+	}
+	environment, path := args[0], args[1]
+	if opts.Format == "" && path != "-" {
+		opts.Format = importFormats[strings.ToLower(filepath.Ext(path))]
+	}
+	if opts.Format == "" {
+		return &usageError{"-format is required when it cannot be guessed from the file name"}
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	var document []byte
+	if path == "-" {
+		document, err = io.ReadAll(a.stdin)
+	} else {
+		document, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := c.Import(ctx, environment, document, opts)
+	if report != nil {
+		if printErr := a.printReport(report); printErr != nil && err == nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func runHistory(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("history", "<env> <key>")
+	args, err := a.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if err := a.rejectEnvOutput(); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	revisions, err := c.History(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return a.printRevisions(revisions)
+}
+
+// runWatch prints the changes of the environment until interrupted. Without
+// -since it starts at the latest change.
+func runWatch(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("watch", "<env>")
+	since := fs.Int64("since", -1, "print the changes after `revision` first")
+	args, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if err := a.rejectEnvOutput(); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	environment := args[0]
+	if *since < 0 {
+		if *since, err = latestRevision(ctx, c, environment); err != nil {
+			return err
+		}
+	}
+
+	printer := a.revisionStream()
+	for {
+		revisions, err := c.Changes(ctx, environment, *since, watchPollTimeout)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(revisions) == 0 {
+			continue
+		}
+		if err := printer(revisions); err != nil {
+			return err
+		}
+		*since = revisions[len(revisions)-1].Revision
+	}
+}
+
+// latestRevision finds the newest revision of the environment. The keys
+// carry their latest revision; deletes after the newest of them are skipped
+// by asking for changes until none are left.
+func latestRevision(ctx context.Context, c *client.Client, environment string) (int64, error) {
+	configs, err := c.ListConfigs(ctx, environment, client.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, config := range configs {
+		latest = max(latest, config.Revision)
+	}
+	for {
+		revisions, err := c.Changes(ctx, environment, latest, time.Millisecond)
+		if err != nil {
+			return 0, err
+		}
+		if len(revisions) == 0 {
+			return latest, nil
+		}
+		latest = revisions[len(revisions)-1].Revision
+	}
+}
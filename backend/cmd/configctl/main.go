@@ -0,0 +1,220 @@
+// Command configctl reads and writes configs of the config service from the
+// shell. Run configctl without arguments for usage.
+package main
+
+import (
+	"config-service/backend/pkg/client"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+const usage = `usage: configctl <command> [flags] [args]
+
+commands:
+  get <env> <key>            show a key
+  set <env> <key> <value>    create or update a key
+  delete <env> <key>         delete a key
+  list <env>                 list the keys of an environment
+  diff <left> <right>        compare environments, either may be env@<RFC 3339 time>
+  export <env>               print the environment as a document
+  import <env> <file>        write the keys of a document, - reads stdin
+  history <env> <key>        list the revisions of a key
+  watch <env>                print changes as they happen
+
+flags of every command:
+  -o, -output FORMAT         table, json, yaml or env (default table)
+  -profile NAME              profile of the profile file (env CONFIGCTL_PROFILE)
+  -config FILE               profile file (env CONFIGCTL_CONFIG)
+  -server URL                server URL (env CONFIGCTL_SERVER)
+  -token TOKEN               API token (env CONFIGCTL_TOKEN)
+
+Run configctl <command> -h for the flags of a command.
+
+exit codes:
+  0 success, 1 error, 2 usage, 3 not found, 4 conflict, 5 version mismatch,
+  6 invalid request, 7 unauthorized or forbidden, 8 service unavailable
+`
+
+// Exit codes, mapped from the HTTP status of a failed request.
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitNotFound
+	exitConflict
+	exitVersionMismatch
+	exitInvalid
+	exitDenied
+	exitUnavailable
+)
+
+var outputFormats = []string{"table", "json", "yaml", "env"}
+
+type commandFunc func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]commandFunc{
+	"get":     runGet,
+	"set":     runSet,
+	"delete":  runDelete,
+	"list":    runList,
+	"diff":    runDiff,
+	"export":  runExport,
+	"import":  runImport,
+	"history": runHistory,
+	"watch":   runWatch,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// app holds the flags shared by all commands and where they write.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	output     string
+	profile    string
+	configFile string
+	server     string
+	token      string
+}
+
+// run runs the command in args and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "configctl: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, getenv: os.Getenv}
+	err := command(ctx, a, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "configctl %s: %v\n", args[0], err)
+	}
+	return exitCode(err)
+}
+
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// exitCode tells scripts why a command failed without parsing its output.
+func exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, client.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, client.ErrConflict):
+		return exitConflict
+	case errors.Is(err, client.ErrVersionMismatch):
+		return exitVersionMismatch
+	case errors.Is(err, client.ErrInvalid):
+		return exitInvalid
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, client.ErrForbidden):
+		return exitDenied
+	case errors.Is(err, client.ErrUnavailable):
+		return exitUnavailable
+	default:
+		return exitError
+	}
+}
+
+// flags returns the flag set of a command with the shared flags registered.
+func (a *app) flags(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "usage: configctl %s %s\n\nflags:\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&a.output, "o", "table", "output `format`: "+strings.Join(outputFormats, ", "))
+	fs.StringVar(&a.output, "output", "table", "output `format`: "+strings.Join(outputFormats, ", "))
+	fs.StringVar(&a.profile, "profile", "", "profile `name`")
+	fs.StringVar(&a.configFile, "config", "", "profile `file`")
+	fs.StringVar(&a.server, "server", "", "server `URL`")
+	fs.StringVar(&a.token, "token", "", "API `token`")
+	return fs
+}
+
+// parse parses args, which may mix flags and arguments, and checks that
+// exactly want arguments remain.
+func (a *app) parse(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{err.Error()}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) != want {
+		fs.Usage()
+		return nil, &usageError{fmt.Sprintf("want %d arguments, got %d", want, len(positional))}
+	}
+	if !slices.Contains(outputFormats, a.output) {
+		return nil, &usageError{fmt.Sprintf("unknown output format %q", a.output)}
+	}
+	return positional, nil
+}
+
+// rejectEnvOutput fails for commands whose output is not a list of keys.
+func (a *app) rejectEnvOutput() error {
+	if a.output == "env" {
+		return &usageError{"env output is only supported by get and list"}
+	}
+	return nil
+}
+
+func (a *app) client() (*client.Client, error) {
+	settings, err := resolveProfile(a.configFile, a.profile, a.getenv)
+	if err != nil {
+		return nil, err
+	}
+	if a.server != "" {
+		settings.Server = a.server
+	}
+	if a.token != "" {
+		settings.Token = a.token
+	}
+	return client.New(settings.Server, client.Options{Token: settings.Token})
+}
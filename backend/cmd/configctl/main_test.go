@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"config-service/backend/internal/handler"
+	"config-service/backend/internal/infrastructure/memory"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer serves the real config API with the environments dev and
+// prod, and keeps configctl away from the profile file of the user.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"CONFIGCTL_CONFIG", "CONFIGCTL_PROFILE", "CONFIGCTL_SERVER", "CONFIGCTL_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	repo, err := memory.NewRepository("")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	for _, name := range []string{"dev", "prod"} {
+		if err := repo.CreateEnvironment(context.Background(), &model.Environment{Name: name}); err != nil {
+			t.Fatalf("CreateEnvironment() error = %v", err)
+		}
+	}
+	mux := http.NewServeMux()
+	handler.NewConfigHandler(service.NewConfigService(repo, service.NewBroker())).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// syncBuffer is written by a running watch while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// configctl runs a command against server and returns its exit code and
+// output.
+func configctl(t *testing.T, server *httptest.Server, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append(args, "-server", server.URL)
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunExitCodes(t *testing.T) {
+	server := newTestServer(t)
+	configctl(t, server, "", "set", "dev", "port", "8080", "-type", "int")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "found", args: []string{"get", "dev", "port"}, want: exitOK},
+		{name: "missing key", args: []string{"get", "dev", "missing"}, want: exitNotFound},
+		{name: "missing environment", args: []string{"set", "staging", "port", "1"}, want: exitNotFound},
+		{name: "stale version", args: []string{"set", "dev", "port", "9090", "-version", "5"}, want: exitVersionMismatch},
+		{name: "invalid value", args: []string{"set", "dev", "port", "abc"}, want: exitInvalid},
+		{name: "missing argument", args: []string{"get", "dev"}, want: exitUsage},
+		{name: "unknown flag", args: []string{"get", "dev", "port", "-bogus"}, want: exitUsage},
+		{name: "unknown output", args: []string{"get", "dev", "port", "-o", "xml"}, want: exitUsage},
+		{name: "env output of history", args: []string{"history", "dev", "port", "-o", "env"}, want: exitUsage},
+		{name: "unknown command", args: []string{"frobnicate"}, want: exitUsage},
+		{name: "help", args: []string{"get", "-h"}, want: exitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, stderr := configctl(t, server, "", tt.args...); code != tt.want {
+				t.Fatalf("exit code = %d, want %d; stderr: %s", code, tt.want, stderr)
+			}
+		})
+	}
+}
+
+func TestRunUnreachableServer(t *testing.T) {
+	newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"get", "dev", "port", "-server", "http://127.0.0.1:1"}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Fatalf("exit code = %d, want %d", code, exitError)
+	}
+}
+
+func TestRunSetGetList(t *testing.T) {
+	server := newTestServer(t)
+
+	if code, stdout, _ := configctl(t, server, "", "set", "dev", "db.host", "localhost"); code != exitOK || stdout != "created db.host in dev\n" {
+		t.Fatalf("set = %d, %q", code, stdout)
+	}
+	if code, stdout, _ := configctl(t, server, "", "set", "dev", "db.host", "db.internal"); code != exitOK || stdout != "updated db.host in dev\n" {
+		t.Fatalf("set existing = %d, %q", code, stdout)
+	}
+	configctl(t, server, "", "set", "dev", "db.port", "5432", "-type", "int")
+	configctl(t, server, "", "set", "dev", "name", "api")
+
+	_, stdout, _ := configctl(t, server, "", "get", "dev", "db.host", "-o", "json")
+	var config struct {
+		Key     string `json:"key"`
+		Value   string `json:"value"`
+		Version int64  `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &config); err != nil || config.Value != "db.internal" || config.Version != 2 {
+		t.Fatalf("get -o json = %q, %v", stdout, err)
+	}
+
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{output: "env", want: []string{`db.host="db.internal"`, `db.port="5432"`}},
+		{output: "table", want: []string{"KEY", "db.host", "db.port", "5432", "int"}},
+		{output: "yaml", want: []string{"- env: dev\n  key: db.host\n  value: db.internal", "type: int"}},
+	}
+	for _, tt := range tests {
+		code, stdout, _ := configctl(t, server, "", "list", "dev", "-prefix", "db.", "-o", tt.output)
+		if code != exitOK || strings.Contains(stdout, "api") {
+			t.Fatalf("list -o %s = %d, %q, want only the db keys", tt.output, code, stdout)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("list -o %s = %q, want it to contain %q", tt.output, stdout, want)
+			}
+		}
+	}
+}
+
+func TestRunDeleteAndHistory(t *testing.T) {
+	server := newTestServer(t)
+	configctl(t, server, "", "set", "dev", "a", "1")
+	configctl(t, server, "", "set", "dev", "a", "2")
+	configctl(t, server, "", "set", "dev", "b", "1")
+	configctl(t, server, "", "set", "dev", "b.c", "1")
+
+	if code, _, _ := configctl(t, server, "", "delete", "dev", "a", "-version", "1"); code != exitVersionMismatch {
+		t.Fatalf("delete stale = %d", code)
+	}
+	if code, stdout, _ := configctl(t, server, "", "delete", "dev", "a"); code != exitOK || stdout != "deleted a in dev\n" {
+		t.Fatalf("delete = %d, %q", code, stdout)
+	}
+	if code, stdout, _ := configctl(t, server, "", "delete", "dev", "b", "-tree"); code != exitOK || strings.Count(stdout, "deleted") != 2 {
+		t.Fatalf("delete -tree = %d, %q", code, stdout)
+	}
+
+	_, stdout, _ := configctl(t, server, "", "history", "dev", "a", "-o", "json")
+	var revisions []struct {
+		Operation string `json:"operation"`
+		Value     string `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &revisions); err != nil || len(revisions) != 3 || revisions[0].Operation != "delete" || revisions[2].Value != "1" {
+		t.Fatalf("history -o json = %q, %v", stdout, err)
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	server := newTestServer(t)
+	configctl(t, server, "", "set", "dev", "shared", "1")
+	configctl(t, server, "", "set", "prod", "shared", "2")
+	configctl(t, server, "", "set", "dev", "only.dev", "x")
+
+	code, stdout, _ := configctl(t, server, "", "diff", "dev", "prod")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != exitOK || len(lines) != 3 || strings.Fields(lines[0])[1] != "DEV" ||
+		strings.Join(strings.Fields(lines[1]), " ") != "only.dev x -" ||
+		strings.Join(strings.Fields(lines[2]), " ") != "shared 1 2" {
+		t.Fatalf("diff = %d, %q", code, stdout)
+	}
+}
+
+func TestRunExportImport(t *testing.T) {
+	server := newTestServer(t)
+	configctl(t, server, "", "set", "dev", "a", "1")
+	configctl(t, server, "", "set", "dev", "b", "2")
+
+	file := filepath.Join(t.TempDir(), "dev.env")
+	if code, _, stderr := configctl(t, server, "", "export", "dev", "-format", "dotenv", "-file", file); code != exitOK {
+		t.Fatalf("export = %d: %s", code, stderr)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != "a=\"1\"\nb=\"2\"\n" {
+		t.Fatalf("exported file = %q, %v", data, err)
+	}
+
+	if code, stdout, _ := configctl(t, server, "", "import", "prod", file, "-dry-run"); code != exitOK || !strings.Contains(stdout, "dry run") {
+		t.Fatalf("import -dry-run = %d, %q", code, stdout)
+	}
+	if code, _, _ := configctl(t, server, "", "get", "prod", "a"); code != exitNotFound {
+		t.Fatalf("get after a dry run = %d, want nothing imported", code)
+	}
+	code, stdout, _ := configctl(t, server, "", "import", "prod", file, "-o", "json")
+	var report struct {
+		Created []string `json:"created"`
+	}
+	if code != exitOK || json.Unmarshal([]byte(stdout), &report) != nil || len(report.Created) != 2 {
+		t.Fatalf("import = %d, %q", code, stdout)
+	}
+
+	if code, _, _ := configctl(t, server, "a=3\n", "import", "prod", "-"); code != exitUsage {
+		t.Fatalf("import from stdin without -format = %d, want a usage error", code)
+	}
+	configctl(t, server, "", "set", "prod", "n", "1", "-type", "int")
+	code, stdout, _ = configctl(t, server, `{"a": "3", "n": "abc"}`, "import", "prod", "-", "-format", "json")
+	if code != exitConflict || !strings.Contains(stdout, "failed") {
+		t.Fatalf("rejected import = %d, %q, want the report and a conflict", code, stdout)
+	}
+}
+
+func TestRunWatch(t *testing.T) {
+	server := newTestServer(t)
+	configctl(t, server, "", "set", "dev", "old", "1")
+	configctl(t, server, "", "delete", "dev", "old")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stdout := &syncBuffer{}
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"watch", "dev", "-o", "json", "-server", server.URL}, nil, stdout, &syncBuffer{})
+	}()
+
+	// Changes made before the watch started are not printed, so keep
+	// writing until one made after it shows up.
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; !strings.Contains(stdout.String(), `"key":"new"`); i++ {
+		if time.Now().After(deadline) {
+			t.Fatalf("watch printed %q", stdout.String())
+		}
+		configctl(t, server, "", "set", "dev", "new", "v")
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Fatalf("watch exit code = %d after interrupt", code)
+	}
+	if strings.Contains(stdout.String(), `"key":"old"`) {
+		t.Fatalf("watch printed changes from before it started: %q", stdout.String())
+	}
+}
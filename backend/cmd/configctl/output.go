@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"config-service/backend/internal/codec"
+	"config-service/backend/internal/model"
+	"config-service/backend/pkg/client"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func (a *app) printConfig(config *client.Config) error {
+	switch a.output {
+	case "json", "yaml":
+		return a.encode(config)
+	default:
+		return a.printConfigs([]*client.Config{config})
+	}
+}
+
+// printConfigs writes env output as a dotenv document, which the import
+// command reads back.
+func (a *app) printConfigs(configs []*client.Config) error {
+	switch a.output {
+	case "json", "yaml":
+		if configs == nil {
+			configs = []*client.Config{}
+		}
+		return a.encode(configs)
+	case "env":
+		values := make([]model.KeyValue, len(configs))
+		for i, config := range configs {
+			values[i] = model.KeyValue{Key: config.Key, Value: config.Value}
+		}
+		return codec.Encode(a.stdout, codec.FormatDotenv, values)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tTYPE\tVERSION\tUPDATED AT\tUPDATED BY")
+	for _, config := range configs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", config.Key, cell(config.Value), configType(config), config.Version, formatTime(config.UpdatedAt), config.UpdatedBy)
+	}
+	return w.Flush()
+}
+
+func (a *app) printRevisions(revisions []*client.Revision) error {
+	switch a.output {
+	case "json", "yaml":
+		if revisions == nil {
+			revisions = []*client.Revision{}
+		}
+		return a.encode(revisions)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tOPERATION\tKEY\tVALUE\tACTOR\tCREATED AT")
+	writeRevisionRows(w, revisions)
+	return w.Flush()
+}
+
+// revisionStream returns a printer for batches of revisions that arrive over
+// time: JSON is printed one revision per line and YAML one document per
+// revision, so that each can be processed as it arrives.
+func (a *app) revisionStream() func([]*client.Revision) error {
+	header := true
+	return func(revisions []*client.Revision) error {
+		switch a.output {
+		case "json":
+			encoder := json.NewEncoder(a.stdout)
+			for _, revision := range revisions {
+				if err := encoder.Encode(revision); err != nil {
+					return err
+				}
+			}
+			return nil
+		case "yaml":
+			for _, revision := range revisions {
+				if _, err := io.WriteString(a.stdout, "---\n"); err != nil {
+					return err
+				}
+				if err := a.encode(revision); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+		if header {
+			fmt.Fprintln(w, "REVISION\tOPERATION\tKEY\tVALUE\tACTOR\tCREATED AT")
+			header = false
+		}
+		writeRevisionRows(w, revisions)
+		return w.Flush()
+	}
+}
+
+func writeRevisionRows(w io.Writer, revisions []*client.Revision) {
+	for _, revision := range revisions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", revision.Revision, revision.Operation, revision.Key, cell(revision.Value), revision.Actor, formatTime(revision.CreatedAt))
+	}
+}
+
+// printDiff lists the keys that differ with their value on either side, or
+// - where the key is missing.
+func (a *app) printDiff(diff *client.Diff) error {
+	switch a.output {
+	case "json", "yaml":
+		return a.encode(diff)
+	}
+
+	type row struct{ key, left, right string }
+	var rows []row
+	for _, config := range diff.OnlyInLeft {
+		rows = append(rows, row{config.Key, cell(config.Value), "-"})
+	}
+	for _, config := range diff.OnlyInRight {
+		rows = append(rows, row{config.Key, "-", cell(config.Value)})
+	}
+	for _, change := range diff.Changed {
+		rows = append(rows, row{change.Key, diffCell(change.Left), diffCell(change.Right)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].key < rows[j].key })
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "KEY\t%s\t%s\n", strings.ToUpper(diff.Left), strings.ToUpper(diff.Right))
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.key, r.left, r.right)
+	}
+	return w.Flush()
+}
+
+// printReport lists every imported key with its outcome, and why the
+// operations that failed were rejected.
+func (a *app) printReport(report *client.ImportReport) error {
+	switch a.output {
+	case "json", "yaml":
+		return a.encode(report)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tOUTCOME\tERROR")
+	for _, outcome := range []struct {
+		name string
+		keys []string
+	}{
+		{"created", report.Created},
+		{"updated", report.Updated},
+		{"deleted", report.Deleted},
+		{"unchanged", report.Unchanged},
+		{"skipped", report.Skipped},
+		{"masked", report.Masked},
+	} {
+		for _, key := range outcome.keys {
+			fmt.Fprintf(w, "%s\t%s\t\n", key, outcome.name)
+		}
+	}
+	for _, result := range report.Results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", result.Key, result.Status, cell(result.Error))
+		}
+	}
+	if report.DryRun {
+		fmt.Fprintln(w, "(dry run, nothing was written)\t\t")
+	}
+	return w.Flush()
+}
+
+// encode writes v as JSON, or as YAML with the same field names.
+func (a *app) encode(v any) error {
+	if a.output == "json" {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// JSON is valid YAML, so decoding it yields the document to re-encode
+	// with the fields in the same order.
+	var out []byte
+	if bytes.HasPrefix(data, []byte("[")) {
+		var list []yaml.MapSlice
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		out, err = yaml.Marshal(list)
+	} else {
+		var document yaml.MapSlice
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return err
+		}
+		out, err = yaml.Marshal(document)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = a.stdout.Write(out)
+	return err
+}
+
+// cellEscaper keeps a value on one table line.
+var cellEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func cell(value string) string {
+	return cellEscaper.Replace(value)
+}
+
+func diffCell(config *client.Config) string {
+	value := cell(config.Value)
+	if config.Type != "" {
+		value += " (" + config.Type + ")"
+	}
+	return value
+}
+
+func configType(config *client.Config) string {
+	valueType := config.Type
+	if valueType == "" {
+		valueType = "-"
+	}
+	if config.Secret {
+		valueType += ", secret"
+	}
+	return valueType
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
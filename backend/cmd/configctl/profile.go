@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultServer  = "http://localhost:8080"
+	defaultProfile = "default"
+)
+
+// profileFile is the YAML file that names servers, such as
+//
+//	current: production
+//	profiles:
+//	  production:
+//	    server: https://config.example.com
+//	    token: ...
+//	  local:
+//	    server: http://localhost:8080
+type profileFile struct {
+	Current  string             `yaml:"current"`
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+type profile struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token"`
+}
+
+// defaultProfilePath is configctl/config.yaml in the user config directory,
+// ~/.config on Linux.
+func defaultProfilePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "configctl", "config.yaml")
+}
+
+// resolveProfile picks the profile named by the flag, CONFIGCTL_PROFILE or
+// the current entry of the profile file, in that order. CONFIGCTL_SERVER and
+// CONFIGCTL_TOKEN override its settings. A missing profile file is only an
+// error when it was asked for.
+func resolveProfile(path, name string, getenv func(string) string) (profile, error) {
+	if path == "" {
+		path = getenv("CONFIGCTL_CONFIG")
+	}
+	explicitPath := path != ""
+	if !explicitPath {
+		path = defaultProfilePath()
+	}
+
+	var file profileFile
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.UnmarshalStrict(data, &file); err != nil {
+				return profile{}, fmt.Errorf("invalid profile file %s: %w", path, err)
+			}
+		case errors.Is(err, fs.ErrNotExist) && !explicitPath:
+		default:
+			return profile{}, err
+		}
+	}
+
+	if name == "" {
+		name = getenv("CONFIGCTL_PROFILE")
+	}
+	explicitName := name != ""
+	if !explicitName {
+		name = file.Current
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	selected, ok := file.Profiles[name]
+	if !ok && (explicitName || file.Current != "") {
+		return profile{}, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+
+	if server := getenv("CONFIGCTL_SERVER"); server != "" {
+		selected.Server = server
+	}
+	if token := getenv("CONFIGCTL_TOKEN"); token != "" {
+		selected.Token = token
+	}
+	if selected.Server == "" {
+		selected.Server = defaultServer
+	}
+	return selected, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `current: prod
+profiles:
+  prod:
+    server: https://config.example.com
+    token: prod-token
+  local:
+    server: http://localhost:9000
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		profile string
+		env     map[string]string
+		want    profile
+		wantErr bool
+	}{
+		{name: "current profile", path: path, want: profile{Server: "https://config.example.com", Token: "prod-token"}},
+		{name: "named profile", path: path, profile: "local", want: profile{Server: "http://localhost:9000"}},
+		{name: "profile from env", path: path, env: map[string]string{"CONFIGCTL_PROFILE": "local"}, want: profile{Server: "http://localhost:9000"}},
+		{name: "file from env", env: map[string]string{"CONFIGCTL_CONFIG": path}, want: profile{Server: "https://config.example.com", Token: "prod-token"}},
+		{
+			name: "env overrides the profile",
+			path: path,
+			env:  map[string]string{"CONFIGCTL_SERVER": "http://other:8080", "CONFIGCTL_TOKEN": "other-token"},
+			want: profile{Server: "http://other:8080", Token: "other-token"},
+		},
+		{name: "unknown profile", path: path, profile: "staging", wantErr: true},
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.yaml"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			got, err := resolveProfile(tt.path, tt.profile, getenv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Fatalf("resolveProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveProfileWithoutFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	getenv := func(string) string { return "" }
+
+	got, err := resolveProfile("", "", getenv)
+	if err != nil || got != (profile{Server: defaultServer}) {
+		t.Fatalf("resolveProfile() = %+v, %v, want the default server", got, err)
+	}
+	if _, err := resolveProfile("", "prod", getenv); err == nil {
+		t.Fatal("resolveProfile() of a named profile without a profile file must fail")
+	}
+}
+
+func TestResolveProfileRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles:\n  prod:\n    url: http://x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolveProfile(path, "prod", func(string) string { return "" }); err == nil {
+		t.Fatal("resolveProfile() must reject a misspelled setting")
+	}
+}
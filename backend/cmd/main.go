@@ -1,8 +1,15 @@
 package main
 
-import "config-service/backend/internal/di"
+import (
+	"config-service/backend/internal/di"
+	"os"
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	app := di.NewApp()
 	app.Run()
 }
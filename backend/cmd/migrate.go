@@ -0,0 +1,140 @@
+package main
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/infrastructure/database"
+	"config-service/backend/internal/infrastructure/sqlite"
+	"config-service/backend/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: config-service migrate up | down [steps] | status"
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+// runMigrate runs the migrate subcommand and returns the exit code.
+func runMigrate(args []string) int {
+	command, steps, err := parseMigrateArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := migrate(command, steps); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", command, err)
+		return 1
+	}
+	return 0
+}
+
+// parseMigrateArgs reads the command and, for down, the number of
+// migrations to revert, which defaults to one.
+func parseMigrateArgs(args []string) (string, int, error) {
+	if len(args) == 0 {
+		return "", 0, errMigrateUsage
+	}
+	switch command := args[0]; command {
+	case "up", "status":
+		if len(args) > 1 {
+			return "", 0, errMigrateUsage
+		}
+		return command, 0, nil
+	case "down":
+		if len(args) == 1 {
+			return command, 1, nil
+		}
+		steps, err := strconv.Atoi(args[1])
+		if len(args) > 2 || err != nil || steps <= 0 {
+			return "", 0, errMigrateUsage
+		}
+		return command, steps, nil
+	default:
+		return "", 0, errMigrateUsage
+	}
+}
+
+func migrate(command string, steps int) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	conn, migrator, err := openMigrator(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied() {
+				appliedAt = status.AppliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+}
+
+func openMigrator(cfg config.DatabaseConfig) (database.Connection, *database.Migrator, error) {
+	var conn database.Connection
+	var err error
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		conn, err = database.NewPostgresConnection(cfg.DSN)
+	case config.DriverSQLite:
+		conn, err = sqlite.NewConnection(cfg.SQLitePath)
+	default:
+		return nil, nil, fmt.Errorf("the %s driver has no schema to migrate", cfg.Driver)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var migrator *database.Migrator
+	if cfg.Driver == config.DriverSQLite {
+		migrator, err = sqlite.NewMigrator(conn.GetDB())
+	} else {
+		migrator, err = database.NewMigrator(conn.GetDB(), migrations.FS)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return conn, migrator, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"config-service/backend/config"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMigrateArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		command   string
+		steps     int
+		wantUsage bool
+	}{
+		{name: "up", args: []string{"up"}, command: "up"},
+		{name: "status", args: []string{"status"}, command: "status"},
+		{name: "down defaults to one step", args: []string{"down"}, command: "down", steps: 1},
+		{name: "down steps", args: []string{"down", "3"}, command: "down", steps: 3},
+		{name: "missing command", wantUsage: true},
+		{name: "unknown command", args: []string{"redo"}, wantUsage: true},
+		{name: "up with steps", args: []string{"up", "2"}, wantUsage: true},
+		{name: "zero steps", args: []string{"down", "0"}, wantUsage: true},
+		{name: "invalid steps", args: []string{"down", "all"}, wantUsage: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, steps, err := parseMigrateArgs(tt.args)
+			if tt.wantUsage {
+				if !errors.Is(err, errMigrateUsage) {
+					t.Fatalf("parseMigrateArgs(%q) error = %v, want usage", tt.args, err)
+				}
+				return
+			}
+			if err != nil || command != tt.command || steps != tt.steps {
+				t.Fatalf("parseMigrateArgs(%q) = %q, %d, %v", tt.args, command, steps, err)
+			}
+		})
+	}
+}
+
+func TestOpenMigrator(t *testing.T) {
+	conn, migrator, err := openMigrator(config.DatabaseConfig{Driver: config.DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "configs.db")})
+	if err != nil {
+		t.Fatalf("openMigrator() error = %v", err)
+	}
+	defer conn.Close()
+	if applied, err := migrator.Up(context.Background()); err != nil || len(applied) == 0 {
+		t.Fatalf("Up() = %v, %v, want the SQLite migrations applied", applied, err)
+	}
+
+	if _, _, err := openMigrator(config.DatabaseConfig{Driver: config.DriverMemory}); err == nil {
+		t.Fatal("openMigrator() must reject the memory driver")
+	}
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
@@ -11,31 +16,139 @@ import (
 type Config struct {
 	Database DatabaseConfig `validate:"required"`
 	HTTP     HTTPConfig     `validate:"required"`
+	GRPC     GRPCConfig     `validate:"required"`
+	Secrets  SecretsConfig
+	Auth     AuthConfig
+	Cache    CacheConfig
 }
 
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+// DatabaseConfig selects the storage driver. The sqlite driver keeps data in
+// the database file SQLitePath. The memory driver keeps data in process,
+// optionally persisted to SnapshotFile. Both ignore the Postgres settings.
 type DatabaseConfig struct {
-	DSN string `validate:"required"`
+	Driver     string `validate:"oneof=postgres sqlite memory"`
+	DSN        string `validate:"required_if=Driver postgres"`
+	SQLitePath string `validate:"required_if=Driver sqlite"`
+	// AutoMigrate applies pending schema migrations on startup.
+	AutoMigrate  bool
+	SnapshotFile string
 }
 
 type HTTPConfig struct {
 	Port string `validate:"required"`
+	// RequestTimeout bounds every request except change streams; zero
+	// disables it.
+	RequestTimeout time.Duration `validate:"gte=0"`
+}
+
+// GRPCConfig is the port of the gRPC API, served next to the HTTP API.
+type GRPCConfig struct {
+	Port string `validate:"required"`
+}
+
+// CacheConfig sizes the in-process cache of single config reads. A zero
+// Size disables the cache.
+type CacheConfig struct {
+	Size int           `validate:"gte=0"`
+	TTL  time.Duration `validate:"gt=0"`
+}
+
+// SecretsConfig is empty when no master key is configured; secret values
+// cannot be written then.
+type SecretsConfig struct {
+	// MasterKeys starts with the active key, followed by retired keys that
+	// are kept until every data key wrapped with them is re-wrapped.
+	MasterKeys []MasterKey
+	// ReencryptInterval is how often data keys are re-wrapped and current
+	// values re-encrypted with the current data key.
+	ReencryptInterval time.Duration `validate:"gt=0"`
+}
+
+// AuthConfig controls bearer token authentication, which is off unless
+// enabled so that upgrades keep serving existing clients. AdminToken is a
+// bootstrap token with admin rights on every environment, used to create
+// the first API tokens.
+type AuthConfig struct {
+	Enabled    bool
+	AdminToken string
+	OIDC       OIDCConfig
+}
+
+// OIDCConfig enables JWT authentication when a JWKS URL or file is set.
+type OIDCConfig struct {
+	JWKSURL  string
+	JWKSFile string
+	Issuer   string
+	Audience string
+	// ClockSkew is tolerated when checking exp, nbf and iat.
+	ClockSkew time.Duration
+	// JWKSRefresh is how often keys are re-fetched from JWKSURL.
+	JWKSRefresh time.Duration
+	NameClaim   string
+	GroupsClaim string
+	// GroupGrants maps a group from GroupsClaim to the grants of its
+	// members.
+	GroupGrants map[string][]GroupGrant
 }
 
+type GroupGrant struct {
+	Environment string
+	KeyPrefix   string
+	Role        string
+}
+
+func (c OIDCConfig) Enabled() bool {
+	return c.JWKSURL != "" || c.JWKSFile != ""
+}
+
+type MasterKey struct {
+	ID  string
+	Key []byte
+}
+
+var masterKeyID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
-	dsn, err := databaseDSN()
+	database, err := databaseConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := secretsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := authConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := cacheConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	httpCfg, err := httpConfig()
 	if err != nil {
 		return nil, err
 	}
 
 	cfg := &Config{
-		Database: DatabaseConfig{
-			DSN: dsn,
-		},
-		HTTP: HTTPConfig{
-			Port: getEnvOrDefault("PORT", "8080"),
-		},
+		Database: database,
+		HTTP:     httpCfg,
+		GRPC:     GRPCConfig{Port: getEnvOrDefault("GRPC_PORT", "50051")},
+		Secrets:  secrets,
+		Auth:     auth,
+		Cache:    cache,
 	}
 
 	validate := validator.New()
@@ -46,6 +159,31 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+func databaseConfig() (DatabaseConfig, error) {
+	driver := getEnvOrDefault("STORAGE_DRIVER", DriverPostgres)
+	switch driver {
+	case DriverPostgres, DriverSQLite:
+	case DriverMemory:
+		return DatabaseConfig{Driver: DriverMemory, SnapshotFile: os.Getenv("MEMORY_SNAPSHOT_FILE")}, nil
+	default:
+		return DatabaseConfig{}, fmt.Errorf("invalid STORAGE_DRIVER %q: want %s, %s or %s", driver, DriverPostgres, DriverSQLite, DriverMemory)
+	}
+
+	autoMigrate, err := strconv.ParseBool(getEnvOrDefault("DB_AUTO_MIGRATE", "true"))
+	if err != nil {
+		return DatabaseConfig{}, fmt.Errorf("invalid DB_AUTO_MIGRATE: %w", err)
+	}
+	if driver == DriverSQLite {
+		return DatabaseConfig{Driver: DriverSQLite, SQLitePath: getEnvOrDefault("SQLITE_PATH", "config-service.db"), AutoMigrate: autoMigrate}, nil
+	}
+
+	dsn, err := databaseDSN()
+	if err != nil {
+		return DatabaseConfig{}, err
+	}
+	return DatabaseConfig{Driver: DriverPostgres, DSN: dsn, AutoMigrate: autoMigrate}, nil
+}
+
 func databaseDSN() (string, error) {
 	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
 		return dsn, nil
@@ -67,6 +205,154 @@ func databaseDSN() (string, error) {
 	), nil
 }
 
+// secretsConfig reads SECRETS_MASTER_KEY and the comma-separated
+// SECRETS_RETIRED_KEYS, both as id:base64 pairs of 32-byte AES keys.
+func secretsConfig() (SecretsConfig, error) {
+	interval, err := time.ParseDuration(getEnvOrDefault("SECRETS_REENCRYPT_INTERVAL", "1h"))
+	if err != nil {
+		return SecretsConfig{}, fmt.Errorf("invalid SECRETS_REENCRYPT_INTERVAL: %w", err)
+	}
+	cfg := SecretsConfig{ReencryptInterval: interval}
+
+	active := os.Getenv("SECRETS_MASTER_KEY")
+	retired := os.Getenv("SECRETS_RETIRED_KEYS")
+	if active == "" {
+		if retired != "" {
+			return SecretsConfig{}, fmt.Errorf("SECRETS_RETIRED_KEYS requires SECRETS_MASTER_KEY")
+		}
+		return cfg, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, raw := range append([]string{active}, strings.Split(retired, ",")...) {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		key, err := parseMasterKey(raw)
+		if err != nil {
+			return SecretsConfig{}, err
+		}
+		if seen[key.ID] {
+			return SecretsConfig{}, fmt.Errorf("duplicate master key id %q", key.ID)
+		}
+		seen[key.ID] = true
+		cfg.MasterKeys = append(cfg.MasterKeys, key)
+	}
+	return cfg, nil
+}
+
+func parseMasterKey(raw string) (MasterKey, error) {
+	id, encoded, ok := strings.Cut(raw, ":")
+	if !ok || !masterKeyID.MatchString(id) {
+		return MasterKey{}, fmt.Errorf("master key must be id:base64 with an id of letters, digits, '-' or '_'")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return MasterKey{}, fmt.Errorf("master key %q is not valid base64", id)
+	}
+	if len(key) != 32 {
+		return MasterKey{}, fmt.Errorf("master key %q must be 32 bytes, got %d", id, len(key))
+	}
+	return MasterKey{ID: id, Key: key}, nil
+}
+
+func httpConfig() (HTTPConfig, error) {
+	timeout, err := time.ParseDuration(getEnvOrDefault("HTTP_REQUEST_TIMEOUT", "30s"))
+	if err != nil || timeout < 0 {
+		return HTTPConfig{}, fmt.Errorf("invalid HTTP_REQUEST_TIMEOUT: %q", os.Getenv("HTTP_REQUEST_TIMEOUT"))
+	}
+	return HTTPConfig{Port: getEnvOrDefault("PORT", "8080"), RequestTimeout: timeout}, nil
+}
+
+func cacheConfig() (CacheConfig, error) {
+	size, err := strconv.Atoi(getEnvOrDefault("CACHE_SIZE", "10000"))
+	if err != nil || size < 0 {
+		return CacheConfig{}, fmt.Errorf("invalid CACHE_SIZE: %q", os.Getenv("CACHE_SIZE"))
+	}
+	ttl, err := time.ParseDuration(getEnvOrDefault("CACHE_TTL", "30s"))
+	if err != nil || ttl <= 0 {
+		return CacheConfig{}, fmt.Errorf("invalid CACHE_TTL: %q", os.Getenv("CACHE_TTL"))
+	}
+	return CacheConfig{Size: size, TTL: ttl}, nil
+}
+
+func authConfig() (AuthConfig, error) {
+	enabled, err := strconv.ParseBool(getEnvOrDefault("AUTH_ENABLED", "false"))
+	if err != nil {
+		return AuthConfig{}, fmt.Errorf("invalid AUTH_ENABLED: %w", err)
+	}
+	oidc, err := oidcConfig()
+	if err != nil {
+		return AuthConfig{}, err
+	}
+	return AuthConfig{Enabled: enabled, AdminToken: os.Getenv("AUTH_ADMIN_TOKEN"), OIDC: oidc}, nil
+}
+
+func oidcConfig() (OIDCConfig, error) {
+	cfg := OIDCConfig{
+		JWKSURL:     os.Getenv("OIDC_JWKS_URL"),
+		JWKSFile:    os.Getenv("OIDC_JWKS_FILE"),
+		Issuer:      os.Getenv("OIDC_ISSUER"),
+		Audience:    os.Getenv("OIDC_AUDIENCE"),
+		NameClaim:   getEnvOrDefault("OIDC_NAME_CLAIM", "email"),
+		GroupsClaim: getEnvOrDefault("OIDC_GROUPS_CLAIM", "groups"),
+	}
+	var err error
+	if cfg.ClockSkew, err = time.ParseDuration(getEnvOrDefault("OIDC_CLOCK_SKEW", "1m")); err != nil || cfg.ClockSkew < 0 {
+		return OIDCConfig{}, fmt.Errorf("invalid OIDC_CLOCK_SKEW: %q", os.Getenv("OIDC_CLOCK_SKEW"))
+	}
+	if cfg.JWKSRefresh, err = time.ParseDuration(getEnvOrDefault("OIDC_JWKS_REFRESH", "15m")); err != nil || cfg.JWKSRefresh <= 0 {
+		return OIDCConfig{}, fmt.Errorf("invalid OIDC_JWKS_REFRESH: %q", os.Getenv("OIDC_JWKS_REFRESH"))
+	}
+	if cfg.GroupGrants, err = parseGroupGrants(os.Getenv("OIDC_GROUP_GRANTS")); err != nil {
+		return OIDCConfig{}, err
+	}
+
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+	if cfg.JWKSURL != "" && cfg.JWKSFile != "" {
+		return OIDCConfig{}, fmt.Errorf("set either OIDC_JWKS_URL or OIDC_JWKS_FILE, not both")
+	}
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return OIDCConfig{}, fmt.Errorf("OIDC_ISSUER and OIDC_AUDIENCE are required with a JWKS")
+	}
+	return cfg, nil
+}
+
+// parseGroupGrants reads group=grant,grant;group=grant where a grant is
+// env:role or env/key-prefix:role, for example
+// "platform=*:admin;billing=prod/billing.:writer,staging:reader".
+func parseGroupGrants(raw string) (map[string][]GroupGrant, error) {
+	grants := make(map[string][]GroupGrant)
+	for _, entry := range strings.Split(raw, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		group, list, ok := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid OIDC_GROUP_GRANTS entry %q: want group=env:role", entry)
+		}
+		for _, item := range strings.Split(list, ",") {
+			scope, role, ok := cutLast(strings.TrimSpace(item), ":")
+			if !ok || scope == "" || role == "" {
+				return nil, fmt.Errorf("invalid OIDC_GROUP_GRANTS grant %q for group %q: want env:role", item, group)
+			}
+			environment, prefix, _ := strings.Cut(scope, "/")
+			grants[group] = append(grants[group], GroupGrant{Environment: environment, KeyPrefix: prefix, Role: role})
+		}
+	}
+	return grants, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
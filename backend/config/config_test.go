@@ -1,13 +1,18 @@
 package config
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadUsesDatabaseURLAndPort(t *testing.T) {
 	t.Setenv("DATABASE_URL", "postgres://user:pass@db:5432/configs?sslmode=disable")
 	t.Setenv("PORT", "9090")
+	t.Setenv("GRPC_PORT", "")
 	t.Setenv("DB_USER", "")
 	t.Setenv("DB_PASSWORD", "")
 	t.Setenv("DB_NAME", "")
@@ -23,6 +28,9 @@ func TestLoadUsesDatabaseURLAndPort(t *testing.T) {
 	if cfg.HTTP.Port != "9090" {
 		t.Fatalf("unexpected port: %s", cfg.HTTP.Port)
 	}
+	if cfg.GRPC.Port != "50051" {
+		t.Fatalf("unexpected gRPC port: %s", cfg.GRPC.Port)
+	}
 }
 
 func TestLoadBuildsDatabaseDSNFromParts(t *testing.T) {
@@ -82,6 +90,83 @@ func TestDatabaseDSNUsesDefaultHostAndPort(t *testing.T) {
 	}
 }
 
+func TestDatabaseConfigAutoMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "default", want: true},
+		{name: "disabled", value: "false", want: false},
+		{name: "invalid", value: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://user:pass@db:5432/configs")
+			t.Setenv("DB_AUTO_MIGRATE", tt.value)
+
+			cfg, err := databaseConfig()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "DB_AUTO_MIGRATE") {
+					t.Fatalf("databaseConfig() error = %v", err)
+				}
+				return
+			}
+			if err != nil || cfg.AutoMigrate != tt.want {
+				t.Fatalf("databaseConfig() = %#v, %v", cfg, err)
+			}
+		})
+	}
+}
+
+func TestDatabaseConfigDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		want    DatabaseConfig
+		wantErr bool
+	}{
+		{name: "default", want: DatabaseConfig{Driver: DriverPostgres, DSN: "postgres://user:pass@db:5432/configs", AutoMigrate: true}},
+		{name: "sqlite", driver: "sqlite", want: DatabaseConfig{Driver: DriverSQLite, SQLitePath: "/data/configs.db", AutoMigrate: true}},
+		{name: "memory", driver: "memory", want: DatabaseConfig{Driver: DriverMemory, SnapshotFile: "/data/configs.json"}},
+		{name: "unknown", driver: "mysql", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://user:pass@db:5432/configs")
+			t.Setenv("STORAGE_DRIVER", tt.driver)
+			t.Setenv("MEMORY_SNAPSHOT_FILE", "/data/configs.json")
+			t.Setenv("SQLITE_PATH", "/data/configs.db")
+
+			cfg, err := databaseConfig()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "STORAGE_DRIVER") {
+					t.Fatalf("databaseConfig() error = %v", err)
+				}
+				return
+			}
+			if err != nil || cfg != tt.want {
+				t.Fatalf("databaseConfig() = %#v, %v, want %#v", cfg, err, tt.want)
+			}
+		})
+	}
+
+	for _, driver := range []string{DriverSQLite, DriverMemory} {
+		t.Run(driver+" needs no Postgres settings", func(t *testing.T) {
+			for _, key := range []string{"DATABASE_URL", "DB_USER", "DB_PASSWORD", "DB_NAME", "SQLITE_PATH"} {
+				t.Setenv(key, "")
+			}
+			t.Setenv("STORAGE_DRIVER", driver)
+
+			cfg, err := Load()
+			if err != nil || cfg.Database.Driver != driver {
+				t.Fatalf("Load() = %#v, %v", cfg, err)
+			}
+		})
+	}
+}
+
 func TestGetEnvOrDefault(t *testing.T) {
 	t.Setenv("CONFIG_TEST_VALUE", "configured")
 
@@ -105,3 +190,206 @@ func TestFirstEnv(t *testing.T) {
 		t.Fatalf("firstEnv() for missing key = %q", got)
 	}
 }
+
+func TestSecretsConfig(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	key2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	tests := []struct {
+		name     string
+		active   string
+		retired  string
+		interval string
+		wantIDs  []string
+		wantErr  string
+	}{
+		{name: "disabled"},
+		{name: "active only", active: "k2:" + key2, wantIDs: []string{"k2"}},
+		{name: "with retired keys", active: "k2:" + key2, retired: "k1:" + key1 + ", ", wantIDs: []string{"k2", "k1"}},
+		{name: "retired without active", retired: "k1:" + key1, wantErr: "requires SECRETS_MASTER_KEY"},
+		{name: "missing id", active: key2, wantErr: "id:base64"},
+		{name: "invalid base64", active: "k2:not-base64", wantErr: "not valid base64"},
+		{name: "short key", active: "k2:" + base64.StdEncoding.EncodeToString([]byte("short")), wantErr: "must be 32 bytes"},
+		{name: "duplicate id", active: "k1:" + key2, retired: "k1:" + key1, wantErr: "duplicate master key id"},
+		{name: "invalid interval", interval: "soon", wantErr: "SECRETS_REENCRYPT_INTERVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SECRETS_MASTER_KEY", tt.active)
+			t.Setenv("SECRETS_RETIRED_KEYS", tt.retired)
+			t.Setenv("SECRETS_REENCRYPT_INTERVAL", tt.interval)
+
+			cfg, err := secretsConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("secretsConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("secretsConfig() error = %v", err)
+			}
+			if cfg.ReencryptInterval != time.Hour {
+				t.Fatalf("ReencryptInterval = %v, want default 1h", cfg.ReencryptInterval)
+			}
+			var ids []string
+			for _, key := range cfg.MasterKeys {
+				ids = append(ids, key.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Fatalf("master key ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestAuthConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     string
+		wantEnabled bool
+		wantErr     bool
+	}{
+		{name: "disabled by default"},
+		{name: "enabled", enabled: "true", wantEnabled: true},
+		{name: "invalid", enabled: "maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AUTH_ENABLED", tt.enabled)
+			t.Setenv("AUTH_ADMIN_TOKEN", "bootstrap")
+
+			cfg, err := authConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("authConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Enabled != tt.wantEnabled || cfg.AdminToken != "bootstrap" {
+				t.Fatalf("authConfig() = %#v", cfg)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", want: 30 * time.Second},
+		{name: "disabled", timeout: "0s", want: 0},
+		{name: "custom", timeout: "2m", want: 2 * time.Minute},
+		{name: "negative", timeout: "-1s", wantErr: true},
+		{name: "invalid", timeout: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PORT", "")
+			t.Setenv("HTTP_REQUEST_TIMEOUT", tt.timeout)
+
+			cfg, err := httpConfig()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "HTTP_REQUEST_TIMEOUT") {
+					t.Fatalf("httpConfig() error = %v", err)
+				}
+				return
+			}
+			if err != nil || cfg != (HTTPConfig{Port: "8080", RequestTimeout: tt.want}) {
+				t.Fatalf("httpConfig() = %#v, %v", cfg, err)
+			}
+		})
+	}
+}
+
+func TestCacheConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    string
+		ttl     string
+		want    CacheConfig
+		wantErr string
+	}{
+		{name: "defaults", want: CacheConfig{Size: 10000, TTL: 30 * time.Second}},
+		{name: "disabled", size: "0", ttl: "5m", want: CacheConfig{Size: 0, TTL: 5 * time.Minute}},
+		{name: "negative size", size: "-1", wantErr: "CACHE_SIZE"},
+		{name: "zero ttl", ttl: "0s", wantErr: "CACHE_TTL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CACHE_SIZE", tt.size)
+			t.Setenv("CACHE_TTL", tt.ttl)
+
+			cfg, err := cacheConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("cacheConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || cfg != tt.want {
+				t.Fatalf("cacheConfig() = %#v, %v, want %#v", cfg, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "disabled"},
+		{name: "jwks file", env: map[string]string{"OIDC_JWKS_FILE": "/etc/jwks.json", "OIDC_ISSUER": "https://sso", "OIDC_AUDIENCE": "config-service"}},
+		{name: "both sources", env: map[string]string{"OIDC_JWKS_FILE": "/etc/jwks.json", "OIDC_JWKS_URL": "https://sso/jwks", "OIDC_ISSUER": "https://sso", "OIDC_AUDIENCE": "config-service"}, wantErr: "not both"},
+		{name: "missing audience", env: map[string]string{"OIDC_JWKS_URL": "https://sso/jwks", "OIDC_ISSUER": "https://sso"}, wantErr: "OIDC_AUDIENCE"},
+		{name: "invalid skew", env: map[string]string{"OIDC_CLOCK_SKEW": "-1s"}, wantErr: "OIDC_CLOCK_SKEW"},
+		{name: "invalid refresh", env: map[string]string{"OIDC_JWKS_REFRESH": "0s"}, wantErr: "OIDC_JWKS_REFRESH"},
+		{name: "invalid grants", env: map[string]string{"OIDC_GROUP_GRANTS": "platform"}, wantErr: "OIDC_GROUP_GRANTS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"OIDC_JWKS_URL", "OIDC_JWKS_FILE", "OIDC_ISSUER", "OIDC_AUDIENCE", "OIDC_CLOCK_SKEW", "OIDC_JWKS_REFRESH", "OIDC_GROUP_GRANTS"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := oidcConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("oidcConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("oidcConfig() error = %v", err)
+			}
+			if cfg.ClockSkew != time.Minute || cfg.NameClaim != "email" || cfg.GroupsClaim != "groups" {
+				t.Fatalf("oidcConfig() defaults = %#v", cfg)
+			}
+		})
+	}
+}
+
+func TestParseGroupGrants(t *testing.T) {
+	grants, err := parseGroupGrants("platform=*:admin; billing=prod/billing.:writer, staging:reader")
+	if err != nil {
+		t.Fatalf("parseGroupGrants() error = %v", err)
+	}
+	want := map[string][]GroupGrant{
+		"platform": {{Environment: "*", Role: "admin"}},
+		"billing":  {{Environment: "prod", KeyPrefix: "billing.", Role: "writer"}, {Environment: "staging", Role: "reader"}},
+	}
+	if fmt.Sprint(grants) != fmt.Sprint(want) {
+		t.Fatalf("parseGroupGrants() = %v, want %v", grants, want)
+	}
+
+	for _, raw := range []string{"=prod:reader", "billing=prod", "billing=:reader", "billing=prod:"} {
+		if _, err := parseGroupGrants(raw); err == nil {
+			t.Fatalf("parseGroupGrants(%q) succeeded", raw)
+		}
+	}
+}
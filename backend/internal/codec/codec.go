@@ -0,0 +1,101 @@
+// Package codec converts flat key/value documents to and from the formats
+// accepted by the import and export endpoints.
+package codec
+
+import (
+	"config-service/backend/internal/model"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type Format string
+
+const (
+	FormatJSON       Format = "json"
+	FormatYAML       Format = "yaml"
+	FormatDotenv     Format = "dotenv"
+	FormatProperties Format = "properties"
+)
+
+var (
+	ErrUnknownFormat   = errors.New("unknown format")
+	ErrInvalidDocument = errors.New("invalid document")
+	ErrUnsupportedKey  = errors.New("key is not supported by the format")
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatJSON, FormatYAML, FormatDotenv, FormatProperties:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatYAML:
+		return "application/yaml"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+func Encode(w io.Writer, format Format, entries []model.KeyValue) error {
+	switch format {
+	case FormatJSON:
+		return encodeJSON(w, entries)
+	case FormatYAML:
+		return encodeYAML(w, entries)
+	case FormatDotenv:
+		return encodeDotenv(w, entries)
+	case FormatProperties:
+		return encodeProperties(w, entries)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// Decode returns the pairs in document order; a repeated key keeps its last
+// value.
+func Decode(r io.Reader, format Format) ([]model.KeyValue, error) {
+	var entries []model.KeyValue
+	var err error
+	switch format {
+	case FormatJSON:
+		entries, err = decodeJSON(r)
+	case FormatYAML:
+		entries, err = decodeYAML(r)
+	case FormatDotenv:
+		entries, err = decodeDotenv(r)
+	case FormatProperties:
+		entries, err = decodeProperties(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dedupe(entries), nil
+}
+
+func dedupe(entries []model.KeyValue) []model.KeyValue {
+	index := make(map[string]int, len(entries))
+	result := make([]model.KeyValue, 0, len(entries))
+	for _, entry := range entries {
+		if i, ok := index[entry.Key]; ok {
+			result[i].Value = entry.Value
+			continue
+		}
+		index[entry.Key] = len(result)
+		result = append(result, entry)
+	}
+	return result
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidDocument, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"bytes"
+	"config-service/backend/internal/model"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	values := []model.KeyValue{
+		{Key: "db.host", Value: "db-1.internal"},
+		{Key: "feature-flag", Value: "true"},
+		{Key: "greeting", Value: "hello \"world\"\nsecond line\twith tab"},
+		{Key: "path/with:colon", Value: " leading space and # hash"},
+		{Key: "empty", Value: ""},
+		{Key: "unicode", Value: "привет"},
+		{Key: "number", Value: "0012"},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatYAML, FormatDotenv, FormatProperties} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, format, values); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			got, err := Decode(&buf, format)
+			if err != nil {
+				t.Fatalf("Decode() error = %v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(got, values) {
+				t.Fatalf("Decode(Encode()) = %#v, want %#v", got, values)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		input   string
+		want    []model.KeyValue
+		wantErr error
+	}{
+		{
+			name:   "json scalars keep spelling",
+			format: FormatJSON,
+			input:  `{"price": 1.50, "enabled": true, "name": "api"}`,
+			want:   []model.KeyValue{{Key: "price", Value: "1.50"}, {Key: "enabled", Value: "true"}, {Key: "name", Value: "api"}},
+		},
+		{name: "json nested", format: FormatJSON, input: `{"db": {"host": "x"}}`, wantErr: ErrInvalidDocument},
+		{name: "json array", format: FormatJSON, input: `["a"]`, wantErr: ErrInvalidDocument},
+		{
+			name:   "yaml scalars",
+			format: FormatYAML,
+			input:  "replicas: 3\nratio: 0.5\nenabled: yes\nname: api\n",
+			want:   []model.KeyValue{{Key: "replicas", Value: "3"}, {Key: "ratio", Value: "0.5"}, {Key: "enabled", Value: "true"}, {Key: "name", Value: "api"}},
+		},
+		{name: "yaml nested", format: FormatYAML, input: "db:\n  host: x\n", wantErr: ErrInvalidDocument},
+		{
+			name:   "dotenv",
+			format: FormatDotenv,
+			input:  "# comment\nexport API_URL=https://example.com # trailing\nQUOTED='a \"b\"'\nESCAPED=\"line\\nnext\"\n\nREPEATED=1\nREPEATED=2\n",
+			want: []model.KeyValue{
+				{Key: "API_URL", Value: "https://example.com"},
+				{Key: "QUOTED", Value: `a "b"`},
+				{Key: "ESCAPED", Value: "line\nnext"},
+				{Key: "REPEATED", Value: "2"},
+			},
+		},
+		{name: "dotenv without separator", format: FormatDotenv, input: "JUST_A_KEY\n", wantErr: ErrInvalidDocument},
+		{name: "dotenv unterminated", format: FormatDotenv, input: "A=\"open\n", wantErr: ErrInvalidDocument},
+		{
+			name:   "properties",
+			format: FormatProperties,
+			input:  "! comment\n# comment\ndb.host = localhost\ndb.port:5432\nmessage hello world\nlong = first \\\n    second\nunicode=\\u0041\n",
+			want: []model.KeyValue{
+				{Key: "db.host", Value: "localhost"},
+				{Key: "db.port", Value: "5432"},
+				{Key: "message", Value: "hello world"},
+				{Key: "long", Value: "first second"},
+				{Key: "unicode", Value: "A"},
+			},
+		},
+		{name: "properties bad escape", format: FormatProperties, input: "key=\\u12\n", wantErr: ErrInvalidDocument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(strings.NewReader(tt.input), tt.format)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDotenvRejectsUnsupportedKeys(t *testing.T) {
+	err := Encode(&bytes.Buffer{}, FormatDotenv, []model.KeyValue{{Key: "a=b", Value: "c"}})
+	if !errors.Is(err, ErrUnsupportedKey) {
+		t.Fatalf("Encode() error = %v, want %v", err, ErrUnsupportedKey)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, FormatProperties, []model.KeyValue{{Key: "a=b", Value: "c"}}); err != nil || buf.String() != "a\\=b=c\n" {
+		t.Fatalf("Encode(properties) = %q, %v", buf.String(), err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("properties"); err != nil || format != FormatProperties {
+		t.Fatalf("ParseFormat(properties) = %q, %v", format, err)
+	}
+	if _, err := ParseFormat("toml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("ParseFormat(toml) error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
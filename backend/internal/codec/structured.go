@@ -0,0 +1,146 @@
+package codec
+
+import (
+	"bytes"
+	"config-service/backend/internal/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+func encodeJSON(w io.Writer, entries []model.KeyValue) error {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, entry := range entries {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(entry.Key)
+		value, _ := json.Marshal(entry.Value)
+		fmt.Fprintf(&buf, "\n  %s: %s", key, value)
+	}
+	if len(entries) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// decodeJSON accepts a flat object. Numbers and booleans are kept in their
+// source spelling so "1.50" does not turn into "1.5".
+func decodeJSON(r io.Reader) ([]model.KeyValue, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, invalid("expected a JSON object")
+	}
+
+	var entries []model.KeyValue
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, invalid("%v", err)
+		}
+		key := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, invalid("%v", err)
+		}
+		value, err := jsonScalar(raw)
+		if err != nil {
+			return nil, invalid("key %q: %v", key, err)
+		}
+		entries = append(entries, model.KeyValue{Key: key, Value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, invalid("%v", err)
+	}
+	return entries, nil
+}
+
+func jsonScalar(raw json.RawMessage) (string, error) {
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case len(trimmed) == 0:
+		return "", fmt.Errorf("missing value")
+	case trimmed[0] == '"':
+		var value string
+		err := json.Unmarshal(trimmed, &value)
+		return value, err
+	case trimmed[0] == '{' || trimmed[0] == '[' || string(trimmed) == "null":
+		return "", fmt.Errorf("value must be a string, number or boolean")
+	default:
+		return string(trimmed), nil
+	}
+}
+
+func encodeYAML(w io.Writer, entries []model.KeyValue) error {
+	document := make(yaml.MapSlice, len(entries))
+	for i, entry := range entries {
+		document[i] = yaml.MapItem{Key: entry.Key, Value: entry.Value}
+	}
+	if len(document) == 0 {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}
+	out, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func decodeYAML(r io.Reader) ([]model.KeyValue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	entries := make([]model.KeyValue, 0, len(document))
+	for _, item := range document {
+		key, err := yamlScalar(item.Key)
+		if err != nil {
+			return nil, invalid("key %v: %v", item.Key, err)
+		}
+		value, err := yamlScalar(item.Value)
+		if err != nil {
+			return nil, invalid("key %q: %v", key, err)
+		}
+		entries = append(entries, model.KeyValue{Key: key, Value: value})
+	}
+	return entries, nil
+}
+
+func yamlScalar(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("value must be a string, number or boolean")
+	}
+}
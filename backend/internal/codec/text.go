@@ -0,0 +1,274 @@
+package codec
+
+import (
+	"bufio"
+	"config-service/backend/internal/model"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// encodeDotenv always double-quotes values. Keys may contain dots and dashes,
+// which stricter dotenv parsers reject, but not '=' or whitespace.
+func encodeDotenv(w io.Writer, entries []model.KeyValue) error {
+	for _, entry := range entries {
+		if strings.ContainsAny(entry.Key, "= \t\r\n") || strings.HasPrefix(entry.Key, "#") {
+			return fmt.Errorf("%w: key %q cannot be written as dotenv", ErrUnsupportedKey, entry.Key)
+		}
+	}
+
+	buf := bufio.NewWriter(w)
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "%s=%s\n", entry.Key, quoteDotenv(entry.Value))
+	}
+	return buf.Flush()
+}
+
+func quoteDotenv(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func decodeDotenv(r io.Reader) ([]model.KeyValue, error) {
+	var entries []model.KeyValue
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, invalid("line %d: expected KEY=value", number)
+		}
+		value, err := unquoteDotenv(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, invalid("line %d: %v", number, err)
+		}
+		entries = append(entries, model.KeyValue{Key: key, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func unquoteDotenv(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				if rest := strings.TrimSpace(raw[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+					return "", fmt.Errorf("unexpected text after closing quote")
+				}
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+func encodeProperties(w io.Writer, entries []model.KeyValue) error {
+	buf := bufio.NewWriter(w)
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "%s=%s\n", escapeProperty(entry.Key, true), escapeProperty(entry.Value, false))
+	}
+	return buf.Flush()
+}
+
+func escapeProperty(s string, key bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case (r == '=' || r == ':' || r == ' ') && key,
+			r == ' ' && i == 0,
+			(r == '#' || r == '!') && i == 0:
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// decodeProperties follows java.util.Properties: '#' and '!' comments,
+// '=', ':' or whitespace separators, backslash escapes and line
+// continuations.
+func decodeProperties(r io.Reader) ([]model.KeyValue, error) {
+	var entries []model.KeyValue
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var logical strings.Builder
+	continued := false
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if !continued && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		if trailingBackslashes(line)%2 == 1 {
+			logical.WriteString(line[:len(line)-1])
+			continued = true
+			continue
+		}
+		logical.WriteString(line)
+		continued = false
+
+		entry, err := parsePropertyLine(logical.String())
+		logical.Reset()
+		if err != nil {
+			return nil, invalid("line %d: %v", number, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if continued {
+		entry, err := parsePropertyLine(logical.String())
+		if err != nil {
+			return nil, invalid("last line: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func trailingBackslashes(line string) int {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count
+}
+
+func parsePropertyLine(line string) (model.KeyValue, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '=' || line[i] == ':' || line[i] == ' ' || line[i] == '\t' || line[i] == '\f' {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return model.KeyValue{}, err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return model.KeyValue{}, err
+	}
+	return model.KeyValue{Key: key, Value: value}, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -2,13 +2,21 @@ package di
 
 import (
 	"config-service/backend/config"
+	"config-service/backend/internal/grpcapi"
 	"config-service/backend/internal/handler"
+	"config-service/backend/internal/infrastructure/cache"
 	"config-service/backend/internal/infrastructure/database"
+	"config-service/backend/internal/infrastructure/memory"
+	"config-service/backend/internal/infrastructure/secrets"
+	"config-service/backend/internal/infrastructure/sqlite"
 	"config-service/backend/internal/repository"
 	"config-service/backend/internal/service"
+	"config-service/backend/migrations"
 	"config-service/backend/pkg/metrics"
+	"config-service/backend/pkg/middleware"
 	"config-service/backend/pkg/server"
 	"context"
+	"fmt"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -19,27 +27,149 @@ func NewApp() *fx.App {
 		fx.Provide(
 			config.Load,
 			zap.NewProduction,
-			provideDatabaseConnection,
+			provideStorage,
+			secrets.NewKeyring,
+			provideConfigCache,
 			provideConfigRepository,
+			provideSecretStore,
+			provideTokenRepository,
+			provideRotator,
+			service.NewBroker,
 			provideConfigService,
 			provideConfigHandler,
+			service.NewEnvironmentService,
+			handler.NewEnvironmentHandler,
+			service.NewTokenService,
+			handler.NewTokenHandler,
+			middleware.NewJWTAuthenticator,
+			provideAuditRepository,
+			service.NewAuditService,
+			handler.NewAuditHandler,
 			server.NewServer,
+			grpcapi.NewConfigServer,
+			server.NewGRPCServer,
 			metrics.NewMetrics,
 		),
 		fx.Invoke(registerLifecycle),
 	)
 }
 
-func provideDatabaseConnection(cfg *config.Config) (database.Connection, error) {
-	return database.NewPostgresConnection(cfg.Database.DSN)
+// storage holds the repositories of the configured driver. The connection and
+// migrator are nil with the memory driver, the change listener with every
+// driver but Postgres.
+type storage struct {
+	configs  repository.ConfigRepository
+	tokens   repository.TokenRepository
+	secrets  repository.SecretStore
+	conn     database.Connection
+	migrator *database.Migrator
+	listener *database.ChangeListener
 }
 
-func provideConfigRepository(conn database.Connection, m *metrics.Metrics) (repository.ConfigRepository, error) {
-	return database.NewPostgresRepository(conn.GetDB(), m)
+func provideStorage(cfg *config.Config, m *metrics.Metrics, logger *zap.Logger) (*storage, error) {
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		repo, err := memory.NewRepository(cfg.Database.SnapshotFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load memory snapshot: %w", err)
+		}
+		return &storage{configs: repo, tokens: repo, secrets: repo}, nil
+	case config.DriverSQLite:
+		conn, err := sqlite.NewConnection(cfg.Database.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		store, err := newSQLiteStorage(conn, m)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return store, nil
+	}
+
+	conn, err := database.NewPostgresConnection(cfg.Database.DSN)
+	if err != nil {
+		return nil, err
+	}
+	store, err := newPostgresStorage(conn, cfg.Database.DSN, m, logger)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func newSQLiteStorage(conn database.Connection, m *metrics.Metrics) (*storage, error) {
+	repo, err := sqlite.NewRepository(conn.GetDB(), m)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := sqlite.NewMigrator(conn.GetDB())
+	if err != nil {
+		return nil, err
+	}
+	return &storage{configs: repo, tokens: repo, secrets: repo, conn: conn, migrator: migrator}, nil
+}
+
+func newPostgresStorage(conn database.Connection, dsn string, m *metrics.Metrics, logger *zap.Logger) (*storage, error) {
+	configs, err := database.NewPostgresRepository(conn.GetDB(), m)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := database.NewPostgresTokenRepository(conn.GetDB(), m)
+	if err != nil {
+		return nil, err
+	}
+	secretStore, err := database.NewPostgresSecretStore(conn.GetDB(), m)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := database.NewMigrator(conn.GetDB(), migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return &storage{
+		configs:  configs,
+		tokens:   tokens,
+		secrets:  secretStore,
+		conn:     conn,
+		migrator: migrator,
+		listener: database.NewChangeListener(dsn, logger),
+	}, nil
+}
+
+func provideConfigCache(store *storage, cfg *config.Config, m *metrics.Metrics) *cache.Repository {
+	return cache.NewRepository(store.configs, cfg.Cache, m)
+}
+
+// provideConfigRepository decrypts secrets above the cache, which therefore
+// only holds ciphertext.
+func provideConfigRepository(configCache *cache.Repository, keyring *secrets.Keyring) repository.ConfigRepository {
+	return secrets.NewRepository(configCache, keyring)
+}
+
+// provideRotator drops the cache after re-encrypting values, which it holds
+// as ciphertext.
+func provideRotator(store repository.SecretStore, keyring *secrets.Keyring, configCache *cache.Repository, cfg *config.Config, logger *zap.Logger) *secrets.Rotator {
+	return secrets.NewRotator(store, keyring, configCache, cfg, logger)
+}
+
+func provideSecretStore(store *storage) repository.SecretStore {
+	return store.secrets
+}
+
+func provideTokenRepository(store *storage) repository.TokenRepository {
+	return store.tokens
+}
+
+// provideAuditRepository reads the audit log through the config repository,
+// which writes it.
+func provideAuditRepository(repo repository.ConfigRepository) repository.AuditRepository {
+	return repo
 }
 
-func provideConfigService(repo repository.ConfigRepository) service.ConfigService {
-	return service.NewConfigService(repo)
+func provideConfigService(repo repository.ConfigRepository, broker *service.Broker) service.ConfigService {
+	return service.NewConfigService(repo, broker)
 }
 
 func provideConfigHandler(svc service.ConfigService) *handler.ConfigHandler {
@@ -48,19 +178,61 @@ func provideConfigHandler(svc service.ConfigService) *handler.ConfigHandler {
 
 func registerLifecycle(
 	lc fx.Lifecycle,
+	cfg *config.Config,
 	server *server.Server,
-	conn database.Connection,
+	grpcServer *server.GRPCServer,
+	store *storage,
+	keyring *secrets.Keyring,
+	rotator *secrets.Rotator,
+	repo repository.ConfigRepository,
+	configCache *cache.Repository,
+	broker *service.Broker,
 	logger *zap.Logger,
 ) {
+	// Loading notified revisions outlives the start hook's context and is
+	// canceled on stop instead.
+	changes, stopChanges := context.WithCancel(context.Background())
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			if store.migrator != nil && cfg.Database.AutoMigrate {
+				if err := migrateUp(ctx, store.migrator, logger); err != nil {
+					return err
+				}
+			}
+			if err := keyring.Load(ctx); err != nil {
+				return fmt.Errorf("failed to load secret data keys: %w", err)
+			}
+			// Other replicas only share changes through Postgres.
+			if store.listener != nil {
+				if err := store.listener.Start(publishRemoteChange(changes, repo, configCache, broker, logger), configCache.InvalidateAll); err != nil {
+					return err
+				}
+			}
+			if !cfg.Auth.Enabled {
+				logger.Warn("authentication is disabled, set AUTH_ENABLED=true outside local development")
+			}
+			rotator.Start()
 			server.Start()
+			grpcServer.Start()
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			server.GracefulShutdown(5)
+			if err := server.Shutdown(ctx); err != nil {
+				logger.Error("failed to stop HTTP server gracefully", zap.Error(err))
+			}
+			grpcServer.Stop(ctx)
+			rotator.Stop()
+			stopChanges()
+			if store.listener != nil {
+				if err := store.listener.Close(); err != nil {
+					logger.Error("failed to close change listener", zap.Error(err))
+				}
+			}
+			if store.conn == nil {
+				return nil
+			}
 			logger.Info("closing database connection")
-			if err := conn.Close(); err != nil {
+			if err := store.conn.Close(); err != nil {
 				logger.Error("failed to close database connection", zap.Error(err))
 				return err
 			}
@@ -68,3 +240,30 @@ func registerLifecycle(
 		},
 	})
 }
+
+func migrateUp(ctx context.Context, migrator *database.Migrator, logger *zap.Logger) error {
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		logger.Info("applied migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// publishRemoteChange drops the changed key from the cache before watchers
+// hear about it, so that they read the new value. When the revision cannot be
+// loaded the changed key is unknown and the whole cache is dropped.
+func publishRemoteChange(ctx context.Context, repo repository.ConfigRepository, configCache *cache.Repository, broker *service.Broker, logger *zap.Logger) func(database.ChangeNotification) {
+	return func(notification database.ChangeNotification) {
+		revision, err := repo.GetRevision(ctx, notification.Revision)
+		if err != nil {
+			logger.Warn("failed to load notified revision", zap.Int64("revision", notification.Revision), zap.Error(err))
+			configCache.InvalidateAll()
+			return
+		}
+		configCache.Invalidate(revision.Environment, revision.Key)
+		broker.Publish(revision)
+	}
+}
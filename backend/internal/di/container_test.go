@@ -1,40 +1,112 @@
 package di
 
 import (
+	"config-service/backend/config"
+	"config-service/backend/internal/infrastructure/cache"
 	"config-service/backend/internal/infrastructure/database"
+	"config-service/backend/internal/infrastructure/secrets"
 	"config-service/backend/internal/model"
 	"config-service/backend/internal/repository"
+	"config-service/backend/internal/service"
 	"config-service/backend/pkg/metrics"
+	"context"
 	"database/sql"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
 )
 
 type diStubRepository struct{}
 
-func (diStubRepository) Create(*model.Config) error {
-	return nil
+func (diStubRepository) Create(context.Context, *model.Config) (*model.Revision, error) {
+	return &model.Revision{}, nil
+}
+
+func (diStubRepository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
+	return &model.Config{Environment: environment, Key: key, Value: "value"}, nil
+}
+
+func (diStubRepository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
+	return []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, nil
+}
+
+func (diStubRepository) ListConfigs(ctx context.Context, environment string, _ model.ConfigQuery) ([]*model.Config, error) {
+	return []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, nil
+}
+
+func (diStubRepository) CountConfigs(context.Context, string, model.ConfigQuery) (int, error) {
+	return 1, nil
+}
+
+func (diStubRepository) Update(context.Context, *model.Config) (*model.Revision, error) {
+	return &model.Revision{}, nil
+}
+
+func (diStubRepository) Delete(context.Context, string, string, string, int64) (*model.Revision, error) {
+	return &model.Revision{}, nil
+}
+
+func (diStubRepository) Exists(context.Context, string, string) (bool, error) {
+	return false, nil
 }
 
-func (diStubRepository) Get(environment, key string) (*model.Config, error) {
+func (diStubRepository) GetAt(ctx context.Context, environment, key string, _ time.Time) (*model.Config, error) {
 	return &model.Config{Environment: environment, Key: key, Value: "value"}, nil
 }
 
-func (diStubRepository) GetAll(environment string) ([]*model.Config, error) {
+func (diStubRepository) GetAllAt(ctx context.Context, environment string, _ time.Time) ([]*model.Config, error) {
 	return []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, nil
 }
 
-func (diStubRepository) Update(*model.Config) error {
+func (diStubRepository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	return []*model.Revision{{Environment: environment, Key: key, Value: "value"}}, nil
+}
+
+func (diStubRepository) GetRevisionsSince(ctx context.Context, environment string, since int64, _ int) ([]*model.Revision, error) {
+	return []*model.Revision{{Revision: since + 1, Environment: environment, Key: "key", Value: "value"}}, nil
+}
+
+func (diStubRepository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	if revision <= 0 {
+		return nil, repository.ErrConfigNotFound
+	}
+	return &model.Revision{Revision: revision, Environment: "dev", Key: "key", Value: "value"}, nil
+}
+
+func (diStubRepository) CreateEnvironment(context.Context, *model.Environment) error {
+	return nil
+}
+
+func (diStubRepository) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	return &model.Environment{Name: name}, nil
+}
+
+func (diStubRepository) GetEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	return []*model.Environment{{Name: "dev"}}, nil
+}
+
+func (diStubRepository) UpdateEnvironment(context.Context, *model.Environment) error {
 	return nil
 }
 
-func (diStubRepository) Delete(string, string) error {
+func (diStubRepository) DeleteEnvironment(context.Context, string) error {
 	return nil
 }
 
-func (diStubRepository) Exists(string, string) (bool, error) {
-	return false, nil
+func (r diStubRepository) WithTx(ctx context.Context, fn func(repository.ConfigRepository) error) error {
+	return fn(r)
+}
+
+func (diStubRepository) AppendAudit(context.Context, *model.AuditEntry) error {
+	return nil
+}
+
+func (diStubRepository) GetAuditEntries(context.Context, model.AuditFilter) ([]*model.AuditEntry, error) {
+	return nil, nil
 }
 
 type diStubConnection struct {
@@ -67,12 +139,17 @@ func diTestMetrics() *metrics.Metrics {
 			prometheus.HistogramOpts{Name: "di_test_db_query_duration_seconds"},
 			[]string{"operation"},
 		),
+		CacheRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Name: "di_test_cache_requests_total"},
+			[]string{"result"},
+		),
+		CacheEntries: prometheus.NewGauge(prometheus.GaugeOpts{Name: "di_test_cache_entries"}),
 	}
 }
 
 func TestProviderHelpers(t *testing.T) {
 	var repo repository.ConfigRepository = diStubRepository{}
-	svc := provideConfigService(repo)
+	svc := provideConfigService(repo, service.NewBroker())
 	if svc == nil {
 		t.Fatal("provideConfigService() returned nil")
 	}
@@ -86,13 +163,110 @@ func TestProviderHelpers(t *testing.T) {
 func TestProvideConfigRepository(t *testing.T) {
 	conn := diStubConnection{db: nil}
 
-	repo, err := provideConfigRepository(conn, diTestMetrics())
+	store, err := newPostgresStorage(conn, "postgres://localhost/configs", diTestMetrics(), zap.NewNop())
 	if err != nil {
-		t.Fatalf("provideConfigRepository() error = %v", err)
+		t.Fatalf("newPostgresStorage() error = %v", err)
 	}
-	if repo == nil {
+	if store.migrator == nil || store.listener == nil || store.conn == nil {
+		t.Fatalf("newPostgresStorage() = %#v, want a migrator, listener and connection", store)
+	}
+
+	cfg := &config.Config{Cache: config.CacheConfig{Size: 10, TTL: time.Minute}}
+	configCache := provideConfigCache(store, cfg, diTestMetrics())
+	if repo := provideConfigRepository(configCache, &secrets.Keyring{}); repo == nil {
 		t.Fatal("provideConfigRepository() returned nil")
 	}
+	if secretStore := provideSecretStore(store); secretStore == nil {
+		t.Fatal("provideSecretStore() returned nil")
+	}
+	if tokens := provideTokenRepository(store); tokens == nil {
+		t.Fatal("provideTokenRepository() returned nil")
+	}
 
 	var _ database.Connection = conn
 }
+
+func TestProvideSQLiteStorage(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: config.DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "configs.db")}}
+	store, err := provideStorage(cfg, diTestMetrics(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("provideStorage() error = %v", err)
+	}
+	defer store.conn.Close()
+	if store.configs == nil || store.tokens == nil || store.secrets == nil || store.migrator == nil {
+		t.Fatalf("provideStorage() = %#v, want every repository and a migrator", store)
+	}
+	if store.listener != nil {
+		t.Fatal("provideStorage() returned a change listener for SQLite")
+	}
+
+	ctx := context.Background()
+	if _, err := store.migrator.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := store.configs.CreateEnvironment(ctx, &model.Environment{Name: "dev"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+}
+
+func TestProvideMemoryStorage(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: config.DriverMemory}}
+	store, err := provideStorage(cfg, diTestMetrics(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("provideStorage() error = %v", err)
+	}
+	if store.configs == nil || store.tokens == nil || store.secrets == nil {
+		t.Fatalf("provideStorage() = %#v, want every repository", store)
+	}
+	if store.conn != nil || store.migrator != nil || store.listener != nil {
+		t.Fatalf("provideStorage() = %#v, want no Postgres resources", store)
+	}
+
+	ctx := context.Background()
+	if err := store.configs.CreateEnvironment(ctx, &model.Environment{Name: "dev"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	if _, err := store.configs.Create(ctx, &model.Config{Environment: "dev", Key: "key", Value: "value"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+}
+
+func TestPublishRemoteChange(t *testing.T) {
+	ctx := context.Background()
+	broker := service.NewBroker()
+	changes, cancel := broker.Subscribe("dev")
+	defer cancel()
+
+	m := diTestMetrics()
+	configCache := cache.NewRepository(diStubRepository{}, config.CacheConfig{Size: 10, TTL: time.Minute}, m)
+	misses := m.CacheRequestsTotal.WithLabelValues("miss")
+	publish := publishRemoteChange(ctx, diStubRepository{}, configCache, broker, zap.NewNop())
+	publish(database.ChangeNotification{Revision: 0, Environment: "dev"})
+
+	if _, err := configCache.Get(ctx, "dev", "key"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	publish(database.ChangeNotification{Revision: 7, Environment: "dev"})
+
+	if _, err := configCache.Get(ctx, "dev", "key"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := testutil.ToFloat64(misses); got != 2 {
+		t.Fatalf("cache misses = %v, want the notified key to be invalidated", got)
+	}
+
+	select {
+	case revision := <-changes:
+		if revision.Revision != 7 {
+			t.Fatalf("published revision = %d, want 7", revision.Revision)
+		}
+	default:
+		t.Fatal("expected notified revision to be published")
+	}
+
+	select {
+	case revision := <-changes:
+		t.Fatalf("unexpected revision %d", revision.Revision)
+	default:
+	}
+}
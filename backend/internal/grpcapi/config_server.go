@@ -0,0 +1,310 @@
+// Package grpcapi serves the config API over gRPC on top of the same
+// service.ConfigService as the REST handlers.
+package grpcapi
+
+import (
+	configv1 "config-service/backend/api/config/v1"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	anonymousActor = "anonymous"
+	// actorMetadata names the caller while authentication is disabled, like
+	// the X-Actor header of the REST API.
+	actorMetadata = "x-actor"
+)
+
+type ConfigServer struct {
+	configv1.UnimplementedConfigServiceServer
+	service service.ConfigService
+}
+
+func NewConfigServer(service service.ConfigService) *ConfigServer {
+	return &ConfigServer{service: service}
+}
+
+// Register adds the config service to s.
+func (s *ConfigServer) Register(server grpc.ServiceRegistrar) {
+	configv1.RegisterConfigServiceServer(server, s)
+}
+
+// serviceFor restricts the service to the grants of the authenticated caller
+// and attributes its writes to the call in the audit log.
+func (s *ConfigServer) serviceFor(ctx context.Context) service.ConfigService {
+	return service.Authorize(service.Audit(s.service, auditRequest(ctx)), model.IdentityFromContext(ctx))
+}
+
+func (s *ConfigServer) Get(ctx context.Context, req *configv1.GetRequest) (*configv1.GetResponse, error) {
+	config, err := s.serviceFor(ctx).GetConfig(ctx, req.GetEnvironment(), req.GetKey())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &configv1.GetResponse{Config: configToProto(config)}, nil
+}
+
+func (s *ConfigServer) List(ctx context.Context, req *configv1.ListRequest) (*configv1.ListResponse, error) {
+	query := model.ConfigQuery{
+		Prefix: req.GetPrefix(),
+		Tree:   req.GetTree(),
+		Search: req.GetSearch(),
+		Limit:  int(req.GetPageSize()),
+	}
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := model.DecodeCursor(token)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		query.After = cursor
+	}
+
+	page, err := s.serviceFor(ctx).ListConfigs(ctx, req.GetEnvironment(), query)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	resp := &configv1.ListResponse{
+		Configs:   make([]*configv1.Config, len(page.Configs)),
+		TotalSize: int32(page.Total),
+	}
+	for i, config := range page.Configs {
+		resp.Configs[i] = configToProto(config)
+	}
+	if page.NextCursor != nil {
+		resp.NextPageToken = page.NextCursor.Encode()
+	}
+	return resp, nil
+}
+
+func (s *ConfigServer) Create(ctx context.Context, req *configv1.CreateRequest) (*configv1.CreateResponse, error) {
+	err := s.serviceFor(ctx).CreateConfig(ctx, req.GetEnvironment(), req.GetKey(), req.GetValue(), specFromProto(req.GetSpec()), actor(ctx))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &configv1.CreateResponse{}, nil
+}
+
+func (s *ConfigServer) Update(ctx context.Context, req *configv1.UpdateRequest) (*configv1.UpdateResponse, error) {
+	err := s.serviceFor(ctx).UpdateConfig(ctx, req.GetEnvironment(), req.GetKey(), req.GetValue(), specFromProto(req.GetSpec()), actor(ctx), req.GetVersion())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &configv1.UpdateResponse{}, nil
+}
+
+func (s *ConfigServer) Delete(ctx context.Context, req *configv1.DeleteRequest) (*configv1.DeleteResponse, error) {
+	err := s.serviceFor(ctx).DeleteConfig(ctx, req.GetEnvironment(), req.GetKey(), actor(ctx), req.GetVersion())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &configv1.DeleteResponse{}, nil
+}
+
+// Watch subscribes before reading the revisions after since, so that none
+// written in between is lost; the ones seen in both are sent once.
+func (s *ConfigServer) Watch(req *configv1.WatchRequest, stream grpc.ServerStreamingServer[configv1.WatchResponse]) error {
+	ctx := stream.Context()
+	environment, lastID := req.GetEnvironment(), req.GetSince()
+	if lastID < 0 {
+		return status.Error(codes.InvalidArgument, "invalid since")
+	}
+
+	svc := s.serviceFor(ctx)
+	changes, cancel, err := svc.WatchConfigs(ctx, environment)
+	if err != nil {
+		return statusError(ctx, err)
+	}
+	defer cancel()
+
+	// Live changes are skipped only when the backlog sent them, as in the
+	// REST stream.
+	sent := make(map[int64]bool)
+	for lastID > 0 {
+		revisions, err := svc.GetChangesSince(ctx, environment, lastID)
+		if err != nil {
+			return statusError(ctx, err)
+		}
+		for _, revision := range revisions {
+			if err := stream.Send(&configv1.WatchResponse{Revision: revisionToProto(revision)}); err != nil {
+				return err
+			}
+			sent[revision.Revision] = true
+			lastID = revision.Revision
+		}
+		if len(revisions) < service.MaxChangesPerRequest {
+			break
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return statusError(ctx, ctx.Err())
+		case revision, ok := <-changes:
+			if !ok {
+				return status.Error(codes.Unavailable, "watch closed")
+			}
+			if sent[revision.Revision] {
+				continue
+			}
+			if err := stream.Send(&configv1.WatchResponse{Revision: revisionToProto(revision)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// statusError maps service errors to status codes the way handleError maps
+// them to HTTP statuses. Rejected values carry their violations as
+// BadRequest details.
+func statusError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+
+	var valueErr *model.ValueError
+	switch {
+	case errors.As(err, &valueErr):
+		return invalidValue(valueErr)
+	case errors.Is(err, model.ErrInvalidEnvironment),
+		errors.Is(err, model.ErrInvalidKey),
+		errors.Is(err, model.ErrInvalidValue),
+		errors.Is(err, model.ErrInvalidSpec),
+		errors.Is(err, model.ErrInvalidConfigQuery),
+		errors.Is(err, model.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrSecretsUnavailable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "request timed out")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "client closed request")
+	case errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "forbidden")
+	case errors.Is(err, service.ErrConfigNotFound):
+		return status.Error(codes.NotFound, "config not found")
+	case errors.Is(err, service.ErrEnvironmentNotFound):
+		return status.Error(codes.NotFound, "environment not found")
+	case errors.Is(err, service.ErrConfigExists):
+		return status.Error(codes.AlreadyExists, "config already exists")
+	case errors.Is(err, service.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, "version mismatch")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func invalidValue(valueErr *model.ValueError) error {
+	st := status.New(codes.InvalidArgument, "invalid value")
+	details := &errdetails.BadRequest{}
+	for _, violation := range valueErr.Violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Path,
+			Description: violation.Message,
+		})
+	}
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func configToProto(config *model.Config) *configv1.Config {
+	return &configv1.Config{
+		Environment: config.Environment,
+		Key:         config.Key,
+		Value:       config.Value,
+		Spec: &configv1.ValueSpec{
+			Type:   string(config.Type),
+			Enum:   config.Enum,
+			Schema: string(config.Schema),
+			Secret: config.Secret,
+		},
+		UpdatedAt: timestamp(config.UpdatedAt),
+		UpdatedBy: config.UpdatedBy,
+		Revision:  config.Revision,
+		Version:   config.Version,
+	}
+}
+
+func revisionToProto(revision *model.Revision) *configv1.Revision {
+	return &configv1.Revision{
+		Revision:    revision.Revision,
+		Environment: revision.Environment,
+		Key:         revision.Key,
+		Value:       revision.Value,
+		Operation:   string(revision.Operation),
+		Actor:       revision.Actor,
+		CreatedAt:   timestamp(revision.CreatedAt),
+		Secret:      revision.Secret,
+	}
+}
+
+func specFromProto(spec *configv1.ValueSpec) model.ValueSpec {
+	if spec == nil {
+		return model.ValueSpec{}
+	}
+	result := model.ValueSpec{
+		Type:   model.ValueType(spec.GetType()),
+		Enum:   spec.GetEnum(),
+		Secret: spec.GetSecret(),
+	}
+	if spec.GetSchema() != "" {
+		result.Schema = json.RawMessage(spec.GetSchema())
+	}
+	return result
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// actor prefers the authenticated caller over the x-actor metadata, which is
+// only trusted while authentication is disabled.
+func actor(ctx context.Context) string {
+	if identity := model.IdentityFromContext(ctx); identity != nil {
+		return identity.Name
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, actorMetadata); len(values) > 0 {
+		if actor := strings.TrimSpace(values[0]); actor != "" {
+			return actor
+		}
+	}
+	return anonymousActor
+}
+
+func auditRequest(ctx context.Context) model.AuditRequest {
+	return model.AuditRequest{
+		Actor:     actor(ctx),
+		SourceIP:  sourceIP(ctx),
+		RequestID: model.RequestIDFromContext(ctx),
+	}
+}
+
+func sourceIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
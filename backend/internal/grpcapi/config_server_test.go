@@ -0,0 +1,258 @@
+package grpcapi
+
+import (
+	configv1 "config-service/backend/api/config/v1"
+	"config-service/backend/internal/infrastructure/memory"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"config-service/backend/pkg/middleware"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type stubAuthenticator struct{}
+
+// Authenticate accepts the token "dev-reader", allowed to read dev only.
+func (stubAuthenticator) Authenticate(_ context.Context, token string) (*model.Identity, error) {
+	if token != "dev-reader" {
+		return nil, nil
+	}
+	return &model.Identity{Name: "reader", Grants: []model.Grant{{Environment: "dev", Role: model.RoleReader}}}, nil
+}
+
+// newTestClient serves the config service over an in-memory connection with
+// the environments dev and prod.
+func newTestClient(t *testing.T, authenticator middleware.Authenticator) *grpc.ClientConn {
+	t.Helper()
+	return newTestClientWithBroker(t, authenticator, service.NewBroker())
+}
+
+func newTestClientWithBroker(t *testing.T, authenticator middleware.Authenticator, broker *service.Broker) *grpc.ClientConn {
+	t.Helper()
+	repo, err := memory.NewRepository("")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	for _, name := range []string{"dev", "prod"} {
+		if err := repo.CreateEnvironment(context.Background(), &model.Environment{Name: name}); err != nil {
+			t.Fatalf("CreateEnvironment() error = %v", err)
+		}
+	}
+
+	server := grpc.NewServer(Interceptors(authenticator)...)
+	NewConfigServer(service.NewConfigService(repo, broker)).Register(server)
+	reflection.Register(server)
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func wantCode(t *testing.T, name string, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("%s error = %v, want %s", name, err, want)
+	}
+}
+
+func TestConfigServerCRUD(t *testing.T) {
+	client := configv1.NewConfigServiceClient(newTestClient(t, nil))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), actorMetadata, "alice")
+
+	_, err := client.Create(ctx, &configv1.CreateRequest{Environment: "dev", Key: "port", Value: "8080", Spec: &configv1.ValueSpec{Type: "int"}})
+	wantCode(t, "Create()", err, codes.OK)
+	_, err = client.Create(ctx, &configv1.CreateRequest{Environment: "dev", Key: "port", Value: "8080"})
+	wantCode(t, "Create() of an existing key", err, codes.AlreadyExists)
+
+	got, err := client.Get(ctx, &configv1.GetRequest{Environment: "dev", Key: "port"})
+	wantCode(t, "Get()", err, codes.OK)
+	config := got.GetConfig()
+	if config.GetValue() != "8080" || config.GetSpec().GetType() != "int" || config.GetVersion() != 1 ||
+		config.GetUpdatedBy() != "alice" || !config.GetUpdatedAt().IsValid() {
+		t.Fatalf("Get() = %v", config)
+	}
+
+	_, err = client.Update(ctx, &configv1.UpdateRequest{Environment: "dev", Key: "port", Value: "9090", Version: 5})
+	wantCode(t, "Update() of a stale version", err, codes.FailedPrecondition)
+	_, err = client.Update(ctx, &configv1.UpdateRequest{Environment: "dev", Key: "port", Value: "abc"})
+	wantCode(t, "Update() with an invalid value", err, codes.InvalidArgument)
+	details := status.Convert(err).Details()
+	if len(details) != 1 || len(details[0].(*errdetails.BadRequest).GetFieldViolations()) != 1 {
+		t.Fatalf("Update() details = %v, want the violation", details)
+	}
+	_, err = client.Update(ctx, &configv1.UpdateRequest{Environment: "dev", Key: "port", Value: "9090", Version: 1})
+	wantCode(t, "Update()", err, codes.OK)
+	_, err = client.Update(ctx, &configv1.UpdateRequest{Environment: "dev", Key: "missing", Value: "1"})
+	wantCode(t, "Update() of a missing key", err, codes.NotFound)
+
+	_, err = client.Delete(ctx, &configv1.DeleteRequest{Environment: "dev", Key: "port", Version: 1})
+	wantCode(t, "Delete() of a stale version", err, codes.FailedPrecondition)
+	_, err = client.Delete(ctx, &configv1.DeleteRequest{Environment: "dev", Key: "port"})
+	wantCode(t, "Delete()", err, codes.OK)
+	_, err = client.Get(ctx, &configv1.GetRequest{Environment: "dev", Key: "port"})
+	wantCode(t, "Get() after Delete()", err, codes.NotFound)
+	_, err = client.Create(ctx, &configv1.CreateRequest{Environment: "dev", Value: "1"})
+	wantCode(t, "Create() without a key", err, codes.InvalidArgument)
+}
+
+func TestConfigServerList(t *testing.T) {
+	client := configv1.NewConfigServiceClient(newTestClient(t, nil))
+	ctx := context.Background()
+	for _, key := range []string{"db.host", "db.port", "name"} {
+		if _, err := client.Create(ctx, &configv1.CreateRequest{Environment: "dev", Key: key, Value: "v"}); err != nil {
+			t.Fatalf("Create(%s) error = %v", key, err)
+		}
+	}
+
+	var keys []string
+	req := &configv1.ListRequest{Environment: "dev", Prefix: "db.", PageSize: 1}
+	for {
+		resp, err := client.List(ctx, req)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if resp.GetTotalSize() != 2 || len(resp.GetConfigs()) != 1 {
+			t.Fatalf("List() = %v, want one of two configs per page", resp)
+		}
+		keys = append(keys, resp.GetConfigs()[0].GetKey())
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+	if len(keys) != 2 || keys[0] != "db.host" || keys[1] != "db.port" {
+		t.Fatalf("listed keys = %v", keys)
+	}
+
+	_, err := client.List(ctx, &configv1.ListRequest{Environment: "dev", PageToken: "bogus"})
+	wantCode(t, "List() with an invalid page token", err, codes.InvalidArgument)
+	_, err = client.List(ctx, &configv1.ListRequest{Environment: "dev", PageSize: 5000})
+	wantCode(t, "List() with a page too large", err, codes.InvalidArgument)
+}
+
+func TestConfigServerWatch(t *testing.T) {
+	broker := service.NewBroker()
+	client := configv1.NewConfigServiceClient(newTestClientWithBroker(t, nil, broker))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, key := range []string{"a", "b"} {
+		if _, err := client.Create(ctx, &configv1.CreateRequest{Environment: "dev", Key: key, Value: "1"}); err != nil {
+			t.Fatalf("Create(%s) error = %v", key, err)
+		}
+	}
+
+	stream, err := client.Watch(ctx, &configv1.WatchRequest{Environment: "dev", Since: 1})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	// The missed revision of b arrives first, which also shows the watch
+	// is subscribed.
+	first, err := stream.Recv()
+	if err != nil || first.GetRevision().GetKey() != "b" || first.GetRevision().GetOperation() != "create" {
+		t.Fatalf("Recv() = %v, %v, want the creation of b", first, err)
+	}
+
+	if _, err := client.Delete(ctx, &configv1.DeleteRequest{Environment: "dev", Key: "a"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	next, err := stream.Recv()
+	if err != nil || next.GetRevision().GetKey() != "a" || next.GetRevision().GetOperation() != "delete" ||
+		next.GetRevision().GetRevision() <= first.GetRevision().GetRevision() {
+		t.Fatalf("Recv() = %v, %v, want the deletion of a", next, err)
+	}
+
+	// A change published after a newer one is still sent.
+	broker.Publish(&model.Revision{Revision: 11, Environment: "dev", Key: "c", Operation: model.OperationCreate})
+	broker.Publish(&model.Revision{Revision: 10, Environment: "dev", Key: "d", Operation: model.OperationCreate})
+	for _, want := range []string{"c", "d"} {
+		next, err := stream.Recv()
+		if err != nil || next.GetRevision().GetKey() != want {
+			t.Fatalf("Recv() = %v, %v, want the creation of %s", next, err, want)
+		}
+	}
+
+	stream, err = client.Watch(ctx, &configv1.WatchRequest{Environment: "dev", Since: -1})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	wantCode(t, "Watch() with a negative since", err, codes.InvalidArgument)
+}
+
+func TestInterceptorsAuthenticate(t *testing.T) {
+	conn := newTestClient(t, stubAuthenticator{})
+	client := configv1.NewConfigServiceClient(conn)
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		env  string
+		want codes.Code
+	}{
+		{name: "without token", ctx: context.Background(), env: "dev", want: codes.Unauthenticated},
+		{name: "unknown token", ctx: withToken("bogus"), env: "dev", want: codes.Unauthenticated},
+		{name: "within grants", ctx: withToken("dev-reader"), env: "dev", want: codes.OK},
+		{name: "outside grants", ctx: withToken("dev-reader"), env: "prod", want: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.List(tt.ctx, &configv1.ListRequest{Environment: tt.env})
+			wantCode(t, "List()", err, tt.want)
+		})
+	}
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(withToken("dev-reader"), requestIDMetadata, "req-42")
+	if _, err := client.List(ctx, &configv1.ListRequest{Environment: "dev"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := header.Get(requestIDMetadata); len(got) != 1 || got[0] != "req-42" {
+		t.Fatalf("request id header = %v, want req-42", got)
+	}
+	_, err := client.Create(withToken("dev-reader"), &configv1.CreateRequest{Environment: "dev", Key: "k", Value: "v"})
+	wantCode(t, "Create() by a reader", err, codes.PermissionDenied)
+
+	// Reflection is served without a token.
+	reflect, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerReflectionInfo() error = %v", err)
+	}
+	if err := reflect.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	resp, err := reflect.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	found := false
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		found = found || svc.GetName() == "config.v1.ConfigService"
+	}
+	if !found {
+		t.Fatalf("reflection services = %v, want config.v1.ConfigService", resp.GetListServicesResponse().GetService())
+	}
+}
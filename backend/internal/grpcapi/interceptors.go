@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/pkg/middleware"
+	"context"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadata carries the request ID like the X-Request-ID header.
+const requestIDMetadata = "x-request-id"
+
+// callContext prepares the context of a call: it keeps or generates the
+// request ID, echoed in the response header, and, when authenticator is
+// set, rejects calls without a valid bearer token in the authorization
+// metadata and stores the caller for model.IdentityFromContext.
+type callContext func(ctx context.Context, method string) (context.Context, error)
+
+func newCallContext(authenticator middleware.Authenticator) callContext {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		var requestID string
+		if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+		requestID = middleware.ValidOrNewRequestID(requestID)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+		ctx = model.ContextWithRequestID(ctx, requestID)
+
+		if authenticator == nil || isPublicMethod(method) {
+			return ctx, nil
+		}
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+		identity, err := authenticator.Authenticate(ctx, token)
+		if err != nil {
+			log.Printf("authentication failed: %v", err)
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+		if identity == nil {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+		return model.ContextWithIdentity(ctx, identity), nil
+	}
+}
+
+// Interceptors return the server options that prepare every call. A nil
+// authenticator disables authentication.
+func Interceptors(authenticator middleware.Authenticator) []grpc.ServerOption {
+	prepare := newCallContext(authenticator)
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := prepare(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := prepare(stream.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+// contextStream replaces the context of a stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// isPublicMethod lets reflection clients such as grpcurl discover the API
+// without a token, like the API docs of the REST API.
+func isPublicMethod(method string) bool {
+	return strings.HasPrefix(method, "/grpc.reflection.")
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultAuditPageSize = 100
+
+type AuditHandler struct {
+	service service.AuditService
+}
+
+func NewAuditHandler(service service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) serviceFor(r *http.Request) service.AuditService {
+	return service.AuthorizeAudit(h.service, model.IdentityFromContext(r.Context()))
+}
+
+func (h *AuditHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/audit", h.handleAudit)
+}
+
+// handleAudit pages through the audit log newest first; next_cursor in the
+// response is passed as cursor to get the following page.
+func (h *AuditHandler) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.AuditFilter{
+		Environment: query.Get("env"),
+		Key:         query.Get("key"),
+		Actor:       query.Get("actor"),
+		Limit:       defaultAuditPageSize,
+	}
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		http.Error(w, "invalid from", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		http.Error(w, "invalid to", http.StatusBadRequest)
+		return
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		if filter.Cursor, err = strconv.ParseInt(raw, 10, 64); err != nil || filter.Cursor <= 0 {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.serviceFor(r).GetAuditLog(r.Context(), filter)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func parseTimeParam(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubAuditService struct {
+	filter *model.AuditFilter
+}
+
+func (s stubAuditService) GetAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	*s.filter = filter
+	value := "v2"
+	return &model.AuditPage{
+		Entries:    []*model.AuditEntry{{ID: 9, Actor: "alice", Operation: model.AuditConfigUpdate, Environment: "prod", Key: "db.host", NewValue: &value}},
+		NextCursor: 9,
+	}, nil
+}
+
+func TestAuditHandler_HandleAudit(t *testing.T) {
+	admin := &model.Identity{Name: "root", Grants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}}}
+	reader := &model.Identity{Name: "ci", Grants: []model.Grant{{Environment: "prod", Role: model.RoleReader}}}
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		caller     *model.Identity
+		wantStatus int
+		wantFilter model.AuditFilter
+		wantBody   string
+	}{
+		{
+			name:       "defaults",
+			method:     http.MethodGet,
+			caller:     admin,
+			wantStatus: http.StatusOK,
+			wantFilter: model.AuditFilter{Limit: defaultAuditPageSize},
+			wantBody:   `"next_cursor":9`,
+		},
+		{
+			name:       "all filters",
+			method:     http.MethodGet,
+			query:      "?env=prod&key=db.host&actor=alice&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&cursor=42&limit=10",
+			wantStatus: http.StatusOK,
+			wantFilter: model.AuditFilter{
+				Environment: "prod",
+				Key:         "db.host",
+				Actor:       "alice",
+				From:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:          time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+				Cursor:      42,
+				Limit:       10,
+			},
+			wantBody: `"new_value":"v2"`,
+		},
+		{name: "invalid from", method: http.MethodGet, query: "?from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "invalid cursor", method: http.MethodGet, query: "?cursor=-1", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", method: http.MethodGet, query: "?limit=many", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", method: http.MethodGet, query: "?limit=5000", wantStatus: http.StatusUnprocessableEntity},
+		{name: "reversed range", method: http.MethodGet, query: "?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", wantStatus: http.StatusUnprocessableEntity},
+		{name: "reader", method: http.MethodGet, query: "?env=prod", caller: reader, wantStatus: http.StatusForbidden},
+		{name: "post", method: http.MethodPost, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFilter model.AuditFilter
+			req := httptest.NewRequest(tt.method, "/api/audit"+tt.query, nil)
+			if tt.caller != nil {
+				req = req.WithContext(model.ContextWithIdentity(req.Context(), tt.caller))
+			}
+			rr := httptest.NewRecorder()
+			NewAuditHandler(stubAuditService{filter: &gotFilter}).handleAudit(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if gotFilter != tt.wantFilter {
+				t.Fatalf("filter = %#v, want %#v", gotFilter, tt.wantFilter)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %q, want it to contain %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAuditRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/api/configs/prod/key", nil)
+	req.RemoteAddr = "10.0.0.7:52100"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	ctx := model.ContextWithRequestID(req.Context(), "req-1")
+	ctx = model.ContextWithIdentity(ctx, &model.Identity{Name: "ci"})
+
+	got := auditRequest(req.WithContext(ctx))
+	want := model.AuditRequest{Actor: "ci", SourceIP: "10.0.0.7", RequestID: "req-1"}
+	if got != want {
+		t.Fatalf("auditRequest() = %#v, want %#v", got, want)
+	}
+}
@@ -1,14 +1,40 @@
 package handler
 
 import (
+	"config-service/backend/internal/model"
 	"config-service/backend/internal/service"
+	"context"
 	"embed"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
+const (
+	anonymousActor = "anonymous"
+	batchSuffix    = ":batch"
+
+	defaultPollTimeout = 30 * time.Second
+	maxPollTimeout     = 60 * time.Second
+	keepAliveInterval  = 15 * time.Second
+
+	// statusClientClosedRequest is the non-standard status nginx logs for
+	// requests the client abandoned before the response.
+	statusClientClosedRequest = 499
+)
+
+var changeEvents = map[model.Operation]string{
+	model.OperationCreate: "created",
+	model.OperationUpdate: "updated",
+	model.OperationDelete: "deleted",
+}
+
 //go:embed doc.yaml doc.json
 var swaggerDocs embed.FS
 
@@ -20,11 +46,18 @@ func NewConfigHandler(service service.ConfigService) *ConfigHandler {
 	return &ConfigHandler{service: service}
 }
 
+// serviceFor restricts the service to the grants of the authenticated caller
+// and attributes its writes to the request in the audit log.
+func (h *ConfigHandler) serviceFor(r *http.Request) service.ConfigService {
+	return service.Authorize(service.Audit(h.service, auditRequest(r)), model.IdentityFromContext(r.Context()))
+}
+
 func (h *ConfigHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", h.health)
 	mux.HandleFunc("/doc.json", h.swaggerJSON)
 	mux.HandleFunc("/doc.yaml", h.swaggerYAML)
 	mux.HandleFunc("/api/configs/", h.handleConfigs)
+	mux.HandleFunc("/api/diff", h.compareEnvironments)
 }
 
 func (h *ConfigHandler) health(w http.ResponseWriter, r *http.Request) {
@@ -37,6 +70,17 @@ func (h *ConfigHandler) health(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// IsWatchRequest reports whether r subscribes to changes. Such requests wait
+// for changes on purpose and are not bound by the request timeout.
+func (h *ConfigHandler) IsWatchRequest(r *http.Request) bool {
+	path, ok := strings.CutPrefix(r.URL.Path, "/api/configs/")
+	if !ok || r.Method != http.MethodGet {
+		return false
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	return len(parts) == 2 && parts[1] == "watch"
+}
+
 func (h *ConfigHandler) handleConfigs(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/configs/")
 
@@ -49,14 +93,45 @@ func (h *ConfigHandler) handleConfigs(w http.ResponseWriter, r *http.Request) {
 
 	environment := parts[0]
 
+	if len(parts) == 1 && strings.HasSuffix(environment, batchSuffix) {
+		if r.Method == http.MethodPost {
+			h.applyBatch(w, r, strings.TrimSuffix(environment, batchSuffix))
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
 	switch {
 	case len(parts) == 1:
-		if r.Method == http.MethodGet {
+		switch r.Method {
+		case http.MethodGet:
 			h.getAllConfigs(w, r, environment)
-		} else {
+		case http.MethodDelete:
+			h.deleteTree(w, r, environment)
+		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 
+	case len(parts) == 2 && parts[1] == "rollback" && r.Method == http.MethodPost:
+		h.rollbackEnvironment(w, r, environment)
+
+	case len(parts) == 2 && parts[1] == "export" && r.Method == http.MethodGet:
+		h.exportConfigs(w, r, environment)
+
+	case len(parts) == 2 && parts[1] == "import" && r.Method == http.MethodPost:
+		h.importConfigs(w, r, environment)
+
+	case len(parts) == 2 && parts[1] == "resolved" && r.Method == http.MethodGet:
+		h.resolveConfigs(w, r, environment)
+
+	case len(parts) == 2 && parts[1] == "watch" && r.Method == http.MethodGet:
+		if r.URL.Query().Has("since") {
+			h.pollChanges(w, r, environment)
+		} else {
+			h.streamChanges(w, r, environment)
+		}
+
 	case len(parts) == 2:
 		key := parts[1]
 
@@ -77,6 +152,20 @@ func (h *ConfigHandler) handleConfigs(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 
+	case len(parts) == 3 && parts[2] == "history":
+		if r.Method == http.MethodGet {
+			h.getConfigHistory(w, r, environment, parts[1])
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	case len(parts) == 3 && parts[2] == "rollback":
+		if r.Method == http.MethodPost {
+			h.rollbackConfig(w, r, environment, parts[1])
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+
 	default:
 		http.Error(w, "invalid path", http.StatusBadRequest)
 	}
@@ -85,6 +174,7 @@ func (h *ConfigHandler) handleConfigs(w http.ResponseWriter, r *http.Request) {
 func (h *ConfigHandler) createConfig(w http.ResponseWriter, r *http.Request, environment, key string) {
 	var req struct {
 		Value string `json:"value"`
+		model.ValueSpec
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -92,29 +182,162 @@ func (h *ConfigHandler) createConfig(w http.ResponseWriter, r *http.Request, env
 		return
 	}
 
-	if err := h.service.CreateConfig(environment, key, req.Value); err != nil {
-		h.handleError(w, err)
+	if err := h.serviceFor(r).CreateConfig(r.Context(), environment, key, req.Value, req.ValueSpec, actorFromRequest(r)); err != nil {
+		if errors.Is(err, service.ErrConfigExists) && strings.TrimSpace(r.Header.Get("If-None-Match")) == "*" {
+			http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+			return
+		}
+		handleError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
 
-func (h *ConfigHandler) getConfig(w http.ResponseWriter, _ *http.Request, environment, key string) {
-	config, err := h.service.GetConfig(environment, key)
+func (h *ConfigHandler) getConfig(w http.ResponseWriter, r *http.Request, environment, key string) {
+	asOf, ok, err := parseAsOf(r)
+	if err != nil {
+		http.Error(w, "invalid as_of", http.StatusBadRequest)
+		return
+	}
+
+	reveal := false
+	if raw := r.URL.Query().Get("reveal"); raw != "" {
+		reveal, err = strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "invalid reveal", http.StatusBadRequest)
+			return
+		}
+	}
+	if reveal && ok {
+		http.Error(w, "reveal cannot be combined with as_of", http.StatusBadRequest)
+		return
+	}
+
+	var config *model.Config
+	switch {
+	case ok:
+		config, err = h.serviceFor(r).GetConfigAt(r.Context(), environment, key, asOf)
+	case reveal:
+		config, err = h.serviceFor(r).RevealConfig(r.Context(), environment, key)
+	default:
+		config, err = h.serviceFor(r).GetConfig(r.Context(), environment, key)
+	}
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	if !ok {
+		w.Header().Set("ETag", formatETag(config.Version))
+	}
+	if reveal {
+		w.Header().Set("Cache-Control", "no-store")
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(config)
 }
 
-func (h *ConfigHandler) getAllConfigs(w http.ResponseWriter, _ *http.Request, environment string) {
-	configs, err := h.service.GetAllConfigs(environment)
+// getAllConfigs lists the environment page by page when limit is given;
+// X-Next-Cursor is passed back as cursor to get the following page.
+func (h *ConfigHandler) getAllConfigs(w http.ResponseWriter, r *http.Request, environment string) {
+	asOf, ok, err := parseAsOf(r)
+	if err != nil {
+		http.Error(w, "invalid as_of", http.StatusBadRequest)
+		return
+	}
+	if ok {
+		for _, name := range listParams {
+			if r.URL.Query().Has(name) {
+				http.Error(w, "as_of cannot be combined with "+name, http.StatusBadRequest)
+				return
+			}
+		}
+		configs, err := h.serviceFor(r).GetAllConfigsAt(r.Context(), environment, asOf)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(configs)
+		return
+	}
+
+	query, err := parseConfigQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	nested := false
+	switch view := r.URL.Query().Get("view"); view {
+	case "", "flat":
+	case "nested":
+		if query.Limit != 0 || query.After != nil {
+			http.Error(w, "view=nested cannot be paged", http.StatusBadRequest)
+			return
+		}
+		nested = true
+	default:
+		http.Error(w, "invalid view", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.serviceFor(r).ListConfigs(r.Context(), environment, query)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if nested {
+		tree, err := model.NestConfigs(page.Configs)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+		_ = json.NewEncoder(w).Encode(tree)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != nil {
+		w.Header().Set("X-Next-Cursor", page.NextCursor.Encode())
+	}
+	_ = json.NewEncoder(w).Encode(page.Configs)
+}
+
+var listParams = []string{"limit", "cursor", "prefix", "tree", "search", "updated_since", "sort", "view"}
+
+func parseConfigQuery(r *http.Request) (model.ConfigQuery, error) {
+	values := r.URL.Query()
+	query := model.ConfigQuery{
+		Prefix: values.Get("prefix"),
+		Tree:   values.Get("tree"),
+		Search: values.Get("search"),
+		Sort:   model.ConfigSort(values.Get("sort")),
+	}
+	var err error
+	if raw := values.Get("limit"); raw != "" {
+		if query.Limit, err = strconv.Atoi(raw); err != nil {
+			return query, errors.New("invalid limit")
+		}
+	}
+	if raw := values.Get("cursor"); raw != "" {
+		if query.After, err = model.DecodeCursor(raw); err != nil {
+			return query, err
+		}
+	}
+	if query.UpdatedSince, err = parseTimeParam(values.Get("updated_since")); err != nil {
+		return query, errors.New("invalid updated_since")
+	}
+	return query, nil
+}
+
+func (h *ConfigHandler) resolveConfigs(w http.ResponseWriter, r *http.Request, environment string) {
+	configs, err := h.serviceFor(r).ResolveConfigs(r.Context(), environment)
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -122,9 +345,26 @@ func (h *ConfigHandler) getAllConfigs(w http.ResponseWriter, _ *http.Request, en
 	_ = json.NewEncoder(w).Encode(configs)
 }
 
+func (h *ConfigHandler) getConfigHistory(w http.ResponseWriter, r *http.Request, environment, key string) {
+	revisions, err := h.serviceFor(r).GetConfigHistory(r.Context(), environment, key)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	writeRevisions(w, revisions)
+}
+
 func (h *ConfigHandler) updateConfig(w http.ResponseWriter, r *http.Request, environment, key string) {
 	var req struct {
 		Value string `json:"value"`
+		model.ValueSpec
+	}
+
+	version, ok := parseIfMatch(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -132,8 +372,8 @@ func (h *ConfigHandler) updateConfig(w http.ResponseWriter, r *http.Request, env
 		return
 	}
 
-	if err := h.service.UpdateConfig(environment, key, req.Value); err != nil {
-		h.handleError(w, err)
+	if err := h.serviceFor(r).UpdateConfig(r.Context(), environment, key, req.Value, req.ValueSpec, actorFromRequest(r), version); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
@@ -141,25 +381,337 @@ func (h *ConfigHandler) updateConfig(w http.ResponseWriter, r *http.Request, env
 }
 
 func (h *ConfigHandler) deleteConfig(w http.ResponseWriter, r *http.Request, environment, key string) {
-	if err := h.service.DeleteConfig(environment, key); err != nil {
-		h.handleError(w, err)
+	version, ok := parseIfMatch(r)
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	if err := h.serviceFor(r).DeleteConfig(r.Context(), environment, key, actorFromRequest(r), version); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *ConfigHandler) handleError(w http.ResponseWriter, err error) {
+// deleteTree deletes a key and the keys nested below it, such as
+// payments.stripe and payments.stripe.timeout for tree=payments.stripe.
+func (h *ConfigHandler) deleteTree(w http.ResponseWriter, r *http.Request, environment string) {
+	tree := r.URL.Query().Get("tree")
+	if tree == "" {
+		http.Error(w, "tree is required", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.serviceFor(r).DeleteTree(r.Context(), environment, tree, actorFromRequest(r))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	writeRevisions(w, revisions)
+}
+
+func (h *ConfigHandler) rollbackConfig(w http.ResponseWriter, r *http.Request, environment, key string) {
+	revision, err := strconv.ParseInt(r.URL.Query().Get("revision"), 10, 64)
+	if err != nil || revision <= 0 {
+		http.Error(w, "invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.serviceFor(r).RollbackConfig(r.Context(), environment, key, revision, actorFromRequest(r))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	writeRevisions(w, revisions)
+}
+
+func (h *ConfigHandler) rollbackEnvironment(w http.ResponseWriter, r *http.Request, environment string) {
+	asOf, ok, err := parseAsOf(r)
+	if err != nil || !ok {
+		http.Error(w, "invalid as_of", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.serviceFor(r).RollbackEnvironment(r.Context(), environment, asOf, actorFromRequest(r))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	writeRevisions(w, revisions)
+}
+
+func (h *ConfigHandler) applyBatch(w http.ResponseWriter, r *http.Request, environment string) {
+	var req struct {
+		Operations []model.BatchOperation `json:"operations"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if len(req.Operations) == 0 {
+		http.Error(w, "operations are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Operations) > service.MaxBatchOperations {
+		http.Error(w, "too many operations", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	results, err := h.serviceFor(r).ApplyBatch(r.Context(), environment, req.Operations, actorFromRequest(r))
+	statusCode := http.StatusOK
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrBatchInvalid):
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrBatchFailed):
+		statusCode = http.StatusConflict
+	default:
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string][]model.BatchResult{"results": results})
+}
+
+// pollChanges answers immediately when revisions newer than since exist and
+// otherwise waits for the next change or the timeout, returning an empty list.
+func (h *ConfigHandler) pollChanges(w http.ResponseWriter, r *http.Request, environment string) {
+	since, err := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	if err != nil || since < 0 {
+		http.Error(w, "invalid since", http.StatusBadRequest)
+		return
+	}
+
+	timeout := defaultPollTimeout
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+			return
+		}
+		timeout = min(timeout, maxPollTimeout)
+	}
+
+	changes, cancel, err := h.serviceFor(r).WatchConfigs(r.Context(), environment)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	defer cancel()
+
+	revisions, err := h.serviceFor(r).GetChangesSince(r.Context(), environment, since)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if len(revisions) == 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-changes:
+			revisions, err = h.serviceFor(r).GetChangesSince(r.Context(), environment, since)
+			if err != nil {
+				handleError(w, r, err)
+				return
+			}
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	writeRevisions(w, revisions)
+}
+
+// streamChanges sends change events over Server-Sent Events. Clients that
+// reconnect with Last-Event-ID first receive the revisions they missed.
+func (h *ConfigHandler) streamChanges(w http.ResponseWriter, r *http.Request, environment string) {
+	var lastID int64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = id
+	}
+
+	changes, cancel, err := h.serviceFor(r).WatchConfigs(r.Context(), environment)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	defer cancel()
+
+	var backlog []*model.Revision
+	for lastID > 0 {
+		revisions, err := h.serviceFor(r).GetChangesSince(r.Context(), environment, lastID)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		backlog = append(backlog, revisions...)
+		if len(revisions) < service.MaxChangesPerRequest {
+			break
+		}
+		lastID = revisions[len(revisions)-1].Revision
+	}
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Revisions commit in order, but are published by the goroutines that
+	// wrote them, so a live change may be older than one already sent and
+	// only the backlog is skipped.
+	sent := make(map[int64]bool, len(backlog))
+	for _, revision := range backlog {
+		if err := writeChangeEvent(w, revision); err != nil {
+			return
+		}
+		sent[revision.Revision] = true
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case revision, ok := <-changes:
+			if !ok {
+				return
+			}
+			if sent[revision.Revision] {
+				continue
+			}
+			if err := writeChangeEvent(w, revision); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeChangeEvent(w io.Writer, revision *model.Revision) error {
+	data, err := json.Marshal(revision)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", revision.Revision, changeEvents[revision.Operation], data)
+	return err
+}
+
+func writeRevisions(w http.ResponseWriter, revisions []*model.Revision) {
+	if revisions == nil {
+		revisions = []*model.Revision{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(revisions)
+}
+
+// handleError blames a request whose context ended on the deadline or the
+// client rather than on the error it caused downstream, such as a canceled
+// query.
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if ctxErr := r.Context().Err(); ctxErr != nil {
+		err = ctxErr
+	}
+
 	var statusCode int
 	var message string
 
+	var valueErr *model.ValueError
 	switch {
+	case errors.As(err, &valueErr):
+		writeValidationError(w, "invalid value", valueErr)
+		return
+	case errors.Is(err, model.ErrInvalidEnvironment),
+		errors.Is(err, model.ErrInvalidKey),
+		errors.Is(err, model.ErrInvalidValue),
+		errors.Is(err, model.ErrInvalidSpec),
+		errors.Is(err, model.ErrInvalidEnvironmentName),
+		errors.Is(err, model.ErrInvalidAttributes),
+		errors.Is(err, model.ErrInvalidFilter),
+		errors.Is(err, model.ErrInvalidImportMode),
+		errors.Is(err, model.ErrInvalidTokenName),
+		errors.Is(err, model.ErrInvalidGrant),
+		errors.Is(err, model.ErrInvalidExpiry),
+		errors.Is(err, model.ErrInvalidAuditFilter),
+		errors.Is(err, model.ErrInvalidConfigQuery),
+		errors.Is(err, model.ErrInvalidCursor),
+		errors.Is(err, service.ErrSamePromotionTarget):
+		writeValidationError(w, err.Error(), nil)
+		return
+	case errors.Is(err, service.ErrSecretsUnavailable):
+		statusCode = http.StatusServiceUnavailable
+		message = err.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		statusCode = http.StatusGatewayTimeout
+		message = "request timed out"
+	case errors.Is(err, context.Canceled):
+		statusCode = statusClientClosedRequest
+		message = "client closed request"
+	case errors.Is(err, service.ErrForbidden):
+		statusCode = http.StatusForbidden
+		message = "forbidden"
 	case errors.Is(err, service.ErrConfigNotFound):
 		statusCode = http.StatusNotFound
 		message = "config not found"
 	case errors.Is(err, service.ErrConfigExists):
 		statusCode = http.StatusConflict
 		message = "config already exists"
+	case errors.Is(err, model.ErrTreeConflict):
+		statusCode = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, service.ErrVersionMismatch):
+		statusCode = http.StatusPreconditionFailed
+		message = "precondition failed"
+	case errors.Is(err, service.ErrRevisionNotFound):
+		statusCode = http.StatusNotFound
+		message = "revision not found"
+	case errors.Is(err, service.ErrEnvironmentNotFound):
+		statusCode = http.StatusNotFound
+		message = "environment not found"
+	case errors.Is(err, service.ErrEnvironmentExists):
+		statusCode = http.StatusConflict
+		message = "environment already exists"
+	case errors.Is(err, service.ErrTokenNotFound):
+		statusCode = http.StatusNotFound
+		message = "token not found"
+	case errors.Is(err, service.ErrTokenExists):
+		statusCode = http.StatusConflict
+		message = "token already exists"
+	case errors.Is(err, service.ErrEnvironmentProtected),
+		errors.Is(err, service.ErrEnvironmentNotEmpty),
+		errors.Is(err, service.ErrEnvironmentInUse):
+		statusCode = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, model.ErrInvalidParent):
+		writeValidationError(w, err.Error(), nil)
+		return
 	default:
 		statusCode = http.StatusInternalServerError
 		message = "internal server error"
@@ -168,6 +720,90 @@ func (h *ConfigHandler) handleError(w http.ResponseWriter, err error) {
 	http.Error(w, message, statusCode)
 }
 
+// writeValidationError answers 422 with a JSON body so clients can show
+// which part of the value was rejected.
+func writeValidationError(w http.ResponseWriter, message string, valueErr *model.ValueError) {
+	body := struct {
+		Error      string            `json:"error"`
+		Type       model.ValueType   `json:"type,omitempty"`
+		Violations []model.Violation `json:"violations,omitempty"`
+	}{Error: message}
+	if valueErr != nil {
+		body.Type = valueErr.Type
+		body.Violations = valueErr.Violations
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// actorFromRequest prefers the authenticated caller over the X-Actor header,
+// which is only trusted while authentication is disabled.
+func actorFromRequest(r *http.Request) string {
+	if identity := model.IdentityFromContext(r.Context()); identity != nil {
+		return identity.Name
+	}
+	if actor := strings.TrimSpace(r.Header.Get("X-Actor")); actor != "" {
+		return actor
+	}
+	return anonymousActor
+}
+
+func auditRequest(r *http.Request) model.AuditRequest {
+	return model.AuditRequest{
+		Actor:     actorFromRequest(r),
+		SourceIP:  sourceIP(r),
+		RequestID: model.RequestIDFromContext(r.Context()),
+	}
+}
+
+// sourceIP is the address of the direct peer; X-Forwarded-For is not
+// trusted since any client can set it.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func parseAsOf(r *http.Request) (time.Time, bool, error) {
+	raw := r.URL.Query().Get("as_of")
+	if raw == "" {
+		return time.Time{}, false, nil
+	}
+	asOf, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return asOf, true, nil
+}
+
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the config version required by the If-Match header.
+// A missing header or "*" yields zero, meaning no version check. The second
+// result is false when the header can never match a config ETag.
+func parseIfMatch(r *http.Request) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
 func (h *ConfigHandler) swaggerJSON(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	data, err := swaggerDocs.ReadFile("doc.json")
@@ -3,57 +3,183 @@ package handler
 import (
 	"config-service/backend/internal/model"
 	"config-service/backend/internal/service"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 type stubConfigService struct {
-	createFunc func(environment, key, value string) error
-	getFunc    func(environment, key string) (*model.Config, error)
-	getAllFunc func(environment string) ([]*model.Config, error)
-	updateFunc func(environment, key, value string) error
-	deleteFunc func(environment, key string) error
+	createFunc      func(environment, key, value string, spec model.ValueSpec, actor string) error
+	getFunc         func(environment, key string) (*model.Config, error)
+	revealFunc      func(environment, key string) (*model.Config, error)
+	getAtFunc       func(environment, key string, asOf time.Time) (*model.Config, error)
+	getAllFunc      func(environment string) ([]*model.Config, error)
+	getAllAtFunc    func(environment string, asOf time.Time) ([]*model.Config, error)
+	listFunc        func(environment string, query model.ConfigQuery) (*model.ConfigPage, error)
+	historyFunc     func(environment, key string) ([]*model.Revision, error)
+	updateFunc      func(environment, key, value string, spec model.ValueSpec, actor string, version int64) error
+	deleteFunc      func(environment, key, actor string, version int64) error
+	deleteTreeFunc  func(environment, tree, actor string) ([]*model.Revision, error)
+	rollbackFunc    func(environment, key string, revision int64, actor string) ([]*model.Revision, error)
+	rollbackEnvFunc func(environment string, asOf time.Time, actor string) ([]*model.Revision, error)
+	batchFunc       func(environment string, operations []model.BatchOperation, actor string) ([]model.BatchResult, error)
+	resolveFunc     func(environment string) ([]*model.ResolvedConfig, error)
+	watchFunc       func(environment string) (<-chan *model.Revision, func(), error)
+	changesFunc     func(environment string, since int64) ([]*model.Revision, error)
+	compareFunc     func(left, right model.EnvironmentRef) (*model.EnvironmentDiff, error)
+	importFunc      func(environment string, values []model.KeyValue, mode model.ImportMode, actor string, dryRun bool) (*model.ImportReport, error)
 }
 
-func (s stubConfigService) CreateConfig(environment, key, value string) error {
+func (s stubConfigService) CreateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, actor string) error {
 	if s.createFunc != nil {
-		return s.createFunc(environment, key, value)
+		return s.createFunc(environment, key, value, spec, actor)
 	}
 	return nil
 }
 
-func (s stubConfigService) GetConfig(environment, key string) (*model.Config, error) {
+func (s stubConfigService) GetConfig(ctx context.Context, environment, key string) (*model.Config, error) {
 	if s.getFunc != nil {
 		return s.getFunc(environment, key)
 	}
-	return &model.Config{Environment: environment, Key: key, Value: "value"}, nil
+	return &model.Config{Environment: environment, Key: key, Value: "value", Version: 3}, nil
 }
 
-func (s stubConfigService) GetAllConfigs(environment string) ([]*model.Config, error) {
+func (s stubConfigService) RevealConfig(ctx context.Context, environment, key string) (*model.Config, error) {
+	if s.revealFunc != nil {
+		return s.revealFunc(environment, key)
+	}
+	return &model.Config{Environment: environment, Key: key, Value: "value", Version: 3}, nil
+}
+
+func (s stubConfigService) GetConfigAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	if s.getAtFunc != nil {
+		return s.getAtFunc(environment, key, asOf)
+	}
+	return &model.Config{Environment: environment, Key: key, Value: "old value"}, nil
+}
+
+func (s stubConfigService) GetAllConfigs(ctx context.Context, environment string) ([]*model.Config, error) {
 	if s.getAllFunc != nil {
 		return s.getAllFunc(environment)
 	}
 	return []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, nil
 }
 
-func (s stubConfigService) UpdateConfig(environment, key, value string) error {
+func (s stubConfigService) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) (*model.ConfigPage, error) {
+	if s.listFunc != nil {
+		return s.listFunc(environment, query)
+	}
+	return &model.ConfigPage{Configs: []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, Total: 1}, nil
+}
+
+func (s stubConfigService) ImportConfigs(ctx context.Context, environment string, values []model.KeyValue, mode model.ImportMode, actor string, dryRun bool) (*model.ImportReport, error) {
+	if s.importFunc != nil {
+		return s.importFunc(environment, values, mode, actor, dryRun)
+	}
+	return &model.ImportReport{Environment: environment, Mode: mode, DryRun: dryRun}, nil
+}
+
+func (s stubConfigService) CompareEnvironments(ctx context.Context, left, right model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+	if s.compareFunc != nil {
+		return s.compareFunc(left, right)
+	}
+	return model.CompareConfigs(left, right, nil, nil), nil
+}
+
+func (s stubConfigService) GetAllConfigsAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	if s.getAllAtFunc != nil {
+		return s.getAllAtFunc(environment, asOf)
+	}
+	return []*model.Config{{Environment: environment, Key: "key", Value: "old value"}}, nil
+}
+
+func (s stubConfigService) GetConfigHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	if s.historyFunc != nil {
+		return s.historyFunc(environment, key)
+	}
+	return []*model.Revision{
+		{Revision: 2, Environment: environment, Key: key, Value: "value", Operation: model.OperationUpdate},
+		{Revision: 1, Environment: environment, Key: key, Value: "old value", Operation: model.OperationCreate},
+	}, nil
+}
+
+func (s stubConfigService) UpdateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, actor string, version int64) error {
 	if s.updateFunc != nil {
-		return s.updateFunc(environment, key, value)
+		return s.updateFunc(environment, key, value, spec, actor, version)
 	}
 	return nil
 }
 
-func (s stubConfigService) DeleteConfig(environment, key string) error {
+func (s stubConfigService) DeleteConfig(ctx context.Context, environment, key, actor string, version int64) error {
 	if s.deleteFunc != nil {
-		return s.deleteFunc(environment, key)
+		return s.deleteFunc(environment, key, actor, version)
 	}
 	return nil
 }
 
+func (s stubConfigService) DeleteTree(ctx context.Context, environment, tree, actor string) ([]*model.Revision, error) {
+	if s.deleteTreeFunc != nil {
+		return s.deleteTreeFunc(environment, tree, actor)
+	}
+	return []*model.Revision{{Revision: 5, Environment: environment, Key: tree, Operation: model.OperationDelete}}, nil
+}
+
+func (s stubConfigService) RollbackConfig(ctx context.Context, environment, key string, revision int64, actor string) ([]*model.Revision, error) {
+	if s.rollbackFunc != nil {
+		return s.rollbackFunc(environment, key, revision, actor)
+	}
+	return []*model.Revision{{Revision: revision + 1, Environment: environment, Key: key, Operation: model.OperationUpdate}}, nil
+}
+
+func (s stubConfigService) RollbackEnvironment(ctx context.Context, environment string, asOf time.Time, actor string) ([]*model.Revision, error) {
+	if s.rollbackEnvFunc != nil {
+		return s.rollbackEnvFunc(environment, asOf, actor)
+	}
+	return nil, nil
+}
+
+func (s stubConfigService) ApplyBatch(ctx context.Context, environment string, operations []model.BatchOperation, actor string) ([]model.BatchResult, error) {
+	if s.batchFunc != nil {
+		return s.batchFunc(environment, operations, actor)
+	}
+	results := make([]model.BatchResult, len(operations))
+	for i, op := range operations {
+		results[i] = model.BatchResult{Op: op.Op, Key: op.Key, Status: model.BatchStatusApplied, Revision: int64(i + 1)}
+	}
+	return results, nil
+}
+
+func (s stubConfigService) ResolveConfigs(ctx context.Context, environment string) ([]*model.ResolvedConfig, error) {
+	if s.resolveFunc != nil {
+		return s.resolveFunc(environment)
+	}
+	return []*model.ResolvedConfig{
+		{Config: model.Config{Environment: environment, Key: "key", Value: "base value"}, Source: "base"},
+	}, nil
+}
+
+func (s stubConfigService) WatchConfigs(ctx context.Context, environment string) (<-chan *model.Revision, func(), error) {
+	if s.watchFunc != nil {
+		return s.watchFunc(environment)
+	}
+	changes := make(chan *model.Revision)
+	close(changes)
+	return changes, func() {}, nil
+}
+
+func (s stubConfigService) GetChangesSince(ctx context.Context, environment string, since int64) ([]*model.Revision, error) {
+	if s.changesFunc != nil {
+		return s.changesFunc(environment, since)
+	}
+	return nil, nil
+}
+
 func TestConfigHandler_RegisterRoutesHealthAndDocs(t *testing.T) {
 	mux := http.NewServeMux()
 	NewConfigHandler(stubConfigService{}).RegisterRoutes(mux)
@@ -155,13 +281,126 @@ func TestConfigHandler_HandleConfigsRoutes(t *testing.T) {
 			method: http.MethodGet,
 			path:   "/api/configs/prod",
 			service: stubConfigService{
-				getAllFunc: func(string) ([]*model.Config, error) {
+				listFunc: func(string, model.ConfigQuery) (*model.ConfigPage, error) {
 					return nil, errors.New("db down")
 				},
 			},
 			wantStatus: http.StatusInternalServerError,
 			wantBody:   "internal server error",
 		},
+		{
+			name:   "list tree nested",
+			method: http.MethodGet,
+			path:   "/api/configs/prod?tree=payments&view=nested",
+			service: stubConfigService{
+				listFunc: func(environment string, query model.ConfigQuery) (*model.ConfigPage, error) {
+					if query.Tree != "payments" {
+						t.Errorf("tree = %q, want payments", query.Tree)
+					}
+					return &model.ConfigPage{Configs: []*model.Config{
+						{Environment: environment, Key: "payments.stripe.timeout", Value: "30s"},
+						{Environment: environment, Key: "payments.currency", Value: "EUR"},
+					}, Total: 2}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"payments":{"currency":"EUR","stripe":{"timeout":"30s"}}}`,
+		},
+		{
+			name:   "list nested conflict",
+			method: http.MethodGet,
+			path:   "/api/configs/prod?view=nested",
+			service: stubConfigService{
+				listFunc: func(environment string, _ model.ConfigQuery) (*model.ConfigPage, error) {
+					return &model.ConfigPage{Configs: []*model.Config{
+						{Environment: environment, Key: "payments", Value: "on"},
+						{Environment: environment, Key: "payments.currency", Value: "EUR"},
+					}, Total: 2}, nil
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "key is both a value and a parent of other keys: payments\n",
+		},
+		{
+			name:       "list nested with limit",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?view=nested&limit=10",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "view=nested cannot be paged",
+		},
+		{
+			name:       "list invalid view",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?view=tree",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid view",
+		},
+		{
+			name:       "delete tree",
+			method:     http.MethodDelete,
+			path:       "/api/configs/prod?tree=payments.stripe",
+			wantStatus: http.StatusOK,
+			wantBody:   `"key":"payments.stripe"`,
+		},
+		{
+			name:       "delete tree without tree",
+			method:     http.MethodDelete,
+			path:       "/api/configs/prod",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "tree is required",
+		},
+		{
+			name:   "delete empty tree",
+			method: http.MethodDelete,
+			path:   "/api/configs/prod?tree=missing",
+			service: stubConfigService{
+				deleteTreeFunc: func(string, string, string) ([]*model.Revision, error) {
+					return nil, service.ErrConfigNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "config not found",
+		},
+		{
+			name:       "list invalid limit",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?limit=ten",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid limit",
+		},
+		{
+			name:       "list invalid cursor",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?limit=10&cursor=garbage",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid cursor",
+		},
+		{
+			name:       "list invalid updated_since",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?updated_since=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid updated_since",
+		},
+		{
+			name:       "list as_of with limit",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?as_of=2026-06-09T10:00:00Z&limit=10",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "as_of cannot be combined with limit",
+		},
+		{
+			name:   "list invalid query",
+			method: http.MethodGet,
+			path:   "/api/configs/prod?sort=value",
+			service: stubConfigService{
+				listFunc: func(_ string, query model.ConfigQuery) (*model.ConfigPage, error) {
+					return nil, query.Validate()
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "invalid config query",
+		},
 		{
 			name:       "get config",
 			method:     http.MethodGet,
@@ -202,7 +441,7 @@ func TestConfigHandler_HandleConfigsRoutes(t *testing.T) {
 			path:   "/api/configs/prod/key",
 			body:   `{"value":"created"}`,
 			service: stubConfigService{
-				createFunc: func(string, string, string) error {
+				createFunc: func(string, string, string, model.ValueSpec, string) error {
 					return service.ErrConfigExists
 				},
 			},
@@ -230,7 +469,7 @@ func TestConfigHandler_HandleConfigsRoutes(t *testing.T) {
 			path:   "/api/configs/prod/key",
 			body:   `{"value":"updated"}`,
 			service: stubConfigService{
-				updateFunc: func(string, string, string) error {
+				updateFunc: func(string, string, string, model.ValueSpec, string, int64) error {
 					return errors.New("validation")
 				},
 			},
@@ -248,7 +487,7 @@ func TestConfigHandler_HandleConfigsRoutes(t *testing.T) {
 			method: http.MethodDelete,
 			path:   "/api/configs/prod/key",
 			service: stubConfigService{
-				deleteFunc: func(string, string) error {
+				deleteFunc: func(string, string, string, int64) error {
 					return service.ErrConfigNotFound
 				},
 			},
@@ -262,6 +501,269 @@ func TestConfigHandler_HandleConfigsRoutes(t *testing.T) {
 			wantStatus: http.StatusMethodNotAllowed,
 			wantBody:   "method not allowed",
 		},
+		{
+			name:       "get config as of",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/key?as_of=2026-06-09T10:00:00Z",
+			wantStatus: http.StatusOK,
+			wantBody:   `"value":"old value"`,
+		},
+		{
+			name:       "get all configs as of",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod?as_of=2026-06-09T10:00:00Z",
+			wantStatus: http.StatusOK,
+			wantBody:   `"value":"old value"`,
+		},
+		{
+			name:       "invalid as of",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/key?as_of=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid as_of",
+		},
+		{
+			name:   "get config masks secret",
+			method: http.MethodGet,
+			path:   "/api/configs/prod/token",
+			service: stubConfigService{
+				getFunc: func(environment, key string) (*model.Config, error) {
+					return &model.Config{Environment: environment, Key: key, Value: model.SecretMask, ValueSpec: model.ValueSpec{Secret: true}}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"value":"********"`,
+		},
+		{
+			name:   "reveal config",
+			method: http.MethodGet,
+			path:   "/api/configs/prod/token?reveal=true",
+			service: stubConfigService{
+				revealFunc: func(environment, key string) (*model.Config, error) {
+					return &model.Config{Environment: environment, Key: key, Value: "hunter2", ValueSpec: model.ValueSpec{Secret: true}}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"value":"hunter2"`,
+		},
+		{
+			name:       "invalid reveal",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/token?reveal=please",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid reveal",
+		},
+		{
+			name:       "reveal with as of",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/token?reveal=true&as_of=2026-06-09T10:00:00Z",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "reveal cannot be combined with as_of",
+		},
+		{
+			name:   "secret without master key",
+			method: http.MethodPost,
+			path:   "/api/configs/prod/token",
+			body:   `{"value":"hunter2","secret":true}`,
+			service: stubConfigService{
+				createFunc: func(_, _, _ string, spec model.ValueSpec, _ string) error {
+					if !spec.Secret {
+						return errors.New("secret flag was not passed")
+					}
+					return service.ErrSecretsUnavailable
+				},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "secret values require a master key",
+		},
+		{
+			name:       "get config history",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/key/history",
+			wantStatus: http.StatusOK,
+			wantBody:   `"operation":"create"`,
+		},
+		{
+			name:   "history not found",
+			method: http.MethodGet,
+			path:   "/api/configs/prod/missing/history",
+			service: stubConfigService{
+				historyFunc: func(string, string) ([]*model.Revision, error) {
+					return nil, service.ErrConfigNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "config not found",
+		},
+		{
+			name:       "history method not allowed",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/key/history",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+		{
+			name:       "rollback config",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/key/rollback?revision=3",
+			wantStatus: http.StatusOK,
+			wantBody:   `"revision":4`,
+		},
+		{
+			name:       "rollback config invalid revision",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/key/rollback?revision=abc",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid revision",
+		},
+		{
+			name:   "rollback config revision not found",
+			method: http.MethodPost,
+			path:   "/api/configs/prod/key/rollback?revision=99",
+			service: stubConfigService{
+				rollbackFunc: func(string, string, int64, string) ([]*model.Revision, error) {
+					return nil, service.ErrRevisionNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "revision not found",
+		},
+		{
+			name:       "rollback config method not allowed",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/key/rollback",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+		{
+			name:       "rollback environment",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/rollback?as_of=2026-06-09T10:00:00Z",
+			wantStatus: http.StatusOK,
+			wantBody:   "[]",
+		},
+		{
+			name:       "rollback environment requires as of",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/rollback",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid as_of",
+		},
+		{
+			name:       "get key named rollback",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/rollback",
+			wantStatus: http.StatusOK,
+			wantBody:   `"key":"rollback"`,
+		},
+		{
+			name:       "batch",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod:batch",
+			body:       `{"operations":[{"op":"create","key":"a","value":"1"},{"op":"delete","key":"b"}]}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"op":"delete","key":"b","status":"applied","revision":2}`,
+		},
+		{
+			name:       "batch invalid json",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod:batch",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid json",
+		},
+		{
+			name:       "batch without operations",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod:batch",
+			body:       `{"operations":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "operations are required",
+		},
+		{
+			name:   "batch validation failure",
+			method: http.MethodPost,
+			path:   "/api/configs/prod:batch",
+			body:   `{"operations":[{"op":"rename","key":"a"}]}`,
+			service: stubConfigService{
+				batchFunc: func(_ string, operations []model.BatchOperation, _ string) ([]model.BatchResult, error) {
+					return []model.BatchResult{{Op: "rename", Key: "a", Status: model.BatchStatusFailed, Error: "invalid operation"}}, service.ErrBatchInvalid
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `"error":"invalid operation"`,
+		},
+		{
+			name:   "batch execution failure",
+			method: http.MethodPost,
+			path:   "/api/configs/prod:batch",
+			body:   `{"operations":[{"op":"update","key":"a","value":"1"}]}`,
+			service: stubConfigService{
+				batchFunc: func(string, []model.BatchOperation, string) ([]model.BatchResult, error) {
+					return []model.BatchResult{{Op: "update", Key: "a", Status: model.BatchStatusFailed, Error: "config not found"}}, service.ErrBatchFailed
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `"status":"failed"`,
+		},
+		{
+			name:       "batch method not allowed",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod:batch",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+		{
+			name:       "resolved configs",
+			method:     http.MethodGet,
+			path:       "/api/configs/production/resolved",
+			wantStatus: http.StatusOK,
+			wantBody:   `"source":"base"`,
+		},
+		{
+			name:   "resolved configs invalid chain",
+			method: http.MethodGet,
+			path:   "/api/configs/production/resolved",
+			service: stubConfigService{
+				resolveFunc: func(string) ([]*model.ResolvedConfig, error) {
+					return nil, model.ErrInvalidParent
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "invalid parent environment",
+		},
+		{
+			name:       "poll changes invalid since",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/watch?since=abc",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid since",
+		},
+		{
+			name:       "poll changes invalid timeout",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/watch?since=1&timeout=soon",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid timeout",
+		},
+		{
+			name:   "poll changes service error",
+			method: http.MethodGet,
+			path:   "/api/configs/prod/watch?since=1",
+			service: stubConfigService{
+				changesFunc: func(string, int64) ([]*model.Revision, error) {
+					return nil, errors.New("db down")
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "internal server error",
+		},
+		{
+			name:       "stream changes",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/watch",
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "invalid path",
 			method:     http.MethodGet,
@@ -290,12 +792,13 @@ func TestConfigHandler_HandleConfigsRoutes(t *testing.T) {
 }
 
 func TestConfigHandler_CreateConfigHelper(t *testing.T) {
-	var gotEnvironment, gotKey, gotValue string
+	var gotEnvironment, gotKey, gotValue, gotActor string
 	h := NewConfigHandler(stubConfigService{
-		createFunc: func(environment, key, value string) error {
+		createFunc: func(environment, key, value string, _ model.ValueSpec, actor string) error {
 			gotEnvironment = environment
 			gotKey = key
 			gotValue = value
+			gotActor = actor
 			return nil
 		},
 	})
@@ -308,8 +811,8 @@ func TestConfigHandler_CreateConfigHelper(t *testing.T) {
 	if rr.Code != http.StatusCreated {
 		t.Fatalf("status = %d, want %d", rr.Code, http.StatusCreated)
 	}
-	if gotEnvironment != "prod" || gotKey != "key" || gotValue != "created" {
-		t.Fatalf("CreateConfig called with %q, %q, %q", gotEnvironment, gotKey, gotValue)
+	if gotEnvironment != "prod" || gotKey != "key" || gotValue != "created" || gotActor != anonymousActor {
+		t.Fatalf("CreateConfig called with %q, %q, %q, %q", gotEnvironment, gotKey, gotValue, gotActor)
 	}
 
 	rr = httptest.NewRecorder()
@@ -320,7 +823,7 @@ func TestConfigHandler_CreateConfigHelper(t *testing.T) {
 	}
 
 	h = NewConfigHandler(stubConfigService{
-		createFunc: func(string, string, string) error {
+		createFunc: func(string, string, string, model.ValueSpec, string) error {
 			return service.ErrConfigExists
 		},
 	})
@@ -347,3 +850,394 @@ func TestConfigHandler_JSONResponseShape(t *testing.T) {
 		t.Fatalf("decoded config = %#v", got)
 	}
 }
+
+func TestConfigHandler_PassesActorAndAsOf(t *testing.T) {
+	var gotActor string
+	var gotAsOf time.Time
+	h := NewConfigHandler(stubConfigService{
+		updateFunc: func(_, _, _ string, _ model.ValueSpec, actor string, _ int64) error {
+			gotActor = actor
+			return nil
+		},
+		getAtFunc: func(environment, key string, asOf time.Time) (*model.Config, error) {
+			gotAsOf = asOf
+			return &model.Config{Environment: environment, Key: key}, nil
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/configs/prod/key", strings.NewReader(`{"value":"updated"}`))
+	req.Header.Set("X-Actor", "alice")
+	h.handleConfigs(rr, req)
+	if gotActor != "alice" {
+		t.Fatalf("UpdateConfig actor = %q, want %q", gotActor, "alice")
+	}
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/configs/prod/key?as_of=2026-06-09T10:00:00%2B03:00", nil)
+	h.handleConfigs(rr, req)
+	want := time.Date(2026, 6, 9, 7, 0, 0, 0, time.UTC)
+	if !gotAsOf.Equal(want) {
+		t.Fatalf("GetConfigAt asOf = %v, want %v", gotAsOf, want)
+	}
+}
+
+func TestConfigHandler_ListConfigs(t *testing.T) {
+	next := &model.ConfigCursor{Sort: model.SortByUpdatedAt, Key: "db.host", UpdatedAt: time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)}
+	var gotQuery model.ConfigQuery
+	h := NewConfigHandler(stubConfigService{
+		listFunc: func(environment string, query model.ConfigQuery) (*model.ConfigPage, error) {
+			gotQuery = query
+			return &model.ConfigPage{Configs: []*model.Config{{Environment: environment, Key: "db.host"}}, Total: 4000, NextCursor: next}, nil
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/configs/prod?limit=1&cursor="+next.Encode()+
+		"&prefix=db.&search=host&sort=updated_at&updated_since=2026-06-09T10:00:00%2B03:00", nil)
+	h.handleConfigs(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d; body=%q", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("X-Total-Count") != "4000" || rr.Header().Get("X-Next-Cursor") != next.Encode() {
+		t.Fatalf("headers = %v", rr.Header())
+	}
+	var configs []model.Config
+	if err := json.NewDecoder(rr.Body).Decode(&configs); err != nil || len(configs) != 1 {
+		t.Fatalf("body = %v, %v", configs, err)
+	}
+	if gotQuery.Limit != 1 || gotQuery.Prefix != "db." || gotQuery.Search != "host" || gotQuery.Sort != model.SortByUpdatedAt ||
+		gotQuery.After == nil || *gotQuery.After != *next || !gotQuery.UpdatedSince.Equal(time.Date(2026, 6, 9, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("query = %#v", gotQuery)
+	}
+
+	rr = httptest.NewRecorder()
+	h = NewConfigHandler(stubConfigService{})
+	h.handleConfigs(rr, httptest.NewRequest(http.MethodGet, "/api/configs/prod", nil))
+	if rr.Header().Get("X-Total-Count") != "1" || rr.Header().Values("X-Next-Cursor") != nil {
+		t.Fatalf("last page headers = %v", rr.Header())
+	}
+}
+
+func TestConfigHandler_EnforcesCallerGrants(t *testing.T) {
+	var gotActor string
+	h := NewConfigHandler(stubConfigService{
+		updateFunc: func(_, _, _ string, _ model.ValueSpec, actor string, _ int64) error {
+			gotActor = actor
+			return nil
+		},
+	})
+	caller := &model.Identity{Name: "ci", Grants: []model.Grant{{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleWriter}}}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "key inside grant", path: "/api/configs/prod/billing.rate", wantStatus: http.StatusNoContent},
+		{name: "key outside grant", path: "/api/configs/prod/db.host", wantStatus: http.StatusForbidden},
+		{name: "other environment", path: "/api/configs/staging/billing.rate", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotActor = ""
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(`{"value":"updated"}`))
+			req.Header.Set("X-Actor", "mallory")
+			h.handleConfigs(rr, req.WithContext(model.ContextWithIdentity(req.Context(), caller)))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if rr.Code == http.StatusNoContent && gotActor != "ci" {
+				t.Fatalf("UpdateConfig actor = %q, want the authenticated caller", gotActor)
+			}
+		})
+	}
+}
+
+func TestConfigHandler_ConditionalRequests(t *testing.T) {
+	var gotVersion int64
+	h := NewConfigHandler(stubConfigService{
+		updateFunc: func(_, _, _ string, _ model.ValueSpec, _ string, version int64) error {
+			gotVersion = version
+			if version != 0 && version != 3 {
+				return service.ErrVersionMismatch
+			}
+			return nil
+		},
+		deleteFunc: func(_, _, _ string, version int64) error {
+			gotVersion = version
+			if version != 0 && version != 3 {
+				return service.ErrVersionMismatch
+			}
+			return nil
+		},
+		createFunc: func(string, string, string, model.ValueSpec, string) error {
+			return service.ErrConfigExists
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.handleConfigs(rr, httptest.NewRequest(http.MethodGet, "/api/configs/prod/key", nil))
+	if got := rr.Header().Get("ETag"); got != `"3"` {
+		t.Fatalf("ETag = %q, want %q", got, `"3"`)
+	}
+
+	rr = httptest.NewRecorder()
+	h.handleConfigs(rr, httptest.NewRequest(http.MethodGet, "/api/configs/prod/key?as_of=2026-06-09T10:00:00Z", nil))
+	if got := rr.Header().Get("ETag"); got != "" {
+		t.Fatalf("historical read ETag = %q, want none", got)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		header      string
+		value       string
+		wantStatus  int
+		wantVersion int64
+	}{
+		{name: "update matching", method: http.MethodPut, header: "If-Match", value: `"3"`, wantStatus: http.StatusNoContent, wantVersion: 3},
+		{name: "update weak tag", method: http.MethodPut, header: "If-Match", value: `W/"3"`, wantStatus: http.StatusNoContent, wantVersion: 3},
+		{name: "update stale", method: http.MethodPut, header: "If-Match", value: `"2"`, wantStatus: http.StatusPreconditionFailed, wantVersion: 2},
+		{name: "update wildcard", method: http.MethodPut, header: "If-Match", value: "*", wantStatus: http.StatusNoContent},
+		{name: "update without header", method: http.MethodPut, wantStatus: http.StatusNoContent},
+		{name: "update malformed", method: http.MethodPut, header: "If-Match", value: "abc", wantStatus: http.StatusPreconditionFailed, wantVersion: -1},
+		{name: "delete matching", method: http.MethodDelete, header: "If-Match", value: `"3"`, wantStatus: http.StatusNoContent, wantVersion: 3},
+		{name: "delete stale", method: http.MethodDelete, header: "If-Match", value: `"1"`, wantStatus: http.StatusPreconditionFailed, wantVersion: 1},
+		{name: "create if none match", method: http.MethodPost, header: "If-None-Match", value: "*", wantStatus: http.StatusPreconditionFailed, wantVersion: -1},
+		{name: "create without header", method: http.MethodPost, wantStatus: http.StatusConflict, wantVersion: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotVersion = -1
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/configs/prod/key", strings.NewReader(`{"value":"v"}`))
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			h.handleConfigs(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if gotVersion != tt.wantVersion {
+				t.Fatalf("service version = %d, want %d", gotVersion, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestConfigHandler_PollChanges(t *testing.T) {
+	t.Run("returns pending revisions immediately", func(t *testing.T) {
+		var gotSince int64
+		h := NewConfigHandler(stubConfigService{
+			changesFunc: func(environment string, since int64) ([]*model.Revision, error) {
+				gotSince = since
+				return []*model.Revision{{Revision: since + 1, Environment: environment, Key: "key", Operation: model.OperationUpdate}}, nil
+			},
+		})
+		rr := httptest.NewRecorder()
+		h.handleConfigs(rr, httptest.NewRequest(http.MethodGet, "/api/configs/prod/watch?since=41", nil))
+
+		if rr.Code != http.StatusOK || gotSince != 41 || !strings.Contains(rr.Body.String(), `"revision":42`) {
+			t.Fatalf("status = %d, since = %d, body = %q", rr.Code, gotSince, rr.Body.String())
+		}
+	})
+
+	t.Run("waits for the next change", func(t *testing.T) {
+		changes := make(chan *model.Revision, 1)
+		calls := 0
+		h := NewConfigHandler(stubConfigService{
+			watchFunc: func(string) (<-chan *model.Revision, func(), error) {
+				return changes, func() {}, nil
+			},
+			changesFunc: func(environment string, since int64) ([]*model.Revision, error) {
+				calls++
+				if calls == 1 {
+					changes <- &model.Revision{Revision: 8, Environment: environment}
+					return nil, nil
+				}
+				return []*model.Revision{{Revision: 8, Environment: environment, Key: "key", Operation: model.OperationCreate}}, nil
+			},
+		})
+		rr := httptest.NewRecorder()
+		h.handleConfigs(rr, httptest.NewRequest(http.MethodGet, "/api/configs/prod/watch?since=7", nil))
+
+		if rr.Code != http.StatusOK || calls != 2 || !strings.Contains(rr.Body.String(), `"revision":8`) {
+			t.Fatalf("status = %d, calls = %d, body = %q", rr.Code, calls, rr.Body.String())
+		}
+	})
+
+	t.Run("times out with empty list", func(t *testing.T) {
+		h := NewConfigHandler(stubConfigService{
+			watchFunc: func(string) (<-chan *model.Revision, func(), error) {
+				return make(chan *model.Revision), func() {}, nil
+			},
+		})
+		rr := httptest.NewRecorder()
+		h.handleConfigs(rr, httptest.NewRequest(http.MethodGet, "/api/configs/prod/watch?since=7&timeout=10ms", nil))
+
+		if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+			t.Fatalf("status = %d, body = %q", rr.Code, rr.Body.String())
+		}
+	})
+}
+
+func TestConfigHandler_StreamChanges(t *testing.T) {
+	changes := make(chan *model.Revision, 3)
+	changes <- &model.Revision{Revision: 5, Environment: "prod", Key: "old", Operation: model.OperationUpdate}
+	// Revision 6 is published after 7, as when its writer is slower to publish.
+	changes <- &model.Revision{Revision: 7, Environment: "prod", Key: "other", Operation: model.OperationDelete}
+	changes <- &model.Revision{Revision: 6, Environment: "prod", Key: "fresh", Operation: model.OperationCreate}
+	close(changes)
+
+	var gotSince int64
+	h := NewConfigHandler(stubConfigService{
+		watchFunc: func(string) (<-chan *model.Revision, func(), error) {
+			return changes, func() {}, nil
+		},
+		changesFunc: func(environment string, since int64) ([]*model.Revision, error) {
+			gotSince = since
+			return []*model.Revision{{Revision: 5, Environment: environment, Key: "old", Operation: model.OperationUpdate}}, nil
+		},
+	})
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/configs/prod/watch", nil)
+	req.Header.Set("Last-Event-ID", "4")
+
+	h.handleConfigs(rr, req)
+
+	if rr.Code != http.StatusOK || gotSince != 4 {
+		t.Fatalf("status = %d, since = %d", rr.Code, gotSince)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	body := rr.Body.String()
+	if strings.Count(body, "id: 5\n") != 1 {
+		t.Fatalf("revision 5 should be sent once, body = %q", body)
+	}
+	for _, want := range []string{"event: updated\n", "id: 7\nevent: deleted\n", "id: 6\nevent: created\n", `"key":"fresh"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body = %q, want substring %q", body, want)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/configs/prod/watch", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	h.handleConfigs(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("invalid Last-Event-ID status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestConfigHandler_TypedValues(t *testing.T) {
+	var gotSpec model.ValueSpec
+	h := NewConfigHandler(stubConfigService{
+		createFunc: func(_, _, value string, spec model.ValueSpec, _ string) error {
+			gotSpec = spec
+			return spec.Check(value)
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/configs/prod/level", strings.NewReader(`{"value":"info","type":"enum","enum":["debug","info"]}`))
+	h.handleConfigs(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body=%q", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if gotSpec.Type != model.TypeEnum || len(gotSpec.Enum) != 2 {
+		t.Fatalf("spec = %#v", gotSpec)
+	}
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/configs/prod/enabled", strings.NewReader(`{"value":"tru","type":"bool"}`))
+	h.handleConfigs(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusUnprocessableEntity)
+	}
+	var body struct {
+		Error      string            `json:"error"`
+		Type       model.ValueType   `json:"type"`
+		Violations []model.Violation `json:"violations"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error body: %v; body=%q", err, rr.Body.String())
+	}
+	if body.Error != "invalid value" || body.Type != model.TypeBool || len(body.Violations) != 1 {
+		t.Fatalf("error body = %#v", body)
+	}
+
+	h = NewConfigHandler(stubConfigService{
+		updateFunc: func(string, string, string, model.ValueSpec, string, int64) error {
+			return model.ErrInvalidKey
+		},
+	})
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/configs/prod/key", strings.NewReader(`{"value":"v"}`))
+	h.handleConfigs(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), `"error":"invalid key"`) {
+		t.Fatalf("status = %d, body = %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestConfigHandler_ContextErrors(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		wantStatus int
+	}{
+		{name: "deadline exceeded", ctx: expired, err: errors.New("pq: canceling statement due to user request"), wantStatus: http.StatusGatewayTimeout},
+		{name: "client gone", ctx: canceled, err: errors.New("pq: canceling statement due to user request"), wantStatus: statusClientClosedRequest},
+		{name: "deadline error", ctx: context.Background(), err: fmt.Errorf("query: %w", context.DeadlineExceeded), wantStatus: http.StatusGatewayTimeout},
+		{name: "other error", ctx: context.Background(), err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewConfigHandler(stubConfigService{
+				getFunc: func(string, string) (*model.Config, error) { return nil, tt.err },
+			})
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/configs/prod/key", nil).WithContext(tt.ctx)
+
+			h.handleConfigs(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestConfigHandler_IsWatchRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: http.MethodGet, path: "/api/configs/prod/watch", want: true},
+		{method: http.MethodGet, path: "/api/configs/prod/watch?since=4", want: true},
+		{method: http.MethodGet, path: "/api/configs/prod/key"},
+		{method: http.MethodGet, path: "/api/configs/prod"},
+		{method: http.MethodPost, path: "/api/configs/prod/watch"},
+		{method: http.MethodGet, path: "/api/environments/watch"},
+	}
+	h := NewConfigHandler(stubConfigService{})
+	for _, tt := range tests {
+		if got := h.IsWatchRequest(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("IsWatchRequest(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
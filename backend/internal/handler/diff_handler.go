@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// compareEnvironments serves GET /api/diff?left=staging&right=production.
+// Either side may be pinned to a point in time as env@<RFC 3339>.
+func (h *ConfigHandler) compareEnvironments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	left, err := parseEnvironmentRef(query.Get("left"))
+	if err != nil {
+		http.Error(w, "invalid left", http.StatusBadRequest)
+		return
+	}
+	right, err := parseEnvironmentRef(query.Get("right"))
+	if err != nil {
+		http.Error(w, "invalid right", http.StatusBadRequest)
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "unified" {
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.serviceFor(r).CompareEnvironments(r.Context(), left, right)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if format == "unified" {
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		_, _ = w.Write([]byte(formatUnifiedDiff(diff)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(diff)
+}
+
+func parseEnvironmentRef(raw string) (model.EnvironmentRef, error) {
+	name, at, pinned := strings.Cut(strings.TrimSpace(raw), "@")
+	if name == "" {
+		return model.EnvironmentRef{}, fmt.Errorf("environment is required")
+	}
+	if !pinned {
+		return model.EnvironmentRef{Name: name}, nil
+	}
+	asOf, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return model.EnvironmentRef{}, err
+	}
+	return model.EnvironmentRef{Name: name, AsOf: asOf}, nil
+}
+
+// formatUnifiedDiff renders the diff as key=value lines in key order, with
+// "-" for the left side and "+" for the right side.
+func formatUnifiedDiff(diff *model.EnvironmentDiff) string {
+	type entry struct {
+		key   string
+		lines []string
+	}
+	entries := make([]entry, 0, len(diff.OnlyInLeft)+len(diff.OnlyInRight)+len(diff.Changed))
+	for _, config := range diff.OnlyInLeft {
+		entries = append(entries, entry{config.Key, []string{"-" + formatDiffLine(config)}})
+	}
+	for _, config := range diff.OnlyInRight {
+		entries = append(entries, entry{config.Key, []string{"+" + formatDiffLine(config)}})
+	}
+	for _, change := range diff.Changed {
+		entries = append(entries, entry{change.Key, []string{"-" + formatDiffLine(change.Left), "+" + formatDiffLine(change.Right)}})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", diff.Left, diff.Right)
+	for _, e := range entries {
+		for _, line := range e.lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func formatDiffLine(config *model.Config) string {
+	line := config.Key + "=" + strings.ReplaceAll(config.Value, "\n", `\n`)
+	if config.Type != "" {
+		line += " # " + string(config.Type)
+	}
+	return line
+}
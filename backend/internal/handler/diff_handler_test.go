@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigHandler_CompareEnvironments(t *testing.T) {
+	compare := func(left, right model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+		return model.CompareConfigs(left, right,
+			[]*model.Config{
+				{Key: "db.host", Value: "db-1"},
+				{Key: "legacy", Value: "on"},
+				{Key: "pool", Value: "10", ValueSpec: model.ValueSpec{Type: model.TypeInt}},
+			},
+			[]*model.Config{
+				{Key: "db.host", Value: "db-2"},
+				{Key: "feature", Value: "true"},
+				{Key: "pool", Value: "10", ValueSpec: model.ValueSpec{Type: model.TypeInt}},
+			},
+		), nil
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		service     stubConfigService
+		wantStatus  int
+		wantBody    string
+		contentType string
+	}{
+		{
+			name:        "json",
+			method:      http.MethodGet,
+			path:        "/api/diff?left=staging&right=production",
+			service:     stubConfigService{compareFunc: compare},
+			wantStatus:  http.StatusOK,
+			wantBody:    `"only_in_left":[{"env":"","key":"legacy"`,
+			contentType: "application/json",
+		},
+		{
+			name:        "unified",
+			method:      http.MethodGet,
+			path:        "/api/diff?left=staging&right=production@2026-06-01T00:00:00Z&format=unified",
+			service:     stubConfigService{compareFunc: compare},
+			wantStatus:  http.StatusOK,
+			wantBody:    "--- staging\n+++ production@2026-06-01T00:00:00Z\n-db.host=db-1\n+db.host=db-2\n+feature=true\n-legacy=on\n",
+			contentType: "text/x-diff; charset=utf-8",
+		},
+		{
+			name:   "pinned side",
+			method: http.MethodGet,
+			path:   "/api/diff?left=production@2026-06-01T10:00:00Z&right=production",
+			service: stubConfigService{
+				compareFunc: func(left, right model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+					if !left.AsOf.Equal(time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)) || !right.AsOf.IsZero() {
+						return nil, service.ErrEnvironmentNotFound
+					}
+					return model.CompareConfigs(left, right, nil, nil), nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"left":"production@2026-06-01T10:00:00Z","right":"production"`,
+		},
+		{
+			name:       "missing left",
+			method:     http.MethodGet,
+			path:       "/api/diff?right=production",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid left",
+		},
+		{
+			name:       "invalid timestamp",
+			method:     http.MethodGet,
+			path:       "/api/diff?left=staging&right=production@yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid right",
+		},
+		{
+			name:       "invalid format",
+			method:     http.MethodGet,
+			path:       "/api/diff?left=staging&right=production&format=xml",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid format",
+		},
+		{
+			name:   "unknown environment",
+			method: http.MethodGet,
+			path:   "/api/diff?left=staging&right=missing",
+			service: stubConfigService{
+				compareFunc: func(model.EnvironmentRef, model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+					return nil, service.ErrEnvironmentNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "environment not found",
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			path:       "/api/diff?left=staging&right=production",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			NewConfigHandler(tt.service).RegisterRoutes(mux)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %q, want substring %q", rr.Body.String(), tt.wantBody)
+			}
+			if tt.contentType != "" && rr.Header().Get("Content-Type") != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", rr.Header().Get("Content-Type"), tt.contentType)
+			}
+		})
+	}
+}
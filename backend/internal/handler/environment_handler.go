@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type EnvironmentHandler struct {
+	service service.EnvironmentService
+}
+
+func NewEnvironmentHandler(service service.EnvironmentService) *EnvironmentHandler {
+	return &EnvironmentHandler{service: service}
+}
+
+func (h *EnvironmentHandler) serviceFor(r *http.Request) service.EnvironmentService {
+	return service.AuthorizeEnvironments(service.AuditEnvironments(h.service, auditRequest(r)), model.IdentityFromContext(r.Context()))
+}
+
+func (h *EnvironmentHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/environments", h.handleEnvironments)
+	mux.HandleFunc("/api/environments/", h.handleEnvironments)
+}
+
+func (h *EnvironmentHandler) handleEnvironments(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/environments")
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(parts) == 1 && parts[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.listEnvironments(w, r)
+		case http.MethodPost:
+			h.createEnvironment(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[0] != "" && parts[1] == "promote" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.promoteEnvironment(w, r, parts[0])
+		return
+	}
+
+	if len(parts) != 1 {
+		http.Error(w, "invalid path", http.StatusBadRequest)
+		return
+	}
+
+	name := parts[0]
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getEnvironment(w, r, name)
+
+	case http.MethodPut:
+		h.putEnvironment(w, r, name)
+
+	case http.MethodDelete:
+		h.deleteEnvironment(w, r, name)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *EnvironmentHandler) listEnvironments(w http.ResponseWriter, r *http.Request) {
+	environments, err := h.serviceFor(r).ListEnvironments(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if environments == nil {
+		environments = []*model.Environment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(environments)
+}
+
+func (h *EnvironmentHandler) createEnvironment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+		model.EnvironmentAttributes
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	environment, err := h.serviceFor(r).CreateEnvironment(r.Context(), strings.TrimSpace(req.Name), trimAttributes(req.EnvironmentAttributes))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(environment)
+}
+
+func (h *EnvironmentHandler) getEnvironment(w http.ResponseWriter, r *http.Request, name string) {
+	environment, err := h.serviceFor(r).GetEnvironment(r.Context(), name)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(environment)
+}
+
+func (h *EnvironmentHandler) putEnvironment(w http.ResponseWriter, r *http.Request, name string) {
+	var req model.EnvironmentAttributes
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	environment, err := h.serviceFor(r).PutEnvironment(r.Context(), name, trimAttributes(req))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(environment)
+}
+
+func (h *EnvironmentHandler) deleteEnvironment(w http.ResponseWriter, r *http.Request, name string) {
+	force := false
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "invalid force", http.StatusBadRequest)
+			return
+		}
+		force = parsed
+	}
+
+	if err := h.serviceFor(r).DeleteEnvironment(r.Context(), name, actorFromRequest(r), force); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *EnvironmentHandler) promoteEnvironment(w http.ResponseWriter, r *http.Request, source string) {
+	query := r.URL.Query()
+	target := strings.TrimSpace(query.Get("to"))
+	if target == "" {
+		http.Error(w, "to is required", http.StatusBadRequest)
+		return
+	}
+	dryRun := false
+	if raw := query.Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "invalid dry_run", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+	filter := model.KeyFilter{
+		Include: splitPatterns(query["include"]),
+		Exclude: splitPatterns(query["exclude"]),
+	}
+
+	promotion, err := h.serviceFor(r).PromoteEnvironment(r.Context(), source, target, filter, actorFromRequest(r), dryRun)
+	statusCode := http.StatusOK
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrBatchInvalid) && promotion != nil:
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrBatchFailed) && promotion != nil:
+		statusCode = http.StatusConflict
+	default:
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(promotion)
+}
+
+// splitPatterns accepts both repeated parameters and comma-separated lists.
+func splitPatterns(values []string) []string {
+	var patterns []string
+	for _, value := range values {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+func trimAttributes(attributes model.EnvironmentAttributes) model.EnvironmentAttributes {
+	attributes.Parent = strings.TrimSpace(attributes.Parent)
+	attributes.Description = strings.TrimSpace(attributes.Description)
+	attributes.Owner = strings.TrimSpace(attributes.Owner)
+	return attributes
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type stubEnvironmentService struct {
+	listFunc    func() ([]*model.Environment, error)
+	getFunc     func(name string) (*model.Environment, error)
+	createFunc  func(name string, attributes model.EnvironmentAttributes) (*model.Environment, error)
+	putFunc     func(name string, attributes model.EnvironmentAttributes) (*model.Environment, error)
+	deleteFunc  func(name, actor string, force bool) error
+	promoteFunc func(source, target string, filter model.KeyFilter, actor string, dryRun bool) (*model.Promotion, error)
+}
+
+func (s stubEnvironmentService) ListEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	if s.listFunc != nil {
+		return s.listFunc()
+	}
+	return []*model.Environment{{Name: "base", KeyCount: 3}}, nil
+}
+
+func (s stubEnvironmentService) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	if s.getFunc != nil {
+		return s.getFunc(name)
+	}
+	return &model.Environment{Name: name, EnvironmentAttributes: model.EnvironmentAttributes{Parent: "base"}}, nil
+}
+
+func (s stubEnvironmentService) CreateEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	if s.createFunc != nil {
+		return s.createFunc(name, attributes)
+	}
+	return &model.Environment{Name: name, EnvironmentAttributes: attributes}, nil
+}
+
+func (s stubEnvironmentService) PutEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	if s.putFunc != nil {
+		return s.putFunc(name, attributes)
+	}
+	return &model.Environment{Name: name, EnvironmentAttributes: attributes}, nil
+}
+
+func (s stubEnvironmentService) DeleteEnvironment(ctx context.Context, name, actor string, force bool) error {
+	if s.deleteFunc != nil {
+		return s.deleteFunc(name, actor, force)
+	}
+	return nil
+}
+
+func (s stubEnvironmentService) PromoteEnvironment(ctx context.Context, source, target string, filter model.KeyFilter, actor string, dryRun bool) (*model.Promotion, error) {
+	if s.promoteFunc != nil {
+		return s.promoteFunc(source, target, filter, actor, dryRun)
+	}
+	return &model.Promotion{
+		Source:     source,
+		Target:     target,
+		DryRun:     dryRun,
+		ConfigDiff: model.ConfigDiff{Added: []model.KeyChange{{Key: "feature.limit", NewValue: "100"}}},
+	}, nil
+}
+
+func TestEnvironmentHandler_HandleEnvironments(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		service    stubEnvironmentService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "list environments",
+			method:     http.MethodGet,
+			path:       "/api/environments",
+			wantStatus: http.StatusOK,
+			wantBody:   `"key_count":3`,
+		},
+		{
+			name:   "list empty",
+			method: http.MethodGet,
+			path:   "/api/environments/",
+			service: stubEnvironmentService{
+				listFunc: func() ([]*model.Environment, error) {
+					return nil, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "[]",
+		},
+		{
+			name:       "create environment",
+			method:     http.MethodPost,
+			path:       "/api/environments",
+			body:       `{"name":"qa","parent":"base","owner":" team-qa ","protected":true}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `"owner":"team-qa","protected":true`,
+		},
+		{
+			name:   "create existing environment",
+			method: http.MethodPost,
+			path:   "/api/environments",
+			body:   `{"name":"qa"}`,
+			service: stubEnvironmentService{
+				createFunc: func(string, model.EnvironmentAttributes) (*model.Environment, error) {
+					return nil, service.ErrEnvironmentExists
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "environment already exists",
+		},
+		{
+			name:   "create invalid name",
+			method: http.MethodPost,
+			path:   "/api/environments",
+			body:   `{"name":"QA"}`,
+			service: stubEnvironmentService{
+				createFunc: func(name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+					return model.NewEnvironment(name, attributes)
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "invalid environment name",
+		},
+		{
+			name:       "collection method not allowed",
+			method:     http.MethodDelete,
+			path:       "/api/environments",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+		{
+			name:       "nested path",
+			method:     http.MethodGet,
+			path:       "/api/environments/prod/extra",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid path",
+		},
+		{
+			name:       "get environment",
+			method:     http.MethodGet,
+			path:       "/api/environments/production",
+			wantStatus: http.StatusOK,
+			wantBody:   `"parent":"base"`,
+		},
+		{
+			name:   "get missing environment",
+			method: http.MethodGet,
+			path:   "/api/environments/missing",
+			service: stubEnvironmentService{
+				getFunc: func(string) (*model.Environment, error) {
+					return nil, service.ErrEnvironmentNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "environment not found",
+		},
+		{
+			name:       "put environment",
+			method:     http.MethodPut,
+			path:       "/api/environments/production",
+			body:       `{"parent":" staging "}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"parent":"staging"`,
+		},
+		{
+			name:       "put invalid json",
+			method:     http.MethodPut,
+			path:       "/api/environments/production",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid json",
+		},
+		{
+			name:   "put invalid parent",
+			method: http.MethodPut,
+			path:   "/api/environments/base",
+			body:   `{"parent":"production"}`,
+			service: stubEnvironmentService{
+				putFunc: func(string, model.EnvironmentAttributes) (*model.Environment, error) {
+					return nil, fmt.Errorf("%w: cycle", model.ErrInvalidParent)
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `"error":"invalid parent environment: cycle"`,
+		},
+		{
+			name:   "put service error",
+			method: http.MethodPut,
+			path:   "/api/environments/base",
+			body:   `{}`,
+			service: stubEnvironmentService{
+				putFunc: func(string, model.EnvironmentAttributes) (*model.Environment, error) {
+					return nil, errors.New("db down")
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "internal server error",
+		},
+		{
+			name:   "delete environment",
+			method: http.MethodDelete,
+			path:   "/api/environments/qa?force=true",
+			service: stubEnvironmentService{
+				deleteFunc: func(name, _ string, force bool) error {
+					if name != "qa" || !force {
+						return errors.New("unexpected delete")
+					}
+					return nil
+				},
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete invalid force",
+			method:     http.MethodDelete,
+			path:       "/api/environments/qa?force=maybe",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid force",
+		},
+		{
+			name:   "delete environment with configs",
+			method: http.MethodDelete,
+			path:   "/api/environments/qa",
+			service: stubEnvironmentService{
+				deleteFunc: func(string, string, bool) error {
+					return service.ErrEnvironmentNotEmpty
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "environment still has configs",
+		},
+		{
+			name:   "delete protected environment",
+			method: http.MethodDelete,
+			path:   "/api/environments/prod",
+			service: stubEnvironmentService{
+				deleteFunc: func(string, string, bool) error {
+					return service.ErrEnvironmentProtected
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "environment is protected",
+		},
+		{
+			name:       "promote dry run",
+			method:     http.MethodPost,
+			path:       "/api/environments/staging/promote?to=prod&dry_run=true",
+			wantStatus: http.StatusOK,
+			wantBody:   `"dry_run":true,"added":[{"key":"feature.limit","new_value":"100"}]`,
+		},
+		{
+			name:   "promote filters",
+			method: http.MethodPost,
+			path:   "/api/environments/staging/promote?to=prod&include=feature.*,db.*&include=cache.*&exclude=feature.legacy",
+			service: stubEnvironmentService{
+				promoteFunc: func(source, target string, filter model.KeyFilter, actor string, dryRun bool) (*model.Promotion, error) {
+					if len(filter.Include) != 3 || filter.Include[2] != "cache.*" || len(filter.Exclude) != 1 || dryRun {
+						return nil, fmt.Errorf("unexpected filter %#v", filter)
+					}
+					return &model.Promotion{Source: source, Target: target}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"target":"prod"`,
+		},
+		{
+			name:       "promote without target",
+			method:     http.MethodPost,
+			path:       "/api/environments/staging/promote",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "to is required",
+		},
+		{
+			name:       "promote invalid dry run",
+			method:     http.MethodPost,
+			path:       "/api/environments/staging/promote?to=prod&dry_run=maybe",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid dry_run",
+		},
+		{
+			name:   "promote conflict",
+			method: http.MethodPost,
+			path:   "/api/environments/staging/promote?to=prod",
+			service: stubEnvironmentService{
+				promoteFunc: func(source, target string, _ model.KeyFilter, _ string, _ bool) (*model.Promotion, error) {
+					return &model.Promotion{
+						Source:  source,
+						Target:  target,
+						Results: []model.BatchResult{{Op: model.OperationUpdate, Key: "a", Status: model.BatchStatusFailed}},
+					}, service.ErrBatchFailed
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `"status":"failed"`,
+		},
+		{
+			name:   "promote invalid filter",
+			method: http.MethodPost,
+			path:   "/api/environments/staging/promote?to=prod&include=[",
+			service: stubEnvironmentService{
+				promoteFunc: func(string, string, model.KeyFilter, string, bool) (*model.Promotion, error) {
+					return nil, model.ErrInvalidFilter
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "invalid key filter",
+		},
+		{
+			name:       "promote method not allowed",
+			method:     http.MethodGet,
+			path:       "/api/environments/staging/promote?to=prod",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPatch,
+			path:       "/api/environments/base",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			NewEnvironmentHandler(tt.service).RegisterRoutes(mux)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantBody != "" && !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %q, want substring %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"bytes"
+	"config-service/backend/internal/codec"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const maxImportBody = 10 << 20
+
+var fileExtensions = map[codec.Format]string{
+	codec.FormatJSON:       "json",
+	codec.FormatYAML:       "yaml",
+	codec.FormatDotenv:     "env",
+	codec.FormatProperties: "properties",
+}
+
+func (h *ConfigHandler) exportConfigs(w http.ResponseWriter, r *http.Request, environment string) {
+	format, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	asOf, pinned, err := parseAsOf(r)
+	if err != nil {
+		http.Error(w, "invalid as_of", http.StatusBadRequest)
+		return
+	}
+
+	var configs []*model.Config
+	if pinned {
+		configs, err = h.serviceFor(r).GetAllConfigsAt(r.Context(), environment, asOf)
+	} else {
+		configs, err = h.serviceFor(r).GetAllConfigs(r.Context(), environment)
+	}
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	tree := r.URL.Query().Get("tree")
+	values := make([]model.KeyValue, 0, len(configs))
+	for _, config := range configs {
+		if model.InTree(config.Key, tree) {
+			values = append(values, model.KeyValue{Key: config.Key, Value: config.Value})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, format, values); err != nil {
+		if errors.Is(err, codec.ErrUnsupportedKey) {
+			writeValidationError(w, err.Error(), nil)
+			return
+		}
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": environment + "." + fileExtensions[format],
+	}))
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (h *ConfigHandler) importConfigs(w http.ResponseWriter, r *http.Request, environment string) {
+	format, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	mode := model.ImportMerge
+	if raw := query.Get("mode"); raw != "" {
+		mode = model.ImportMode(raw)
+	}
+	dryRun := false
+	if raw := query.Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "invalid dry_run", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+
+	values, err := codec.Decode(http.MaxBytesReader(w, r.Body, maxImportBody), format)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.serviceFor(r).ImportConfigs(r.Context(), environment, values, mode, actorFromRequest(r), dryRun)
+	statusCode := http.StatusOK
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrBatchInvalid) && report != nil:
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrBatchFailed) && report != nil:
+		statusCode = http.StatusConflict
+	case errors.Is(err, service.ErrImportTooLarge):
+		http.Error(w, "too many keys", http.StatusRequestEntityTooLarge)
+		return
+	default:
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func parseFormat(w http.ResponseWriter, r *http.Request) (codec.Format, bool) {
+	raw := r.URL.Query().Get("format")
+	if raw == "" {
+		return codec.FormatJSON, true
+	}
+	format, err := codec.ParseFormat(raw)
+	if err != nil {
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return "", false
+	}
+	return format, true
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigHandler_ImportExport(t *testing.T) {
+	configs := func(string) ([]*model.Config, error) {
+		return []*model.Config{
+			{Environment: "prod", Key: "db.host", Value: "db-1"},
+			{Environment: "prod", Key: "replicas", Value: "3"},
+		}, nil
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		service     stubConfigService
+		wantStatus  int
+		wantBody    string
+		contentType string
+	}{
+		{
+			name:        "export json",
+			method:      http.MethodGet,
+			path:        "/api/configs/prod/export",
+			service:     stubConfigService{getAllFunc: configs},
+			wantStatus:  http.StatusOK,
+			wantBody:    "{\n  \"db.host\": \"db-1\",\n  \"replicas\": \"3\"\n}\n",
+			contentType: "application/json",
+		},
+		{
+			name:        "export dotenv",
+			method:      http.MethodGet,
+			path:        "/api/configs/prod/export?format=dotenv",
+			service:     stubConfigService{getAllFunc: configs},
+			wantStatus:  http.StatusOK,
+			wantBody:    "db.host=\"db-1\"\nreplicas=\"3\"\n",
+			contentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:       "export properties",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/export?format=properties",
+			service:    stubConfigService{getAllFunc: configs},
+			wantStatus: http.StatusOK,
+			wantBody:   "db.host=db-1\nreplicas=3\n",
+		},
+		{
+			name:   "export as of",
+			method: http.MethodGet,
+			path:   "/api/configs/prod/export?format=yaml&as_of=2026-06-01T00:00:00Z",
+			service: stubConfigService{
+				getAllAtFunc: func(environment string, _ time.Time) ([]*model.Config, error) {
+					return configs(environment)
+				},
+			},
+			wantStatus:  http.StatusOK,
+			wantBody:    "db.host: db-1\nreplicas: \"3\"\n",
+			contentType: "application/yaml",
+		},
+		{
+			name:   "export unsupported dotenv key",
+			method: http.MethodGet,
+			path:   "/api/configs/prod/export?format=dotenv",
+			service: stubConfigService{
+				getAllFunc: func(string) ([]*model.Config, error) {
+					return []*model.Config{{Key: "a=b", Value: "c"}}, nil
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "cannot be written as dotenv",
+		},
+		{
+			name:       "export tree",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/export?format=properties&tree=db",
+			service:    stubConfigService{getAllFunc: configs},
+			wantStatus: http.StatusOK,
+			wantBody:   "db.host=db-1\n",
+		},
+		{
+			name:       "export invalid format",
+			method:     http.MethodGet,
+			path:       "/api/configs/prod/export?format=toml",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid format",
+		},
+		{
+			name:   "import properties",
+			method: http.MethodPost,
+			path:   "/api/configs/prod/import?format=properties&mode=replace&dry_run=true",
+			body:   "db.host=db-2\nreplicas=4\n",
+			service: stubConfigService{
+				importFunc: func(environment string, values []model.KeyValue, mode model.ImportMode, _ string, dryRun bool) (*model.ImportReport, error) {
+					if len(values) != 2 || values[1].Value != "4" || mode != model.ImportReplace || !dryRun {
+						return nil, errors.New("unexpected import")
+					}
+					return &model.ImportReport{Environment: environment, Mode: mode, DryRun: dryRun, Updated: []string{"db.host", "replicas"}}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"updated":["db.host","replicas"]`,
+		},
+		{
+			name:       "import invalid document",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/import",
+			body:       `{"db": {"host": "x"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid document",
+		},
+		{
+			name:   "import invalid mode",
+			method: http.MethodPost,
+			path:   "/api/configs/prod/import?mode=overwrite",
+			body:   `{}`,
+			service: stubConfigService{importFunc: func(string, []model.KeyValue, model.ImportMode, string, bool) (*model.ImportReport, error) {
+				return nil, model.ErrInvalidImportMode
+			}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "invalid import mode",
+		},
+		{
+			name:       "import invalid dry run",
+			method:     http.MethodPost,
+			path:       "/api/configs/prod/import?dry_run=maybe",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid dry_run",
+		},
+		{
+			name:   "import failed",
+			method: http.MethodPost,
+			path:   "/api/configs/prod/import",
+			body:   `{"replicas": "many"}`,
+			service: stubConfigService{
+				importFunc: func(environment string, _ []model.KeyValue, mode model.ImportMode, _ string, _ bool) (*model.ImportReport, error) {
+					return &model.ImportReport{
+						Environment: environment,
+						Mode:        mode,
+						Results:     []model.BatchResult{{Op: model.OperationUpdate, Key: "replicas", Status: model.BatchStatusFailed}},
+					}, service.ErrBatchFailed
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `"status":"failed"`,
+		},
+		{
+			name:   "import too many keys",
+			method: http.MethodPost,
+			path:   "/api/configs/prod/import",
+			body:   `{}`,
+			service: stubConfigService{
+				importFunc: func(string, []model.KeyValue, model.ImportMode, string, bool) (*model.ImportReport, error) {
+					return nil, service.ErrImportTooLarge
+				},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   "too many keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			NewConfigHandler(tt.service).RegisterRoutes(mux)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %q, want substring %q", rr.Body.String(), tt.wantBody)
+			}
+			if tt.contentType != "" && rr.Header().Get("Content-Type") != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", rr.Header().Get("Content-Type"), tt.contentType)
+			}
+		})
+	}
+}
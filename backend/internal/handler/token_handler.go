@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TokenHandler struct {
+	service service.TokenService
+}
+
+func NewTokenHandler(service service.TokenService) *TokenHandler {
+	return &TokenHandler{service: service}
+}
+
+func (h *TokenHandler) serviceFor(r *http.Request) service.TokenService {
+	return service.AuthorizeTokens(h.service, model.IdentityFromContext(r.Context()))
+}
+
+func (h *TokenHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/tokens", h.handleTokens)
+	mux.HandleFunc("/api/tokens/", h.handleTokens)
+	mux.HandleFunc("/api/me", h.handleMe)
+}
+
+// anonymousIdentity describes callers when authentication is disabled and
+// every request is allowed everything.
+var anonymousIdentity = model.Identity{
+	Name:   "anonymous",
+	Source: "none",
+	Grants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}},
+}
+
+// handleMe returns the caller and the grants resolved for it, so that
+// clients can check what a token or an OIDC login is allowed to do.
+func (h *TokenHandler) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	identity := model.IdentityFromContext(r.Context())
+	if identity == nil {
+		identity = &anonymousIdentity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(identity)
+}
+
+func (h *TokenHandler) handleTokens(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tokens"), "/")
+
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.listTokens(w, r)
+		case http.MethodPost:
+			h.createToken(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.ParseInt(path, 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid token id", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.deleteToken(w, r, id)
+}
+
+func (h *TokenHandler) listTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.serviceFor(r).ListTokens(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if tokens == nil {
+		tokens = []*model.Token{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tokens)
+}
+
+// createToken returns the secret once; only its hash is stored.
+func (h *TokenHandler) createToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name      string        `json:"name"`
+		Grants    []model.Grant `json:"grants"`
+		ExpiresAt *time.Time    `json:"expires_at"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	token, secret, err := h.serviceFor(r).CreateToken(r.Context(), strings.TrimSpace(req.Name), req.Grants, req.ExpiresAt, actorFromRequest(r))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(struct {
+		*model.Token
+		Secret string `json:"token"`
+	}{Token: token, Secret: secret})
+}
+
+func (h *TokenHandler) deleteToken(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := h.serviceFor(r).DeleteToken(r.Context(), id); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubTokenService struct {
+	createFunc func(name string, grants []model.Grant, expiresAt *time.Time, actor string) (*model.Token, string, error)
+	deleteFunc func(id int64) error
+}
+
+func (stubTokenService) Authenticate(context.Context, string) (*model.Identity, error) {
+	return nil, nil
+}
+
+func (stubTokenService) ListTokens(ctx context.Context) ([]*model.Token, error) {
+	return []*model.Token{{ID: 1, Name: "ci", Grants: []model.Grant{{Environment: "prod", Role: model.RoleReader}}}}, nil
+}
+
+func (s stubTokenService) CreateToken(ctx context.Context, name string, grants []model.Grant, expiresAt *time.Time, actor string) (*model.Token, string, error) {
+	if s.createFunc != nil {
+		return s.createFunc(name, grants, expiresAt, actor)
+	}
+	return &model.Token{ID: 2, Name: name, Grants: grants, CreatedBy: actor, ExpiresAt: expiresAt}, "cfg_secret", nil
+}
+
+func (s stubTokenService) DeleteToken(ctx context.Context, id int64) error {
+	if s.deleteFunc != nil {
+		return s.deleteFunc(id)
+	}
+	return nil
+}
+
+func TestTokenHandler_HandleTokens(t *testing.T) {
+	globalAdmin := &model.Identity{Name: "root", Grants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}}}
+	envAdmin := &model.Identity{Name: "ops", Grants: []model.Grant{{Environment: "prod", Role: model.RoleAdmin}}}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		caller     *model.Identity
+		service    stubTokenService
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/tokens", caller: globalAdmin, wantStatus: http.StatusOK, wantBody: `"name":"ci"`},
+		{name: "list without auth", method: http.MethodGet, path: "/api/tokens", wantStatus: http.StatusOK},
+		{name: "list as environment admin", method: http.MethodGet, path: "/api/tokens", caller: envAdmin, wantStatus: http.StatusForbidden},
+		{
+			name:       "create returns the secret",
+			method:     http.MethodPost,
+			path:       "/api/tokens",
+			body:       `{"name":"deploy","grants":[{"env":"prod","key_prefix":"billing.","role":"writer"}],"expires_at":"2030-01-01T00:00:00Z"}`,
+			caller:     globalAdmin,
+			wantStatus: http.StatusCreated,
+			wantBody:   `"token":"cfg_secret"`,
+		},
+		{
+			name:   "create with invalid grant",
+			method: http.MethodPost,
+			path:   "/api/tokens",
+			body:   `{"name":"deploy","grants":[]}`,
+			service: stubTokenService{createFunc: func(string, []model.Grant, *time.Time, string) (*model.Token, string, error) {
+				return nil, "", model.ErrInvalidGrant
+			}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "create duplicate",
+			method: http.MethodPost,
+			path:   "/api/tokens",
+			body:   `{"name":"deploy","grants":[{"env":"prod","role":"reader"}]}`,
+			service: stubTokenService{createFunc: func(string, []model.Grant, *time.Time, string) (*model.Token, string, error) {
+				return nil, "", service.ErrTokenExists
+			}},
+			wantStatus: http.StatusConflict,
+		},
+		{name: "create with invalid json", method: http.MethodPost, path: "/api/tokens", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "delete", method: http.MethodDelete, path: "/api/tokens/2", caller: globalAdmin, wantStatus: http.StatusNoContent},
+		{
+			name:       "delete missing",
+			method:     http.MethodDelete,
+			path:       "/api/tokens/9",
+			service:    stubTokenService{deleteFunc: func(int64) error { return service.ErrTokenNotFound }},
+			wantStatus: http.StatusNotFound,
+		},
+		{name: "delete invalid id", method: http.MethodDelete, path: "/api/tokens/abc", wantStatus: http.StatusBadRequest},
+		{name: "get single token", method: http.MethodGet, path: "/api/tokens/2", wantStatus: http.StatusMethodNotAllowed},
+		{name: "put collection", method: http.MethodPut, path: "/api/tokens", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.caller != nil {
+				req = req.WithContext(model.ContextWithIdentity(req.Context(), tt.caller))
+			}
+			rr := httptest.NewRecorder()
+			NewTokenHandler(tt.service).handleTokens(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %q, want it to contain %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestTokenHandler_CreateRecordsCaller(t *testing.T) {
+	var gotActor string
+	h := NewTokenHandler(stubTokenService{createFunc: func(name string, grants []model.Grant, _ *time.Time, actor string) (*model.Token, string, error) {
+		gotActor = actor
+		return &model.Token{ID: 3, Name: name, Grants: grants, CreatedBy: actor}, "cfg_secret", nil
+	}})
+	caller := &model.Identity{Name: "root", Grants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(`{"name":"ci","grants":[{"env":"*","role":"reader"}]}`))
+	req.Header.Set("X-Actor", "mallory")
+	req = req.WithContext(model.ContextWithIdentity(req.Context(), caller))
+	rr := httptest.NewRecorder()
+	h.handleTokens(rr, req)
+
+	var body struct {
+		ID        int64  `json:"id"`
+		Token     string `json:"token"`
+		CreatedBy string `json:"created_by"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response %q: %v", rr.Body.String(), err)
+	}
+	if gotActor != "root" || body.ID != 3 || body.Token != "cfg_secret" || body.CreatedBy != "root" {
+		t.Fatalf("actor = %q, body = %#v", gotActor, body)
+	}
+	if rr.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Cache-Control = %q, want no-store", rr.Header().Get("Cache-Control"))
+	}
+}
+
+func TestTokenHandler_HandleMe(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	oidcUser := &model.Identity{
+		Name:      "alice@example.com",
+		Source:    model.IdentitySourceOIDC,
+		Groups:    []string{"billing"},
+		Grants:    []model.Grant{{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleWriter}},
+		ExpiresAt: &expiresAt,
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		caller     *model.Identity
+		wantStatus int
+		want       model.Identity
+	}{
+		{name: "oidc user", method: http.MethodGet, caller: oidcUser, wantStatus: http.StatusOK, want: *oidcUser},
+		{name: "without auth", method: http.MethodGet, wantStatus: http.StatusOK, want: anonymousIdentity},
+		{name: "post", method: http.MethodPost, caller: oidcUser, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/me", nil)
+			if tt.caller != nil {
+				req = req.WithContext(model.ContextWithIdentity(req.Context(), tt.caller))
+			}
+			rr := httptest.NewRecorder()
+			NewTokenHandler(stubTokenService{}).handleMe(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got model.Identity
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid response %q: %v", rr.Body.String(), err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("identity = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
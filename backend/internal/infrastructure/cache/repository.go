@@ -0,0 +1,242 @@
+// Package cache keeps recently read configs in memory in front of the
+// database.
+package cache
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"config-service/backend/pkg/metrics"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Repository serves Get from a size-bounded LRU cache whose entries expire
+// after a TTL. Writes through the repository invalidate the keys they touch;
+// writes made by other replicas must be reported with Invalidate. Everything
+// else is passed through.
+//
+// It is meant to wrap the database repository directly, below the secrets
+// decorator, so that secret values are only cached as ciphertext.
+type Repository struct {
+	repository.ConfigRepository
+	store *store
+}
+
+func NewRepository(next repository.ConfigRepository, cfg config.CacheConfig, m *metrics.Metrics) *Repository {
+	return &Repository{ConfigRepository: next, store: newStore(cfg.Size, cfg.TTL, m)}
+}
+
+func (r *Repository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
+	if r.store.size == 0 {
+		return r.ConfigRepository.Get(ctx, environment, key)
+	}
+	if config, ok := r.store.get(environment, key); ok {
+		return config, nil
+	}
+
+	generation := r.store.generation()
+	config, err := r.ConfigRepository.Get(ctx, environment, key)
+	if err != nil {
+		return nil, err
+	}
+	r.store.put(generation, config)
+	return config, nil
+}
+
+func (r *Repository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	defer r.store.invalidate(config.Environment, config.Key)
+	return r.ConfigRepository.Create(ctx, config)
+}
+
+func (r *Repository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	defer r.store.invalidate(config.Environment, config.Key)
+	return r.ConfigRepository.Update(ctx, config)
+}
+
+func (r *Repository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
+	defer r.store.invalidate(environment, key)
+	return r.ConfigRepository.Delete(ctx, environment, key, actor, version)
+}
+
+func (r *Repository) DeleteEnvironment(ctx context.Context, name string) error {
+	defer r.store.invalidateEnvironment(name)
+	return r.ConfigRepository.DeleteEnvironment(ctx, name)
+}
+
+// WithTx reads around the cache inside the transaction, which may see
+// uncommitted writes, and invalidates the keys written once it ends.
+func (r *Repository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	tx := &txRepository{}
+	defer func() {
+		for _, key := range tx.keys {
+			r.store.invalidate(key.environment, key.key)
+		}
+		for _, environment := range tx.environments {
+			r.store.invalidateEnvironment(environment)
+		}
+	}()
+	return r.ConfigRepository.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		tx.ConfigRepository = repo
+		return fn(tx)
+	})
+}
+
+// Invalidate drops a key changed elsewhere, such as by another replica.
+func (r *Repository) Invalidate(environment, key string) {
+	r.store.invalidate(environment, key)
+}
+
+// InvalidateAll drops every entry, for when changes may have been missed.
+func (r *Repository) InvalidateAll() {
+	r.store.clear()
+}
+
+type entryKey struct {
+	environment string
+	key         string
+}
+
+// txRepository records the keys written in a transaction.
+type txRepository struct {
+	repository.ConfigRepository
+	keys         []entryKey
+	environments []string
+}
+
+func (r *txRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	r.keys = append(r.keys, entryKey{config.Environment, config.Key})
+	return r.ConfigRepository.Create(ctx, config)
+}
+
+func (r *txRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	r.keys = append(r.keys, entryKey{config.Environment, config.Key})
+	return r.ConfigRepository.Update(ctx, config)
+}
+
+func (r *txRepository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
+	r.keys = append(r.keys, entryKey{environment, key})
+	return r.ConfigRepository.Delete(ctx, environment, key, actor, version)
+}
+
+func (r *txRepository) DeleteEnvironment(ctx context.Context, name string) error {
+	r.environments = append(r.environments, name)
+	return r.ConfigRepository.DeleteEnvironment(ctx, name)
+}
+
+func (r *txRepository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	return fn(r)
+}
+
+type entry struct {
+	key       entryKey
+	config    model.Config
+	expiresAt time.Time
+}
+
+// store is an LRU map of configs. Every invalidation bumps a generation
+// counter, and put drops a config read before the latest invalidation,
+// since the read may have returned the value the invalidation replaced.
+type store struct {
+	size    int
+	ttl     time.Duration
+	metrics *metrics.Metrics
+	now     func() time.Time
+
+	mu      sync.Mutex
+	gen     uint64
+	entries map[entryKey]*list.Element
+	order   *list.List
+}
+
+func newStore(size int, ttl time.Duration, m *metrics.Metrics) *store {
+	return &store{
+		size:    size,
+		ttl:     ttl,
+		metrics: m,
+		now:     time.Now,
+		entries: make(map[entryKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns a copy, so that callers may change the config.
+func (s *store) get(environment, key string) (*model.Config, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[entryKey{environment, key}]
+	if ok && s.now().After(element.Value.(*entry).expiresAt) {
+		s.remove(element)
+		ok = false
+	}
+	if !ok {
+		s.metrics.CacheRequestsTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	s.metrics.CacheRequestsTotal.WithLabelValues("hit").Inc()
+	s.order.MoveToFront(element)
+	config := element.Value.(*entry).config
+	return &config, true
+}
+
+func (s *store) generation() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen
+}
+
+func (s *store) put(generation uint64, config *model.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if generation != s.gen {
+		return
+	}
+
+	key := entryKey{config.Environment, config.Key}
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	s.entries[key] = s.order.PushFront(&entry{key: key, config: *config, expiresAt: s.now().Add(s.ttl)})
+	if s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+	s.metrics.CacheEntries.Set(float64(s.order.Len()))
+}
+
+func (s *store) invalidate(environment, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	if element, ok := s.entries[entryKey{environment, key}]; ok {
+		s.remove(element)
+	}
+}
+
+func (s *store) invalidateEnvironment(environment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	for key, element := range s.entries {
+		if key.environment == environment {
+			s.remove(element)
+		}
+	}
+}
+
+func (s *store) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	s.entries = make(map[entryKey]*list.Element)
+	s.order.Init()
+	s.metrics.CacheEntries.Set(0)
+}
+
+func (s *store) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*entry).key)
+	s.metrics.CacheEntries.Set(float64(s.order.Len()))
+}
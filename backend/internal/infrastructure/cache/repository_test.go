@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"config-service/backend/pkg/metrics"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// countingRepository stores configs in a map and counts the reads that
+// reach it.
+type countingRepository struct {
+	repository.ConfigRepository
+	configs map[string]string
+	reads   int
+	// onRead runs during a read, after the cache gave up on the key.
+	onRead func()
+}
+
+func newCountingRepository() *countingRepository {
+	return &countingRepository{configs: map[string]string{"prod/db.host": "db1", "prod/db.port": "5432", "dev/db.host": "dev1"}}
+}
+
+func (r *countingRepository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
+	r.reads++
+	if r.onRead != nil {
+		r.onRead()
+	}
+	value, ok := r.configs[environment+"/"+key]
+	if !ok {
+		return nil, repository.ErrConfigNotFound
+	}
+	return &model.Config{Environment: environment, Key: key, Value: value}, nil
+}
+
+func (r *countingRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	r.configs[config.Environment+"/"+config.Key] = config.Value
+	return &model.Revision{Environment: config.Environment, Key: config.Key, Value: config.Value}, nil
+}
+
+func (r *countingRepository) DeleteEnvironment(context.Context, string) error {
+	return nil
+}
+
+func (r *countingRepository) WithTx(ctx context.Context, fn func(repository.ConfigRepository) error) error {
+	return fn(r)
+}
+
+func testMetrics() *metrics.Metrics {
+	return &metrics.Metrics{
+		CacheRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_cache_requests_total"}, []string{"result"}),
+		CacheEntries:       prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_cache_entries"}),
+	}
+}
+
+func newTestRepository(inner *countingRepository, size int) (*Repository, *metrics.Metrics) {
+	m := testMetrics()
+	return NewRepository(inner, config.CacheConfig{Size: size, TTL: time.Minute}, m), m
+}
+
+func mustGet(t *testing.T, repo repository.ConfigRepository, environment, key string) *model.Config {
+	t.Helper()
+	config, err := repo.Get(context.Background(), environment, key)
+	if err != nil {
+		t.Fatalf("Get(%s, %s) error = %v", environment, key, err)
+	}
+	return config
+}
+
+func TestRepositoryCachesReads(t *testing.T) {
+	ctx := context.Background()
+	inner := newCountingRepository()
+	repo, m := newTestRepository(inner, 10)
+
+	first := mustGet(t, repo, "prod", "db.host")
+	first.Value = "changed by caller"
+	if second := mustGet(t, repo, "prod", "db.host"); second.Value != "db1" {
+		t.Fatalf("cached value = %q, want a copy unaffected by callers", second.Value)
+	}
+	if inner.reads != 1 {
+		t.Fatalf("reads = %d, want 1", inner.reads)
+	}
+	if hits, misses := testutil.ToFloat64(m.CacheRequestsTotal.WithLabelValues("hit")), testutil.ToFloat64(m.CacheRequestsTotal.WithLabelValues("miss")); hits != 1 || misses != 1 {
+		t.Fatalf("hits = %v, misses = %v", hits, misses)
+	}
+
+	if _, err := repo.Get(ctx, "prod", "missing"); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("Get() missing error = %v", err)
+	}
+	if _, err := repo.Get(ctx, "prod", "missing"); !errors.Is(err, repository.ErrConfigNotFound) || inner.reads != 3 {
+		t.Fatalf("missing keys must not be cached, reads = %d", inner.reads)
+	}
+}
+
+func TestRepositoryExpiresAndEvicts(t *testing.T) {
+	inner := newCountingRepository()
+	repo, m := newTestRepository(inner, 2)
+	now := time.Now()
+	repo.store.now = func() time.Time { return now }
+
+	mustGet(t, repo, "prod", "db.host")
+	mustGet(t, repo, "prod", "db.port")
+	mustGet(t, repo, "prod", "db.host")
+	mustGet(t, repo, "dev", "db.host")
+	if got := testutil.ToFloat64(m.CacheEntries); got != 2 {
+		t.Fatalf("entries = %v, want 2", got)
+	}
+	reads := inner.reads
+	mustGet(t, repo, "prod", "db.host")
+	if inner.reads != reads {
+		t.Fatal("recently used key was evicted")
+	}
+	mustGet(t, repo, "prod", "db.port")
+	if inner.reads != reads+1 {
+		t.Fatal("least recently used key was not evicted")
+	}
+
+	now = now.Add(2 * time.Minute)
+	mustGet(t, repo, "prod", "db.port")
+	if inner.reads != reads+2 {
+		t.Fatal("expired key was served from the cache")
+	}
+}
+
+func TestRepositoryInvalidatesWrites(t *testing.T) {
+	ctx := context.Background()
+	inner := newCountingRepository()
+	repo, _ := newTestRepository(inner, 10)
+
+	mustGet(t, repo, "prod", "db.host")
+	if _, err := repo.Update(ctx, &model.Config{Environment: "prod", Key: "db.host", Value: "db2"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := mustGet(t, repo, "prod", "db.host"); got.Value != "db2" {
+		t.Fatalf("value after Update() = %q, want db2", got.Value)
+	}
+
+	err := repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		if _, err := tx.Update(ctx, &model.Config{Environment: "prod", Key: "db.host", Value: "db3"}); err != nil {
+			return err
+		}
+		if got := mustGet(t, tx, "prod", "db.host"); got.Value != "db3" {
+			t.Fatalf("read in transaction = %q, want the uncommitted db3", got.Value)
+		}
+		return errors.New("rolled back")
+	})
+	if err == nil {
+		t.Fatal("WithTx() must return the error of fn")
+	}
+	reads := inner.reads
+	mustGet(t, repo, "prod", "db.host")
+	if inner.reads != reads+1 {
+		t.Fatal("key written in a transaction stayed cached")
+	}
+
+	mustGet(t, repo, "prod", "db.port")
+	mustGet(t, repo, "dev", "db.host")
+	if err := repo.DeleteEnvironment(ctx, "prod"); err != nil {
+		t.Fatalf("DeleteEnvironment() error = %v", err)
+	}
+	reads = inner.reads
+	mustGet(t, repo, "dev", "db.host")
+	mustGet(t, repo, "prod", "db.port")
+	if inner.reads != reads+1 {
+		t.Fatalf("reads = %d, want only the deleted environment to be read again", inner.reads-reads)
+	}
+}
+
+func TestRepositoryInvalidate(t *testing.T) {
+	inner := newCountingRepository()
+	repo, _ := newTestRepository(inner, 10)
+
+	mustGet(t, repo, "prod", "db.host")
+	inner.configs["prod/db.host"] = "remote"
+	repo.Invalidate("prod", "db.host")
+	if got := mustGet(t, repo, "prod", "db.host"); got.Value != "remote" {
+		t.Fatalf("value after Invalidate() = %q", got.Value)
+	}
+
+	inner.configs["prod/db.host"] = "missed"
+	repo.InvalidateAll()
+	if got := mustGet(t, repo, "prod", "db.host"); got.Value != "missed" {
+		t.Fatalf("value after InvalidateAll() = %q", got.Value)
+	}
+}
+
+func TestRepositoryDropsReadsRacingInvalidation(t *testing.T) {
+	inner := newCountingRepository()
+	repo, _ := newTestRepository(inner, 10)
+
+	// The read returns db1, but the key changes before it is cached.
+	inner.onRead = func() {
+		inner.onRead = nil
+		inner.configs["prod/db.host"] = "db2"
+		repo.Invalidate("prod", "db.host")
+	}
+	mustGet(t, repo, "prod", "db.host")
+	if got := mustGet(t, repo, "prod", "db.host"); got.Value != "db2" {
+		t.Fatalf("value = %q, want the read racing the invalidation to be dropped", got.Value)
+	}
+}
+
+func TestRepositoryDisabled(t *testing.T) {
+	inner := newCountingRepository()
+	repo, _ := newTestRepository(inner, 0)
+
+	mustGet(t, repo, "prod", "db.host")
+	mustGet(t, repo, "prod", "db.host")
+	if inner.reads != 2 {
+		t.Fatalf("reads = %d, want every read to reach the repository", inner.reads)
+	}
+}
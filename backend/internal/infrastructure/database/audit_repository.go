@@ -0,0 +1,121 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (r *postgresRepository) AppendAudit(ctx context.Context, entry *model.AuditEntry) error {
+	start := time.Now()
+	query := r.queries["append_audit"]
+	if query == "" {
+		return errors.New("append_audit query not found")
+	}
+	var revision sql.NullInt64
+	if entry.Revision != 0 {
+		revision = sql.NullInt64{Int64: entry.Revision, Valid: true}
+	}
+	err := r.db.QueryRowContext(ctx,
+		query,
+		entry.Actor,
+		entry.SourceIP,
+		entry.RequestID,
+		string(entry.Operation),
+		entry.Environment,
+		entry.Key,
+		revision,
+		nullString(entry.OldValue),
+		nullString(entry.NewValue),
+		entry.Secret,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("append_audit").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("append_audit").Observe(duration)
+	return err
+}
+
+func (r *postgresRepository) GetAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	start := time.Now()
+	query := r.queries["get_audit_entries"]
+	if query == "" {
+		return nil, errors.New("get_audit_entries query not found")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		query,
+		filter.Environment,
+		filter.Key,
+		filter.Actor,
+		nullTime(filter.From),
+		nullTime(filter.To),
+		filter.Cursor,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_audit_entries").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_audit_entries").Observe(duration)
+	return entries, nil
+}
+
+func scanAuditEntry(row rowScanner) (*model.AuditEntry, error) {
+	var entry model.AuditEntry
+	var operation string
+	var revision sql.NullInt64
+	var oldValue, newValue sql.NullString
+	if err := row.Scan(
+		&entry.ID,
+		&entry.Actor,
+		&entry.SourceIP,
+		&entry.RequestID,
+		&operation,
+		&entry.Environment,
+		&entry.Key,
+		&revision,
+		&oldValue,
+		&newValue,
+		&entry.Secret,
+		&entry.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	entry.Operation = model.AuditOperation(operation)
+	entry.Revision = revision.Int64
+	if oldValue.Valid {
+		entry.OldValue = &oldValue.String
+	}
+	if newValue.Valid {
+		entry.NewValue = &newValue.String
+	}
+	return &entry, nil
+}
+
+func nullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *value, Valid: true}
+}
+
+// nullTime passes an unset time as NULL so the query skips the bound.
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
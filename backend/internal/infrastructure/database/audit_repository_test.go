@@ -0,0 +1,56 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+var auditColumns = []string{
+	"id", "actor", "source_ip", "request_id", "operation", "env", "key", "revision", "old_value", "new_value", "secret", "created_at",
+}
+
+func TestPostgresRepositoryAppendAudit(t *testing.T) {
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	value := "v2"
+	entry := &model.AuditEntry{Actor: "alice", Operation: model.AuditConfigUpdate, Environment: "prod", Key: "db.host", NewValue: &value}
+
+	err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{columns: []string{"id", "created_at"}, values: [][]driver.Value{{int64(11), createdAt}}},
+	}).AppendAudit(context.Background(), entry)
+	if err != nil || entry.ID != 11 || !entry.CreatedAt.Equal(createdAt) {
+		t.Fatalf("AppendAudit() = %v, entry %#v", err, entry)
+	}
+}
+
+func TestPostgresRepositoryGetAuditEntries(t *testing.T) {
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	entries, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{
+			columns: auditColumns,
+			values: [][]driver.Value{
+				{int64(12), "alice", "10.0.0.1", "req-1", "config.update", "prod", "db.host", int64(40), "v1", "v2", false, createdAt},
+				{int64(11), "bob", "", "", "config.update", "prod", "db.password", int64(39), nil, nil, true, createdAt},
+				{int64(10), "ops", "", "", "environment.create", "prod", "", nil, nil, `{"protected":true}`, false, createdAt},
+			},
+		},
+	}).GetAuditEntries(context.Background(), model.AuditFilter{Environment: "prod", Limit: 3})
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("GetAuditEntries() = %#v, %v", entries, err)
+	}
+
+	first := entries[0]
+	if first.ID != 12 || first.SourceIP != "10.0.0.1" || first.RequestID != "req-1" || first.Operation != model.AuditConfigUpdate ||
+		first.Revision != 40 || first.OldValue == nil || *first.OldValue != "v1" || first.NewValue == nil || *first.NewValue != "v2" {
+		t.Fatalf("entry = %#v", first)
+	}
+	if secret := entries[1]; !secret.Secret || secret.OldValue != nil || secret.NewValue != nil {
+		t.Fatalf("secret entry = %#v", secret)
+	}
+	if environment := entries[2]; environment.Revision != 0 || environment.Key != "" || environment.NewValue == nil {
+		t.Fatalf("environment entry = %#v", environment)
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *postgresRepository) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) ([]*model.Config, error) {
+	start := time.Now()
+	name := "list_configs_by_key"
+	if query.SortOrDefault() == model.SortByUpdatedAt {
+		name = "list_configs_by_updated_at"
+	}
+	statement := r.queries[name]
+	if statement == "" {
+		return nil, errors.New(name + " query not found")
+	}
+
+	var limit sql.NullInt64
+	if query.Limit > 0 {
+		limit = sql.NullInt64{Int64: int64(query.Limit), Valid: true}
+	}
+	var after string
+	var afterTime time.Time
+	if query.After != nil {
+		after, afterTime = query.After.Key, query.After.UpdatedAt.UTC()
+	}
+	args := append(filterArgs(environment, query), after)
+	if name == "list_configs_by_updated_at" {
+		args = append(args, afterTime)
+	}
+	rows, err := r.db.QueryContext(ctx, statement, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []*model.Config
+	for rows.Next() {
+		config, err := scanConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues(name).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues(name).Observe(duration)
+	return configs, nil
+}
+
+func (r *postgresRepository) CountConfigs(ctx context.Context, environment string, query model.ConfigQuery) (int, error) {
+	start := time.Now()
+	statement := r.queries["count_configs"]
+	if statement == "" {
+		return 0, errors.New("count_configs query not found")
+	}
+	var count int
+	if err := r.db.QueryRowContext(ctx, statement, filterArgs(environment, query)...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("count_configs").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("count_configs").Observe(duration)
+	return count, nil
+}
+
+// filterArgs binds the filters shared by the list and count queries. Patterns
+// are escaped so that '%' and '_' in keys match literally; configs.updated_at
+// has no time zone and holds UTC.
+func filterArgs(environment string, query model.ConfigQuery) []any {
+	var prefix, search, below string
+	if query.Prefix != "" {
+		prefix = likeEscaper.Replace(query.Prefix) + "%"
+	}
+	if query.Tree != "" {
+		below = likeEscaper.Replace(query.Tree+model.KeySeparator) + "%"
+	}
+	if query.Search != "" {
+		search = "%" + likeEscaper.Replace(query.Search) + "%"
+	}
+	var since sql.NullTime
+	if !query.UpdatedSince.IsZero() {
+		since = sql.NullTime{Time: query.UpdatedSince.UTC(), Valid: true}
+	}
+	var allowed any
+	if query.AllowedPrefixes != nil {
+		patterns := make([]string, 0, len(query.AllowedPrefixes))
+		for _, prefix := range query.AllowedPrefixes {
+			patterns = append(patterns, likeEscaper.Replace(prefix)+"%")
+		}
+		allowed = pq.Array(patterns)
+	}
+	return []any{environment, prefix, search, since, allowed, query.Tree, below}
+}
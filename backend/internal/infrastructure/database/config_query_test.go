@@ -0,0 +1,63 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPostgresRepositoryListConfigs(t *testing.T) {
+	ctx := context.Background()
+	updatedAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	rows := func() *fakeRows {
+		return &fakeRows{
+			columns: configColumns,
+			values: [][]driver.Value{
+				{"prod", "db.host", "db1", updatedAt, "alice", int64(2), int64(1), nil},
+				{"prod", "db.password", "enc:v1:x", updatedAt, "bob", int64(1), int64(1), []byte(`{"secret":true}`)},
+			},
+		}
+	}
+
+	queries := []model.ConfigQuery{
+		{},
+		{Prefix: "db.", Search: "100%_sure", Limit: 2, AllowedPrefixes: []string{"db."}},
+		{Sort: model.SortByUpdatedAt, UpdatedSince: updatedAt, After: &model.ConfigCursor{Sort: model.SortByUpdatedAt, Key: "a", UpdatedAt: updatedAt}},
+	}
+	for _, query := range queries {
+		configs, err := newRepositoryForTest(t, &fakeDBState{queryRows: rows()}).ListConfigs(ctx, "prod", query)
+		if err != nil || len(configs) != 2 || configs[0].Key != "db.host" || !configs[1].Secret {
+			t.Fatalf("ListConfigs(%#v) = %#v, %v", query, configs, err)
+		}
+	}
+
+	wantErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).ListConfigs(ctx, "prod", model.ConfigQuery{}); !errors.Is(err, wantErr) {
+		t.Fatalf("ListConfigs() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestPostgresRepositoryCountConfigs(t *testing.T) {
+	ctx := context.Background()
+	count, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(4000)}}},
+	}).CountConfigs(ctx, "prod", model.ConfigQuery{Prefix: "db.", AllowedPrefixes: []string{}})
+	if err != nil || count != 4000 {
+		t.Fatalf("CountConfigs() = %d, %v", count, err)
+	}
+
+	wantErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).CountConfigs(ctx, "prod", model.ConfigQuery{}); !errors.Is(err, wantErr) {
+		t.Fatalf("CountConfigs() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestFilterArgs(t *testing.T) {
+	args := filterArgs("prod", model.ConfigQuery{Prefix: "a_b.", Search: `50%\`, Tree: "pay_ments"})
+	if args[1] != `a\_b.%` || args[2] != `%50\%\\%` || args[4] != nil || args[5] != "pay_ments" || args[6] != `pay\_ments.%` {
+		t.Fatalf("filterArgs() = %#v", args)
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"config-service/backend/internal/repository"
+	"config-service/backend/internal/repository/repositorytest"
+	"config-service/backend/migrations"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// TestPostgresRepositoryConformance needs a scratch database, whose tables it
+// empties before every test: TEST_DATABASE_URL=postgres://... go test ./...
+func TestPostgresRepositoryConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	migrator, err := NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	truncate := func(t *testing.T) {
+		if _, err := db.ExecContext(ctx, "TRUNCATE configs, config_revisions, environments, audit_log, api_tokens, secret_data_keys RESTART IDENTITY CASCADE"); err != nil {
+			t.Fatalf("TRUNCATE error = %v", err)
+		}
+	}
+	newRepository := func(t *testing.T) repository.ConfigRepository {
+		truncate(t)
+		repo, err := NewPostgresRepository(db, newRepositoryMetrics())
+		if err != nil {
+			t.Fatalf("NewPostgresRepository() error = %v", err)
+		}
+		return repo
+	}
+
+	repositorytest.RunConfigRepository(t, newRepository)
+	repositorytest.RunTokenRepository(t, func(t *testing.T) repository.TokenRepository {
+		truncate(t)
+		repo, err := NewPostgresTokenRepository(db, newRepositoryMetrics())
+		if err != nil {
+			t.Fatalf("NewPostgresTokenRepository() error = %v", err)
+		}
+		return repo
+	})
+	repositorytest.RunSecretStore(t, func(t *testing.T) (repository.SecretStore, repository.ConfigRepository) {
+		repo := newRepository(t)
+		store, err := NewPostgresSecretStore(db, newRepositoryMetrics())
+		if err != nil {
+			t.Fatalf("NewPostgresSecretStore() error = %v", err)
+		}
+		return store, repo
+	})
+}
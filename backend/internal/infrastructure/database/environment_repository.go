@@ -0,0 +1,170 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (r *postgresRepository) CreateEnvironment(ctx context.Context, environment *model.Environment) error {
+	start := time.Now()
+	query := r.queries["create_environment"]
+	if query == "" {
+		return errors.New("create_environment query not found")
+	}
+	_, err := r.db.ExecContext(ctx,
+		query,
+		environment.Name,
+		environment.Parent,
+		environment.Description,
+		environment.Owner,
+		environment.Protected,
+		environment.CreatedAt,
+	)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("create_environment").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("create_environment").Observe(duration)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrEnvironmentAlreadyExists
+		}
+		if isForeignKeyViolation(err) {
+			return repository.ErrEnvironmentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *postgresRepository) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	start := time.Now()
+	query := r.queries["get_environment"]
+	if query == "" {
+		return nil, errors.New("get_environment query not found")
+	}
+	environment, err := scanEnvironment(r.db.QueryRowContext(ctx, query, name))
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_environment").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_environment").Observe(duration)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+	return environment, nil
+}
+
+func (r *postgresRepository) GetEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	start := time.Now()
+	query := r.queries["get_environments"]
+	if query == "" {
+		return nil, errors.New("get_environments query not found")
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var environments []*model.Environment
+	for rows.Next() {
+		environment, err := scanEnvironment(rows)
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, environment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_environments").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_environments").Observe(duration)
+	return environments, nil
+}
+
+func (r *postgresRepository) UpdateEnvironment(ctx context.Context, environment *model.Environment) error {
+	start := time.Now()
+	query := r.queries["update_environment"]
+	if query == "" {
+		return errors.New("update_environment query not found")
+	}
+	result, err := r.db.ExecContext(ctx,
+		query,
+		environment.Name,
+		environment.Parent,
+		environment.Description,
+		environment.Owner,
+		environment.Protected,
+	)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("update_environment").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("update_environment").Observe(duration)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repository.ErrEnvironmentNotFound
+		}
+		return err
+	}
+
+	return requireAffected(result, repository.ErrEnvironmentNotFound)
+}
+
+func (r *postgresRepository) DeleteEnvironment(ctx context.Context, name string) error {
+	start := time.Now()
+	query := r.queries["delete_environment"]
+	if query == "" {
+		return errors.New("delete_environment query not found")
+	}
+	result, err := r.db.ExecContext(ctx, query, name)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("delete_environment").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("delete_environment").Observe(duration)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repository.ErrEnvironmentInUse
+		}
+		return err
+	}
+
+	return requireAffected(result, repository.ErrEnvironmentNotFound)
+}
+
+func scanEnvironment(row rowScanner) (*model.Environment, error) {
+	var environment model.Environment
+	if err := row.Scan(
+		&environment.Name,
+		&environment.Parent,
+		&environment.Description,
+		&environment.Owner,
+		&environment.Protected,
+		&environment.CreatedAt,
+		&environment.KeyCount,
+	); err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func requireAffected(result sql.Result, errNone error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errNone
+	}
+	return nil
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var environmentColumns = []string{"name", "parent", "description", "owner", "protected", "created_at", "key_count"}
+
+func TestPostgresRepositoryCreateEnvironment(t *testing.T) {
+	ctx := context.Background()
+	environment := &model.Environment{
+		Name:                  "production",
+		EnvironmentAttributes: model.EnvironmentAttributes{Parent: "base", Owner: "platform"},
+		CreatedAt:             time.Now(),
+	}
+
+	if err := newRepositoryForTest(t, &fakeDBState{}).CreateEnvironment(ctx, environment); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		execErr error
+		wantErr error
+	}{
+		{name: "duplicate", execErr: &pq.Error{Code: "23505"}, wantErr: repository.ErrEnvironmentAlreadyExists},
+		{name: "missing parent", execErr: &pq.Error{Code: "23503"}, wantErr: repository.ErrEnvironmentNotFound},
+		{name: "database error", execErr: errors.New("exec failed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newRepositoryForTest(t, &fakeDBState{execErr: tt.execErr}).CreateEnvironment(ctx, environment)
+			want := tt.wantErr
+			if want == nil {
+				want = tt.execErr
+			}
+			if !errors.Is(err, want) {
+				t.Fatalf("CreateEnvironment() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+func TestPostgresRepositoryGetEnvironment(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	environment, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{
+			columns: environmentColumns,
+			values:  [][]driver.Value{{"production", "base", "Live traffic", "platform", true, createdAt, int64(12)}},
+		},
+	}).GetEnvironment(ctx, "production")
+	if err != nil {
+		t.Fatalf("GetEnvironment() error = %v", err)
+	}
+	if environment.Name != "production" || environment.Parent != "base" || environment.Owner != "platform" ||
+		!environment.Protected || environment.KeyCount != 12 || !environment.CreatedAt.Equal(createdAt) {
+		t.Fatalf("GetEnvironment() = %#v", environment)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{columns: environmentColumns},
+	}).GetEnvironment(ctx, "missing")
+	if !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("GetEnvironment() missing error = %v, want %v", err, repository.ErrEnvironmentNotFound)
+	}
+}
+
+func TestPostgresRepositoryUpdateEnvironment(t *testing.T) {
+	ctx := context.Background()
+	environment := &model.Environment{Name: "production", EnvironmentAttributes: model.EnvironmentAttributes{Parent: "base"}}
+
+	if err := newRepositoryForTest(t, &fakeDBState{}).UpdateEnvironment(ctx, environment); err != nil {
+		t.Fatalf("UpdateEnvironment() error = %v", err)
+	}
+
+	err := newRepositoryForTest(t, &fakeDBState{execResult: fakeResult{rowsAffected: 0}}).UpdateEnvironment(ctx, environment)
+	if !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("UpdateEnvironment() missing error = %v, want %v", err, repository.ErrEnvironmentNotFound)
+	}
+
+	err = newRepositoryForTest(t, &fakeDBState{execErr: &pq.Error{Code: "23503"}}).UpdateEnvironment(ctx, environment)
+	if !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("UpdateEnvironment() missing parent error = %v, want %v", err, repository.ErrEnvironmentNotFound)
+	}
+}
+
+func TestPostgresRepositoryGetEnvironments(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	environments, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{
+			columns: environmentColumns,
+			values: [][]driver.Value{
+				{"base", "", "", "", false, createdAt, int64(4)},
+				{"production", "base", "", "platform", true, createdAt, int64(0)},
+			},
+		},
+	}).GetEnvironments(ctx)
+	if err != nil {
+		t.Fatalf("GetEnvironments() error = %v", err)
+	}
+	if len(environments) != 2 || environments[0].KeyCount != 4 || environments[1].Parent != "base" {
+		t.Fatalf("GetEnvironments() = %#v", environments)
+	}
+
+	queryErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: queryErr}).GetEnvironments(ctx); !errors.Is(err, queryErr) {
+		t.Fatalf("GetEnvironments() error = %v, want %v", err, queryErr)
+	}
+}
+
+func TestPostgresRepositoryDeleteEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   *fakeDBState
+		wantErr error
+	}{
+		{name: "deleted", state: &fakeDBState{}},
+		{name: "missing", state: &fakeDBState{execResult: fakeResult{rowsAffected: 0}}, wantErr: repository.ErrEnvironmentNotFound},
+		{name: "referenced", state: &fakeDBState{execErr: &pq.Error{Code: "23503"}}, wantErr: repository.ErrEnvironmentInUse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newRepositoryForTest(t, tt.state).DeleteEnvironment(context.Background(), "qa")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteEnvironment() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const changesChannel = "config_changes"
+
+type ChangeNotification struct {
+	Revision    int64  `json:"revision"`
+	Environment string `json:"env"`
+}
+
+// ChangeListener receives the notifications sent by the config_revisions
+// trigger, so writes made by other replicas reach local watchers too.
+type ChangeListener struct {
+	listener *pq.Listener
+	logger   *zap.Logger
+	done     chan struct{}
+}
+
+func NewChangeListener(dsn string, logger *zap.Logger) *ChangeListener {
+	report := func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("config change listener event", zap.Int("event", int(event)), zap.Error(err))
+		}
+	}
+
+	return &ChangeListener{
+		listener: pq.NewListener(dsn, time.Second, time.Minute, report),
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start calls handle for every notification and reconnected whenever the
+// connection was re-established, since notifications may have been missed
+// while it was down.
+func (l *ChangeListener) Start(handle func(ChangeNotification), reconnected func()) error {
+	if err := l.listener.Listen(changesChannel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", changesChannel, err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-l.done:
+				return
+			case n, ok := <-l.listener.Notify:
+				if !ok {
+					return
+				}
+				// A nil notification means the connection was re-established.
+				if n == nil {
+					reconnected()
+					continue
+				}
+				notification, err := parseNotification(n.Extra)
+				if err != nil {
+					l.logger.Warn("invalid config change notification", zap.String("payload", n.Extra), zap.Error(err))
+					continue
+				}
+				handle(notification)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (l *ChangeListener) Close() error {
+	close(l.done)
+	return l.listener.Close()
+}
+
+func parseNotification(payload string) (ChangeNotification, error) {
+	var notification ChangeNotification
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		return ChangeNotification{}, err
+	}
+	if notification.Revision <= 0 || notification.Environment == "" {
+		return ChangeNotification{}, fmt.Errorf("incomplete notification")
+	}
+	return notification, nil
+}
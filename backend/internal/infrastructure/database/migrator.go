@@ -0,0 +1,308 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrIrreversibleMigration = errors.New("migration has no down script")
+
+var migrationFile = regexp.MustCompile(`^(\d+)_([^.]+)(\.down)?\.sql$`)
+
+var migrationQueries = []string{
+	"lock_migrations",
+	"create_migrations_table",
+	"get_migrations",
+	"get_last_migration",
+	"exists_migration",
+	"record_migration",
+	"delete_migration",
+}
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	// AppliedAt is zero for pending migrations.
+	AppliedAt time.Time
+}
+
+func (s MigrationStatus) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// MigrationDialect adapts the migrator to a database other than Postgres.
+type MigrationDialect struct {
+	// Queries holds the queries named in migrationQueries. lock_migrations
+	// must keep other migrators out until the transaction ends.
+	Queries map[string]string
+	// UndefinedTable reports whether err comes from reading a table that
+	// does not exist.
+	UndefinedTable func(err error) bool
+}
+
+// Migrator applies the schema migrations and records them in
+// schema_migrations. Every migration runs in its own transaction under an
+// advisory lock, so replicas starting at the same time apply it only once.
+type Migrator struct {
+	db             *sql.DB
+	queries        map[string]string
+	undefinedTable func(err error) bool
+	migrations     []Migration
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	queries, err := loadQueries()
+	if err != nil {
+		return nil, err
+	}
+	return NewDialectMigrator(db, fsys, MigrationDialect{Queries: queries, UndefinedTable: isUndefinedTable})
+}
+
+func NewDialectMigrator(db *sql.DB, fsys fs.FS, dialect MigrationDialect) (*Migrator, error) {
+	for _, name := range migrationQueries {
+		if dialect.Queries[name] == "" {
+			return nil, errors.New(name + " query not found")
+		}
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, queries: dialect.Queries, undefinedTable: dialect.UndefinedTable, migrations: migrations}, nil
+}
+
+// LoadMigrations reads NNN_name.sql and NNN_name.down.sql files from the
+// root of fsys, ordered by version. Files without the .sql extension are
+// ignored.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	downs := make(map[int64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %q must be named NNN_name.sql or NNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration file %q has an invalid version", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] != "" {
+			downs[version] = strings.TrimSpace(string(data))
+			continue
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", existing.Name, match[2], version)
+		}
+		byVersion[version] = &Migration{Version: version, Name: match[2], Up: strings.TrimSpace(string(data))}
+	}
+
+	for version, down := range downs {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration %d has no up migration", version)
+		}
+		migration.Down = down
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Status lists every known migration and every applied one, ordered by
+// version. Applied migrations missing from the binary have no scripts.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = record.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, status := range applied {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Up applies the pending migrations in version order and returns those it
+// applied, including when it fails on a later one.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, status := range statuses {
+		if status.Applied() {
+			continue
+		}
+		migration := status.Migration
+		ran := false
+		err := m.inLockedTx(ctx, func(tx *sql.Tx) error {
+			// Another replica may have applied it while we waited for the lock.
+			var exists bool
+			if err := tx.QueryRowContext(ctx, m.queries["exists_migration"], migration.Version).Scan(&exists); err != nil || exists {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if _, err := tx.ExecContext(ctx, m.queries["record_migration"], migration.Version, migration.Name); err != nil {
+				return err
+			}
+			ran = true
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+		if ran {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// Down reverts up to steps of the latest applied migrations, newest first,
+// and returns those it reverted. It stops with ErrIrreversibleMigration at a
+// migration without a down script.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	for range steps {
+		var migration *Migration
+		err := m.inLockedTx(ctx, func(tx *sql.Tx) error {
+			var version int64
+			err := tx.QueryRowContext(ctx, m.queries["get_last_migration"]).Scan(&version)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			known, ok := m.find(version)
+			if !ok {
+				return fmt.Errorf("applied migration %d is unknown to this binary", version)
+			}
+			if known.Down == "" {
+				return fmt.Errorf("%w: %d_%s", ErrIrreversibleMigration, known.Version, known.Name)
+			}
+			if _, err := tx.ExecContext(ctx, known.Down); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", known.Version, known.Name, err)
+			}
+			if _, err := tx.ExecContext(ctx, m.queries["delete_migration"], known.Version); err != nil {
+				return err
+			}
+			migration = &known
+			return nil
+		})
+		if err != nil {
+			return reverted, err
+		}
+		if migration == nil {
+			break
+		}
+		reverted = append(reverted, *migration)
+	}
+	return reverted, nil
+}
+
+// applied reads schema_migrations, which does not exist before the first
+// migration.
+func (m *Migrator) applied(ctx context.Context) (map[int64]MigrationStatus, error) {
+	applied := make(map[int64]MigrationStatus)
+	rows, err := m.db.QueryContext(ctx, m.queries["get_migrations"])
+	if m.undefinedTable(err) {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status MigrationStatus
+		if err := rows.Scan(&status.Version, &status.Name, &status.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[status.Version] = status
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// inLockedTx runs fn in a transaction holding the migration lock, which is
+// released on commit or rollback. The lock is taken before
+// schema_migrations is created, so that concurrent creations do not collide.
+func (m *Migrator) inLockedTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"lock_migrations", "create_migrations_table"} {
+		if _, err := tx.ExecContext(ctx, m.queries[name]); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func isUndefinedTable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"config-service/backend/migrations"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"001_init.sql":         {Data: []byte("CREATE TABLE one ();\n")},
+		"001_init.down.sql":    {Data: []byte("DROP TABLE one;")},
+		"002_second.sql":       {Data: []byte("CREATE TABLE two ();")},
+		"002_second.down.sql":  {Data: []byte("DROP TABLE two;")},
+		"003_irreversible.sql": {Data: []byte("CREATE TABLE three ();")},
+		"migrations.go":        {Data: []byte("package migrations")},
+	}
+}
+
+func newMigratorForTest(t *testing.T, state *fakeDBState) *Migrator {
+	t.Helper()
+
+	migrator, err := NewMigrator(newFakeDB(t, state), testMigrations())
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	return migrator
+}
+
+func appliedRows(versions ...int64) *fakeRows {
+	rows := &fakeRows{columns: []string{"version", "name", "applied_at"}}
+	for _, version := range versions {
+		rows.values = append(rows.values, []driver.Value{version, "applied", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)})
+	}
+	return rows
+}
+
+func lastVersionRows(versions ...int64) *fakeRows {
+	rows := &fakeRows{columns: []string{"version"}}
+	for _, version := range versions {
+		rows.values = append(rows.values, []driver.Value{version})
+	}
+	return rows
+}
+
+func migrationVersions(migrations []Migration) []int64 {
+	var versions []int64
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(testMigrations())
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if got := migrationVersions(migrations); !slices.Equal(got, []int64{1, 2, 3}) {
+		t.Fatalf("versions = %v, want [1 2 3]", got)
+	}
+	if migrations[0].Name != "init" || migrations[0].Up != "CREATE TABLE one ();" || migrations[0].Down != "DROP TABLE one;" {
+		t.Fatalf("first migration = %#v", migrations[0])
+	}
+	if migrations[2].Down != "" {
+		t.Fatalf("migration without a down script has Down = %q", migrations[2].Down)
+	}
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{name: "shared version", files: fstest.MapFS{"001_a.sql": {}, "001_b.sql": {}}, wantErr: "share version 1"},
+		{name: "down without up", files: fstest.MapFS{"001_a.sql": {}, "002_b.down.sql": {}}, wantErr: "has no up migration"},
+		{name: "no version", files: fstest.MapFS{"init.sql": {}}, wantErr: "must be named"},
+		{name: "zero version", files: fstest.MapFS{"000_init.sql": {}}, wantErr: "invalid version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMigrations(tt.files); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadMigrations() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsAreReversible(t *testing.T) {
+	embedded, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(embedded) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, migration := range embedded {
+		if migration.Version != int64(i+1) {
+			t.Fatalf("migration %s has version %d, want %d", migration.Name, migration.Version, i+1)
+		}
+		if migration.Down == "" {
+			t.Fatalf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	ctx := context.Background()
+	state := &fakeDBState{queryRows: appliedRows(1, 7)}
+	migrator := newMigratorForTest(t, state)
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	var applied []int64
+	for _, status := range statuses {
+		if status.Applied() {
+			applied = append(applied, status.Version)
+		}
+	}
+	if len(statuses) != 4 || !slices.Equal(applied, []int64{1, 7}) {
+		t.Fatalf("Status() = %#v, want 1 and the unknown 7 applied", statuses)
+	}
+	if statuses[3].Name != "applied" || statuses[3].Up != "" {
+		t.Fatalf("unknown migration = %#v, want its recorded name and no scripts", statuses[3])
+	}
+
+	state.queryRows, state.queryErr = nil, &pq.Error{Code: "42P01"}
+	statuses, err = migrator.Status(ctx)
+	if err != nil || len(statuses) != 3 || statuses[0].Applied() {
+		t.Fatalf("Status() without schema_migrations = %#v, %v, want everything pending", statuses, err)
+	}
+
+	state.queryErr = errors.New("connection refused")
+	if _, err := migrator.Status(ctx); err == nil {
+		t.Fatal("Status() must return query errors")
+	}
+}
+
+func TestMigratorUp(t *testing.T) {
+	ctx := context.Background()
+	// Migration 3 is applied by another replica while this one waits.
+	state := &fakeDBState{queryQueue: []*fakeRows{appliedRows(1), existsRows(false), existsRows(true)}}
+	migrator := newMigratorForTest(t, state)
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if got := migrationVersions(applied); !slices.Equal(got, []int64{2}) {
+		t.Fatalf("Up() applied %v, want [2]", got)
+	}
+	if !slices.Contains(state.execs, "CREATE TABLE two ();") || slices.Contains(state.execs, "CREATE TABLE three ();") {
+		t.Fatalf("executed %q, want only migration 2", state.execs)
+	}
+	if locks := countPrefix(state.execs, "SELECT pg_advisory_xact_lock"); locks != 2 {
+		t.Fatalf("took the lock %d times, want once per pending migration", locks)
+	}
+	if state.commits != 2 {
+		t.Fatalf("commits = %d, want 2", state.commits)
+	}
+
+	state = &fakeDBState{queryQueue: []*fakeRows{appliedRows()}, execErr: errors.New("syntax error")}
+	migrator = newMigratorForTest(t, state)
+	if applied, err := migrator.Up(ctx); err == nil || len(applied) != 0 || state.rollbacks != 1 {
+		t.Fatalf("Up() = %v, %v with %d rollbacks, want the failed migration rolled back", applied, err, state.rollbacks)
+	}
+}
+
+func TestMigratorDown(t *testing.T) {
+	ctx := context.Background()
+	state := &fakeDBState{queryQueue: []*fakeRows{lastVersionRows(2), lastVersionRows(1), lastVersionRows()}}
+	migrator := newMigratorForTest(t, state)
+
+	reverted, err := migrator.Down(ctx, 5)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if got := migrationVersions(reverted); !slices.Equal(got, []int64{2, 1}) {
+		t.Fatalf("Down() reverted %v, want [2 1]", got)
+	}
+	if i, j := slices.Index(state.execs, "DROP TABLE two;"), slices.Index(state.execs, "DROP TABLE one;"); i < 0 || j < i {
+		t.Fatalf("executed %q, want migration 2 reverted before 1", state.execs)
+	}
+
+	state = &fakeDBState{queryQueue: []*fakeRows{lastVersionRows(3)}}
+	migrator = newMigratorForTest(t, state)
+	if reverted, err := migrator.Down(ctx, 1); !errors.Is(err, ErrIrreversibleMigration) || len(reverted) != 0 || state.rollbacks != 1 {
+		t.Fatalf("Down() = %v, %v, want ErrIrreversibleMigration", reverted, err)
+	}
+
+	state = &fakeDBState{queryQueue: []*fakeRows{lastVersionRows(9)}}
+	migrator = newMigratorForTest(t, state)
+	if _, err := migrator.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("Down() error = %v, want unknown migration", err)
+	}
+}
+
+func countPrefix(values []string, prefix string) int {
+	count := 0
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			count++
+		}
+	}
+	return count
+}
@@ -4,8 +4,10 @@ import (
 	"config-service/backend/internal/model"
 	"config-service/backend/internal/repository"
 	"config-service/backend/pkg/metrics"
+	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -16,13 +18,24 @@ import (
 //go:embed queries/*.sql
 var queriesFS embed.FS
 
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type postgresRepository struct {
-	db      *sql.DB
+	db      querier
+	conn    *sql.DB
 	queries map[string]string
 	metrics *metrics.Metrics
 }
 
 func NewPostgresRepository(db *sql.DB, m *metrics.Metrics) (repository.ConfigRepository, error) {
+	return newPostgresRepository(db, m)
+}
+
+func newPostgresRepository(db *sql.DB, m *metrics.Metrics) (*postgresRepository, error) {
 	queries, err := loadQueries()
 	if err != nil {
 		return nil, err
@@ -30,6 +43,7 @@ func NewPostgresRepository(db *sql.DB, m *metrics.Metrics) (repository.ConfigRep
 
 	return &postgresRepository{
 		db:      db,
+		conn:    db,
 		queries: queries,
 		metrics: m,
 	}, nil
@@ -61,37 +75,50 @@ func loadQueries() (map[string]string, error) {
 	return queries, nil
 }
 
-func (r *postgresRepository) Create(config *model.Config) error {
+func (r *postgresRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
 	start := time.Now()
 	query := r.queries["create_config"]
 	if query == "" {
-		return errors.New("create_config query not found")
+		return nil, errors.New("create_config query not found")
 	}
-	_, err := r.db.Exec(query, config.Environment, config.Key, config.Value, config.UpdatedAt)
+	spec, err := encodeSpec(config.ValueSpec)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := scanRevision(r.db.QueryRowContext(ctx,
+		query,
+		config.Environment,
+		config.Key,
+		config.Value,
+		config.UpdatedAt,
+		config.UpdatedBy,
+		spec,
+	))
 	duration := time.Since(start).Seconds()
 	r.metrics.DBQueriesTotal.WithLabelValues("create").Inc()
 	r.metrics.DBQueryDuration.WithLabelValues("create").Observe(duration)
-	if isUniqueViolation(err) {
-		return repository.ErrConfigAlreadyExists
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, repository.ErrConfigAlreadyExists
+		}
+		if isForeignKeyViolation(err) {
+			return nil, repository.ErrEnvironmentNotFound
+		}
+		return nil, err
 	}
-	return err
+
+	config.Revision = revision.Revision
+	config.Version = 1
+	return revision, nil
 }
 
-func (r *postgresRepository) Get(environment, key string) (*model.Config, error) {
+func (r *postgresRepository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
 	start := time.Now()
 	query := r.queries["get_config"]
 	if query == "" {
 		return nil, errors.New("get_config query not found")
 	}
-	var config model.Config
-	var updatedAt time.Time
-
-	err := r.db.QueryRow(query, environment, key).Scan(
-		&config.Environment,
-		&config.Key,
-		&config.Value,
-		&updatedAt,
-	)
+	config, err := scanConfig(r.db.QueryRowContext(ctx, query, environment, key))
 	duration := time.Since(start).Seconds()
 	r.metrics.DBQueriesTotal.WithLabelValues("get").Inc()
 	r.metrics.DBQueryDuration.WithLabelValues("get").Observe(duration)
@@ -102,17 +129,16 @@ func (r *postgresRepository) Get(environment, key string) (*model.Config, error)
 		return nil, err
 	}
 
-	config.UpdatedAt = updatedAt
-	return &config, nil
+	return config, nil
 }
 
-func (r *postgresRepository) GetAll(environment string) ([]*model.Config, error) {
+func (r *postgresRepository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
 	start := time.Now()
 	query := r.queries["get_all_configs"]
 	if query == "" {
 		return nil, errors.New("get_all_configs query not found")
 	}
-	rows, err := r.db.Query(query, environment)
+	rows, err := r.db.QueryContext(ctx, query, environment)
 	if err != nil {
 		return nil, err
 	}
@@ -120,18 +146,11 @@ func (r *postgresRepository) GetAll(environment string) ([]*model.Config, error)
 
 	var configs []*model.Config
 	for rows.Next() {
-		var config model.Config
-		var updatedAt time.Time
-		if err := rows.Scan(
-			&config.Environment,
-			&config.Key,
-			&config.Value,
-			&updatedAt,
-		); err != nil {
+		config, err := scanConfig(rows)
+		if err != nil {
 			return nil, err
 		}
-		config.UpdatedAt = updatedAt
-		configs = append(configs, &config)
+		configs = append(configs, config)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -144,66 +163,84 @@ func (r *postgresRepository) GetAll(environment string) ([]*model.Config, error)
 	return configs, nil
 }
 
-func (r *postgresRepository) Update(config *model.Config) error {
+func (r *postgresRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
 	start := time.Now()
 	query := r.queries["update_config"]
 	if query == "" {
-		return errors.New("update_config query not found")
+		return nil, errors.New("update_config query not found")
 	}
-	result, err := r.db.Exec(query, config.Environment, config.Key, config.Value, config.UpdatedAt)
+	spec, err := encodeSpec(config.ValueSpec)
 	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return repository.ErrConfigNotFound
+		return nil, err
 	}
-
+	revision, err := scanRevision(r.db.QueryRowContext(ctx,
+		query,
+		config.Environment,
+		config.Key,
+		config.Value,
+		config.UpdatedAt,
+		config.UpdatedBy,
+		config.Version,
+		spec,
+	))
 	duration := time.Since(start).Seconds()
 	r.metrics.DBQueriesTotal.WithLabelValues("update").Inc()
 	r.metrics.DBQueryDuration.WithLabelValues("update").Observe(duration)
-	return nil
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.missingOrConflict(ctx, config.Environment, config.Key)
+		}
+		return nil, err
+	}
+
+	config.Revision = revision.Revision
+	config.Version++
+	return revision, nil
 }
 
-func (r *postgresRepository) Delete(environment, key string) error {
+func (r *postgresRepository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
 	start := time.Now()
 	query := r.queries["delete_config"]
 	if query == "" {
-		return errors.New("delete_config query not found")
+		return nil, errors.New("delete_config query not found")
 	}
-	result, err := r.db.Exec(query, environment, key)
+	revision, err := scanRevision(r.db.QueryRowContext(ctx, query, environment, key, actor, version))
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("delete").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("delete").Observe(duration)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			if version == 0 {
+				return nil, repository.ErrConfigNotFound
+			}
+			return nil, r.missingOrConflict(ctx, environment, key)
+		}
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return revision, nil
+}
+
+// missingOrConflict explains why a versioned write matched no rows.
+func (r *postgresRepository) missingOrConflict(ctx context.Context, environment, key string) error {
+	exists, err := r.Exists(ctx, environment, key)
 	if err != nil {
 		return err
 	}
-
-	if rowsAffected == 0 {
-		return repository.ErrConfigNotFound
+	if exists {
+		return repository.ErrVersionConflict
 	}
-
-	duration := time.Since(start).Seconds()
-	r.metrics.DBQueriesTotal.WithLabelValues("delete").Inc()
-	r.metrics.DBQueryDuration.WithLabelValues("delete").Observe(duration)
-	return nil
+	return repository.ErrConfigNotFound
 }
 
-func (r *postgresRepository) Exists(environment, key string) (bool, error) {
+func (r *postgresRepository) Exists(ctx context.Context, environment, key string) (bool, error) {
 	start := time.Now()
 	query := r.queries["exists_config"]
 	if query == "" {
 		return false, errors.New("exists_config query not found")
 	}
 	var exists bool
-	err := r.db.QueryRow(query, environment, key).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, environment, key).Scan(&exists)
 	duration := time.Since(start).Seconds()
 	r.metrics.DBQueriesTotal.WithLabelValues("exists").Inc()
 	r.metrics.DBQueryDuration.WithLabelValues("exists").Observe(duration)
@@ -213,6 +250,208 @@ func (r *postgresRepository) Exists(environment, key string) (bool, error) {
 	return exists, nil
 }
 
+func (r *postgresRepository) GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	start := time.Now()
+	query := r.queries["get_config_at"]
+	if query == "" {
+		return nil, errors.New("get_config_at query not found")
+	}
+	revision, err := scanRevision(r.db.QueryRowContext(ctx, query, environment, key, asOf))
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_at").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_at").Observe(duration)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrConfigNotFound
+		}
+		return nil, err
+	}
+
+	return revision.Config(), nil
+}
+
+func (r *postgresRepository) GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	start := time.Now()
+	query := r.queries["get_all_configs_at"]
+	if query == "" {
+		return nil, errors.New("get_all_configs_at query not found")
+	}
+	revisions, err := r.queryRevisions(ctx, query, environment, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]*model.Config, 0, len(revisions))
+	for _, revision := range revisions {
+		configs = append(configs, revision.Config())
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_all_at").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_all_at").Observe(duration)
+	return configs, nil
+}
+
+func (r *postgresRepository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	start := time.Now()
+	query := r.queries["get_config_history"]
+	if query == "" {
+		return nil, errors.New("get_config_history query not found")
+	}
+	revisions, err := r.queryRevisions(ctx, query, environment, key)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_history").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_history").Observe(duration)
+	return revisions, nil
+}
+
+func (r *postgresRepository) GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error) {
+	start := time.Now()
+	query := r.queries["get_revisions_since"]
+	if query == "" {
+		return nil, errors.New("get_revisions_since query not found")
+	}
+	revisions, err := r.queryRevisions(ctx, query, environment, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_revisions_since").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_revisions_since").Observe(duration)
+	return revisions, nil
+}
+
+func (r *postgresRepository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	start := time.Now()
+	query := r.queries["get_revision"]
+	if query == "" {
+		return nil, errors.New("get_revision query not found")
+	}
+	result, err := scanRevision(r.db.QueryRowContext(ctx, query, revision))
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_revision").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_revision").Observe(duration)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrConfigNotFound
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *postgresRepository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	txRepo := &postgresRepository{
+		db:      tx,
+		queries: r.queries,
+		metrics: r.metrics,
+	}
+	if err := fn(txRepo); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *postgresRepository) queryRevisions(ctx context.Context, query string, args ...any) ([]*model.Revision, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*model.Revision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanConfig(row rowScanner) (*model.Config, error) {
+	var config model.Config
+	var spec []byte
+	if err := row.Scan(
+		&config.Environment,
+		&config.Key,
+		&config.Value,
+		&config.UpdatedAt,
+		&config.UpdatedBy,
+		&config.Revision,
+		&config.Version,
+		&spec,
+	); err != nil {
+		return nil, err
+	}
+	if len(spec) > 0 {
+		if err := json.Unmarshal(spec, &config.ValueSpec); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// encodeSpec stores untyped keys as NULL.
+func encodeSpec(spec model.ValueSpec) (any, error) {
+	if spec.IsZero() {
+		return nil, nil
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanRevision(row rowScanner) (*model.Revision, error) {
+	var revision model.Revision
+	var spec []byte
+	if err := row.Scan(
+		&revision.Revision,
+		&revision.Environment,
+		&revision.Key,
+		&revision.Value,
+		&revision.Operation,
+		&revision.Actor,
+		&revision.CreatedAt,
+		&spec,
+	); err != nil {
+		return nil, err
+	}
+	if len(spec) > 0 {
+		if err := json.Unmarshal(spec, &revision.ValueSpec); err != nil {
+			return nil, err
+		}
+	}
+	return &revision, nil
+}
+
 func isUniqueViolation(err error) bool {
 	var pqErr *pq.Error
 	return errors.As(err, &pqErr) && pqErr.Code == "23505"
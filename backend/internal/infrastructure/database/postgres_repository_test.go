@@ -28,8 +28,17 @@ type fakeDriver struct {
 type fakeDBState struct {
 	execResult driver.Result
 	execErr    error
+	execs      []string
 	queryRows  *fakeRows
+	queryQueue []*fakeRows
 	queryErr   error
+	beginErr   error
+	commits    int
+	rollbacks  int
+}
+
+type fakeTx struct {
+	state *fakeDBState
 }
 
 type fakeConn struct {
@@ -61,10 +70,24 @@ func (c *fakeConn) Close() error {
 }
 
 func (c *fakeConn) Begin() (driver.Tx, error) {
-	return nil, errors.New("transactions not supported")
+	if c.state.beginErr != nil {
+		return nil, c.state.beginErr
+	}
+	return fakeTx{state: c.state}, nil
+}
+
+func (tx fakeTx) Commit() error {
+	tx.state.commits++
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.state.rollbacks++
+	return nil
 }
 
-func (c *fakeConn) ExecContext(_ context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.state.execs = append(c.state.execs, query)
 	if c.state.execErr != nil {
 		return nil, c.state.execErr
 	}
@@ -78,6 +101,11 @@ func (c *fakeConn) QueryContext(_ context.Context, _ string, _ []driver.NamedVal
 	if c.state.queryErr != nil {
 		return nil, c.state.queryErr
 	}
+	if len(c.state.queryQueue) > 0 {
+		rows := c.state.queryQueue[0]
+		c.state.queryQueue = c.state.queryQueue[1:]
+		return rows, nil
+	}
 	if c.state.queryRows != nil {
 		return c.state.queryRows, nil
 	}
@@ -160,6 +188,19 @@ func newRepositoryMetrics() *metrics.Metrics {
 	}
 }
 
+var (
+	configColumns   = []string{"env", "key", "value", "updated_at", "updated_by", "revision", "version", "value_spec"}
+	revisionColumns = []string{"revision", "env", "key", "value", "operation", "actor", "created_at", "value_spec"}
+)
+
+func revisionRows(values ...[]driver.Value) *fakeRows {
+	return &fakeRows{columns: revisionColumns, values: values}
+}
+
+func existsRows(exists bool) *fakeRows {
+	return &fakeRows{columns: []string{"exists"}, values: [][]driver.Value{{exists}}}
+}
+
 func TestLoadQueries(t *testing.T) {
 	queries, err := loadQueries()
 	if err != nil {
@@ -167,12 +208,23 @@ func TestLoadQueries(t *testing.T) {
 	}
 
 	for _, name := range []string{
+		"count_configs",
 		"create_config",
+		"create_environment",
 		"delete_config",
 		"exists_config",
 		"get_all_configs",
+		"get_all_configs_at",
 		"get_config",
+		"get_config_at",
+		"get_config_history",
+		"get_environment",
+		"get_revision",
+		"get_revisions_since",
+		"list_configs_by_key",
+		"list_configs_by_updated_at",
 		"update_config",
+		"update_environment",
 	} {
 		if strings.TrimSpace(queries[name]) == "" {
 			t.Fatalf("query %q is missing", name)
@@ -191,6 +243,7 @@ func TestNewPostgresRepository(t *testing.T) {
 }
 
 func TestPostgresRepositoryMissingQueries(t *testing.T) {
+	ctx := context.Background()
 	repo := &postgresRepository{
 		db:      newFakeDB(t, &fakeDBState{}),
 		queries: map[string]string{},
@@ -198,88 +251,134 @@ func TestPostgresRepositoryMissingQueries(t *testing.T) {
 	}
 	config := &model.Config{Environment: "prod", Key: "key", Value: "value", UpdatedAt: time.Now()}
 
-	if err := repo.Create(config); err == nil || !strings.Contains(err.Error(), "create_config") {
+	if _, err := repo.Create(ctx, config); err == nil || !strings.Contains(err.Error(), "create_config") {
 		t.Fatalf("Create() error = %v", err)
 	}
-	if _, err := repo.Get("prod", "key"); err == nil || !strings.Contains(err.Error(), "get_config") {
+	if _, err := repo.Get(ctx, "prod", "key"); err == nil || !strings.Contains(err.Error(), "get_config") {
 		t.Fatalf("Get() error = %v", err)
 	}
-	if _, err := repo.GetAll("prod"); err == nil || !strings.Contains(err.Error(), "get_all_configs") {
+	if _, err := repo.GetAll(ctx, "prod"); err == nil || !strings.Contains(err.Error(), "get_all_configs") {
 		t.Fatalf("GetAll() error = %v", err)
 	}
-	if err := repo.Update(config); err == nil || !strings.Contains(err.Error(), "update_config") {
+	if _, err := repo.ListConfigs(ctx, "prod", model.ConfigQuery{Sort: model.SortByUpdatedAt}); err == nil || !strings.Contains(err.Error(), "list_configs_by_updated_at") {
+		t.Fatalf("ListConfigs() error = %v", err)
+	}
+	if _, err := repo.CountConfigs(ctx, "prod", model.ConfigQuery{}); err == nil || !strings.Contains(err.Error(), "count_configs") {
+		t.Fatalf("CountConfigs() error = %v", err)
+	}
+	if _, err := repo.Update(ctx, config); err == nil || !strings.Contains(err.Error(), "update_config") {
 		t.Fatalf("Update() error = %v", err)
 	}
-	if err := repo.Delete("prod", "key"); err == nil || !strings.Contains(err.Error(), "delete_config") {
+	if _, err := repo.Delete(ctx, "prod", "key", "alice", 0); err == nil || !strings.Contains(err.Error(), "delete_config") {
 		t.Fatalf("Delete() error = %v", err)
 	}
-	if _, err := repo.Exists("prod", "key"); err == nil || !strings.Contains(err.Error(), "exists_config") {
+	if _, err := repo.Exists(ctx, "prod", "key"); err == nil || !strings.Contains(err.Error(), "exists_config") {
 		t.Fatalf("Exists() error = %v", err)
 	}
+	if _, err := repo.GetAt(ctx, "prod", "key", time.Now()); err == nil || !strings.Contains(err.Error(), "get_config_at") {
+		t.Fatalf("GetAt() error = %v", err)
+	}
+	if _, err := repo.GetAllAt(ctx, "prod", time.Now()); err == nil || !strings.Contains(err.Error(), "get_all_configs_at") {
+		t.Fatalf("GetAllAt() error = %v", err)
+	}
+	if _, err := repo.GetHistory(ctx, "prod", "key"); err == nil || !strings.Contains(err.Error(), "get_config_history") {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if _, err := repo.GetRevisionsSince(ctx, "prod", 0, 10); err == nil || !strings.Contains(err.Error(), "get_revisions_since") {
+		t.Fatalf("GetRevisionsSince() error = %v", err)
+	}
+	if _, err := repo.GetRevision(ctx, 1); err == nil || !strings.Contains(err.Error(), "get_revision") {
+		t.Fatalf("GetRevision() error = %v", err)
+	}
+	if err := repo.CreateEnvironment(ctx, &model.Environment{Name: "prod"}); err == nil || !strings.Contains(err.Error(), "create_environment") {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	if _, err := repo.GetEnvironment(ctx, "prod"); err == nil || !strings.Contains(err.Error(), "get_environment") {
+		t.Fatalf("GetEnvironment() error = %v", err)
+	}
+	if err := repo.UpdateEnvironment(ctx, &model.Environment{Name: "prod"}); err == nil || !strings.Contains(err.Error(), "update_environment") {
+		t.Fatalf("UpdateEnvironment() error = %v", err)
+	}
 }
 
 func TestPostgresRepositoryCreate(t *testing.T) {
-	config := &model.Config{Environment: "prod", Key: "key", Value: "value", UpdatedAt: time.Now()}
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	config := &model.Config{Environment: "prod", Key: "key", Value: "value", UpdatedAt: time.Now(), UpdatedBy: "alice"}
 
-	if err := newRepositoryForTest(t, &fakeDBState{}).Create(config); err != nil {
+	revision, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows([]driver.Value{int64(7), "prod", "key", "value", "create", "alice", createdAt, nil}),
+	}).Create(ctx, config)
+	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
+	if revision.Revision != 7 || revision.Operation != model.OperationCreate || revision.Actor != "alice" {
+		t.Fatalf("Create() revision = %#v", revision)
+	}
+	if config.Revision != 7 || config.Version != 1 {
+		t.Fatalf("config revision = %d, version = %d, want 7 and 1", config.Revision, config.Version)
+	}
 
-	wantErr := errors.New("exec failed")
-	if err := newRepositoryForTest(t, &fakeDBState{execErr: wantErr}).Create(config); !errors.Is(err, wantErr) {
+	wantErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Create(ctx, config); !errors.Is(err, wantErr) {
 		t.Fatalf("Create() error = %v, want %v", err, wantErr)
 	}
 
 	duplicateErr := &pq.Error{Code: "23505"}
-	if err := newRepositoryForTest(t, &fakeDBState{execErr: duplicateErr}).Create(config); !errors.Is(err, repository.ErrConfigAlreadyExists) {
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: duplicateErr}).Create(ctx, config); !errors.Is(err, repository.ErrConfigAlreadyExists) {
 		t.Fatalf("Create() duplicate error = %v, want %v", err, repository.ErrConfigAlreadyExists)
 	}
 }
 
 func TestPostgresRepositoryGet(t *testing.T) {
+	ctx := context.Background()
 	updatedAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
 	repo := newRepositoryForTest(t, &fakeDBState{
 		queryRows: &fakeRows{
-			columns: []string{"env", "key", "value", "updated_at"},
-			values:  [][]driver.Value{{"prod", "key", "value", updatedAt}},
+			columns: configColumns,
+			values:  [][]driver.Value{{"prod", "key", "8080", updatedAt, "alice", int64(3), int64(2), []byte(`{"type":"int"}`)}},
 		},
 	})
 
-	config, err := repo.Get("prod", "key")
+	config, err := repo.Get(ctx, "prod", "key")
 	if err != nil {
 		t.Fatalf("Get() error = %v", err)
 	}
-	if config.Environment != "prod" || config.Key != "key" || config.Value != "value" || !config.UpdatedAt.Equal(updatedAt) {
+	if config.Environment != "prod" || config.Key != "key" || config.Value != "8080" || !config.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("Get() = %#v", config)
+	}
+	if config.UpdatedBy != "alice" || config.Revision != 3 || config.Version != 2 || config.Type != model.TypeInt {
 		t.Fatalf("Get() = %#v", config)
 	}
 
 	_, err = newRepositoryForTest(t, &fakeDBState{
-		queryRows: &fakeRows{columns: []string{"env", "key", "value", "updated_at"}},
-	}).Get("prod", "missing")
+		queryRows: &fakeRows{columns: configColumns},
+	}).Get(ctx, "prod", "missing")
 	if !errors.Is(err, repository.ErrConfigNotFound) {
 		t.Fatalf("Get() no rows error = %v", err)
 	}
 
 	wantErr := errors.New("query failed")
-	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Get("prod", "key")
+	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Get(ctx, "prod", "key")
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("Get() error = %v, want %v", err, wantErr)
 	}
 }
 
 func TestPostgresRepositoryGetAll(t *testing.T) {
+	ctx := context.Background()
 	updatedAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
 	repo := newRepositoryForTest(t, &fakeDBState{
 		queryRows: &fakeRows{
-			columns: []string{"env", "key", "value", "updated_at"},
+			columns: configColumns,
 			values: [][]driver.Value{
-				{"prod", "a", "1", updatedAt},
-				{"prod", "b", "2", updatedAt.Add(time.Minute)},
+				{"prod", "a", "1", updatedAt, "alice", int64(1), int64(1), nil},
+				{"prod", "b", "2", updatedAt.Add(time.Minute), "bob", int64(2), int64(1), nil},
 			},
 		},
 	})
 
-	configs, err := repo.GetAll("prod")
+	configs, err := repo.GetAll(ctx, "prod")
 	if err != nil {
 		t.Fatalf("GetAll() error = %v", err)
 	}
@@ -288,89 +387,264 @@ func TestPostgresRepositoryGetAll(t *testing.T) {
 	}
 
 	wantErr := errors.New("query failed")
-	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).GetAll("prod")
+	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).GetAll(ctx, "prod")
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("GetAll() query error = %v, want %v", err, wantErr)
 	}
 
 	_, err = newRepositoryForTest(t, &fakeDBState{
 		queryRows: &fakeRows{
-			columns: []string{"env", "key", "value", "updated_at"},
-			values:  [][]driver.Value{{"prod", "a", "1", "not-a-time"}},
+			columns: configColumns,
+			values:  [][]driver.Value{{"prod", "a", "1", "not-a-time", "alice", int64(1), int64(1), nil}},
 		},
-	}).GetAll("prod")
+	}).GetAll(ctx, "prod")
 	if err == nil {
 		t.Fatal("expected scan error")
 	}
 
 	_, err = newRepositoryForTest(t, &fakeDBState{
 		queryRows: &fakeRows{
-			columns: []string{"env", "key", "value", "updated_at"},
+			columns: configColumns,
 			err:     errors.New("rows failed"),
 		},
-	}).GetAll("prod")
+	}).GetAll(ctx, "prod")
 	if err == nil || !strings.Contains(err.Error(), "rows failed") {
 		t.Fatalf("GetAll() rows error = %v", err)
 	}
 }
 
 func TestPostgresRepositoryUpdate(t *testing.T) {
-	config := &model.Config{Environment: "prod", Key: "key", Value: "value", UpdatedAt: time.Now()}
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	config := &model.Config{Environment: "prod", Key: "key", Value: "value", UpdatedAt: time.Now(), UpdatedBy: "bob", Version: 2}
 
-	if err := newRepositoryForTest(t, &fakeDBState{}).Update(config); err != nil {
+	revision, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows([]driver.Value{int64(8), "prod", "key", "value", "update", "bob", createdAt, nil}),
+	}).Update(ctx, config)
+	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
+	if revision.Operation != model.OperationUpdate || config.Revision != 8 || config.Version != 3 {
+		t.Fatalf("Update() revision = %#v, config = %#v", revision, config)
+	}
 
-	if err := newRepositoryForTest(t, &fakeDBState{
-		execResult: fakeResult{rowsAffected: 0},
-	}).Update(config); !errors.Is(err, repository.ErrConfigNotFound) {
+	if _, err := newRepositoryForTest(t, &fakeDBState{
+		queryQueue: []*fakeRows{revisionRows(), existsRows(false)},
+	}).Update(ctx, config); !errors.Is(err, repository.ErrConfigNotFound) {
 		t.Fatalf("Update() no rows error = %v", err)
 	}
 
-	wantErr := errors.New("exec failed")
-	if err := newRepositoryForTest(t, &fakeDBState{execErr: wantErr}).Update(config); !errors.Is(err, wantErr) {
-		t.Fatalf("Update() exec error = %v, want %v", err, wantErr)
+	if _, err := newRepositoryForTest(t, &fakeDBState{
+		queryQueue: []*fakeRows{revisionRows(), existsRows(true)},
+	}).Update(ctx, config); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Update() stale version error = %v, want %v", err, repository.ErrVersionConflict)
 	}
 
-	rowsErr := errors.New("rows affected failed")
-	if err := newRepositoryForTest(t, &fakeDBState{
-		execResult: fakeResult{rowsAffected: 1, rowsErr: rowsErr},
-	}).Update(config); !errors.Is(err, rowsErr) {
-		t.Fatalf("Update() rows error = %v, want %v", err, rowsErr)
+	wantErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Update(ctx, config); !errors.Is(err, wantErr) {
+		t.Fatalf("Update() query error = %v, want %v", err, wantErr)
 	}
 }
 
 func TestPostgresRepositoryDelete(t *testing.T) {
-	if err := newRepositoryForTest(t, &fakeDBState{}).Delete("prod", "key"); err != nil {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	revision, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows([]driver.Value{int64(9), "prod", "key", "value", "delete", "carol", createdAt, nil}),
+	}).Delete(ctx, "prod", "key", "carol", 0)
+	if err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
+	if revision.Operation != model.OperationDelete || revision.Actor != "carol" {
+		t.Fatalf("Delete() revision = %#v", revision)
+	}
 
-	if err := newRepositoryForTest(t, &fakeDBState{
-		execResult: fakeResult{rowsAffected: 0},
-	}).Delete("prod", "key"); !errors.Is(err, repository.ErrConfigNotFound) {
+	if _, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows(),
+	}).Delete(ctx, "prod", "key", "carol", 0); !errors.Is(err, repository.ErrConfigNotFound) {
 		t.Fatalf("Delete() no rows error = %v", err)
 	}
 
-	wantErr := errors.New("exec failed")
-	if err := newRepositoryForTest(t, &fakeDBState{execErr: wantErr}).Delete("prod", "key"); !errors.Is(err, wantErr) {
-		t.Fatalf("Delete() exec error = %v, want %v", err, wantErr)
+	if _, err := newRepositoryForTest(t, &fakeDBState{
+		queryQueue: []*fakeRows{revisionRows(), existsRows(true)},
+	}).Delete(ctx, "prod", "key", "carol", 4); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Delete() stale version error = %v, want %v", err, repository.ErrVersionConflict)
 	}
 
-	rowsErr := errors.New("rows affected failed")
-	if err := newRepositoryForTest(t, &fakeDBState{
-		execResult: fakeResult{rowsAffected: 1, rowsErr: rowsErr},
-	}).Delete("prod", "key"); !errors.Is(err, rowsErr) {
-		t.Fatalf("Delete() rows error = %v, want %v", err, rowsErr)
+	wantErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Delete(ctx, "prod", "key", "carol", 0); !errors.Is(err, wantErr) {
+		t.Fatalf("Delete() query error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestPostgresRepositoryGetAt(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	config, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows([]driver.Value{int64(4), "prod", "key", "old", "update", "alice", createdAt, []byte(`{"type":"int"}`)}),
+	}).GetAt(ctx, "prod", "key", createdAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetAt() error = %v", err)
+	}
+	if config.Value != "old" || config.Revision != 4 || !config.UpdatedAt.Equal(createdAt) || config.UpdatedBy != "alice" || config.Type != model.TypeInt {
+		t.Fatalf("GetAt() = %#v", config)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{queryRows: revisionRows()}).GetAt(ctx, "prod", "key", createdAt)
+	if !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("GetAt() no rows error = %v", err)
+	}
+
+	wantErr := errors.New("query failed")
+	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).GetAt(ctx, "prod", "key", createdAt)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("GetAt() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestPostgresRepositoryGetAllAt(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	configs, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows(
+			[]driver.Value{int64(1), "prod", "a", "1", "create", "alice", createdAt, nil},
+			[]driver.Value{int64(5), "prod", "b", "2", "update", "bob", createdAt, nil},
+		),
+	}).GetAllAt(ctx, "prod", createdAt)
+	if err != nil {
+		t.Fatalf("GetAllAt() error = %v", err)
+	}
+	if len(configs) != 2 || configs[0].Key != "a" || configs[1].Revision != 5 {
+		t.Fatalf("GetAllAt() = %#v", configs)
+	}
+
+	wantErr := errors.New("query failed")
+	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).GetAllAt(ctx, "prod", createdAt)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("GetAllAt() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestPostgresRepositoryGetHistory(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	revisions, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows(
+			[]driver.Value{int64(3), "prod", "key", "2", "update", "bob", createdAt.Add(time.Minute), nil},
+			[]driver.Value{int64(1), "prod", "key", "1", "create", "alice", createdAt, nil},
+		),
+	}).GetHistory(ctx, "prod", "key")
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 3 || revisions[1].Operation != model.OperationCreate {
+		t.Fatalf("GetHistory() = %#v", revisions)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{columns: revisionColumns, err: errors.New("rows failed")},
+	}).GetHistory(ctx, "prod", "key")
+	if err == nil || !strings.Contains(err.Error(), "rows failed") {
+		t.Fatalf("GetHistory() rows error = %v", err)
+	}
+}
+
+func TestPostgresRepositoryGetRevisionsSince(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	revisions, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows(
+			[]driver.Value{int64(4), "prod", "a", "1", "create", "alice", createdAt, nil},
+			[]driver.Value{int64(6), "prod", "b", "", "delete", "bob", createdAt.Add(time.Minute), nil},
+		),
+	}).GetRevisionsSince(ctx, "prod", 3, 10)
+	if err != nil {
+		t.Fatalf("GetRevisionsSince() error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 4 || revisions[1].Operation != model.OperationDelete {
+		t.Fatalf("GetRevisionsSince() = %#v", revisions)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: errors.New("query failed")}).GetRevisionsSince(ctx, "prod", 3, 10)
+	if err == nil || !strings.Contains(err.Error(), "query failed") {
+		t.Fatalf("GetRevisionsSince() query error = %v", err)
+	}
+}
+
+func TestPostgresRepositoryGetRevision(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	revision, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: revisionRows([]driver.Value{int64(12), "prod", "key", "value", "update", "bob", createdAt, nil}),
+	}).GetRevision(ctx, 12)
+	if err != nil {
+		t.Fatalf("GetRevision() error = %v", err)
+	}
+	if revision.Revision != 12 || revision.Environment != "prod" || revision.Actor != "bob" {
+		t.Fatalf("GetRevision() = %#v", revision)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{queryRows: revisionRows()}).GetRevision(ctx, 12)
+	if !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("GetRevision() missing error = %v, want %v", err, repository.ErrConfigNotFound)
+	}
+}
+
+func TestEncodeSpec(t *testing.T) {
+	untyped, err := encodeSpec(model.ValueSpec{})
+	if err != nil || untyped != nil {
+		t.Fatalf("encodeSpec(zero) = %v, %v; want nil", untyped, err)
+	}
+
+	typed, err := encodeSpec(model.ValueSpec{Type: model.TypeEnum, Enum: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("encodeSpec() error = %v", err)
+	}
+	if typed != `{"type":"enum","enum":["a","b"]}` {
+		t.Fatalf("encodeSpec() = %v", typed)
+	}
+}
+
+func TestParseNotification(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    ChangeNotification
+		wantErr bool
+	}{
+		{name: "valid", payload: `{"revision":42,"env":"prod"}`, want: ChangeNotification{Revision: 42, Environment: "prod"}},
+		{name: "invalid json", payload: `not json`, wantErr: true},
+		{name: "missing revision", payload: `{"env":"prod"}`, wantErr: true},
+		{name: "missing environment", payload: `{"revision":42}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNotification(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseNotification() = %#v, want %#v", got, tt.want)
+			}
+		})
 	}
 }
 
 func TestPostgresRepositoryExists(t *testing.T) {
+	ctx := context.Background()
 	exists, err := newRepositoryForTest(t, &fakeDBState{
 		queryRows: &fakeRows{
 			columns: []string{"exists"},
 			values:  [][]driver.Value{{true}},
 		},
-	}).Exists("prod", "key")
+	}).Exists(ctx, "prod", "key")
 	if err != nil {
 		t.Fatalf("Exists() error = %v", err)
 	}
@@ -379,12 +653,73 @@ func TestPostgresRepositoryExists(t *testing.T) {
 	}
 
 	wantErr := errors.New("query failed")
-	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Exists("prod", "key")
+	_, err = newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).Exists(ctx, "prod", "key")
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("Exists() error = %v, want %v", err, wantErr)
 	}
 }
 
+func TestPostgresRepositoryWithTx(t *testing.T) {
+	ctx := context.Background()
+	state := &fakeDBState{}
+	repo := newRepositoryForTest(t, state)
+
+	var txRepo repository.ConfigRepository
+	if err := repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		txRepo = tx
+		return tx.WithTx(ctx, func(nested repository.ConfigRepository) error {
+			if nested != tx {
+				t.Fatal("nested WithTx should reuse the transaction")
+			}
+			return nil
+		})
+	}); err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if txRepo == repository.ConfigRepository(repo) {
+		t.Fatal("WithTx() should pass a transaction-bound repository")
+	}
+	if state.commits != 1 || state.rollbacks != 0 {
+		t.Fatalf("commits = %d, rollbacks = %d", state.commits, state.rollbacks)
+	}
+
+	wantErr := errors.New("rollback me")
+	if err := repo.WithTx(ctx, func(repository.ConfigRepository) error {
+		return wantErr
+	}); !errors.Is(err, wantErr) {
+		t.Fatalf("WithTx() error = %v, want %v", err, wantErr)
+	}
+	if state.commits != 1 || state.rollbacks != 1 {
+		t.Fatalf("commits = %d, rollbacks = %d", state.commits, state.rollbacks)
+	}
+
+	beginErr := errors.New("begin failed")
+	err := newRepositoryForTest(t, &fakeDBState{beginErr: beginErr}).WithTx(ctx, func(repository.ConfigRepository) error {
+		t.Fatal("fn must not run when begin fails")
+		return nil
+	})
+	if !errors.Is(err, beginErr) {
+		t.Fatalf("WithTx() begin error = %v, want %v", err, beginErr)
+	}
+}
+
+func TestPostgresRepositoryCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	repo := newRepositoryForTest(t, &fakeDBState{})
+
+	if _, err := repo.Get(ctx, "prod", "key"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Get() error = %v, want %v", err, context.Canceled)
+	}
+	err := repo.WithTx(ctx, func(repository.ConfigRepository) error {
+		t.Fatal("fn must not run when the context is canceled")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("WithTx() error = %v, want %v", err, context.Canceled)
+	}
+}
+
 func TestPostgresConnectionAccessors(t *testing.T) {
 	db := newFakeDB(t, &fakeDBState{})
 	conn := &postgresConnection{db: db}
@@ -1,3 +1,16 @@
-INSERT INTO configs (env, key, value, updated_at)
-VALUES ($1, $2, $3, $4);
-
+-- Номера ревизий окружения выдаются под блокировкой окружения до конца
+-- транзакции, поэтому ревизии фиксируются в порядке номеров и выборка
+-- revision > since не пропускает ревизию, зафиксированную позже следующей.
+WITH locked AS (
+    SELECT pg_advisory_xact_lock(hashtext('config_revisions'), hashtext($1))
+),
+inserted AS (
+    INSERT INTO configs (env, key, value, updated_at, updated_by, value_spec, revision)
+    SELECT $1, $2, $3, $4, $5, $6, nextval('config_revision_seq')
+    FROM locked
+    RETURNING env, key, value, updated_by, revision, value_spec
+)
+INSERT INTO config_revisions (revision, env, key, value, operation, actor, value_spec)
+SELECT revision, env, key, value, 'create', updated_by, value_spec
+FROM inserted
+RETURNING revision, env, key, value, operation, actor, created_at, value_spec;
@@ -0,0 +1,3 @@
+INSERT INTO secret_data_keys (master_key, wrapped, created_at)
+VALUES ($1, $2, $3)
+RETURNING id;
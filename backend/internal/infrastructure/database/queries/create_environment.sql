@@ -0,0 +1,2 @@
+INSERT INTO environments (name, parent, description, owner, protected, created_at)
+VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6);
@@ -0,0 +1,3 @@
+INSERT INTO api_tokens (name, token_hash, grants, created_by, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
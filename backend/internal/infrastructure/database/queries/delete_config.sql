@@ -1,3 +1,14 @@
-DELETE FROM configs
-WHERE env = $1 AND key = $2;
-
+-- Блокировка окружения: см. create_config.sql.
+WITH locked AS (
+    SELECT pg_advisory_xact_lock(hashtext('config_revisions'), hashtext($1))
+),
+deleted AS (
+    DELETE FROM configs
+    USING locked
+    WHERE env = $1 AND key = $2 AND ($4::BIGINT = 0 OR version = $4)
+    RETURNING env, key, value, value_spec
+)
+INSERT INTO config_revisions (env, key, value, operation, actor, value_spec)
+SELECT env, key, value, 'delete', $3, value_spec
+FROM deleted
+RETURNING revision, env, key, value, operation, actor, created_at, value_spec;
@@ -1,5 +1,4 @@
-SELECT env, key, value, updated_at
+SELECT env, key, value, updated_at, updated_by, revision, version, value_spec
 FROM configs
 WHERE env = $1
 ORDER BY key;
-
@@ -0,0 +1,9 @@
+SELECT revision, env, key, value, operation, actor, created_at, value_spec
+FROM (
+    SELECT DISTINCT ON (key) revision, env, key, value, operation, actor, created_at, value_spec
+    FROM config_revisions
+    WHERE env = $1 AND created_at <= $2
+    ORDER BY key, revision DESC
+) latest
+WHERE operation <> 'delete'
+ORDER BY key;
@@ -0,0 +1,10 @@
+SELECT id, actor, source_ip, request_id, operation, env, key, revision, old_value, new_value, secret, created_at
+FROM audit_log
+WHERE ($1::TEXT = '' OR env = $1)
+  AND ($2::TEXT = '' OR key = $2)
+  AND ($3::TEXT = '' OR actor = $3)
+  AND ($4::TIMESTAMPTZ IS NULL OR created_at >= $4)
+  AND ($5::TIMESTAMPTZ IS NULL OR created_at < $5)
+  AND ($6::BIGINT = 0 OR id < $6)
+ORDER BY id DESC
+LIMIT $7;
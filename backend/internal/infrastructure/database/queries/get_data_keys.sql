@@ -0,0 +1,3 @@
+SELECT id, master_key, wrapped, created_at
+FROM secret_data_keys
+ORDER BY id;
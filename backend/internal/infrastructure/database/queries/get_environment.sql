@@ -0,0 +1,4 @@
+SELECT e.name, COALESCE(e.parent, ''), e.description, e.owner, e.protected, e.created_at,
+       (SELECT COUNT(*) FROM configs c WHERE c.env = e.name)
+FROM environments e
+WHERE e.name = $1;
@@ -0,0 +1,4 @@
+SELECT version
+FROM schema_migrations
+ORDER BY version DESC
+LIMIT 1;
@@ -0,0 +1,3 @@
+SELECT revision, env, key, value, operation, actor, created_at, value_spec
+FROM config_revisions
+WHERE revision = $1;
@@ -0,0 +1,3 @@
+SELECT DISTINCT value FROM configs
+WHERE left(value, length($1)) = $1 AND left(value, length($2)) <> $2
+LIMIT $3;
@@ -0,0 +1,3 @@
+SELECT id, name, grants, created_by, created_at, expires_at
+FROM api_tokens
+WHERE token_hash = $1;
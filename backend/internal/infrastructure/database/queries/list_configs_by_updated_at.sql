@@ -0,0 +1,11 @@
+SELECT env, key, value, updated_at, updated_by, revision, version, value_spec
+FROM configs
+WHERE env = $1
+  AND ($2::TEXT = '' OR key LIKE $2)
+  AND ($3::TEXT = '' OR key ILIKE $3 OR (value ILIKE $3 AND COALESCE(value_spec->>'secret', '') <> 'true'))
+  AND ($4::TIMESTAMP IS NULL OR updated_at >= $4)
+  AND ($5::TEXT[] IS NULL OR key LIKE ANY($5))
+  AND ($6::TEXT = '' OR key = $6 OR key LIKE $7)
+  AND ($8::TEXT = '' OR updated_at < $9::TIMESTAMP OR (updated_at = $9::TIMESTAMP AND key > $8))
+ORDER BY updated_at DESC, key
+LIMIT $10;
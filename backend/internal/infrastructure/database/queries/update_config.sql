@@ -1,4 +1,20 @@
-UPDATE configs
-SET value = $3, updated_at = $4
-WHERE env = $1 AND key = $2;
-
+-- Блокировка окружения: см. create_config.sql.
+WITH locked AS (
+    SELECT pg_advisory_xact_lock(hashtext('config_revisions'), hashtext($1))
+),
+updated AS (
+    UPDATE configs
+    SET value = $3,
+        updated_at = $4,
+        updated_by = $5,
+        value_spec = $7,
+        revision = nextval('config_revision_seq'),
+        version = version + 1
+    FROM locked
+    WHERE env = $1 AND key = $2 AND version = $6
+    RETURNING env, key, value, updated_by, revision, value_spec
+)
+INSERT INTO config_revisions (revision, env, key, value, operation, actor, value_spec)
+SELECT revision, env, key, value, 'update', updated_by, value_spec
+FROM updated
+RETURNING revision, env, key, value, operation, actor, created_at, value_spec;
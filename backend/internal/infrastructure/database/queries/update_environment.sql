@@ -0,0 +1,6 @@
+UPDATE environments
+SET parent = NULLIF($2, ''),
+    description = $3,
+    owner = $4,
+    protected = $5
+WHERE name = $1;
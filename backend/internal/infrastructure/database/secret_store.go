@@ -0,0 +1,146 @@
+package database
+
+import (
+	"config-service/backend/internal/repository"
+	"config-service/backend/pkg/metrics"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func NewPostgresSecretStore(db *sql.DB, m *metrics.Metrics) (repository.SecretStore, error) {
+	return newPostgresRepository(db, m)
+}
+
+func (r *postgresRepository) CreateDataKey(ctx context.Context, key *repository.DataKey) error {
+	start := time.Now()
+	query := r.queries["create_data_key"]
+	if query == "" {
+		return errors.New("create_data_key query not found")
+	}
+	err := r.db.QueryRowContext(ctx, query, key.MasterKey, key.Wrapped, key.CreatedAt).Scan(&key.ID)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("create_data_key").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("create_data_key").Observe(duration)
+	return err
+}
+
+func (r *postgresRepository) GetDataKey(ctx context.Context, id int64) (*repository.DataKey, error) {
+	start := time.Now()
+	query := r.queries["get_data_key"]
+	if query == "" {
+		return nil, errors.New("get_data_key query not found")
+	}
+	key, err := scanDataKey(r.db.QueryRowContext(ctx, query, id))
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_data_key").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_data_key").Observe(duration)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrDataKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *postgresRepository) GetDataKeys(ctx context.Context) ([]*repository.DataKey, error) {
+	start := time.Now()
+	query := r.queries["get_data_keys"]
+	if query == "" {
+		return nil, errors.New("get_data_keys query not found")
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*repository.DataKey
+	for rows.Next() {
+		key, err := scanDataKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_data_keys").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_data_keys").Observe(duration)
+	return keys, nil
+}
+
+func (r *postgresRepository) RewrapDataKey(ctx context.Context, key *repository.DataKey) error {
+	start := time.Now()
+	query := r.queries["rewrap_data_key"]
+	if query == "" {
+		return errors.New("rewrap_data_key query not found")
+	}
+	result, err := r.db.ExecContext(ctx, query, key.ID, key.MasterKey, key.Wrapped)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("rewrap_data_key").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("rewrap_data_key").Observe(duration)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, repository.ErrDataKeyNotFound)
+}
+
+func (r *postgresRepository) GetStaleSecrets(ctx context.Context, prefix, current string, limit int) ([]string, error) {
+	start := time.Now()
+	query := r.queries["get_stale_secrets"]
+	if query == "" {
+		return nil, errors.New("get_stale_secrets query not found")
+	}
+	rows, err := r.db.QueryContext(ctx, query, prefix, current, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_stale_secrets").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_stale_secrets").Observe(duration)
+	return values, nil
+}
+
+func (r *postgresRepository) ReplaceSecret(ctx context.Context, old, replacement string) (int64, error) {
+	start := time.Now()
+	query := r.queries["replace_secret"]
+	if query == "" {
+		return 0, errors.New("replace_secret query not found")
+	}
+	result, err := r.db.ExecContext(ctx, query, old, replacement)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("replace_secret").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("replace_secret").Observe(duration)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanDataKey(row rowScanner) (*repository.DataKey, error) {
+	var key repository.DataKey
+	if err := row.Scan(&key.ID, &key.MasterKey, &key.Wrapped, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+var dataKeyColumns = []string{"id", "master_key", "wrapped", "created_at"}
+
+func TestPostgresSecretStoreGetDataKeys(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	store, err := NewPostgresSecretStore(newFakeDB(t, &fakeDBState{
+		queryRows: &fakeRows{
+			columns: dataKeyColumns,
+			values:  [][]driver.Value{{int64(1), "k1", []byte("a"), createdAt}, {int64(2), "k2", []byte("b"), createdAt}},
+		},
+	}), newRepositoryMetrics())
+	if err != nil {
+		t.Fatalf("NewPostgresSecretStore() error = %v", err)
+	}
+
+	keys, err := store.GetDataKeys(ctx)
+	if err != nil || len(keys) != 2 || keys[1].ID != 2 || keys[1].MasterKey != "k2" || string(keys[1].Wrapped) != "b" {
+		t.Fatalf("GetDataKeys() = %v, %v", keys, err)
+	}
+
+	wantErr := errors.New("query failed")
+	if _, err := newRepositoryForTest(t, &fakeDBState{queryErr: wantErr}).GetDataKeys(ctx); !errors.Is(err, wantErr) {
+		t.Fatalf("GetDataKeys() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestPostgresSecretStoreGetDataKey(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	key, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{columns: dataKeyColumns, values: [][]driver.Value{{int64(3), "k1", []byte("a"), createdAt}}},
+	}).GetDataKey(ctx, 3)
+	if err != nil || key.ID != 3 || key.MasterKey != "k1" || !key.CreatedAt.Equal(createdAt) {
+		t.Fatalf("GetDataKey() = %#v, %v", key, err)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{queryRows: &fakeRows{columns: dataKeyColumns}}).GetDataKey(ctx, 3)
+	if !errors.Is(err, repository.ErrDataKeyNotFound) {
+		t.Fatalf("GetDataKey() no rows error = %v", err)
+	}
+}
+
+func TestPostgresSecretStoreCreateDataKey(t *testing.T) {
+	ctx := context.Background()
+	key := &repository.DataKey{MasterKey: "k1", Wrapped: []byte("a"), CreatedAt: time.Now()}
+	err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(4)}}},
+	}).CreateDataKey(ctx, key)
+	if err != nil || key.ID != 4 {
+		t.Fatalf("CreateDataKey() = %d, %v", key.ID, err)
+	}
+}
+
+func TestPostgresSecretStoreRewrapDataKey(t *testing.T) {
+	ctx := context.Background()
+	key := &repository.DataKey{ID: 4, MasterKey: "k2", Wrapped: []byte("b")}
+	if err := newRepositoryForTest(t, &fakeDBState{}).RewrapDataKey(ctx, key); err != nil {
+		t.Fatalf("RewrapDataKey() error = %v", err)
+	}
+	if err := newRepositoryForTest(t, &fakeDBState{execResult: fakeResult{rowsAffected: 0}}).RewrapDataKey(ctx, key); !errors.Is(err, repository.ErrDataKeyNotFound) {
+		t.Fatalf("RewrapDataKey() missing error = %v, want %v", err, repository.ErrDataKeyNotFound)
+	}
+
+	wantErr := errors.New("exec failed")
+	if err := newRepositoryForTest(t, &fakeDBState{execErr: wantErr}).RewrapDataKey(ctx, key); !errors.Is(err, wantErr) {
+		t.Fatalf("RewrapDataKey() error = %v, want %v", err, wantErr)
+	}
+}
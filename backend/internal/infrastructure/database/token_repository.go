@@ -0,0 +1,140 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"config-service/backend/pkg/metrics"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func NewPostgresTokenRepository(db *sql.DB, m *metrics.Metrics) (repository.TokenRepository, error) {
+	return newPostgresRepository(db, m)
+}
+
+func (r *postgresRepository) CreateToken(ctx context.Context, token *model.Token, hash string) error {
+	start := time.Now()
+	query := r.queries["create_token"]
+	if query == "" {
+		return errors.New("create_token query not found")
+	}
+	grants, err := json.Marshal(token.Grants)
+	if err != nil {
+		return err
+	}
+	var expiresAt sql.NullTime
+	if token.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *token.ExpiresAt, Valid: true}
+	}
+	err = r.db.QueryRowContext(ctx,
+		query,
+		token.Name,
+		hash,
+		string(grants),
+		token.CreatedBy,
+		token.CreatedAt,
+		expiresAt,
+	).Scan(&token.ID)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("create_token").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("create_token").Observe(duration)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrTokenAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *postgresRepository) GetTokenByHash(ctx context.Context, hash string) (*model.Token, error) {
+	start := time.Now()
+	query := r.queries["get_token_by_hash"]
+	if query == "" {
+		return nil, errors.New("get_token_by_hash query not found")
+	}
+	token, err := scanToken(r.db.QueryRowContext(ctx, query, hash))
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_token_by_hash").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_token_by_hash").Observe(duration)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *postgresRepository) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	start := time.Now()
+	query := r.queries["get_tokens"]
+	if query == "" {
+		return nil, errors.New("get_tokens query not found")
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*model.Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("get_tokens").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("get_tokens").Observe(duration)
+	return tokens, nil
+}
+
+func (r *postgresRepository) DeleteToken(ctx context.Context, id int64) error {
+	start := time.Now()
+	query := r.queries["delete_token"]
+	if query == "" {
+		return errors.New("delete_token query not found")
+	}
+	result, err := r.db.ExecContext(ctx, query, id)
+	duration := time.Since(start).Seconds()
+	r.metrics.DBQueriesTotal.WithLabelValues("delete_token").Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("delete_token").Observe(duration)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, repository.ErrTokenNotFound)
+}
+
+func scanToken(row rowScanner) (*model.Token, error) {
+	var token model.Token
+	var grants []byte
+	var expiresAt sql.NullTime
+	if err := row.Scan(
+		&token.ID,
+		&token.Name,
+		&grants,
+		&token.CreatedBy,
+		&token.CreatedAt,
+		&expiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(grants, &token.Grants); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return &token, nil
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var tokenColumns = []string{"id", "name", "grants", "created_by", "created_at", "expires_at"}
+
+func TestPostgresTokenRepositoryCreateToken(t *testing.T) {
+	ctx := context.Background()
+	token := &model.Token{Name: "ci", Grants: []model.Grant{{Environment: "prod", Role: model.RoleReader}}, CreatedAt: time.Now()}
+
+	repo, err := NewPostgresTokenRepository(newFakeDB(t, &fakeDBState{
+		queryRows: &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(7)}}},
+	}), newRepositoryMetrics())
+	if err != nil {
+		t.Fatalf("NewPostgresTokenRepository() error = %v", err)
+	}
+	if err := repo.CreateToken(ctx, token, "hash"); err != nil || token.ID != 7 {
+		t.Fatalf("CreateToken() = %v, id %d", err, token.ID)
+	}
+
+	err = newRepositoryForTest(t, &fakeDBState{queryErr: &pq.Error{Code: "23505"}}).CreateToken(ctx, token, "hash")
+	if !errors.Is(err, repository.ErrTokenAlreadyExists) {
+		t.Fatalf("CreateToken() duplicate error = %v, want %v", err, repository.ErrTokenAlreadyExists)
+	}
+}
+
+func TestPostgresTokenRepositoryGetTokenByHash(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+
+	token, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{
+			columns: tokenColumns,
+			values: [][]driver.Value{{
+				int64(7), "ci", []byte(`[{"env":"prod","key_prefix":"billing.","role":"writer"}]`), "alice", createdAt, expiresAt,
+			}},
+		},
+	}).GetTokenByHash(ctx, "hash")
+	if err != nil {
+		t.Fatalf("GetTokenByHash() error = %v", err)
+	}
+	wantGrant := model.Grant{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleWriter}
+	if token.ID != 7 || token.Name != "ci" || len(token.Grants) != 1 || token.Grants[0] != wantGrant ||
+		token.ExpiresAt == nil || !token.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("GetTokenByHash() = %#v", token)
+	}
+
+	_, err = newRepositoryForTest(t, &fakeDBState{queryRows: &fakeRows{columns: tokenColumns}}).GetTokenByHash(ctx, "missing")
+	if !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Fatalf("GetTokenByHash() missing error = %v, want %v", err, repository.ErrTokenNotFound)
+	}
+}
+
+func TestPostgresTokenRepositoryGetTokens(t *testing.T) {
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+
+	tokens, err := newRepositoryForTest(t, &fakeDBState{
+		queryRows: &fakeRows{
+			columns: tokenColumns,
+			values: [][]driver.Value{
+				{int64(1), "admin", []byte(`[{"env":"*","role":"admin"}]`), "", createdAt, nil},
+				{int64(2), "ci", []byte(`[{"env":"prod","role":"reader"}]`), "admin", createdAt, nil},
+			},
+		},
+	}).GetTokens(context.Background())
+	if err != nil || len(tokens) != 2 || tokens[0].Grants[0].Environment != model.AllEnvironments || tokens[1].ExpiresAt != nil {
+		t.Fatalf("GetTokens() = %#v, %v", tokens, err)
+	}
+}
+
+func TestPostgresTokenRepositoryDeleteToken(t *testing.T) {
+	ctx := context.Background()
+	if err := newRepositoryForTest(t, &fakeDBState{}).DeleteToken(ctx, 7); err != nil {
+		t.Fatalf("DeleteToken() error = %v", err)
+	}
+
+	err := newRepositoryForTest(t, &fakeDBState{execResult: fakeResult{rowsAffected: 0}}).DeleteToken(ctx, 7)
+	if !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Fatalf("DeleteToken() missing error = %v, want %v", err, repository.ErrTokenNotFound)
+	}
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"slices"
+)
+
+func (r *Repository) AppendAudit(ctx context.Context, entry *model.AuditEntry) error {
+	return r.update(ctx, func(d *data) error {
+		d.LastAuditID++
+		stored := *entry
+		stored.ID = d.LastAuditID
+		stored.CreatedAt = r.now()
+		d.Audit = append(d.Audit, &stored)
+
+		entry.ID, entry.CreatedAt = stored.ID, stored.CreatedAt
+		return nil
+	})
+}
+
+func (r *Repository) GetAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	var entries []*model.AuditEntry
+	err := r.view(ctx, func(d *data) error {
+		for _, entry := range slices.Backward(d.Audit) {
+			if len(entries) >= filter.Limit {
+				break
+			}
+			if matchesAudit(entry, filter) {
+				copied := *entry
+				entries = append(entries, &copied)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func matchesAudit(entry *model.AuditEntry, filter model.AuditFilter) bool {
+	switch {
+	case filter.Environment != "" && entry.Environment != filter.Environment,
+		filter.Key != "" && entry.Key != filter.Key,
+		filter.Actor != "" && entry.Actor != filter.Actor,
+		!filter.From.IsZero() && entry.CreatedAt.Before(filter.From),
+		!filter.To.IsZero() && !entry.CreatedAt.Before(filter.To),
+		filter.Cursor != 0 && entry.ID >= filter.Cursor:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"cmp"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (r *Repository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	var revision *model.Revision
+	err := r.update(ctx, func(d *data) error {
+		if _, ok := d.Environments[config.Environment]; !ok {
+			return repository.ErrEnvironmentNotFound
+		}
+		if _, ok := d.Configs[config.Environment][config.Key]; ok {
+			return repository.ErrConfigAlreadyExists
+		}
+
+		revision = d.appendRevision(config.Environment, config.Key, config.Value, config.ValueSpec, model.OperationCreate, config.UpdatedBy, r.now())
+		stored := *config
+		stored.Revision = revision.Revision
+		stored.Version = 1
+		d.putConfig(&stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config.Revision = revision.Revision
+	config.Version = 1
+	return copyRevision(revision), nil
+}
+
+func (r *Repository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
+	var config *model.Config
+	err := r.view(ctx, func(d *data) error {
+		stored, ok := d.Configs[environment][key]
+		if !ok {
+			return repository.ErrConfigNotFound
+		}
+		config = copyConfig(stored)
+		return nil
+	})
+	return config, err
+}
+
+func (r *Repository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
+	return r.ListConfigs(ctx, environment, model.ConfigQuery{})
+}
+
+func (r *Repository) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) ([]*model.Config, error) {
+	var configs []*model.Config
+	err := r.view(ctx, func(d *data) error {
+		for _, config := range d.matching(environment, query) {
+			if query.After != nil && !after(config, query) {
+				continue
+			}
+			configs = append(configs, copyConfig(config))
+			if len(configs) == query.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func (r *Repository) CountConfigs(ctx context.Context, environment string, query model.ConfigQuery) (int, error) {
+	var count int
+	err := r.view(ctx, func(d *data) error {
+		count = len(d.matching(environment, query))
+		return nil
+	})
+	return count, err
+}
+
+func (r *Repository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	var revision *model.Revision
+	err := r.update(ctx, func(d *data) error {
+		stored, ok := d.Configs[config.Environment][config.Key]
+		if !ok {
+			return repository.ErrConfigNotFound
+		}
+		if stored.Version != config.Version {
+			return repository.ErrVersionConflict
+		}
+
+		revision = d.appendRevision(config.Environment, config.Key, config.Value, config.ValueSpec, model.OperationUpdate, config.UpdatedBy, r.now())
+		updated := *config
+		updated.Revision = revision.Revision
+		updated.Version = stored.Version + 1
+		d.putConfig(&updated)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config.Revision = revision.Revision
+	config.Version++
+	return copyRevision(revision), nil
+}
+
+func (r *Repository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
+	var revision *model.Revision
+	err := r.update(ctx, func(d *data) error {
+		stored, ok := d.Configs[environment][key]
+		if !ok {
+			return repository.ErrConfigNotFound
+		}
+		if version != 0 && stored.Version != version {
+			return repository.ErrVersionConflict
+		}
+
+		revision = d.appendRevision(environment, key, stored.Value, stored.ValueSpec, model.OperationDelete, actor, r.now())
+		delete(d.Configs[environment], key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyRevision(revision), nil
+}
+
+func (r *Repository) Exists(ctx context.Context, environment, key string) (bool, error) {
+	var exists bool
+	err := r.view(ctx, func(d *data) error {
+		_, exists = d.Configs[environment][key]
+		return nil
+	})
+	return exists, err
+}
+
+func (r *Repository) GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	var config *model.Config
+	err := r.view(ctx, func(d *data) error {
+		for _, revision := range slices.Backward(d.Revisions) {
+			if revision.Environment != environment || revision.Key != key || revision.CreatedAt.After(asOf) {
+				continue
+			}
+			if revision.Operation == model.OperationDelete {
+				break
+			}
+			config = revision.Config()
+			return nil
+		}
+		return repository.ErrConfigNotFound
+	})
+	return config, err
+}
+
+func (r *Repository) GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	var configs []*model.Config
+	err := r.view(ctx, func(d *data) error {
+		latest := make(map[string]*model.Revision)
+		for _, revision := range d.Revisions {
+			if revision.Environment == environment && !revision.CreatedAt.After(asOf) {
+				latest[revision.Key] = revision
+			}
+		}
+		for _, revision := range latest {
+			if revision.Operation != model.OperationDelete {
+				configs = append(configs, revision.Config())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(configs, func(a, b *model.Config) int { return strings.Compare(a.Key, b.Key) })
+	return configs, nil
+}
+
+func (r *Repository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	var revisions []*model.Revision
+	err := r.view(ctx, func(d *data) error {
+		for _, revision := range slices.Backward(d.Revisions) {
+			if revision.Environment == environment && revision.Key == key {
+				revisions = append(revisions, copyRevision(revision))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *Repository) GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error) {
+	var revisions []*model.Revision
+	err := r.view(ctx, func(d *data) error {
+		for _, revision := range d.Revisions {
+			if len(revisions) >= limit {
+				break
+			}
+			if revision.Environment == environment && revision.Revision > since {
+				revisions = append(revisions, copyRevision(revision))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *Repository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	var result *model.Revision
+	err := r.view(ctx, func(d *data) error {
+		i, ok := slices.BinarySearchFunc(d.Revisions, revision, func(stored *model.Revision, target int64) int {
+			return cmp.Compare(stored.Revision, target)
+		})
+		if !ok {
+			return repository.ErrConfigNotFound
+		}
+		result = copyRevision(d.Revisions[i])
+		return nil
+	})
+	return result, err
+}
+
+// matching returns the configs of the environment that pass the filters of
+// query, in its sort order.
+func (d *data) matching(environment string, query model.ConfigQuery) []*model.Config {
+	search := strings.ToLower(query.Search)
+	var configs []*model.Config
+	for _, config := range d.Configs[environment] {
+		if query.Prefix != "" && !strings.HasPrefix(config.Key, query.Prefix) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(config.Key), search) &&
+			(config.Secret || !strings.Contains(strings.ToLower(config.Value), search)) {
+			continue
+		}
+		if !query.UpdatedSince.IsZero() && config.UpdatedAt.Before(query.UpdatedSince) {
+			continue
+		}
+		if query.AllowedPrefixes != nil && !slices.ContainsFunc(query.AllowedPrefixes, func(prefix string) bool {
+			return strings.HasPrefix(config.Key, prefix)
+		}) {
+			continue
+		}
+		if query.Tree != "" && !model.InTree(config.Key, query.Tree) {
+			continue
+		}
+		configs = append(configs, config)
+	}
+
+	if query.SortOrDefault() == model.SortByUpdatedAt {
+		slices.SortFunc(configs, func(a, b *model.Config) int {
+			return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), strings.Compare(a.Key, b.Key))
+		})
+	} else {
+		slices.SortFunc(configs, func(a, b *model.Config) int { return strings.Compare(a.Key, b.Key) })
+	}
+	return configs
+}
+
+// after reports whether config comes after the cursor of query.
+func after(config *model.Config, query model.ConfigQuery) bool {
+	if query.SortOrDefault() == model.SortByUpdatedAt {
+		switch config.UpdatedAt.Compare(query.After.UpdatedAt) {
+		case -1:
+			return true
+		case 1:
+			return false
+		}
+	}
+	return config.Key > query.After.Key
+}
+
+func (d *data) appendRevision(environment, key, value string, spec model.ValueSpec, operation model.Operation, actor string, now time.Time) *model.Revision {
+	d.LastRevision++
+	revision := &model.Revision{
+		Revision:    d.LastRevision,
+		Environment: environment,
+		Key:         key,
+		Value:       value,
+		Operation:   operation,
+		Actor:       actor,
+		CreatedAt:   now,
+		ValueSpec:   spec,
+	}
+	d.Revisions = append(d.Revisions, revision)
+	return revision
+}
+
+func (d *data) putConfig(config *model.Config) {
+	if d.Configs[config.Environment] == nil {
+		d.Configs[config.Environment] = make(map[string]*model.Config)
+	}
+	d.Configs[config.Environment][config.Key] = config
+}
+
+func copyConfig(config *model.Config) *model.Config {
+	copied := *config
+	return &copied
+}
+
+func copyRevision(revision *model.Revision) *model.Revision {
+	copied := *revision
+	return &copied
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"slices"
+	"strings"
+)
+
+func (r *Repository) CreateEnvironment(ctx context.Context, environment *model.Environment) error {
+	return r.update(ctx, func(d *data) error {
+		if _, ok := d.Environments[environment.Name]; ok {
+			return repository.ErrEnvironmentAlreadyExists
+		}
+		if _, ok := d.Environments[environment.Parent]; environment.Parent != "" && !ok {
+			return repository.ErrEnvironmentNotFound
+		}
+		stored := *environment
+		stored.KeyCount = 0
+		d.Environments[environment.Name] = &stored
+		return nil
+	})
+}
+
+func (r *Repository) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	var environment *model.Environment
+	err := r.view(ctx, func(d *data) error {
+		stored, ok := d.Environments[name]
+		if !ok {
+			return repository.ErrEnvironmentNotFound
+		}
+		environment = d.copyEnvironment(stored)
+		return nil
+	})
+	return environment, err
+}
+
+func (r *Repository) GetEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	var environments []*model.Environment
+	err := r.view(ctx, func(d *data) error {
+		for _, stored := range d.Environments {
+			environments = append(environments, d.copyEnvironment(stored))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(environments, func(a, b *model.Environment) int { return strings.Compare(a.Name, b.Name) })
+	return environments, nil
+}
+
+func (r *Repository) UpdateEnvironment(ctx context.Context, environment *model.Environment) error {
+	return r.update(ctx, func(d *data) error {
+		stored, ok := d.Environments[environment.Name]
+		if !ok {
+			return repository.ErrEnvironmentNotFound
+		}
+		if _, ok := d.Environments[environment.Parent]; environment.Parent != "" && !ok {
+			return repository.ErrEnvironmentNotFound
+		}
+		updated := *stored
+		updated.EnvironmentAttributes = environment.EnvironmentAttributes
+		d.Environments[environment.Name] = &updated
+		return nil
+	})
+}
+
+func (r *Repository) DeleteEnvironment(ctx context.Context, name string) error {
+	return r.update(ctx, func(d *data) error {
+		if _, ok := d.Environments[name]; !ok {
+			return repository.ErrEnvironmentNotFound
+		}
+		if len(d.Configs[name]) > 0 {
+			return repository.ErrEnvironmentInUse
+		}
+		for _, environment := range d.Environments {
+			if environment.Parent == name {
+				return repository.ErrEnvironmentInUse
+			}
+		}
+		delete(d.Environments, name)
+		delete(d.Configs, name)
+		return nil
+	})
+}
+
+// copyEnvironment fills in KeyCount, which is not stored.
+func (d *data) copyEnvironment(environment *model.Environment) *model.Environment {
+	copied := *environment
+	copied.KeyCount = len(d.Configs[environment.Name])
+	return &copied
+}
@@ -0,0 +1,181 @@
+// Package memory keeps configs in process memory, for local development and
+// tests that run without Postgres.
+package memory
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Repository implements the config, token and secret repositories on maps
+// guarded by a mutex, with the semantics of the Postgres repository. Every
+// write is applied to a copy of the data that replaces it only on success,
+// so a failed write or transaction leaves no trace.
+//
+// With a snapshot file the data is loaded from it on start and written back
+// after every change.
+type Repository struct {
+	mu       *sync.RWMutex
+	data     *data
+	snapshot string
+	now      func() time.Time
+	// tx is set on the repository passed to WithTx, which already holds the
+	// lock and works on a copy of the data.
+	tx bool
+}
+
+// NewRepository returns an empty repository when snapshot is empty or the
+// file does not exist yet.
+func NewRepository(snapshot string) (*Repository, error) {
+	d := newData()
+	if snapshot != "" {
+		raw, err := os.ReadFile(snapshot)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, err
+		default:
+			if err := json.Unmarshal(raw, d); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Repository{mu: &sync.RWMutex{}, data: d, snapshot: snapshot, now: time.Now}, nil
+}
+
+// WithTx runs fn with the write lock held, so fn must only use the
+// repository it is given.
+func (r *Repository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	if r.tx {
+		return fn(r)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tx := &Repository{mu: r.mu, data: r.data.clone(), snapshot: r.snapshot, now: r.now, tx: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return r.commit(tx.data)
+}
+
+// view runs fn on the data under the read lock.
+func (r *Repository) view(ctx context.Context, fn func(d *data) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !r.tx {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+	}
+	return fn(r.data)
+}
+
+// update runs fn on a copy of the data and keeps the copy if fn succeeds.
+func (r *Repository) update(ctx context.Context, fn func(d *data) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.tx {
+		return fn(r.data)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.data.clone()
+	if err := fn(next); err != nil {
+		return err
+	}
+	return r.commit(next)
+}
+
+func (r *Repository) commit(next *data) error {
+	if r.snapshot != "" {
+		if err := next.save(r.snapshot); err != nil {
+			return err
+		}
+	}
+	r.data = next
+	return nil
+}
+
+// data is never changed in place once committed: writes replace configs,
+// environments and revisions instead of modifying them, which lets clone
+// share them.
+type data struct {
+	Environments map[string]*model.Environment `json:"environments"`
+	// Configs maps environments to their configs by key.
+	Configs map[string]map[string]*model.Config `json:"configs"`
+	// Revisions and Audit are ordered by number and ID.
+	Revisions    []*model.Revision   `json:"revisions"`
+	Audit        []*model.AuditEntry `json:"audit"`
+	Tokens       []*storedToken      `json:"tokens"`
+	LastRevision int64               `json:"last_revision"`
+	LastAuditID  int64               `json:"last_audit_id"`
+	LastTokenID  int64               `json:"last_token_id"`
+	// DataKeys are ordered by ID.
+	DataKeys      []*repository.DataKey `json:"data_keys"`
+	LastDataKeyID int64                 `json:"last_data_key_id"`
+}
+
+type storedToken struct {
+	model.Token
+	Hash string `json:"hash"`
+}
+
+func newData() *data {
+	return &data{
+		Environments: make(map[string]*model.Environment),
+		Configs:      make(map[string]map[string]*model.Config),
+	}
+}
+
+// clone clips the append-only slices, so that appending to the copy never
+// writes to the original.
+func (d *data) clone() *data {
+	next := *d
+	next.Environments = maps.Clone(d.Environments)
+	next.Configs = make(map[string]map[string]*model.Config, len(d.Configs))
+	for environment, configs := range d.Configs {
+		next.Configs[environment] = maps.Clone(configs)
+	}
+	next.Revisions = slices.Clip(d.Revisions)
+	next.Audit = slices.Clip(d.Audit)
+	next.Tokens = slices.Clone(d.Tokens)
+	next.DataKeys = slices.Clone(d.DataKeys)
+	return &next
+}
+
+// save replaces the snapshot file atomically.
+func (d *data) save(path string) error {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"config-service/backend/internal/repository/repositorytest"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	repo, err := NewRepository("")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	return repo
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.RunConfigRepository(t, func(t *testing.T) repository.ConfigRepository {
+		return newTestRepository(t)
+	})
+	repositorytest.RunTokenRepository(t, func(t *testing.T) repository.TokenRepository {
+		return newTestRepository(t)
+	})
+	repositorytest.RunSecretStore(t, func(t *testing.T) (repository.SecretStore, repository.ConfigRepository) {
+		repo := newTestRepository(t)
+		return repo, repo
+	})
+}
+
+func TestRepositorySnapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "configs.json")
+	repo, err := NewRepository(path)
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if err := repo.CreateEnvironment(ctx, &model.Environment{Name: "dev"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	if _, err := repo.Create(ctx, &model.Config{Environment: "dev", Key: "a", Value: "1", ValueSpec: model.ValueSpec{Secret: true}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_ = repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		if _, err := tx.Create(ctx, &model.Config{Environment: "dev", Key: "rolled.back", Value: "1"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return errors.New("rollback")
+	})
+
+	reopened, err := NewRepository(path)
+	if err != nil {
+		t.Fatalf("NewRepository() reopen error = %v", err)
+	}
+	config, err := reopened.Get(ctx, "dev", "a")
+	if err != nil || config.Value != "1" || !config.Secret || config.Version != 1 {
+		t.Fatalf("Get() after reopen = %#v, %v", config, err)
+	}
+	if exists, _ := reopened.Exists(ctx, "dev", "rolled.back"); exists {
+		t.Fatal("rolled back write reached the snapshot")
+	}
+	revision, err := reopened.Update(ctx, &model.Config{Environment: "dev", Key: "a", Value: "2", Version: 1})
+	if err != nil || revision.Revision != 2 {
+		t.Fatalf("Update() after reopen = %#v, %v, want the revision sequence to continue", revision, err)
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"config-service/backend/internal/repository"
+	"context"
+	"slices"
+	"strings"
+)
+
+func (r *Repository) CreateDataKey(ctx context.Context, key *repository.DataKey) error {
+	return r.update(ctx, func(d *data) error {
+		d.LastDataKeyID++
+		stored := *key
+		stored.ID = d.LastDataKeyID
+		d.DataKeys = append(d.DataKeys, &stored)
+		key.ID = stored.ID
+		return nil
+	})
+}
+
+func (r *Repository) GetDataKey(ctx context.Context, id int64) (*repository.DataKey, error) {
+	var key *repository.DataKey
+	err := r.view(ctx, func(d *data) error {
+		i := slices.IndexFunc(d.DataKeys, func(stored *repository.DataKey) bool { return stored.ID == id })
+		if i < 0 {
+			return repository.ErrDataKeyNotFound
+		}
+		copied := *d.DataKeys[i]
+		key = &copied
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *Repository) GetDataKeys(ctx context.Context) ([]*repository.DataKey, error) {
+	var keys []*repository.DataKey
+	err := r.view(ctx, func(d *data) error {
+		for _, stored := range d.DataKeys {
+			copied := *stored
+			keys = append(keys, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *Repository) RewrapDataKey(ctx context.Context, key *repository.DataKey) error {
+	return r.update(ctx, func(d *data) error {
+		i := slices.IndexFunc(d.DataKeys, func(stored *repository.DataKey) bool { return stored.ID == key.ID })
+		if i < 0 {
+			return repository.ErrDataKeyNotFound
+		}
+		rewrapped := *d.DataKeys[i]
+		rewrapped.MasterKey = key.MasterKey
+		rewrapped.Wrapped = key.Wrapped
+		d.DataKeys[i] = &rewrapped
+		return nil
+	})
+}
+
+func (r *Repository) GetStaleSecrets(ctx context.Context, prefix, current string, limit int) ([]string, error) {
+	var values []string
+	err := r.view(ctx, func(d *data) error {
+		seen := make(map[string]bool)
+		for _, configs := range d.Configs {
+			for _, config := range configs {
+				value := config.Value
+				if len(values) < limit && !seen[value] && strings.HasPrefix(value, prefix) && !strings.HasPrefix(value, current) {
+					seen[value] = true
+					values = append(values, value)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (r *Repository) ReplaceSecret(ctx context.Context, old, replacement string) (int64, error) {
+	var replaced int64
+	err := r.update(ctx, func(d *data) error {
+		for _, configs := range d.Configs {
+			for key, config := range configs {
+				if config.Value == old {
+					updated := *config
+					updated.Value = replacement
+					configs[key] = &updated
+					replaced++
+				}
+			}
+		}
+		return nil
+	})
+	return replaced, err
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"slices"
+	"strings"
+)
+
+func (r *Repository) CreateToken(ctx context.Context, token *model.Token, hash string) error {
+	return r.update(ctx, func(d *data) error {
+		for _, stored := range d.Tokens {
+			if stored.Name == token.Name || stored.Hash == hash {
+				return repository.ErrTokenAlreadyExists
+			}
+		}
+		d.LastTokenID++
+		stored := &storedToken{Token: *token, Hash: hash}
+		stored.ID = d.LastTokenID
+		d.Tokens = append(d.Tokens, stored)
+
+		token.ID = stored.ID
+		return nil
+	})
+}
+
+func (r *Repository) GetTokenByHash(ctx context.Context, hash string) (*model.Token, error) {
+	var token *model.Token
+	err := r.view(ctx, func(d *data) error {
+		for _, stored := range d.Tokens {
+			if stored.Hash == hash {
+				copied := stored.Token
+				token = &copied
+				return nil
+			}
+		}
+		return repository.ErrTokenNotFound
+	})
+	return token, err
+}
+
+func (r *Repository) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	var tokens []*model.Token
+	err := r.view(ctx, func(d *data) error {
+		for _, stored := range d.Tokens {
+			copied := stored.Token
+			tokens = append(tokens, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(tokens, func(a, b *model.Token) int { return strings.Compare(a.Name, b.Name) })
+	return tokens, nil
+}
+
+func (r *Repository) DeleteToken(ctx context.Context, id int64) error {
+	return r.update(ctx, func(d *data) error {
+		i := slices.IndexFunc(d.Tokens, func(stored *storedToken) bool { return stored.ID == id })
+		if i < 0 {
+			return repository.ErrTokenNotFound
+		}
+		d.Tokens = slices.Delete(d.Tokens, i, i+1)
+		return nil
+	})
+}
@@ -0,0 +1,305 @@
+package secrets
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/repository"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix starts every encrypted value. The full format is
+// enc:v1:<data key id>:<sealed value>, where the sealed value is AES-256-GCM
+// with the nonce prepended, base64 encoded. Data keys are stored separately,
+// wrapped with a master key the same way.
+const Prefix = "enc:v1:"
+
+const dataKeySize = 32
+
+var (
+	ErrUnknownKey     = errors.New("unknown master key")
+	ErrMalformedValue = errors.New("malformed encrypted value")
+
+	errNotLoaded = errors.New("keyring is not loaded")
+)
+
+// Keyring holds the master keys and the unwrapped data keys. Values are
+// always encrypted with a data key wrapped with the active master key;
+// retired master keys only unwrap.
+type Keyring struct {
+	store  repository.SecretStore
+	active string
+	keys   map[string]cipher.AEAD
+
+	mu       sync.Mutex
+	current  int64
+	dataKeys map[int64]*dataKey
+}
+
+// dataKey is a stored data key. aead is nil when its master key is unknown.
+type dataKey struct {
+	masterKey string
+	aead      cipher.AEAD
+}
+
+func NewKeyring(cfg *config.Config, store repository.SecretStore) (*Keyring, error) {
+	keyring := &Keyring{
+		store:    store,
+		keys:     make(map[string]cipher.AEAD),
+		dataKeys: make(map[int64]*dataKey),
+	}
+	for i, masterKey := range cfg.Secrets.MasterKeys {
+		aead, err := newAEAD(masterKey.Key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", masterKey.ID, err)
+		}
+		if i == 0 {
+			keyring.active = masterKey.ID
+		}
+		keyring.keys[masterKey.ID] = aead
+	}
+	return keyring, nil
+}
+
+// Load reads the stored data keys and picks the newest one wrapped with the
+// active master key for new values, creating it if there is none. It must
+// run before the first Encrypt, outside any transaction of the store.
+func (k *Keyring) Load(ctx context.Context) error {
+	stored, err := k.store.GetDataKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, key := range stored {
+		unwrapped, err := k.unwrap(key)
+		if err != nil && !errors.Is(err, ErrUnknownKey) {
+			return fmt.Errorf("data key %d: %w", key.ID, err)
+		}
+		k.dataKeys[key.ID] = unwrapped
+		if key.MasterKey == k.active {
+			k.current = key.ID
+		}
+	}
+	if k.active == "" || k.current != 0 {
+		return nil
+	}
+
+	key, aead, err := k.newDataKey()
+	if err != nil {
+		return err
+	}
+	if err := k.store.CreateDataKey(ctx, key); err != nil {
+		return err
+	}
+	k.dataKeys[key.ID] = &dataKey{masterKey: key.MasterKey, aead: aead}
+	k.current = key.ID
+	return nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Active returns the ID of the active master key, or an empty string when no
+// master key is configured.
+func (k *Keyring) Active() string {
+	return k.active
+}
+
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k.active == "" {
+		return "", repository.ErrSecretsUnavailable
+	}
+	k.mu.Lock()
+	id := k.current
+	key := k.dataKeys[id]
+	k.mu.Unlock()
+	if key == nil {
+		return "", errNotLoaded
+	}
+
+	sealed, err := seal(key.aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return format(id, sealed), nil
+}
+
+// Decrypt looks up data keys created by other replicas since Load in the
+// store.
+func (k *Keyring) Decrypt(ctx context.Context, value string) (string, error) {
+	id, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	aead, err := k.dataKey(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// reencrypt returns value encrypted again with the current data key.
+func (k *Keyring) reencrypt(ctx context.Context, value string) (string, error) {
+	plaintext, err := k.Decrypt(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return k.Encrypt(plaintext)
+}
+
+// currentPrefix returns the start of values encrypted with the current data
+// key, or an empty string before Load.
+func (k *Keyring) currentPrefix() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.current == 0 {
+		return ""
+	}
+	return dataKeyPrefix(k.current)
+}
+
+// Rewrap returns a copy of key wrapped with the active master key.
+func (k *Keyring) Rewrap(key *repository.DataKey) (*repository.DataKey, error) {
+	if k.active == "" {
+		return nil, repository.ErrSecretsUnavailable
+	}
+	aead, ok := k.keys[key.MasterKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, key.MasterKey)
+	}
+	plain, err := open(aead, key.Wrapped)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(k.keys[k.active], plain)
+	if err != nil {
+		return nil, err
+	}
+	rewrapped := *key
+	rewrapped.MasterKey = k.active
+	rewrapped.Wrapped = wrapped
+	return &rewrapped, nil
+}
+
+func (k *Keyring) dataKey(ctx context.Context, id int64) (cipher.AEAD, error) {
+	k.mu.Lock()
+	key, ok := k.dataKeys[id]
+	k.mu.Unlock()
+	if !ok {
+		stored, err := k.store.GetDataKey(ctx, id)
+		if errors.Is(err, repository.ErrDataKeyNotFound) {
+			return nil, fmt.Errorf("%w: unknown data key %d", ErrMalformedValue, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if key, err = k.unwrap(stored); err != nil && !errors.Is(err, ErrUnknownKey) {
+			return nil, err
+		}
+		k.mu.Lock()
+		k.dataKeys[id] = key
+		k.mu.Unlock()
+	}
+	if key.aead == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, key.masterKey)
+	}
+	return key.aead, nil
+}
+
+// unwrap returns the data key with a nil aead and ErrUnknownKey when its
+// master key is not configured.
+func (k *Keyring) unwrap(key *repository.DataKey) (*dataKey, error) {
+	unwrapped := &dataKey{masterKey: key.MasterKey}
+	masterKey, ok := k.keys[key.MasterKey]
+	if !ok {
+		return unwrapped, fmt.Errorf("%w: %q", ErrUnknownKey, key.MasterKey)
+	}
+	plain, err := open(masterKey, key.Wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if unwrapped.aead, err = newAEAD(plain); err != nil {
+		return nil, err
+	}
+	return unwrapped, nil
+}
+
+func (k *Keyring) newDataKey() (*repository.DataKey, cipher.AEAD, error) {
+	plain := make([]byte, dataKeySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newAEAD(plain)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := seal(k.keys[k.active], plain)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &repository.DataKey{MasterKey: k.active, Wrapped: wrapped, CreatedAt: time.Now().UTC()}, aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformedValue
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedValue, err)
+	}
+	return plaintext, nil
+}
+
+func format(id int64, sealed []byte) string {
+	return dataKeyPrefix(id) + base64.RawStdEncoding.EncodeToString(sealed)
+}
+
+func dataKeyPrefix(id int64) string {
+	return Prefix + strconv.FormatInt(id, 10) + ":"
+}
+
+func parse(value string) (id int64, sealed []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if !IsEncrypted(value) || len(parts) != 2 {
+		return 0, nil, ErrMalformedValue
+	}
+	if id, err = strconv.ParseInt(parts[0], 10, 64); err != nil || id <= 0 {
+		return 0, nil, ErrMalformedValue
+	}
+	if sealed, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return 0, nil, ErrMalformedValue
+	}
+	return id, sealed, nil
+}
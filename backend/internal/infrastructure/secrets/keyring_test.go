@@ -0,0 +1,171 @@
+package secrets
+
+import (
+	"bytes"
+	"config-service/backend/config"
+	"config-service/backend/internal/infrastructure/memory"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) repository.SecretStore {
+	t.Helper()
+	store, err := memory.NewRepository("")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	return store
+}
+
+// newTestKeyring loads a keyring with the master keys ids from store, the
+// first one active.
+func newTestKeyring(t *testing.T, store repository.SecretStore, ids ...string) *Keyring {
+	t.Helper()
+	cfg := &config.Config{}
+	for _, id := range ids {
+		cfg.Secrets.MasterKeys = append(cfg.Secrets.MasterKeys, config.MasterKey{
+			ID:  id,
+			Key: bytes.Repeat([]byte{id[len(id)-1]}, 32),
+		})
+	}
+	keyring, err := NewKeyring(cfg, store)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	if err := keyring.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return keyring
+}
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	keyring := newTestKeyring(t, newTestStore(t), "k1")
+
+	first, err := keyring.Encrypt("hunter2")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	second, _ := keyring.Encrypt("hunter2")
+	if !strings.HasPrefix(first, "enc:v1:1:") || strings.Contains(first, "hunter2") {
+		t.Fatalf("Encrypt() = %q", first)
+	}
+	if first == second {
+		t.Fatal("Encrypt() must use a fresh nonce for every value")
+	}
+
+	plaintext, err := keyring.Decrypt(ctx, first)
+	if err != nil || plaintext != "hunter2" {
+		t.Fatalf("Decrypt() = %q, %v", plaintext, err)
+	}
+}
+
+func TestKeyringLoad(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	value, _ := newTestKeyring(t, store, "k1").Encrypt("hunter2")
+
+	// A restart with the same master key reuses its data key.
+	restarted := newTestKeyring(t, store, "k1")
+	if keys, _ := store.GetDataKeys(ctx); len(keys) != 1 {
+		t.Fatalf("data keys after restart = %v, want one", keys)
+	}
+	if reused, _ := restarted.Encrypt("hunter2"); !strings.HasPrefix(reused, "enc:v1:1:") {
+		t.Fatalf("Encrypt() after restart = %q, want data key 1", reused)
+	}
+
+	// A new master key gets a data key of its own, and the old one still
+	// decrypts while it is configured.
+	rotated := newTestKeyring(t, store, "k2", "k1")
+	if fresh, _ := rotated.Encrypt("hunter2"); !strings.HasPrefix(fresh, "enc:v1:2:") {
+		t.Fatalf("Encrypt() with a new master key = %q, want data key 2", fresh)
+	}
+	if plaintext, err := rotated.Decrypt(ctx, value); err != nil || plaintext != "hunter2" {
+		t.Fatalf("Decrypt() with a retired master key = %q, %v", plaintext, err)
+	}
+}
+
+func TestKeyringDecryptsDataKeysCreatedLater(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	// Both replicas load before the data key of k2 exists.
+	reader := newTestKeyring(t, store, "k1", "k2")
+	outdated := newTestKeyring(t, store, "k1")
+	value, _ := newTestKeyring(t, store, "k2", "k1").Encrypt("hunter2")
+
+	if plaintext, err := reader.Decrypt(ctx, value); err != nil || plaintext != "hunter2" {
+		t.Fatalf("Decrypt() = %q, %v", plaintext, err)
+	}
+	if _, err := outdated.Decrypt(ctx, value); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Decrypt() without the master key error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestKeyringDecryptErrors(t *testing.T) {
+	keyring := newTestKeyring(t, newTestStore(t), "k1")
+	valid, _ := keyring.Encrypt("hunter2")
+	parts := strings.Split(valid, ":")
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "not encrypted", value: "hunter2", wantErr: ErrMalformedValue},
+		{name: "missing part", value: "enc:v1:abc", wantErr: ErrMalformedValue},
+		{name: "bad data key id", value: "enc:v1:k1:abc", wantErr: ErrMalformedValue},
+		{name: "bad base64", value: "enc:v1:1:!!", wantErr: ErrMalformedValue},
+		{name: "unknown data key", value: strings.Replace(valid, ":1:", ":9:", 1), wantErr: ErrMalformedValue},
+		{name: "tampered value", value: strings.Join(append(parts[:3], parts[3][:len(parts[3])-4]), ":"), wantErr: ErrMalformedValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.Decrypt(context.Background(), tt.value); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyringRewrap(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	value, _ := newTestKeyring(t, store, "k1").Encrypt("hunter2")
+	keys, _ := store.GetDataKeys(ctx)
+
+	rewrapped, err := newTestKeyring(t, store, "k2", "k1").Rewrap(keys[0])
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+	if rewrapped.ID != keys[0].ID || rewrapped.MasterKey != "k2" || bytes.Equal(rewrapped.Wrapped, keys[0].Wrapped) {
+		t.Fatalf("Rewrap() = %#v", rewrapped)
+	}
+	if err := store.RewrapDataKey(ctx, rewrapped); err != nil {
+		t.Fatalf("RewrapDataKey() error = %v", err)
+	}
+	if plaintext, err := newTestKeyring(t, store, "k2").Decrypt(ctx, value); err != nil || plaintext != "hunter2" {
+		t.Fatalf("Decrypt() after rewrap = %q, %v", plaintext, err)
+	}
+
+	if _, err := newTestKeyring(t, newTestStore(t), "k3").Rewrap(keys[0]); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Rewrap() with an unknown master key error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestKeyringWithoutMasterKey(t *testing.T) {
+	store := newTestStore(t)
+	keyring := newTestKeyring(t, store)
+
+	if keyring.Active() != "" {
+		t.Fatalf("Active() = %q, want empty", keyring.Active())
+	}
+	if keys, _ := store.GetDataKeys(context.Background()); len(keys) != 0 {
+		t.Fatalf("Load() created data keys %v without a master key", keys)
+	}
+	if _, err := keyring.Encrypt("hunter2"); !errors.Is(err, repository.ErrSecretsUnavailable) {
+		t.Fatalf("Encrypt() error = %v, want %v", err, repository.ErrSecretsUnavailable)
+	}
+}
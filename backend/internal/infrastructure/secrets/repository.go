@@ -0,0 +1,185 @@
+package secrets
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"fmt"
+	"time"
+)
+
+// secretRepository encrypts the values of secret keys before they reach the
+// wrapped repository and decrypts them on the way back. A config or revision
+// is marked secret when its stored value is encrypted.
+type secretRepository struct {
+	repository.ConfigRepository
+	keyring *Keyring
+}
+
+func NewRepository(repo repository.ConfigRepository, keyring *Keyring) repository.ConfigRepository {
+	return &secretRepository{ConfigRepository: repo, keyring: keyring}
+}
+
+func (r *secretRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	stored, err := r.seal(config)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := r.ConfigRepository.Create(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	config.Revision, config.Version = stored.Revision, stored.Version
+	return r.openRevision(ctx, revision)
+}
+
+func (r *secretRepository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
+	config, err := r.ConfigRepository.Get(ctx, environment, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.openConfig(ctx, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (r *secretRepository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
+	configs, err := r.ConfigRepository.GetAll(ctx, environment)
+	return r.openConfigs(ctx, configs, err)
+}
+
+func (r *secretRepository) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) ([]*model.Config, error) {
+	configs, err := r.ConfigRepository.ListConfigs(ctx, environment, query)
+	return r.openConfigs(ctx, configs, err)
+}
+
+func (r *secretRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	stored, err := r.seal(config)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := r.ConfigRepository.Update(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	config.Revision, config.Version = stored.Revision, stored.Version
+	return r.openRevision(ctx, revision)
+}
+
+func (r *secretRepository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
+	revision, err := r.ConfigRepository.Delete(ctx, environment, key, actor, version)
+	if err != nil {
+		return nil, err
+	}
+	return r.openRevision(ctx, revision)
+}
+
+func (r *secretRepository) GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	config, err := r.ConfigRepository.GetAt(ctx, environment, key, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.openConfig(ctx, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (r *secretRepository) GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	configs, err := r.ConfigRepository.GetAllAt(ctx, environment, asOf)
+	return r.openConfigs(ctx, configs, err)
+}
+
+func (r *secretRepository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	revisions, err := r.ConfigRepository.GetHistory(ctx, environment, key)
+	return r.openRevisions(ctx, revisions, err)
+}
+
+func (r *secretRepository) GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error) {
+	revisions, err := r.ConfigRepository.GetRevisionsSince(ctx, environment, since, limit)
+	return r.openRevisions(ctx, revisions, err)
+}
+
+func (r *secretRepository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	result, err := r.ConfigRepository.GetRevision(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+	return r.openRevision(ctx, result)
+}
+
+func (r *secretRepository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	return r.ConfigRepository.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		return fn(&secretRepository{ConfigRepository: repo, keyring: r.keyring})
+	})
+}
+
+// seal returns a copy of a secret config with its value encrypted. Plain
+// values that look encrypted are rejected, since they could not be told
+// apart from ciphertext on the way back.
+func (r *secretRepository) seal(config *model.Config) (*model.Config, error) {
+	if !config.Secret {
+		if IsEncrypted(config.Value) {
+			return nil, fmt.Errorf("%w: values starting with %q are reserved for secrets", model.ErrInvalidValue, Prefix)
+		}
+		return config, nil
+	}
+	value, err := r.keyring.Encrypt(config.Value)
+	if err != nil {
+		return nil, err
+	}
+	stored := *config
+	stored.Value = value
+	return &stored, nil
+}
+
+func (r *secretRepository) openConfig(ctx context.Context, config *model.Config) error {
+	if !IsEncrypted(config.Value) {
+		return nil
+	}
+	value, err := r.keyring.Decrypt(ctx, config.Value)
+	if err != nil {
+		return fmt.Errorf("decrypt %s/%s: %w", config.Environment, config.Key, err)
+	}
+	config.Value = value
+	config.Secret = true
+	return nil
+}
+
+func (r *secretRepository) openConfigs(ctx context.Context, configs []*model.Config, err error) ([]*model.Config, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range configs {
+		if err := r.openConfig(ctx, config); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
+
+func (r *secretRepository) openRevision(ctx context.Context, revision *model.Revision) (*model.Revision, error) {
+	if revision == nil || !IsEncrypted(revision.Value) {
+		return revision, nil
+	}
+	value, err := r.keyring.Decrypt(ctx, revision.Value)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt revision %d: %w", revision.Revision, err)
+	}
+	revision.Value = value
+	revision.Secret = true
+	return revision, nil
+}
+
+func (r *secretRepository) openRevisions(ctx context.Context, revisions []*model.Revision, err error) ([]*model.Revision, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, revision := range revisions {
+		if _, err := r.openRevision(ctx, revision); err != nil {
+			return nil, err
+		}
+	}
+	return revisions, nil
+}
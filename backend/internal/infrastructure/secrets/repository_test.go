@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// storingRepository keeps the last written config as the wrapped repository
+// would store it.
+type storingRepository struct {
+	repository.ConfigRepository
+	stored *model.Config
+}
+
+func (r *storingRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	stored := *config
+	stored.Version = 1
+	config.Version = 1
+	r.stored = &stored
+	return &model.Revision{Revision: 1, Environment: config.Environment, Key: config.Key, Value: config.Value}, nil
+}
+
+func (r *storingRepository) Get(context.Context, string, string) (*model.Config, error) {
+	stored := *r.stored
+	stored.ValueSpec = model.ValueSpec{}
+	return &stored, nil
+}
+
+func (r *storingRepository) ListConfigs(context.Context, string, model.ConfigQuery) ([]*model.Config, error) {
+	stored := *r.stored
+	return []*model.Config{&stored}, nil
+}
+
+func (r *storingRepository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	return []*model.Revision{
+		{Revision: 2, Environment: environment, Key: key, Value: r.stored.Value},
+		{Revision: 1, Environment: environment, Key: key, Value: "plain"},
+	}, nil
+}
+
+func (r *storingRepository) WithTx(ctx context.Context, fn func(repository.ConfigRepository) error) error {
+	return fn(r)
+}
+
+func TestRepositoryEncryptsSecretValues(t *testing.T) {
+	ctx := context.Background()
+	inner := &storingRepository{}
+	repo := NewRepository(inner, newTestKeyring(t, newTestStore(t), "k1"))
+
+	config := &model.Config{Environment: "prod", Key: "token", Value: "t-1", ValueSpec: model.ValueSpec{Secret: true}}
+	revision, err := repo.Create(ctx, config)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !IsEncrypted(inner.stored.Value) {
+		t.Fatalf("stored value = %q, want ciphertext", inner.stored.Value)
+	}
+	if config.Value != "t-1" || config.Version != 1 {
+		t.Fatalf("Create() changed the caller's config to %#v", config)
+	}
+	if revision.Value != "t-1" || !revision.Secret {
+		t.Fatalf("Create() revision = %#v", revision)
+	}
+
+	var got *model.Config
+	err = repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		got, err = tx.Get(ctx, "prod", "token")
+		return err
+	})
+	if err != nil || got.Value != "t-1" || !got.Secret {
+		t.Fatalf("Get() in transaction = %#v, %v", got, err)
+	}
+
+	listed, err := repo.ListConfigs(ctx, "prod", model.ConfigQuery{Limit: 1})
+	if err != nil || listed[0].Value != "t-1" {
+		t.Fatalf("ListConfigs() = %#v, %v", listed, err)
+	}
+
+	history, err := repo.GetHistory(ctx, "prod", "token")
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if history[0].Value != "t-1" || !history[0].Secret || history[1].Value != "plain" || history[1].Secret {
+		t.Fatalf("GetHistory() = %#v, %#v", history[0], history[1])
+	}
+}
+
+func TestRepositoryRejectsPlainValuesWithPrefix(t *testing.T) {
+	repo := NewRepository(&storingRepository{}, newTestKeyring(t, newTestStore(t), "k1"))
+
+	_, err := repo.Create(context.Background(), &model.Config{Environment: "prod", Key: "token", Value: Prefix + "1:abc"})
+	if !errors.Is(err, model.ErrInvalidValue) {
+		t.Fatalf("Create() error = %v, want %v", err, model.ErrInvalidValue)
+	}
+}
+
+func TestRepositoryDecryptFailure(t *testing.T) {
+	ctx := context.Background()
+	inner := &storingRepository{}
+	store := newTestStore(t)
+	if _, err := NewRepository(inner, newTestKeyring(t, store, "k1")).Create(ctx, &model.Config{
+		Environment: "prod", Key: "token", Value: "t-1", ValueSpec: model.ValueSpec{Secret: true},
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	_, err := NewRepository(inner, newTestKeyring(t, store, "k2")).Get(ctx, "prod", "token")
+	if !errors.Is(err, ErrUnknownKey) || !strings.Contains(err.Error(), "prod/token") {
+		t.Fatalf("Get() error = %v, want %v", err, ErrUnknownKey)
+	}
+}
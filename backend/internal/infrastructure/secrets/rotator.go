@@ -0,0 +1,171 @@
+package secrets
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const reencryptBatchSize = 100
+
+// Invalidator drops cached configs, whose ciphertext re-encryption changes.
+type Invalidator interface {
+	InvalidateAll()
+}
+
+// Rotation counts what a run of the job changed.
+type Rotation struct {
+	Rewrapped   int
+	Reencrypted int
+}
+
+// Rotator re-wraps data keys still wrapped with a retired master key, so the
+// retired key can be removed once a run reports nothing left to do, and then
+// re-encrypts current values with the current data key, which Load creates
+// for every new master key. Revisions keep the values they were written
+// with; their data keys are never deleted.
+type Rotator struct {
+	store    repository.SecretStore
+	keyring  *Keyring
+	cache    Invalidator
+	interval time.Duration
+	logger   *zap.Logger
+	stop     context.CancelFunc
+	stopped  chan struct{}
+}
+
+func NewRotator(store repository.SecretStore, keyring *Keyring, cache Invalidator, cfg *config.Config, logger *zap.Logger) *Rotator {
+	return &Rotator{
+		store:    store,
+		keyring:  keyring,
+		cache:    cache,
+		interval: cfg.Secrets.ReencryptInterval,
+		logger:   logger,
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start runs the job right away and then every interval until Stop, which
+// also cancels a run in progress.
+func (r *Rotator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stop = cancel
+	go func() {
+		defer close(r.stopped)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			r.run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *Rotator) Stop() {
+	r.stop()
+	<-r.stopped
+}
+
+func (r *Rotator) run(ctx context.Context) {
+	rotation, err := r.RunOnce(ctx)
+	if err != nil {
+		r.logger.Error("secret rotation failed",
+			zap.Int("rewrapped", rotation.Rewrapped), zap.Int("reencrypted", rotation.Reencrypted), zap.Error(err))
+		return
+	}
+	if rotation.Rewrapped > 0 || rotation.Reencrypted > 0 {
+		r.logger.Info("rotated secrets", zap.Int("rewrapped", rotation.Rewrapped), zap.Int("reencrypted", rotation.Reencrypted))
+	}
+}
+
+// RunOnce re-wraps every stale data key and re-encrypts every current value
+// that uses an older data key. It stops at the first key or value it cannot
+// handle, which usually means a master key is missing from the
+// configuration.
+func (r *Rotator) RunOnce(ctx context.Context) (Rotation, error) {
+	var rotation Rotation
+	active := r.keyring.Active()
+	if active == "" {
+		return rotation, nil
+	}
+
+	keys, err := r.store.GetDataKeys(ctx)
+	if ctx.Err() != nil {
+		return rotation, nil
+	}
+	if err != nil {
+		return rotation, err
+	}
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return rotation, nil
+		}
+		if key.MasterKey == active {
+			continue
+		}
+		replacement, err := r.keyring.Rewrap(key)
+		if err != nil {
+			return rotation, fmt.Errorf("data key %d: %w", key.ID, err)
+		}
+		if err := r.store.RewrapDataKey(ctx, replacement); err != nil {
+			return rotation, err
+		}
+		rotation.Rewrapped++
+	}
+
+	err = r.reencrypt(ctx, &rotation)
+	return rotation, err
+}
+
+// reencrypt works in batches and drops the cache after each one. A value
+// written concurrently already uses the current data key and is left alone.
+func (r *Rotator) reencrypt(ctx context.Context, rotation *Rotation) error {
+	current := r.keyring.currentPrefix()
+	if current == "" {
+		return errNotLoaded
+	}
+	for {
+		values, err := r.store.GetStaleSecrets(ctx, Prefix, current, reencryptBatchSize)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			return nil
+		}
+
+		err = r.reencryptBatch(ctx, values, rotation)
+		r.cache.InvalidateAll()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Rotator) reencryptBatch(ctx context.Context, values []string, rotation *Rotation) error {
+	for _, value := range values {
+		replacement, err := r.keyring.reencrypt(ctx, value)
+		if err != nil {
+			return fmt.Errorf("re-encrypt value: %w", err)
+		}
+		replaced, err := r.store.ReplaceSecret(ctx, value, replacement)
+		if err != nil {
+			return err
+		}
+		rotation.Reencrypted += int(replaced)
+	}
+	return nil
+}
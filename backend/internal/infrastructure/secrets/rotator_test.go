@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/infrastructure/memory"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type countingInvalidator struct {
+	calls int
+}
+
+func (c *countingInvalidator) InvalidateAll() {
+	c.calls++
+}
+
+func newTestRotator(store repository.SecretStore, keyring *Keyring) *Rotator {
+	cfg := &config.Config{Secrets: config.SecretsConfig{ReencryptInterval: time.Hour}}
+	return NewRotator(store, keyring, &countingInvalidator{}, cfg, zap.NewNop())
+}
+
+func TestRotatorRunOnce(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	old := newTestKeyring(t, store, "k1")
+	value, _ := old.Encrypt("secret")
+
+	rotated := newTestKeyring(t, store, "k2", "k1")
+	rotation, err := newTestRotator(store, rotated).RunOnce(ctx)
+	if err != nil || rotation != (Rotation{Rewrapped: 1}) {
+		t.Fatalf("RunOnce() = %+v, %v", rotation, err)
+	}
+	keys, _ := store.GetDataKeys(ctx)
+	for _, key := range keys {
+		if key.MasterKey != "k2" {
+			t.Fatalf("data key %d is still wrapped with %q", key.ID, key.MasterKey)
+		}
+	}
+	// The value itself is unchanged and no longer needs the retired key.
+	if plaintext, err := newTestKeyring(t, store, "k2").Decrypt(ctx, value); err != nil || plaintext != "secret" {
+		t.Fatalf("Decrypt() after RunOnce() = %q, %v", plaintext, err)
+	}
+
+	if rotation, err := newTestRotator(store, rotated).RunOnce(ctx); err != nil || rotation != (Rotation{}) {
+		t.Fatalf("second RunOnce() = %+v, %v", rotation, err)
+	}
+}
+
+func TestRotatorReencryptsCurrentValues(t *testing.T) {
+	ctx := context.Background()
+	store, err := memory.NewRepository("")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if err := store.CreateEnvironment(ctx, &model.Environment{Name: "prod"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	repo := NewRepository(store, newTestKeyring(t, store, "k1"))
+	for _, key := range []string{"token", "password"} {
+		config := &model.Config{Environment: "prod", Key: key, Value: key + "-1", ValueSpec: model.ValueSpec{Secret: true}}
+		if _, err := repo.Create(ctx, config); err != nil {
+			t.Fatalf("Create(%s) error = %v", key, err)
+		}
+	}
+	if _, err := repo.Create(ctx, &model.Config{Environment: "prod", Key: "plain", Value: "p"}); err != nil {
+		t.Fatalf("Create(plain) error = %v", err)
+	}
+	before, _ := store.GetHistory(ctx, "prod", "token")
+
+	rotated := newTestKeyring(t, store, "k2", "k1")
+	cache := &countingInvalidator{}
+	cfg := &config.Config{Secrets: config.SecretsConfig{ReencryptInterval: time.Hour}}
+	rotation, err := NewRotator(store, rotated, cache, cfg, zap.NewNop()).RunOnce(ctx)
+	if err != nil || rotation != (Rotation{Rewrapped: 1, Reencrypted: 2}) {
+		t.Fatalf("RunOnce() = %+v, %v", rotation, err)
+	}
+	if cache.calls == 0 {
+		t.Fatal("RunOnce() did not invalidate the cache")
+	}
+
+	current := rotated.currentPrefix()
+	for _, key := range []string{"token", "password"} {
+		stored, _ := store.Get(ctx, "prod", key)
+		if !strings.HasPrefix(stored.Value, current) {
+			t.Fatalf("stored %s = %q, want prefix %q", key, stored.Value, current)
+		}
+		if plaintext, err := rotated.Decrypt(ctx, stored.Value); err != nil || plaintext != key+"-1" {
+			t.Fatalf("Decrypt(%s) = %q, %v", key, plaintext, err)
+		}
+	}
+	if stored, _ := store.Get(ctx, "prod", "plain"); stored.Value != "p" {
+		t.Fatalf("plain value = %q, want it untouched", stored.Value)
+	}
+	// History keeps the ciphertext it was written with.
+	after, _ := store.GetHistory(ctx, "prod", "token")
+	if len(after) != len(before) || after[0].Value != before[0].Value {
+		t.Fatalf("history changed: %v -> %v", before, after)
+	}
+
+	if rotation, err := NewRotator(store, rotated, cache, cfg, zap.NewNop()).RunOnce(ctx); err != nil || rotation != (Rotation{}) {
+		t.Fatalf("second RunOnce() = %+v, %v", rotation, err)
+	}
+}
+
+func TestRotatorStopsOnUnknownKey(t *testing.T) {
+	store := newTestStore(t)
+	newTestKeyring(t, store, "k1")
+
+	_, err := newTestRotator(store, newTestKeyring(t, store, "k2")).RunOnce(context.Background())
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("RunOnce() error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestRotatorWithoutMasterKey(t *testing.T) {
+	store := newTestStore(t)
+	newTestKeyring(t, store, "k1")
+
+	if rotation, err := newTestRotator(store, newTestKeyring(t, store)).RunOnce(context.Background()); err != nil || rotation != (Rotation{}) {
+		t.Fatalf("RunOnce() = %+v, %v", rotation, err)
+	}
+}
+
+func TestRotatorCancelInterruptsRun(t *testing.T) {
+	store := newTestStore(t)
+	newTestKeyring(t, store, "k1")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rotator := newTestRotator(store, newTestKeyring(t, store, "k2", "k1"))
+	if rotation, err := rotator.RunOnce(ctx); err != nil || rotation != (Rotation{}) {
+		t.Fatalf("RunOnce() after cancel = %+v, %v", rotation, err)
+	}
+
+	rotator.Start()
+	rotator.Stop()
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (r *Repository) AppendAudit(ctx context.Context, entry *model.AuditEntry) error {
+	start := time.Now()
+	query, err := r.query("append_audit")
+	if err != nil {
+		return err
+	}
+	var revision sql.NullInt64
+	if entry.Revision != 0 {
+		revision = sql.NullInt64{Int64: entry.Revision, Valid: true}
+	}
+	var createdAt timestamp
+	err = r.db.QueryRowContext(ctx,
+		query,
+		entry.Actor,
+		entry.SourceIP,
+		entry.RequestID,
+		string(entry.Operation),
+		entry.Environment,
+		entry.Key,
+		revision,
+		nullString(entry.OldValue),
+		nullString(entry.NewValue),
+		entry.Secret,
+		formatTime(r.now()),
+	).Scan(&entry.ID, &createdAt)
+	r.observe("append_audit", start)
+	entry.CreatedAt = createdAt.Time
+	return err
+}
+
+func (r *Repository) GetAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	start := time.Now()
+	query, err := r.query("get_audit_entries")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx,
+		query,
+		filter.Environment,
+		filter.Key,
+		filter.Actor,
+		nullTime(filter.From),
+		nullTime(filter.To),
+		filter.Cursor,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	r.observe("get_audit_entries", start)
+	return entries, nil
+}
+
+func scanAuditEntry(row rowScanner) (*model.AuditEntry, error) {
+	var entry model.AuditEntry
+	var operation string
+	var revision sql.NullInt64
+	var oldValue, newValue sql.NullString
+	var createdAt timestamp
+	if err := row.Scan(
+		&entry.ID,
+		&entry.Actor,
+		&entry.SourceIP,
+		&entry.RequestID,
+		&operation,
+		&entry.Environment,
+		&entry.Key,
+		&revision,
+		&oldValue,
+		&newValue,
+		&entry.Secret,
+		&createdAt,
+	); err != nil {
+		return nil, err
+	}
+	entry.Operation = model.AuditOperation(operation)
+	entry.Revision = revision.Int64
+	if oldValue.Valid {
+		entry.OldValue = &oldValue.String
+	}
+	if newValue.Valid {
+		entry.NewValue = &newValue.String
+	}
+	entry.CreatedAt = createdAt.Time
+	return &entry, nil
+}
@@ -0,0 +1,446 @@
+package sqlite
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+func (r *Repository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	start := time.Now()
+	query, err := r.query("create_config")
+	if err != nil {
+		return nil, err
+	}
+	spec, err := encodeSpec(config.ValueSpec)
+	if err != nil {
+		return nil, err
+	}
+	var revision *model.Revision
+	err = r.write(ctx, func(db querier) error {
+		var err error
+		revision, err = r.recordRevision(ctx, db, config.Environment, config.Key, config.Value, spec, model.OperationCreate, config.UpdatedBy)
+		if err != nil {
+			return err
+		}
+		_, err = db.ExecContext(ctx,
+			query,
+			config.Environment,
+			config.Key,
+			config.Value,
+			formatTime(config.UpdatedAt),
+			config.UpdatedBy,
+			spec,
+			revision.Revision,
+		)
+		return err
+	})
+	r.observe("create", start)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, repository.ErrConfigAlreadyExists
+		}
+		if isForeignKeyViolation(err) {
+			return nil, repository.ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+
+	config.Revision = revision.Revision
+	config.Version = 1
+	return revision, nil
+}
+
+func (r *Repository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
+	start := time.Now()
+	query, err := r.query("get_config")
+	if err != nil {
+		return nil, err
+	}
+	config, err := scanConfig(r.db.QueryRowContext(ctx, query, environment, key))
+	r.observe("get", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrConfigNotFound
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+func (r *Repository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
+	start := time.Now()
+	query, err := r.query("get_all_configs")
+	if err != nil {
+		return nil, err
+	}
+	configs, err := r.queryConfigs(ctx, query, environment)
+	if err != nil {
+		return nil, err
+	}
+	r.observe("get_all", start)
+	return configs, nil
+}
+
+func (r *Repository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	start := time.Now()
+	query, err := r.query("update_config")
+	if err != nil {
+		return nil, err
+	}
+	spec, err := encodeSpec(config.ValueSpec)
+	if err != nil {
+		return nil, err
+	}
+	var revision *model.Revision
+	err = r.write(ctx, func(db querier) error {
+		var err error
+		revision, err = r.recordRevision(ctx, db, config.Environment, config.Key, config.Value, spec, model.OperationUpdate, config.UpdatedBy)
+		if err != nil {
+			return err
+		}
+		result, err := db.ExecContext(ctx,
+			query,
+			config.Environment,
+			config.Key,
+			config.Value,
+			formatTime(config.UpdatedAt),
+			config.UpdatedBy,
+			config.Version,
+			spec,
+			revision.Revision,
+		)
+		if err != nil {
+			return err
+		}
+		return requireAffected(result, sql.ErrNoRows)
+	})
+	r.observe("update", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.missingOrConflict(ctx, config.Environment, config.Key)
+		}
+		return nil, err
+	}
+
+	config.Revision = revision.Revision
+	config.Version++
+	return revision, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
+	start := time.Now()
+	query, err := r.query("delete_config")
+	if err != nil {
+		return nil, err
+	}
+	var revision *model.Revision
+	err = r.write(ctx, func(db querier) error {
+		var value string
+		var spec sql.NullString
+		if err := db.QueryRowContext(ctx, query, environment, key, version).Scan(&value, &spec); err != nil {
+			return err
+		}
+		var err error
+		revision, err = r.recordRevision(ctx, db, environment, key, value, spec, model.OperationDelete, actor)
+		return err
+	})
+	r.observe("delete", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if version == 0 {
+				return nil, repository.ErrConfigNotFound
+			}
+			return nil, r.missingOrConflict(ctx, environment, key)
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+// recordRevision appends a revision, which the caller undoes together with
+// the write it records if that write fails. spec is the encoded value spec
+// of the key after the write.
+func (r *Repository) recordRevision(ctx context.Context, db querier, environment, key, value string, spec any, operation model.Operation, actor string) (*model.Revision, error) {
+	query, err := r.query("record_revision")
+	if err != nil {
+		return nil, err
+	}
+	return scanRevision(db.QueryRowContext(ctx, query, environment, key, value, string(operation), actor, formatTime(r.now()), spec))
+}
+
+// missingOrConflict explains why a versioned write matched no rows.
+func (r *Repository) missingOrConflict(ctx context.Context, environment, key string) error {
+	exists, err := r.Exists(ctx, environment, key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return repository.ErrVersionConflict
+	}
+	return repository.ErrConfigNotFound
+}
+
+func (r *Repository) Exists(ctx context.Context, environment, key string) (bool, error) {
+	start := time.Now()
+	query, err := r.query("exists_config")
+	if err != nil {
+		return false, err
+	}
+	var exists bool
+	err = r.db.QueryRowContext(ctx, query, environment, key).Scan(&exists)
+	r.observe("exists", start)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *Repository) GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	start := time.Now()
+	query, err := r.query("get_config_at")
+	if err != nil {
+		return nil, err
+	}
+	revision, err := scanRevision(r.db.QueryRowContext(ctx, query, environment, key, formatTime(asOf)))
+	r.observe("get_at", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrConfigNotFound
+		}
+		return nil, err
+	}
+	return revision.Config(), nil
+}
+
+func (r *Repository) GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	start := time.Now()
+	query, err := r.query("get_all_configs_at")
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := r.queryRevisions(ctx, query, environment, formatTime(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]*model.Config, 0, len(revisions))
+	for _, revision := range revisions {
+		configs = append(configs, revision.Config())
+	}
+	r.observe("get_all_at", start)
+	return configs, nil
+}
+
+func (r *Repository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	start := time.Now()
+	query, err := r.query("get_config_history")
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := r.queryRevisions(ctx, query, environment, key)
+	if err != nil {
+		return nil, err
+	}
+	r.observe("get_history", start)
+	return revisions, nil
+}
+
+func (r *Repository) GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error) {
+	start := time.Now()
+	query, err := r.query("get_revisions_since")
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := r.queryRevisions(ctx, query, environment, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	r.observe("get_revisions_since", start)
+	return revisions, nil
+}
+
+func (r *Repository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	start := time.Now()
+	query, err := r.query("get_revision")
+	if err != nil {
+		return nil, err
+	}
+	result, err := scanRevision(r.db.QueryRowContext(ctx, query, revision))
+	r.observe("get_revision", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrConfigNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *Repository) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) ([]*model.Config, error) {
+	start := time.Now()
+	name := "list_configs_by_key"
+	if query.SortOrDefault() == model.SortByUpdatedAt {
+		name = "list_configs_by_updated_at"
+	}
+	statement, err := r.query(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A negative LIMIT has no upper bound.
+	limit := -1
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+	var after, afterTime string
+	if query.After != nil {
+		after, afterTime = query.After.Key, formatTime(query.After.UpdatedAt)
+	}
+	args := append(filterArgs(environment, query), after)
+	if name == "list_configs_by_updated_at" {
+		args = append(args, afterTime)
+	}
+	configs, err := r.queryConfigs(ctx, statement, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	r.observe(name, start)
+	return configs, nil
+}
+
+func (r *Repository) CountConfigs(ctx context.Context, environment string, query model.ConfigQuery) (int, error) {
+	start := time.Now()
+	statement, err := r.query("count_configs")
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := r.db.QueryRowContext(ctx, statement, filterArgs(environment, query)...).Scan(&count); err != nil {
+		return 0, err
+	}
+	r.observe("count_configs", start)
+	return count, nil
+}
+
+// filterArgs binds the filters shared by the list and count queries. Keys
+// are matched with substr rather than LIKE, which would need escaping and
+// ignores case. lower() folds ASCII letters only, so search is
+// case-insensitive for ASCII.
+func filterArgs(environment string, query model.ConfigQuery) []any {
+	var below string
+	if query.Tree != "" {
+		below = query.Tree + model.KeySeparator
+	}
+	var allowed sql.NullString
+	if query.AllowedPrefixes != nil {
+		prefixes, _ := json.Marshal(query.AllowedPrefixes)
+		allowed = sql.NullString{String: string(prefixes), Valid: true}
+	}
+	return []any{
+		environment,
+		query.Prefix,
+		strings.ToLower(query.Search),
+		nullTime(query.UpdatedSince),
+		allowed,
+		query.Tree,
+		below,
+	}
+}
+
+func (r *Repository) queryConfigs(ctx context.Context, query string, args ...any) ([]*model.Config, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []*model.Config
+	for rows.Next() {
+		config, err := scanConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func (r *Repository) queryRevisions(ctx context.Context, query string, args ...any) ([]*model.Revision, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*model.Revision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func scanConfig(row rowScanner) (*model.Config, error) {
+	var config model.Config
+	var updatedAt timestamp
+	var spec sql.NullString
+	if err := row.Scan(
+		&config.Environment,
+		&config.Key,
+		&config.Value,
+		&updatedAt,
+		&config.UpdatedBy,
+		&config.Revision,
+		&config.Version,
+		&spec,
+	); err != nil {
+		return nil, err
+	}
+	config.UpdatedAt = updatedAt.Time
+	if spec.Valid {
+		if err := json.Unmarshal([]byte(spec.String), &config.ValueSpec); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+func scanRevision(row rowScanner) (*model.Revision, error) {
+	var revision model.Revision
+	var createdAt timestamp
+	var spec sql.NullString
+	if err := row.Scan(
+		&revision.Revision,
+		&revision.Environment,
+		&revision.Key,
+		&revision.Value,
+		&revision.Operation,
+		&revision.Actor,
+		&createdAt,
+		&spec,
+	); err != nil {
+		return nil, err
+	}
+	revision.CreatedAt = createdAt.Time
+	if spec.Valid {
+		if err := json.Unmarshal([]byte(spec.String), &revision.ValueSpec); err != nil {
+			return nil, err
+		}
+	}
+	return &revision, nil
+}
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (r *Repository) CreateEnvironment(ctx context.Context, environment *model.Environment) error {
+	start := time.Now()
+	query, err := r.query("create_environment")
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		query,
+		environment.Name,
+		environment.Parent,
+		environment.Description,
+		environment.Owner,
+		environment.Protected,
+		formatTime(environment.CreatedAt),
+	)
+	r.observe("create_environment", start)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrEnvironmentAlreadyExists
+		}
+		if isForeignKeyViolation(err) {
+			return repository.ErrEnvironmentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	start := time.Now()
+	query, err := r.query("get_environment")
+	if err != nil {
+		return nil, err
+	}
+	environment, err := scanEnvironment(r.db.QueryRowContext(ctx, query, name))
+	r.observe("get_environment", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+	return environment, nil
+}
+
+func (r *Repository) GetEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	start := time.Now()
+	query, err := r.query("get_environments")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var environments []*model.Environment
+	for rows.Next() {
+		environment, err := scanEnvironment(rows)
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, environment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	r.observe("get_environments", start)
+	return environments, nil
+}
+
+func (r *Repository) UpdateEnvironment(ctx context.Context, environment *model.Environment) error {
+	start := time.Now()
+	query, err := r.query("update_environment")
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx,
+		query,
+		environment.Name,
+		environment.Parent,
+		environment.Description,
+		environment.Owner,
+		environment.Protected,
+	)
+	r.observe("update_environment", start)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repository.ErrEnvironmentNotFound
+		}
+		return err
+	}
+	return requireAffected(result, repository.ErrEnvironmentNotFound)
+}
+
+func (r *Repository) DeleteEnvironment(ctx context.Context, name string) error {
+	start := time.Now()
+	query, err := r.query("delete_environment")
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, query, name)
+	r.observe("delete_environment", start)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repository.ErrEnvironmentInUse
+		}
+		return err
+	}
+	return requireAffected(result, repository.ErrEnvironmentNotFound)
+}
+
+func scanEnvironment(row rowScanner) (*model.Environment, error) {
+	var environment model.Environment
+	var createdAt timestamp
+	if err := row.Scan(
+		&environment.Name,
+		&environment.Parent,
+		&environment.Description,
+		&environment.Owner,
+		&environment.Protected,
+		&createdAt,
+		&environment.KeyCount,
+	); err != nil {
+		return nil, err
+	}
+	environment.CreatedAt = createdAt.Time
+	return &environment, nil
+}
@@ -0,0 +1,3 @@
+INSERT INTO audit_log (actor, source_ip, request_id, operation, env, key, revision, old_value, new_value, secret, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, created_at;
@@ -0,0 +1,2 @@
+INSERT INTO configs (env, key, value, updated_at, updated_by, value_spec, revision)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
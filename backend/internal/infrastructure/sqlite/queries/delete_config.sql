@@ -0,0 +1,3 @@
+DELETE FROM configs
+WHERE env = $1 AND key = $2 AND ($3 = 0 OR version = $3)
+RETURNING value, value_spec;
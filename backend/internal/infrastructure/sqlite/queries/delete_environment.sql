@@ -0,0 +1,2 @@
+DELETE FROM environments
+WHERE name = $1;
@@ -0,0 +1,2 @@
+DELETE FROM schema_migrations
+WHERE version = $1;
@@ -0,0 +1 @@
+DELETE FROM api_tokens WHERE id = $1;
@@ -0,0 +1,9 @@
+SELECT revision, env, key, value, operation, actor, created_at, value_spec
+FROM (
+    SELECT revision, env, key, value, operation, actor, created_at, value_spec,
+           ROW_NUMBER() OVER (PARTITION BY key ORDER BY revision DESC) AS position
+    FROM config_revisions
+    WHERE env = $1 AND created_at <= $2
+) latest
+WHERE position = 1 AND operation <> 'delete'
+ORDER BY key;
@@ -0,0 +1,10 @@
+SELECT id, actor, source_ip, request_id, operation, env, key, revision, old_value, new_value, secret, created_at
+FROM audit_log
+WHERE ($1 = '' OR env = $1)
+  AND ($2 = '' OR key = $2)
+  AND ($3 = '' OR actor = $3)
+  AND ($4 IS NULL OR created_at >= $4)
+  AND ($5 IS NULL OR created_at < $5)
+  AND ($6 = 0 OR id < $6)
+ORDER BY id DESC
+LIMIT $7;
@@ -0,0 +1,9 @@
+SELECT revision, env, key, value, operation, actor, created_at, value_spec
+FROM (
+    SELECT revision, env, key, value, operation, actor, created_at, value_spec
+    FROM config_revisions
+    WHERE env = $1 AND key = $2 AND created_at <= $3
+    ORDER BY revision DESC
+    LIMIT 1
+) latest
+WHERE operation <> 'delete';
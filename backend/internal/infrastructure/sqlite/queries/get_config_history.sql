@@ -0,0 +1,4 @@
+SELECT revision, env, key, value, operation, actor, created_at, value_spec
+FROM config_revisions
+WHERE env = $1 AND key = $2
+ORDER BY revision DESC;
@@ -0,0 +1,6 @@
+SELECT e.name, COALESCE(e.parent, ''), e.description, e.owner, e.protected, e.created_at,
+       COUNT(c.key)
+FROM environments e
+LEFT JOIN configs c ON c.env = e.name
+GROUP BY e.name
+ORDER BY e.name;
@@ -0,0 +1,5 @@
+SELECT revision, env, key, value, operation, actor, created_at, value_spec
+FROM config_revisions
+WHERE env = $1 AND revision > $2
+ORDER BY revision
+LIMIT $3;
@@ -0,0 +1,3 @@
+SELECT DISTINCT value FROM configs
+WHERE substr(value, 1, length($1)) = $1 AND substr(value, 1, length($2)) <> $2
+LIMIT $3;
@@ -0,0 +1,11 @@
+SELECT c.env, c.key, c.value, c.updated_at, c.updated_by, c.revision, c.version, c.value_spec
+FROM configs c
+WHERE c.env = $1
+  AND ($2 = '' OR substr(c.key, 1, length($2)) = $2)
+  AND ($3 = '' OR instr(lower(c.key), $3) > 0 OR (instr(lower(c.value), $3) > 0 AND COALESCE(c.value_spec ->> '$.secret', 0) = 0))
+  AND ($4 IS NULL OR c.updated_at >= $4)
+  AND ($5 IS NULL OR EXISTS (SELECT 1 FROM json_each($5) p WHERE substr(c.key, 1, length(p.value)) = p.value))
+  AND ($6 = '' OR c.key = $6 OR substr(c.key, 1, length($7)) = $7)
+  AND ($8 = '' OR c.updated_at < $9 OR (c.updated_at = $9 AND c.key > $8))
+ORDER BY c.updated_at DESC, c.key
+LIMIT $10;
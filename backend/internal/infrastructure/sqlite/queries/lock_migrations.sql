@@ -0,0 +1,3 @@
+-- Transactions begin IMMEDIATE (_txlock=immediate), so the write lock on the
+-- database file is already held.
+SELECT 1;
@@ -0,0 +1,3 @@
+INSERT INTO config_revisions (env, key, value, operation, actor, created_at, value_spec)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING revision, env, key, value, operation, actor, created_at, value_spec;
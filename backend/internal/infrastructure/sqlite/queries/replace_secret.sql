@@ -0,0 +1,2 @@
+UPDATE configs SET value = $2
+WHERE value = $1;
@@ -0,0 +1,3 @@
+UPDATE secret_data_keys
+SET master_key = $2, wrapped = $3
+WHERE id = $1;
@@ -0,0 +1,8 @@
+UPDATE configs
+SET value = $3,
+    updated_at = $4,
+    updated_by = $5,
+    value_spec = $7,
+    revision = $8,
+    version = version + 1
+WHERE env = $1 AND key = $2 AND version = $6;
@@ -0,0 +1,274 @@
+// Package sqlite stores configs in an embedded SQLite database file, for
+// deployments that run the service as a single binary without Postgres.
+package sqlite
+
+import (
+	"config-service/backend/internal/infrastructure/database"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"config-service/backend/migrations"
+	"config-service/backend/pkg/metrics"
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+//go:embed queries/*.sql
+var queriesFS embed.FS
+
+// timeLayout has a fixed width, so that stored times compare and sort as
+// text in chronological order.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type connection struct {
+	db *sql.DB
+}
+
+// NewConnection opens the database file at path, creating it if needed.
+// Transactions begin IMMEDIATE, taking the write lock up front, and wait for
+// it instead of failing with SQLITE_BUSY. Foreign keys, which SQLite leaves
+// off by default, are enforced.
+func NewConnection(path string) (database.Connection, error) {
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	return &connection{db: db}, nil
+}
+
+func (c *connection) GetDB() *sql.DB {
+	return c.db
+}
+
+func (c *connection) Close() error {
+	return c.db.Close()
+}
+
+// NewMigrator applies the SQLite migrations, which are separate from the
+// Postgres ones.
+func NewMigrator(db *sql.DB) (*database.Migrator, error) {
+	queries, err := loadQueries()
+	if err != nil {
+		return nil, err
+	}
+	return database.NewDialectMigrator(db, migrations.SQLite, database.MigrationDialect{
+		Queries:        queries,
+		UndefinedTable: isUndefinedTable,
+	})
+}
+
+// Repository implements the config, token and secret repositories with the
+// semantics of the Postgres repository. Writes that take several statements
+// run in a transaction, or in a savepoint inside WithTx.
+type Repository struct {
+	db querier
+	// conn is nil on the repository passed to WithTx.
+	conn    *sql.DB
+	queries map[string]string
+	metrics *metrics.Metrics
+	now     func() time.Time
+}
+
+func NewRepository(db *sql.DB, m *metrics.Metrics) (*Repository, error) {
+	queries, err := loadQueries()
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{db: db, conn: db, queries: queries, metrics: m, now: time.Now}, nil
+}
+
+func loadQueries() (map[string]string, error) {
+	queries := make(map[string]string)
+	entries, err := queriesFS.ReadDir("queries")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		data, err := queriesFS.ReadFile("queries/" + name)
+		if err != nil {
+			return nil, err
+		}
+		queries[strings.TrimSuffix(name, ".sql")] = strings.TrimSpace(string(data))
+	}
+	return queries, nil
+}
+
+func (r *Repository) query(name string) (string, error) {
+	query := r.queries[name]
+	if query == "" {
+		return "", errors.New(name + " query not found")
+	}
+	return query, nil
+}
+
+func (r *Repository) observe(operation string, start time.Time) {
+	r.metrics.DBQueriesTotal.WithLabelValues(operation).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+func (r *Repository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	if r.conn == nil {
+		return fn(r)
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return fn(&Repository{db: tx, queries: r.queries, metrics: r.metrics, now: r.now})
+	})
+}
+
+func (r *Repository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// write runs the statements of one write atomically. Unlike Postgres, SQLite
+// keeps a transaction usable after a failed statement, so inside WithTx the
+// statements run in a savepoint that is rolled back when they fail.
+func (r *Repository) write(ctx context.Context, fn func(db querier) error) error {
+	if r.conn != nil {
+		return r.inTx(ctx, func(tx *sql.Tx) error { return fn(tx) })
+	}
+
+	if _, err := r.db.ExecContext(ctx, "SAVEPOINT repository_write"); err != nil {
+		return err
+	}
+	if err := fn(r.db); err != nil {
+		ctx := context.WithoutCancel(ctx)
+		_, _ = r.db.ExecContext(ctx, "ROLLBACK TO repository_write")
+		_, _ = r.db.ExecContext(ctx, "RELEASE repository_write")
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, "RELEASE repository_write")
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// timestamp scans stored times, which the driver returns as time.Time from
+// TIMESTAMP columns and as text from expressions.
+type timestamp struct {
+	time.Time
+	Valid bool
+}
+
+func (t *timestamp) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*t = timestamp{}
+	case time.Time:
+		*t = timestamp{Time: v.UTC(), Valid: true}
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return err
+		}
+		*t = timestamp{Time: parsed, Valid: true}
+	default:
+		return fmt.Errorf("cannot scan %T into a time", value)
+	}
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTime passes an unset time as NULL so the query skips the bound.
+func nullTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(t), Valid: true}
+}
+
+func nullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *value, Valid: true}
+}
+
+// encodeSpec stores untyped keys as NULL.
+func encodeSpec(spec model.ValueSpec) (any, error) {
+	if spec.IsZero() {
+		return nil, nil
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func requireAffected(result sql.Result, errNone error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errNone
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	code, ok := errorCode(err)
+	return ok && (code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
+
+func isForeignKeyViolation(err error) bool {
+	code, ok := errorCode(err)
+	return ok && code == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+func isUndefinedTable(err error) bool {
+	code, ok := errorCode(err)
+	return ok && code == sqlite3.SQLITE_ERROR && strings.Contains(err.Error(), "no such table")
+}
+
+// errorCode returns the extended result code of a SQLite error.
+func errorCode(err error) (int, bool) {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return 0, false
+	}
+	return sqliteErr.Code(), true
+}
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"config-service/backend/internal/repository/repositorytest"
+	"config-service/backend/pkg/metrics"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestMetrics() *metrics.Metrics {
+	return &metrics.Metrics{
+		DBQueriesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Name: "test_db_queries_total"},
+			[]string{"operation"},
+		),
+		DBQueryDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Name: "test_db_query_duration_seconds"},
+			[]string{"operation"},
+		),
+	}
+}
+
+// openTestDB opens a migrated database in a temporary file.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := NewConnection(filepath.Join(t.TempDir(), "configs.db"))
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	migrator, err := NewMigrator(conn.GetDB())
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	return conn.GetDB()
+}
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	repo, err := NewRepository(openTestDB(t), newTestMetrics())
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	return repo
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.RunConfigRepository(t, func(t *testing.T) repository.ConfigRepository {
+		return newTestRepository(t)
+	})
+	repositorytest.RunTokenRepository(t, func(t *testing.T) repository.TokenRepository {
+		return newTestRepository(t)
+	})
+	repositorytest.RunSecretStore(t, func(t *testing.T) (repository.SecretStore, repository.ConfigRepository) {
+		repo := newTestRepository(t)
+		return repo, repo
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	conn, err := NewConnection(filepath.Join(t.TempDir(), "configs.db"))
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	defer conn.Close()
+	migrator, err := NewMigrator(conn.GetDB())
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil || len(statuses) == 0 || statuses[0].Applied() {
+		t.Fatalf("Status() before migrating = %v, %v, want everything pending", statuses, err)
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil || len(applied) != len(statuses) {
+		t.Fatalf("Up() = %v, %v, want every migration applied", applied, err)
+	}
+	statuses, err = migrator.Status(ctx)
+	if err != nil || !statuses[0].Applied() {
+		t.Fatalf("Status() after Up() = %v, %v", statuses, err)
+	}
+	if again, err := migrator.Up(ctx); err != nil || len(again) != 0 {
+		t.Fatalf("second Up() = %v, %v, want nothing to apply", again, err)
+	}
+
+	reverted, err := migrator.Down(ctx, len(statuses))
+	if err != nil || len(reverted) != len(statuses) {
+		t.Fatalf("Down() = %v, %v, want every migration reverted", reverted, err)
+	}
+	var tables int
+	if err := conn.GetDB().QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'configs'").Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("configs tables after Down() = %d, %v", tables, err)
+	}
+}
+
+func TestRepositoryTxKeepsFailedWritesOut(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+	if err := repo.CreateEnvironment(ctx, &model.Environment{Name: "dev"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	if _, err := repo.Create(ctx, &model.Config{Environment: "dev", Key: "a", Value: "1"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// SQLite keeps the transaction usable after a failed statement, so the
+	// revision of the failed create must be undone on its own.
+	err := repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		if _, err := tx.Create(ctx, &model.Config{Environment: "dev", Key: "a", Value: "2"}); !errors.Is(err, repository.ErrConfigAlreadyExists) {
+			t.Fatalf("Create() duplicate error = %v", err)
+		}
+		if _, err := tx.Update(ctx, &model.Config{Environment: "dev", Key: "a", Value: "2", Version: 5}); !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("Update() stale error = %v", err)
+		}
+		_, err := tx.Create(ctx, &model.Config{Environment: "dev", Key: "b", Value: "1"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+
+	revisions, err := repo.GetRevisionsSince(ctx, "dev", 0, 10)
+	if err != nil || len(revisions) != 2 || revisions[1].Key != "b" {
+		t.Fatalf("GetRevisionsSince() = %v, %v, want only the revisions of a and b", revisions, err)
+	}
+}
+
+func TestRepositoryAuditLogIsAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+	if err := repo.AppendAudit(ctx, &model.AuditEntry{Operation: model.AuditEnvironmentCreate, Environment: "dev"}); err != nil {
+		t.Fatalf("AppendAudit() error = %v", err)
+	}
+	if _, err := repo.conn.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+		t.Fatal("deleting audit entries must fail")
+	}
+}
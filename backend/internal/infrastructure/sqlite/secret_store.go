@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (r *Repository) CreateDataKey(ctx context.Context, key *repository.DataKey) error {
+	start := time.Now()
+	query, err := r.query("create_data_key")
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(ctx, query, key.MasterKey, key.Wrapped, formatTime(key.CreatedAt)).Scan(&key.ID)
+	r.observe("create_data_key", start)
+	return err
+}
+
+func (r *Repository) GetDataKey(ctx context.Context, id int64) (*repository.DataKey, error) {
+	start := time.Now()
+	query, err := r.query("get_data_key")
+	if err != nil {
+		return nil, err
+	}
+	key, err := scanDataKey(r.db.QueryRowContext(ctx, query, id))
+	r.observe("get_data_key", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrDataKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *Repository) GetDataKeys(ctx context.Context) ([]*repository.DataKey, error) {
+	start := time.Now()
+	query, err := r.query("get_data_keys")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*repository.DataKey
+	for rows.Next() {
+		key, err := scanDataKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	r.observe("get_data_keys", start)
+	return keys, nil
+}
+
+func (r *Repository) RewrapDataKey(ctx context.Context, key *repository.DataKey) error {
+	start := time.Now()
+	query, err := r.query("rewrap_data_key")
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, query, key.ID, key.MasterKey, key.Wrapped)
+	r.observe("rewrap_data_key", start)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, repository.ErrDataKeyNotFound)
+}
+
+func (r *Repository) GetStaleSecrets(ctx context.Context, prefix, current string, limit int) ([]string, error) {
+	start := time.Now()
+	query, err := r.query("get_stale_secrets")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query, prefix, current, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	r.observe("get_stale_secrets", start)
+	return values, nil
+}
+
+func (r *Repository) ReplaceSecret(ctx context.Context, old, replacement string) (int64, error) {
+	start := time.Now()
+	query, err := r.query("replace_secret")
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.ExecContext(ctx, query, old, replacement)
+	r.observe("replace_secret", start)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanDataKey(row rowScanner) (*repository.DataKey, error) {
+	var key repository.DataKey
+	var createdAt timestamp
+	if err := row.Scan(&key.ID, &key.MasterKey, &key.Wrapped, &createdAt); err != nil {
+		return nil, err
+	}
+	key.CreatedAt = createdAt.Time
+	return &key, nil
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func (r *Repository) CreateToken(ctx context.Context, token *model.Token, hash string) error {
+	start := time.Now()
+	query, err := r.query("create_token")
+	if err != nil {
+		return err
+	}
+	grants, err := json.Marshal(token.Grants)
+	if err != nil {
+		return err
+	}
+	var expiresAt sql.NullString
+	if token.ExpiresAt != nil {
+		expiresAt = nullTime(*token.ExpiresAt)
+	}
+	err = r.db.QueryRowContext(ctx,
+		query,
+		token.Name,
+		hash,
+		string(grants),
+		token.CreatedBy,
+		formatTime(token.CreatedAt),
+		expiresAt,
+	).Scan(&token.ID)
+	r.observe("create_token", start)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrTokenAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetTokenByHash(ctx context.Context, hash string) (*model.Token, error) {
+	start := time.Now()
+	query, err := r.query("get_token_by_hash")
+	if err != nil {
+		return nil, err
+	}
+	token, err := scanToken(r.db.QueryRowContext(ctx, query, hash))
+	r.observe("get_token_by_hash", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *Repository) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	start := time.Now()
+	query, err := r.query("get_tokens")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*model.Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	r.observe("get_tokens", start)
+	return tokens, nil
+}
+
+func (r *Repository) DeleteToken(ctx context.Context, id int64) error {
+	start := time.Now()
+	query, err := r.query("delete_token")
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, query, id)
+	r.observe("delete_token", start)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, repository.ErrTokenNotFound)
+}
+
+func scanToken(row rowScanner) (*model.Token, error) {
+	var token model.Token
+	var grants string
+	var createdAt, expiresAt timestamp
+	if err := row.Scan(
+		&token.ID,
+		&token.Name,
+		&grants,
+		&token.CreatedBy,
+		&createdAt,
+		&expiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(grants), &token.Grants); err != nil {
+		return nil, err
+	}
+	token.CreatedAt = createdAt.Time
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return &token, nil
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter: limit must be between 1 and 1000 and from before to")
+
+// MaxAuditPageSize bounds AuditFilter.Limit.
+const MaxAuditPageSize = 1000
+
+type AuditOperation string
+
+const (
+	AuditConfigCreate      AuditOperation = "config.create"
+	AuditConfigUpdate      AuditOperation = "config.update"
+	AuditConfigDelete      AuditOperation = "config.delete"
+	AuditEnvironmentCreate AuditOperation = "environment.create"
+	AuditEnvironmentUpdate AuditOperation = "environment.update"
+	AuditEnvironmentDelete AuditOperation = "environment.delete"
+)
+
+// AuditEntry records a single change. Values of secret keys are never
+// stored: OldValue and NewValue are nil and Secret is set instead. For
+// environments the values are the JSON encoded attributes.
+type AuditEntry struct {
+	ID          int64          `json:"id"`
+	Actor       string         `json:"actor"`
+	SourceIP    string         `json:"source_ip,omitempty"`
+	RequestID   string         `json:"request_id,omitempty"`
+	Operation   AuditOperation `json:"operation"`
+	Environment string         `json:"env"`
+	Key         string         `json:"key,omitempty"`
+	Revision    int64          `json:"revision,omitempty"`
+	OldValue    *string        `json:"old_value,omitempty"`
+	NewValue    *string        `json:"new_value,omitempty"`
+	Secret      bool           `json:"secret,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// AuditRequest describes the request that caused a change.
+type AuditRequest struct {
+	Actor     string
+	SourceIP  string
+	RequestID string
+}
+
+// AuditFilter selects audit entries; empty fields match everything. Entries
+// are returned newest first.
+type AuditFilter struct {
+	Environment string
+	Key         string
+	Actor       string
+	// From is inclusive and To is exclusive.
+	From time.Time
+	To   time.Time
+	// Cursor continues a previous page: only entries with a smaller ID are
+	// returned.
+	Cursor int64
+	Limit  int
+}
+
+func (f AuditFilter) Validate() error {
+	if f.Limit < 1 || f.Limit > MaxAuditPageSize || f.Cursor < 0 {
+		return ErrInvalidAuditFilter
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return ErrInvalidAuditFilter
+	}
+	return nil
+}
+
+type AuditPage struct {
+	Entries []*AuditEntry `json:"entries"`
+	// NextCursor is zero on the last page.
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
+
+type requestIDKey struct{}
+
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuditFilter_Validate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		filter  AuditFilter
+		wantErr bool
+	}{
+		{name: "defaults", filter: AuditFilter{Limit: 100}},
+		{name: "time range", filter: AuditFilter{From: now.Add(-time.Hour), To: now, Limit: 1}},
+		{name: "open range", filter: AuditFilter{From: now, Limit: MaxAuditPageSize}},
+		{name: "zero limit", filter: AuditFilter{}, wantErr: true},
+		{name: "limit too large", filter: AuditFilter{Limit: MaxAuditPageSize + 1}, wantErr: true},
+		{name: "negative cursor", filter: AuditFilter{Cursor: -1, Limit: 10}, wantErr: true},
+		{name: "empty range", filter: AuditFilter{From: now, To: now, Limit: 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr != errors.Is(err, ErrInvalidAuditFilter) || !tt.wantErr && err != nil {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	if got := RequestIDFromContext(context.Background()); got != "" {
+		t.Fatalf("RequestIDFromContext() = %q, want empty", got)
+	}
+	if got := RequestIDFromContext(ContextWithRequestID(context.Background(), "req-1")); got != "req-1" {
+		t.Fatalf("RequestIDFromContext() = %q, want req-1", got)
+	}
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTokenName = errors.New("invalid token name: use 1-64 letters, digits, '.', '-' or '_'")
+	ErrInvalidGrant     = errors.New("invalid grant: env must be an environment name or '*' and role one of reader, writer, admin")
+	ErrInvalidExpiry    = errors.New("token expiry must be in the future")
+)
+
+var tokenNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// AllEnvironments in a grant matches every environment, including ones
+// created later.
+const AllEnvironments = "*"
+
+// Role is ordered: every role includes the permissions of the ones before it.
+type Role string
+
+const (
+	// RoleReader reads configs, history and masked secrets.
+	RoleReader Role = "reader"
+	// RoleWriter also writes configs and reveals secret values.
+	RoleWriter Role = "writer"
+	// RoleAdmin also manages environments; admin on AllEnvironments manages
+	// API tokens.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleReader:
+		return 1
+	case RoleWriter:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Grant gives a role on an environment, limited to keys starting with
+// KeyPrefix when it is set.
+type Grant struct {
+	Environment string `json:"env"`
+	KeyPrefix   string `json:"key_prefix,omitempty"`
+	Role        Role   `json:"role"`
+}
+
+func (g Grant) Validate() error {
+	if g.Role.rank() == 0 {
+		return ErrInvalidGrant
+	}
+	if g.Environment != AllEnvironments && !environmentNamePattern.MatchString(g.Environment) {
+		return ErrInvalidGrant
+	}
+	return nil
+}
+
+func (g Grant) covers(role Role, environment string) bool {
+	return g.Role.rank() >= role.rank() &&
+		(g.Environment == AllEnvironments || g.Environment == environment)
+}
+
+// Token is an API token without its secret part, which is only stored as a
+// hash.
+type Token struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Grants    []Grant    `json:"grants"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func NewToken(name string, grants []Grant, expiresAt *time.Time, createdBy string) (*Token, error) {
+	if !tokenNamePattern.MatchString(name) {
+		return nil, ErrInvalidTokenName
+	}
+	if len(grants) == 0 {
+		return nil, ErrInvalidGrant
+	}
+	for _, grant := range grants {
+		if err := grant.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, ErrInvalidExpiry
+	}
+
+	return &Token{
+		Name:      name,
+		Grants:    grants,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (t *Token) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// How a caller authenticated.
+const (
+	IdentitySourceToken      = "token"
+	IdentitySourceAdminToken = "admin-token"
+	IdentitySourceOIDC       = "oidc"
+)
+
+// Identity is an authenticated caller.
+type Identity struct {
+	Name   string   `json:"name"`
+	Source string   `json:"source,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	Grants []Grant  `json:"grants"`
+	// ExpiresAt is when the credential stops being accepted.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Allows reports whether the caller has role on the key.
+func (i *Identity) Allows(role Role, environment, key string) bool {
+	for _, grant := range i.Grants {
+		if grant.covers(role, environment) && strings.HasPrefix(key, grant.KeyPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsEnvironment reports whether the caller has role on every key of the
+// environment, including keys that do not exist yet.
+func (i *Identity) AllowsEnvironment(role Role, environment string) bool {
+	for _, grant := range i.Grants {
+		if grant.covers(role, environment) && grant.KeyPrefix == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAny reports whether the caller has role on at least some keys of
+// the environment.
+func (i *Identity) AllowsAny(role Role, environment string) bool {
+	for _, grant := range i.Grants {
+		if grant.covers(role, environment) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyPrefixes returns the key prefixes of the grants that give role on the
+// environment. An empty prefix among them means every key.
+func (i *Identity) KeyPrefixes(role Role, environment string) []string {
+	var prefixes []string
+	for _, grant := range i.Grants {
+		if grant.covers(role, environment) {
+			prefixes = append(prefixes, grant.KeyPrefix)
+		}
+	}
+	return prefixes
+}
+
+type identityKey struct{}
+
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns nil when the request was not authenticated,
+// which is the case when authentication is disabled.
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
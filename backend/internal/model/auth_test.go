@@ -0,0 +1,116 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewToken(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	reader := []Grant{{Environment: "prod", Role: RoleReader}}
+
+	tests := []struct {
+		name      string
+		tokenName string
+		grants    []Grant
+		expiresAt *time.Time
+		wantErr   error
+	}{
+		{name: "valid", tokenName: "ci-deploy", grants: reader},
+		{name: "all environments with prefix", tokenName: "billing", grants: []Grant{{Environment: AllEnvironments, KeyPrefix: "billing.", Role: RoleWriter}}, expiresAt: &future},
+		{name: "empty name", tokenName: "", grants: reader, wantErr: ErrInvalidTokenName},
+		{name: "name with space", tokenName: "ci deploy", grants: reader, wantErr: ErrInvalidTokenName},
+		{name: "too long name", tokenName: strings.Repeat("a", 65), grants: reader, wantErr: ErrInvalidTokenName},
+		{name: "no grants", tokenName: "ci", wantErr: ErrInvalidGrant},
+		{name: "unknown role", tokenName: "ci", grants: []Grant{{Environment: "prod", Role: "owner"}}, wantErr: ErrInvalidGrant},
+		{name: "invalid environment", tokenName: "ci", grants: []Grant{{Environment: "Prod", Role: RoleReader}}, wantErr: ErrInvalidGrant},
+		{name: "expired", tokenName: "ci", grants: reader, expiresAt: &past, wantErr: ErrInvalidExpiry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := NewToken(tt.tokenName, tt.grants, tt.expiresAt, "alice")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (token.Name != tt.tokenName || token.CreatedBy != "alice" || token.CreatedAt.IsZero()) {
+				t.Fatalf("NewToken() = %#v", token)
+			}
+		})
+	}
+}
+
+func TestTokenExpired(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Minute)
+	token := &Token{ExpiresAt: &expiresAt}
+
+	if token.Expired(now) || !token.Expired(expiresAt) {
+		t.Fatal("Expired() must switch at ExpiresAt")
+	}
+	if (&Token{}).Expired(now) {
+		t.Fatal("token without expiry must not expire")
+	}
+}
+
+func TestIdentityAllows(t *testing.T) {
+	identity := &Identity{Name: "ci", Grants: []Grant{
+		{Environment: "prod", KeyPrefix: "billing.", Role: RoleWriter},
+		{Environment: "staging", Role: RoleAdmin},
+		{Environment: AllEnvironments, Role: RoleReader},
+	}}
+
+	tests := []struct {
+		name        string
+		check       func() bool
+		wantAllowed bool
+	}{
+		{name: "read any environment", check: func() bool { return identity.Allows(RoleReader, "dev", "db.host") }, wantAllowed: true},
+		{name: "write prefixed key", check: func() bool { return identity.Allows(RoleWriter, "prod", "billing.rate") }, wantAllowed: true},
+		{name: "write other key", check: func() bool { return identity.Allows(RoleWriter, "prod", "db.host") }},
+		{name: "admin key", check: func() bool { return identity.Allows(RoleAdmin, "prod", "billing.rate") }},
+		{name: "write whole environment with prefix grant", check: func() bool { return identity.AllowsEnvironment(RoleWriter, "prod") }},
+		{name: "admin whole environment", check: func() bool { return identity.AllowsEnvironment(RoleAdmin, "staging") }, wantAllowed: true},
+		{name: "write some keys", check: func() bool { return identity.AllowsAny(RoleWriter, "prod") }, wantAllowed: true},
+		{name: "write some keys elsewhere", check: func() bool { return identity.AllowsAny(RoleWriter, "dev") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check(); got != tt.wantAllowed {
+				t.Fatalf("allowed = %v, want %v", got, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestIdentityKeyPrefixes(t *testing.T) {
+	identity := &Identity{Name: "ci", Grants: []Grant{
+		{Environment: "prod", KeyPrefix: "billing.", Role: RoleReader},
+		{Environment: AllEnvironments, KeyPrefix: "feature.", Role: RoleWriter},
+		{Environment: "staging", Role: RoleReader},
+	}}
+
+	if got := identity.KeyPrefixes(RoleReader, "prod"); !reflect.DeepEqual(got, []string{"billing.", "feature."}) {
+		t.Fatalf("KeyPrefixes(reader, prod) = %q", got)
+	}
+	if got := identity.KeyPrefixes(RoleWriter, "prod"); !reflect.DeepEqual(got, []string{"feature."}) {
+		t.Fatalf("KeyPrefixes(writer, prod) = %q", got)
+	}
+	if got := identity.KeyPrefixes(RoleAdmin, "staging"); got != nil {
+		t.Fatalf("KeyPrefixes(admin, staging) = %q", got)
+	}
+}
+
+func TestIdentityContext(t *testing.T) {
+	if IdentityFromContext(context.Background()) != nil {
+		t.Fatal("IdentityFromContext() of an empty context must be nil")
+	}
+	identity := &Identity{Name: "ci"}
+	if got := IdentityFromContext(ContextWithIdentity(context.Background(), identity)); got != identity {
+		t.Fatalf("IdentityFromContext() = %#v", got)
+	}
+}
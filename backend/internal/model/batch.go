@@ -0,0 +1,53 @@
+package model
+
+import "errors"
+
+var (
+	ErrInvalidOperation = errors.New("invalid operation")
+	ErrDuplicateKey     = errors.New("duplicate key in batch")
+)
+
+type BatchStatus string
+
+const (
+	BatchStatusApplied BatchStatus = "applied"
+	BatchStatusFailed  BatchStatus = "failed"
+	BatchStatusAborted BatchStatus = "aborted"
+)
+
+type BatchOperation struct {
+	Op      Operation `json:"op"`
+	Key     string    `json:"key"`
+	Value   string    `json:"value,omitempty"`
+	Version int64     `json:"version,omitempty"`
+	ValueSpec
+}
+
+type BatchResult struct {
+	Op       Operation   `json:"op"`
+	Key      string      `json:"key"`
+	Status   BatchStatus `json:"status"`
+	Revision int64       `json:"revision,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	// Violations lists why the value was rejected when the operation
+	// failed type validation.
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Validate applies the same rules as NewTypedConfig to a single batch
+// operation. Updates without a type are checked against the stored type
+// only when they are applied.
+func (o BatchOperation) Validate(environment string) error {
+	switch o.Op {
+	case OperationCreate, OperationUpdate, OperationUpsert:
+		_, err := NewTypedConfig(environment, o.Key, o.Value, o.ValueSpec)
+		return err
+	case OperationDelete:
+		if err := validateEnvironment(environment); err != nil {
+			return err
+		}
+		return validateKey(o.Key)
+	default:
+		return ErrInvalidOperation
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchOperation_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      BatchOperation
+		wantErr error
+	}{
+		{name: "create", op: BatchOperation{Op: OperationCreate, Key: "key", Value: "value"}},
+		{name: "upsert", op: BatchOperation{Op: OperationUpsert, Key: "key", Value: "value"}},
+		{name: "delete without value", op: BatchOperation{Op: OperationDelete, Key: "key"}},
+		{name: "unknown operation", op: BatchOperation{Op: "rename", Key: "key"}, wantErr: ErrInvalidOperation},
+		{name: "empty key", op: BatchOperation{Op: OperationUpdate, Key: "", Value: "value"}, wantErr: ErrInvalidKey},
+		{name: "delete empty key", op: BatchOperation{Op: OperationDelete, Key: ""}, wantErr: ErrInvalidKey},
+		{name: "reserved key", op: BatchOperation{Op: OperationUpsert, Key: "rollback", Value: "value"}, wantErr: ErrInvalidKey},
+		{name: "too long value", op: BatchOperation{Op: OperationCreate, Key: "key", Value: string(make([]byte, 10001))}, wantErr: ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op.Validate("prod")
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (BatchOperation{Op: OperationDelete, Key: "key"}).Validate(""); !errors.Is(err, ErrInvalidEnvironment) {
+		t.Fatalf("Validate() empty environment error = %v, want %v", err, ErrInvalidEnvironment)
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -16,16 +17,24 @@ type Config struct {
 	Key         string    `json:"key"`
 	Value       string    `json:"value"`
 	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	Revision    int64     `json:"revision,omitempty"`
+	Version     int64     `json:"version,omitempty"`
+	ValueSpec
 }
 
 func NewConfig(environment, key, value string) (*Config, error) {
+	return NewTypedConfig(environment, key, value, ValueSpec{})
+}
+
+func NewTypedConfig(environment, key, value string, spec ValueSpec) (*Config, error) {
 	if err := validateEnvironment(environment); err != nil {
 		return nil, err
 	}
 	if err := validateKey(key); err != nil {
 		return nil, err
 	}
-	if err := validateValue(value); err != nil {
+	if err := validateTypedValue(value, spec); err != nil {
 		return nil, err
 	}
 
@@ -34,14 +43,22 @@ func NewConfig(environment, key, value string) (*Config, error) {
 		Key:         key,
 		Value:       value,
 		UpdatedAt:   time.Now(),
+		ValueSpec:   spec,
 	}, nil
 }
 
+// UpdateValue replaces the value, which must conform to the key's current type.
 func (c *Config) UpdateValue(value string) error {
-	if err := validateValue(value); err != nil {
+	return c.UpdateTypedValue(value, c.ValueSpec)
+}
+
+// UpdateTypedValue replaces both the value and the type of the key.
+func (c *Config) UpdateTypedValue(value string, spec ValueSpec) error {
+	if err := validateTypedValue(value, spec); err != nil {
 		return err
 	}
 	c.Value = value
+	c.ValueSpec = spec
 	c.UpdatedAt = time.Now()
 	return nil
 }
@@ -56,6 +73,16 @@ func validateEnvironment(env string) error {
 	return nil
 }
 
+// reservedKeys are the environment actions of the REST API, such as
+// /api/configs/{env}/watch, which would shadow keys of the same name.
+var reservedKeys = map[string]bool{
+	"export":   true,
+	"import":   true,
+	"resolved": true,
+	"rollback": true,
+	"watch":    true,
+}
+
 func validateKey(key string) error {
 	if key == "" {
 		return ErrInvalidKey
@@ -63,6 +90,9 @@ func validateKey(key string) error {
 	if len(key) > 255 {
 		return ErrInvalidKey
 	}
+	if reservedKeys[key] {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidKey, key)
+	}
 	return nil
 }
 
@@ -72,3 +102,13 @@ func validateValue(value string) error {
 	}
 	return nil
 }
+
+func validateTypedValue(value string, spec ValueSpec) error {
+	if err := validateValue(value); err != nil {
+		return err
+	}
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	return spec.Check(value)
+}
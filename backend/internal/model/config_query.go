@@ -0,0 +1,105 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidConfigQuery = errors.New("invalid config query: limit must be between 0 and 1000 and sort one of key, updated_at")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+)
+
+// MaxConfigPageSize bounds ConfigQuery.Limit.
+const MaxConfigPageSize = 1000
+
+type ConfigSort string
+
+const (
+	// SortByKey lists keys in ascending order.
+	SortByKey ConfigSort = "key"
+	// SortByUpdatedAt lists the most recently updated keys first.
+	SortByUpdatedAt ConfigSort = "updated_at"
+)
+
+// ConfigQuery selects a page of the configs of an environment. Empty fields
+// match everything and a zero Limit returns all matches.
+type ConfigQuery struct {
+	Prefix string
+	// Tree limits the results to the key Tree and the keys nested below it.
+	Tree string
+	// Search matches a substring of the key or, for keys that are not
+	// secret, of the value, ignoring case.
+	Search       string
+	UpdatedSince time.Time
+	Sort         ConfigSort
+	// After continues from the last config of the previous page.
+	After *ConfigCursor
+	Limit int
+	// AllowedPrefixes, when set, limits the results to keys starting with
+	// one of them. It narrows the query to the grants of the caller.
+	AllowedPrefixes []string
+}
+
+func (q ConfigQuery) Validate() error {
+	if q.Limit < 0 || q.Limit > MaxConfigPageSize {
+		return ErrInvalidConfigQuery
+	}
+	if q.Sort != "" && q.Sort != SortByKey && q.Sort != SortByUpdatedAt {
+		return ErrInvalidConfigQuery
+	}
+	if q.After != nil && q.After.Sort != q.SortOrDefault() {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (q ConfigQuery) SortOrDefault() ConfigSort {
+	if q.Sort == "" {
+		return SortByKey
+	}
+	return q.Sort
+}
+
+// ConfigCursor is the position of the last config of a page. It is passed to
+// clients as an opaque string.
+type ConfigCursor struct {
+	Sort      ConfigSort `json:"s"`
+	Key       string     `json:"k"`
+	UpdatedAt time.Time  `json:"t,omitempty"`
+}
+
+func CursorAfter(config *Config, sort ConfigSort) *ConfigCursor {
+	cursor := &ConfigCursor{Sort: sort, Key: config.Key}
+	if sort == SortByUpdatedAt {
+		cursor.UpdatedAt = config.UpdatedAt
+	}
+	return cursor
+}
+
+func (c *ConfigCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(raw string) (*ConfigCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor ConfigCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Key == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// ConfigPage is a page of configs; Total counts every match of the query
+// regardless of the page.
+type ConfigPage struct {
+	Configs    []*Config
+	Total      int
+	NextCursor *ConfigCursor
+}
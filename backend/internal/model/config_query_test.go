@@ -0,0 +1,51 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ConfigQuery
+		wantErr error
+	}{
+		{name: "empty", query: ConfigQuery{}},
+		{name: "page by updated_at", query: ConfigQuery{Sort: SortByUpdatedAt, Limit: 50, After: &ConfigCursor{Sort: SortByUpdatedAt, Key: "a"}}},
+		{name: "cursor with default sort", query: ConfigQuery{After: &ConfigCursor{Sort: SortByKey, Key: "a"}}},
+		{name: "negative limit", query: ConfigQuery{Limit: -1}, wantErr: ErrInvalidConfigQuery},
+		{name: "limit too large", query: ConfigQuery{Limit: MaxConfigPageSize + 1}, wantErr: ErrInvalidConfigQuery},
+		{name: "unknown sort", query: ConfigQuery{Sort: "value"}, wantErr: ErrInvalidConfigQuery},
+		{name: "cursor of another sort", query: ConfigQuery{Sort: SortByUpdatedAt, After: &ConfigCursor{Sort: SortByKey, Key: "a"}}, wantErr: ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigCursor_RoundTrip(t *testing.T) {
+	updatedAt := time.Date(2026, 6, 9, 10, 0, 0, 123456000, time.UTC)
+	config := &Config{Key: "db.host", UpdatedAt: updatedAt}
+
+	for _, sort := range []ConfigSort{SortByKey, SortByUpdatedAt} {
+		cursor, err := DecodeCursor(CursorAfter(config, sort).Encode())
+		if err != nil || cursor.Sort != sort || cursor.Key != "db.host" {
+			t.Fatalf("DecodeCursor() = %#v, %v", cursor, err)
+		}
+		if sort == SortByUpdatedAt && !cursor.UpdatedAt.Equal(updatedAt) {
+			t.Fatalf("updated_at = %v, want %v", cursor.UpdatedAt, updatedAt)
+		}
+	}
+
+	for _, raw := range []string{"", "not base64!", "bnVsbA"} {
+		if _, err := DecodeCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("DecodeCursor(%q) error = %v, want %v", raw, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -52,6 +53,21 @@ func TestNewConfig(t *testing.T) {
 			wantErr:     true,
 			errType:     ErrInvalidKey,
 		},
+		{
+			name:        "reserved key",
+			environment: "prod",
+			key:         "watch",
+			value:       "value",
+			wantErr:     true,
+			errType:     ErrInvalidKey,
+		},
+		{
+			name:        "key with a reserved prefix",
+			environment: "prod",
+			key:         "watch.interval",
+			value:       "value",
+			wantErr:     false,
+		},
 		{
 			name:        "too long value",
 			environment: "prod",
@@ -70,7 +86,7 @@ func TestNewConfig(t *testing.T) {
 					t.Errorf("expected error but got none")
 					return
 				}
-				if err != tt.errType {
+				if !errors.Is(err, tt.errType) {
 					t.Errorf("expected error %v, got %v", tt.errType, err)
 				}
 				if config != nil {
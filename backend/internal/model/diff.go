@@ -0,0 +1,179 @@
+package model
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"time"
+)
+
+var ErrInvalidFilter = errors.New("invalid key filter")
+
+// KeyFilter selects keys by glob pattern in path.Match syntax. An empty
+// Include selects every key; Exclude takes precedence over Include.
+type KeyFilter struct {
+	Include []string
+	Exclude []string
+}
+
+func (f KeyFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return ErrInvalidFilter
+		}
+	}
+	return nil
+}
+
+func (f KeyFilter) Match(key string) bool {
+	for _, pattern := range f.Exclude {
+		if matched, _ := path.Match(pattern, key); matched {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+type KeyChange struct {
+	Key      string    `json:"key"`
+	OldValue string    `json:"old_value,omitempty"`
+	NewValue string    `json:"new_value,omitempty"`
+	Type     ValueType `json:"type,omitempty"`
+}
+
+// ConfigDiff lists what has to change for a target environment to match a
+// source, each slice sorted by key.
+type ConfigDiff struct {
+	Added   []KeyChange `json:"added"`
+	Changed []KeyChange `json:"changed"`
+	Removed []KeyChange `json:"removed"`
+}
+
+func (d ConfigDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// DiffConfigs compares the keys accepted by filter. A key counts as changed
+// when its value differs or when the source declares a different type; an
+// untyped source key does not strip the type from the target. Secret values
+// are compared in plaintext but reported masked.
+func DiffConfigs(source, target []*Config, filter KeyFilter) ConfigDiff {
+	diff := ConfigDiff{Added: []KeyChange{}, Changed: []KeyChange{}, Removed: []KeyChange{}}
+
+	current := make(map[string]*Config, len(target))
+	for _, config := range target {
+		if filter.Match(config.Key) {
+			current[config.Key] = config
+		}
+	}
+
+	for _, config := range source {
+		if !filter.Match(config.Key) {
+			continue
+		}
+		existing, ok := current[config.Key]
+		delete(current, config.Key)
+
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, KeyChange{Key: config.Key, NewValue: config.Masked().Value, Type: config.Type})
+		case existing.Value != config.Value || (!config.ValueSpec.IsZero() && !config.ValueSpec.Equal(existing.ValueSpec)):
+			diff.Changed = append(diff.Changed, KeyChange{
+				Key:      config.Key,
+				OldValue: existing.Masked().Value,
+				NewValue: config.Masked().Value,
+				Type:     config.Type,
+			})
+		}
+	}
+	for _, config := range current {
+		diff.Removed = append(diff.Removed, KeyChange{Key: config.Key, OldValue: config.Masked().Value, Type: config.Type})
+	}
+
+	for _, changes := range [][]KeyChange{diff.Added, diff.Changed, diff.Removed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	}
+	return diff
+}
+
+// Promotion is the outcome of making Target match Source. Results are only
+// set when the diff was applied.
+type Promotion struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	DryRun bool   `json:"dry_run"`
+	ConfigDiff
+	Results []BatchResult `json:"results,omitempty"`
+}
+
+// EnvironmentRef names an environment, optionally as it was at AsOf.
+type EnvironmentRef struct {
+	Name string
+	AsOf time.Time
+}
+
+func (r EnvironmentRef) String() string {
+	if r.AsOf.IsZero() {
+		return r.Name
+	}
+	return r.Name + "@" + r.AsOf.Format(time.RFC3339)
+}
+
+type ConfigChange struct {
+	Key   string  `json:"key"`
+	Left  *Config `json:"left"`
+	Right *Config `json:"right"`
+}
+
+// EnvironmentDiff compares two environments key by key. Unlike ConfigDiff it
+// is symmetric: a key is changed when its value or type differs. Secret
+// values are masked.
+type EnvironmentDiff struct {
+	Left        string         `json:"left"`
+	Right       string         `json:"right"`
+	OnlyInLeft  []*Config      `json:"only_in_left"`
+	OnlyInRight []*Config      `json:"only_in_right"`
+	Changed     []ConfigChange `json:"changed"`
+}
+
+func CompareConfigs(left, right EnvironmentRef, leftConfigs, rightConfigs []*Config) *EnvironmentDiff {
+	diff := &EnvironmentDiff{
+		Left:        left.String(),
+		Right:       right.String(),
+		OnlyInLeft:  []*Config{},
+		OnlyInRight: []*Config{},
+		Changed:     []ConfigChange{},
+	}
+
+	remaining := make(map[string]*Config, len(rightConfigs))
+	for _, config := range rightConfigs {
+		remaining[config.Key] = config
+	}
+	for _, config := range leftConfigs {
+		other, ok := remaining[config.Key]
+		delete(remaining, config.Key)
+
+		switch {
+		case !ok:
+			diff.OnlyInLeft = append(diff.OnlyInLeft, config.Masked())
+		case other.Value != config.Value || !other.ValueSpec.Equal(config.ValueSpec):
+			diff.Changed = append(diff.Changed, ConfigChange{Key: config.Key, Left: config.Masked(), Right: other.Masked()})
+		}
+	}
+	for _, config := range remaining {
+		diff.OnlyInRight = append(diff.OnlyInRight, config.Masked())
+	}
+
+	sort.Slice(diff.OnlyInLeft, func(i, j int) bool { return diff.OnlyInLeft[i].Key < diff.OnlyInLeft[j].Key })
+	sort.Slice(diff.OnlyInRight, func(i, j int) bool { return diff.OnlyInRight[i].Key < diff.OnlyInRight[j].Key })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+	return diff
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeyFilter_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter KeyFilter
+		key    string
+		want   bool
+	}{
+		{name: "empty filter", filter: KeyFilter{}, key: "db.host", want: true},
+		{name: "include match", filter: KeyFilter{Include: []string{"db.*"}}, key: "db.host", want: true},
+		{name: "include miss", filter: KeyFilter{Include: []string{"db.*"}}, key: "cache.ttl", want: false},
+		{name: "exclude wins", filter: KeyFilter{Include: []string{"db.*"}, Exclude: []string{"db.password"}}, key: "db.password", want: false},
+		{name: "exclude only", filter: KeyFilter{Exclude: []string{"*.secret"}}, key: "cache.ttl", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.key); got != tt.want {
+				t.Fatalf("Match(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	if err := (KeyFilter{Include: []string{"["}}).Validate(); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidFilter)
+	}
+	if err := (KeyFilter{Include: []string{"db.*"}, Exclude: []string{"db.[ab]"}}).Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	source := []*Config{
+		{Key: "db.host", Value: "db-2"},
+		{Key: "db.port", Value: "5432"},
+		{Key: "db.pool", Value: "10", ValueSpec: ValueSpec{Type: TypeInt}},
+		{Key: "feature.new", Value: "true"},
+		{Key: "local.only", Value: "x"},
+	}
+	target := []*Config{
+		{Key: "db.host", Value: "db-1"},
+		{Key: "db.port", Value: "5432", ValueSpec: ValueSpec{Type: TypeInt}},
+		{Key: "db.pool", Value: "10"},
+		{Key: "feature.old", Value: "false"},
+		{Key: "local.other", Value: "y"},
+	}
+
+	diff := DiffConfigs(source, target, KeyFilter{Exclude: []string{"local.*"}})
+
+	if len(diff.Added) != 1 || diff.Added[0].Key != "feature.new" || diff.Added[0].NewValue != "true" {
+		t.Fatalf("Added = %#v", diff.Added)
+	}
+	if len(diff.Changed) != 2 || diff.Changed[0].Key != "db.host" || diff.Changed[0].OldValue != "db-1" || diff.Changed[1].Key != "db.pool" {
+		t.Fatalf("Changed = %#v", diff.Changed)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "feature.old" {
+		t.Fatalf("Removed = %#v", diff.Removed)
+	}
+
+	if diff := DiffConfigs(source, source, KeyFilter{}); !diff.IsEmpty() {
+		t.Fatalf("DiffConfigs() of identical environments = %#v", diff)
+	}
+}
+
+func TestCompareConfigs(t *testing.T) {
+	asOf := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	left := []*Config{
+		{Key: "b", Value: "1"},
+		{Key: "a", Value: "left"},
+		{Key: "same", Value: "x"},
+		{Key: "typed", Value: "5"},
+	}
+	right := []*Config{
+		{Key: "c", Value: "2"},
+		{Key: "a", Value: "right"},
+		{Key: "same", Value: "x"},
+		{Key: "typed", Value: "5", ValueSpec: ValueSpec{Type: TypeInt}},
+	}
+
+	diff := CompareConfigs(EnvironmentRef{Name: "staging"}, EnvironmentRef{Name: "prod", AsOf: asOf}, left, right)
+
+	if diff.Left != "staging" || diff.Right != "prod@2026-06-01T12:00:00Z" {
+		t.Fatalf("sides = %q, %q", diff.Left, diff.Right)
+	}
+	if len(diff.OnlyInLeft) != 1 || diff.OnlyInLeft[0].Key != "b" {
+		t.Fatalf("OnlyInLeft = %#v", diff.OnlyInLeft)
+	}
+	if len(diff.OnlyInRight) != 1 || diff.OnlyInRight[0].Key != "c" {
+		t.Fatalf("OnlyInRight = %#v", diff.OnlyInRight)
+	}
+	if len(diff.Changed) != 2 || diff.Changed[0].Key != "a" || diff.Changed[0].Right.Value != "right" || diff.Changed[1].Key != "typed" {
+		t.Fatalf("Changed = %#v", diff.Changed)
+	}
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrInvalidParent          = errors.New("invalid parent environment")
+	ErrInvalidEnvironmentName = errors.New("invalid environment name: use 1-63 lowercase letters, digits, '.', '-' or '_', starting with a letter or digit")
+	ErrInvalidAttributes      = errors.New("invalid environment attributes")
+)
+
+var environmentNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// EnvironmentAttributes are the user-editable fields of an environment.
+type EnvironmentAttributes struct {
+	Parent      string `json:"parent,omitempty"`
+	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Protected   bool   `json:"protected"`
+}
+
+// Environment is a named set of configs. Keys missing from an environment
+// are inherited from its parent chain when configs are resolved.
+type Environment struct {
+	Name string `json:"name"`
+	EnvironmentAttributes
+	KeyCount  int       `json:"key_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewEnvironment(name string, attributes EnvironmentAttributes) (*Environment, error) {
+	if !environmentNamePattern.MatchString(name) {
+		return nil, ErrInvalidEnvironmentName
+	}
+	if err := validateAttributes(name, attributes); err != nil {
+		return nil, err
+	}
+
+	return &Environment{
+		Name:                  name,
+		EnvironmentAttributes: attributes,
+		CreatedAt:             time.Now(),
+	}, nil
+}
+
+// Update replaces the attributes. The name is not re-validated so that
+// environments created before the naming rules existed stay editable.
+func (e *Environment) Update(attributes EnvironmentAttributes) error {
+	if err := validateAttributes(e.Name, attributes); err != nil {
+		return err
+	}
+	e.EnvironmentAttributes = attributes
+	return nil
+}
+
+// ResolvedConfig is a config as seen through inheritance: Environment is the
+// requested environment and Source is the environment the value came from.
+type ResolvedConfig struct {
+	Config
+	Source string `json:"source"`
+}
+
+func validateAttributes(name string, attributes EnvironmentAttributes) error {
+	if err := validateParent(name, attributes.Parent); err != nil {
+		return err
+	}
+	if len(attributes.Description) > 1000 || len(attributes.Owner) > 255 {
+		return ErrInvalidAttributes
+	}
+	return nil
+}
+
+func validateParent(name, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	if parent == name {
+		return ErrInvalidParent
+	}
+	if err := validateEnvironment(parent); err != nil {
+		return ErrInvalidParent
+	}
+	return nil
+}
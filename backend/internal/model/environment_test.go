@@ -0,0 +1,53 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		attributes  EnvironmentAttributes
+		wantErr     error
+	}{
+		{name: "without parent", environment: "base"},
+		{name: "with metadata", environment: "production", attributes: EnvironmentAttributes{Parent: "base", Description: "live traffic", Owner: "platform", Protected: true}},
+		{name: "digits and separators", environment: "eu-west-1.prod_v2"},
+		{name: "empty name", environment: "", wantErr: ErrInvalidEnvironmentName},
+		{name: "uppercase", environment: "Prod", wantErr: ErrInvalidEnvironmentName},
+		{name: "trailing space", environment: "prod ", wantErr: ErrInvalidEnvironmentName},
+		{name: "leading dash", environment: "-prod", wantErr: ErrInvalidEnvironmentName},
+		{name: "too long", environment: strings.Repeat("a", 64), wantErr: ErrInvalidEnvironmentName},
+		{name: "own parent", environment: "base", attributes: EnvironmentAttributes{Parent: "base"}, wantErr: ErrInvalidParent},
+		{name: "too long parent", environment: "base", attributes: EnvironmentAttributes{Parent: strings.Repeat("a", 101)}, wantErr: ErrInvalidParent},
+		{name: "too long description", environment: "base", attributes: EnvironmentAttributes{Description: strings.Repeat("a", 1001)}, wantErr: ErrInvalidAttributes},
+		{name: "too long owner", environment: "base", attributes: EnvironmentAttributes{Owner: strings.Repeat("a", 256)}, wantErr: ErrInvalidAttributes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment, err := NewEnvironment(tt.environment, tt.attributes)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewEnvironment() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (environment.Name != tt.environment || environment.EnvironmentAttributes != tt.attributes || environment.CreatedAt.IsZero()) {
+				t.Fatalf("NewEnvironment() = %#v", environment)
+			}
+		})
+	}
+}
+
+func TestEnvironmentUpdate(t *testing.T) {
+	environment := &Environment{Name: "Legacy Env"}
+
+	if err := environment.Update(EnvironmentAttributes{Parent: "Legacy Env"}); !errors.Is(err, ErrInvalidParent) {
+		t.Fatalf("Update() error = %v, want %v", err, ErrInvalidParent)
+	}
+	attributes := EnvironmentAttributes{Parent: "base", Owner: "team"}
+	if err := environment.Update(attributes); err != nil || environment.EnvironmentAttributes != attributes {
+		t.Fatalf("Update() = %v, environment %#v", err, environment)
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import "errors"
+
+var ErrInvalidImportMode = errors.New("invalid import mode: use merge, replace or skip-existing")
+
+// ImportMode decides what happens to keys that already exist.
+type ImportMode string
+
+const (
+	// ImportMerge creates new keys and overwrites existing ones.
+	ImportMerge ImportMode = "merge"
+	// ImportReplace works like merge and also deletes keys missing from
+	// the imported document.
+	ImportReplace ImportMode = "replace"
+	// ImportSkipExisting only creates keys that do not exist yet.
+	ImportSkipExisting ImportMode = "skip-existing"
+)
+
+func (m ImportMode) Validate() error {
+	switch m {
+	case ImportMerge, ImportReplace, ImportSkipExisting:
+		return nil
+	default:
+		return ErrInvalidImportMode
+	}
+}
+
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// ImportReport lists the keys per outcome. Masked keys hold SecretMask in
+// the document but are not stored secrets, so they were left alone. Results
+// are only set when the import failed and explain which operation caused it.
+type ImportReport struct {
+	Environment string        `json:"env"`
+	Mode        ImportMode    `json:"mode"`
+	DryRun      bool          `json:"dry_run"`
+	Created     []string      `json:"created"`
+	Updated     []string      `json:"updated"`
+	Deleted     []string      `json:"deleted"`
+	Unchanged   []string      `json:"unchanged"`
+	Skipped     []string      `json:"skipped"`
+	Masked      []string      `json:"masked"`
+	Results     []BatchResult `json:"results,omitempty"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+	OperationUpsert Operation = "upsert"
+)
+
+// Revision is an immutable record of a single write to a config key.
+// Revision numbers come from one sequence shared by all keys, so they also
+// order changes across an environment.
+type Revision struct {
+	Revision    int64     `json:"revision"`
+	Environment string    `json:"env"`
+	Key         string    `json:"key"`
+	Value       string    `json:"value"`
+	Operation   Operation `json:"operation"`
+	Actor       string    `json:"actor"`
+	CreatedAt   time.Time `json:"created_at"`
+	// ValueSpec is the type of the key right after this revision, kept so
+	// that restoring a deleted key restores its type too.
+	ValueSpec
+}
+
+// Config returns the state of the key right after this revision was written.
+func (r *Revision) Config() *Config {
+	return &Config{
+		Environment: r.Environment,
+		Key:         r.Key,
+		Value:       r.Value,
+		UpdatedAt:   r.CreatedAt,
+		UpdatedBy:   r.Actor,
+		Revision:    r.Revision,
+		ValueSpec:   r.ValueSpec,
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevision_Config(t *testing.T) {
+	createdAt := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	revision := &Revision{
+		Revision:    42,
+		Environment: "prod",
+		Key:         "key",
+		Value:       "value",
+		Operation:   OperationUpdate,
+		Actor:       "alice",
+		CreatedAt:   createdAt,
+	}
+
+	config := revision.Config()
+	if config.Environment != "prod" || config.Key != "key" || config.Value != "value" {
+		t.Fatalf("Config() = %#v", config)
+	}
+	if config.Revision != 42 || config.UpdatedBy != "alice" || !config.UpdatedAt.Equal(createdAt) {
+		t.Fatalf("Config() = %#v", config)
+	}
+}
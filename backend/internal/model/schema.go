@@ -0,0 +1,244 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema supported for json values:
+// type, enum, const, properties, required, additionalProperties, items,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength,
+// maxLength, pattern, minItems and maxItems. Other keywords are ignored.
+type jsonSchema struct {
+	types                []string
+	enum                 []any
+	constValue           any
+	hasConst             bool
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	noAdditional         bool
+	items                *jsonSchema
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minItems             *int
+	maxItems             *int
+}
+
+type rawSchema struct {
+	Type                 json.RawMessage            `json:"type"`
+	Enum                 []any                      `json:"enum"`
+	Const                json.RawMessage            `json:"const"`
+	Properties           map[string]json.RawMessage `json:"properties"`
+	Required             []string                   `json:"required"`
+	AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	Items                json.RawMessage            `json:"items"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+	ExclusiveMinimum     *float64                   `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64                   `json:"exclusiveMaximum"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	Pattern              string                     `json:"pattern"`
+	MinItems             *int                       `json:"minItems"`
+	MaxItems             *int                       `json:"maxItems"`
+}
+
+var schemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+func compileSchema(data json.RawMessage) (*jsonSchema, error) {
+	if string(data) == "true" {
+		return &jsonSchema{}, nil
+	}
+
+	var raw rawSchema
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("schema must be a JSON object: %v", err)
+	}
+
+	schema := &jsonSchema{
+		enum:             raw.Enum,
+		required:         raw.Required,
+		minimum:          raw.Minimum,
+		maximum:          raw.Maximum,
+		exclusiveMinimum: raw.ExclusiveMinimum,
+		exclusiveMaximum: raw.ExclusiveMaximum,
+		minLength:        raw.MinLength,
+		maxLength:        raw.MaxLength,
+		minItems:         raw.MinItems,
+		maxItems:         raw.MaxItems,
+	}
+
+	if len(raw.Type) > 0 {
+		if err := json.Unmarshal(raw.Type, &schema.types); err != nil {
+			var single string
+			if err := json.Unmarshal(raw.Type, &single); err != nil {
+				return nil, fmt.Errorf("type must be a string or an array of strings")
+			}
+			schema.types = []string{single}
+		}
+		for _, t := range schema.types {
+			if !slices.Contains(schemaTypes, t) {
+				return nil, fmt.Errorf("unknown type %q", t)
+			}
+		}
+	}
+
+	if len(raw.Const) > 0 {
+		if err := json.Unmarshal(raw.Const, &schema.constValue); err != nil {
+			return nil, err
+		}
+		schema.hasConst = true
+	}
+
+	if raw.Pattern != "" {
+		pattern, err := regexp.Compile(raw.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		schema.pattern = pattern
+	}
+
+	if len(raw.Properties) > 0 {
+		schema.properties = make(map[string]*jsonSchema, len(raw.Properties))
+		for name, data := range raw.Properties {
+			property, err := compileSchema(data)
+			if err != nil {
+				return nil, fmt.Errorf("properties.%s: %v", name, err)
+			}
+			schema.properties[name] = property
+		}
+	}
+
+	switch string(raw.AdditionalProperties) {
+	case "", "true":
+	case "false":
+		schema.noAdditional = true
+	default:
+		additional, err := compileSchema(raw.AdditionalProperties)
+		if err != nil {
+			return nil, fmt.Errorf("additionalProperties: %v", err)
+		}
+		schema.additionalProperties = additional
+	}
+
+	if len(raw.Items) > 0 {
+		items, err := compileSchema(raw.Items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %v", err)
+		}
+		schema.items = items
+	}
+
+	return schema, nil
+}
+
+func (s *jsonSchema) validate(path string, value any) []Violation {
+	var violations []Violation
+	fail := func(format string, args ...any) {
+		violations = append(violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return hasJSONType(value, t) }) {
+		fail("must be of type %s", strings.Join(s.types, " or "))
+		return violations
+	}
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(v any) bool { return reflect.DeepEqual(v, value) }) {
+		fail("must be one of the enumerated values")
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constValue, value) {
+		fail("must be equal to the constant value")
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			fail("must be >= %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			fail("must be <= %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			fail("must be > %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			fail("must be < %v", *s.exclusiveMaximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			fail("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			fail("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match pattern %s", s.pattern.String())
+		}
+	case []any:
+		if s.minItems != nil && len(v) < *s.minItems {
+			fail("must contain at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			fail("must contain at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				violations = append(violations, s.items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := path + "." + name
+			if property, ok := s.properties[name]; ok {
+				violations = append(violations, property.validate(child, v[name])...)
+			} else if s.noAdditional {
+				violations = append(violations, Violation{Path: child, Message: "additional property is not allowed"})
+			} else if s.additionalProperties != nil {
+				violations = append(violations, s.additionalProperties.validate(child, v[name])...)
+			}
+		}
+	}
+
+	return violations
+}
+
+func hasJSONType(value any, t string) bool {
+	switch v := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case float64:
+		return t == "number" || (t == "integer" && v == math.Trunc(v))
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
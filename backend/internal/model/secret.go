@@ -0,0 +1,25 @@
+package model
+
+// SecretMask replaces secret values in responses unless they are revealed
+// explicitly.
+const SecretMask = "********"
+
+// Masked returns the config with its value replaced by SecretMask when the
+// key is secret, and the config itself otherwise.
+func (c *Config) Masked() *Config {
+	if !c.Secret {
+		return c
+	}
+	masked := *c
+	masked.Value = SecretMask
+	return &masked
+}
+
+func (r *Revision) Masked() *Revision {
+	if !r.Secret {
+		return r
+	}
+	masked := *r
+	masked.Value = SecretMask
+	return &masked
+}
@@ -0,0 +1,55 @@
+package model
+
+import "testing"
+
+func TestMasked(t *testing.T) {
+	plain := &Config{Key: "db.host", Value: "db-1"}
+	if plain.Masked() != plain {
+		t.Fatal("Masked() of a plain config must return it unchanged")
+	}
+
+	secret := &Config{Key: "db.password", Value: "hunter2", Version: 3, ValueSpec: ValueSpec{Secret: true}}
+	masked := secret.Masked()
+	if masked.Value != SecretMask || masked.Version != 3 || !masked.Secret {
+		t.Fatalf("Masked() = %#v", masked)
+	}
+	if secret.Value != "hunter2" {
+		t.Fatalf("Masked() changed the original value to %q", secret.Value)
+	}
+
+	revision := &Revision{Revision: 7, Value: "hunter2", ValueSpec: ValueSpec{Secret: true}}
+	if masked := revision.Masked(); masked.Value != SecretMask || masked.Revision != 7 || revision.Value != "hunter2" {
+		t.Fatalf("Revision.Masked() = %#v, original %#v", masked, revision)
+	}
+	if config := revision.Config(); !config.Secret {
+		t.Fatal("Revision.Config() must keep the secret flag")
+	}
+}
+
+func TestDiffMasksSecrets(t *testing.T) {
+	source := []*Config{
+		{Key: "token", Value: "new", ValueSpec: ValueSpec{Secret: true}},
+		{Key: "api.key", Value: "k-1", ValueSpec: ValueSpec{Secret: true}},
+	}
+	target := []*Config{
+		{Key: "token", Value: "old", ValueSpec: ValueSpec{Secret: true}},
+		{Key: "legacy", Value: "l-1", ValueSpec: ValueSpec{Secret: true}},
+	}
+
+	diff := DiffConfigs(source, target, KeyFilter{})
+	if len(diff.Changed) != 1 || diff.Changed[0].OldValue != SecretMask || diff.Changed[0].NewValue != SecretMask {
+		t.Fatalf("Changed = %#v", diff.Changed)
+	}
+	if diff.Added[0].NewValue != SecretMask || diff.Removed[0].OldValue != SecretMask {
+		t.Fatalf("Added = %#v, Removed = %#v", diff.Added, diff.Removed)
+	}
+
+	compared := CompareConfigs(EnvironmentRef{Name: "a"}, EnvironmentRef{Name: "b"}, source, target)
+	if compared.OnlyInLeft[0].Value != SecretMask || compared.OnlyInRight[0].Value != SecretMask ||
+		compared.Changed[0].Left.Value != SecretMask || compared.Changed[0].Right.Value != SecretMask {
+		t.Fatalf("CompareConfigs() = %#v", compared)
+	}
+	if source[0].Value != "new" {
+		t.Fatal("diffing must not mask the input configs")
+	}
+}
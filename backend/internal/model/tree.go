@@ -0,0 +1,48 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrTreeConflict = errors.New("key is both a value and a parent of other keys")
+
+// KeySeparator splits keys into namespaces: payments.stripe.timeout lies in
+// the trees payments and payments.stripe.
+const KeySeparator = "."
+
+// InTree reports whether key is tree itself or nested below it. Every key is
+// in the empty tree.
+func InTree(key, tree string) bool {
+	return tree == "" || key == tree || strings.HasPrefix(key, tree+KeySeparator)
+}
+
+// NestConfigs renders dotted keys as nested objects with the values as
+// leaves. It fails with ErrTreeConflict when a key has a value and nested
+// keys at the same time, since the object cannot hold both.
+func NestConfigs(configs []*Config) (map[string]any, error) {
+	root := make(map[string]any)
+	for _, config := range configs {
+		segments := strings.Split(config.Key, KeySeparator)
+		node := root
+		for i, segment := range segments[:len(segments)-1] {
+			child, exists := node[segment]
+			if !exists {
+				child = make(map[string]any)
+				node[segment] = child
+			}
+			next, ok := child.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrTreeConflict, strings.Join(segments[:i+1], KeySeparator))
+			}
+			node = next
+		}
+		leaf := segments[len(segments)-1]
+		if _, exists := node[leaf]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrTreeConflict, config.Key)
+		}
+		node[leaf] = config.Value
+	}
+	return root, nil
+}
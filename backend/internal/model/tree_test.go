@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInTree(t *testing.T) {
+	tests := []struct {
+		key  string
+		tree string
+		want bool
+	}{
+		{key: "payments.stripe.timeout", tree: "", want: true},
+		{key: "payments.stripe.timeout", tree: "payments", want: true},
+		{key: "payments.stripe.timeout", tree: "payments.stripe", want: true},
+		{key: "payments.stripe", tree: "payments.stripe", want: true},
+		{key: "payments.stripe_v2.timeout", tree: "payments.stripe"},
+		{key: "payments", tree: "payments.stripe"},
+	}
+	for _, tt := range tests {
+		if got := InTree(tt.key, tt.tree); got != tt.want {
+			t.Fatalf("InTree(%q, %q) = %v, want %v", tt.key, tt.tree, got, tt.want)
+		}
+	}
+}
+
+func TestNestConfigs(t *testing.T) {
+	nested, err := NestConfigs([]*Config{
+		{Key: "payments.stripe.timeout", Value: "30s"},
+		{Key: "payments.stripe.retries", Value: "3"},
+		{Key: "payments.currency", Value: "EUR"},
+		{Key: "debug", Value: "false"},
+	})
+	want := map[string]any{
+		"payments": map[string]any{
+			"stripe":   map[string]any{"timeout": "30s", "retries": "3"},
+			"currency": "EUR",
+		},
+		"debug": "false",
+	}
+	if err != nil || !reflect.DeepEqual(nested, want) {
+		t.Fatalf("NestConfigs() = %#v, %v", nested, err)
+	}
+
+	for _, keys := range [][]string{
+		{"payments.stripe", "payments.stripe.timeout"},
+		{"payments.stripe.timeout", "payments.stripe"},
+	} {
+		configs := []*Config{{Key: keys[0], Value: "a"}, {Key: keys[1], Value: "b"}}
+		if _, err := NestConfigs(configs); !errors.Is(err, ErrTreeConflict) {
+			t.Fatalf("NestConfigs(%v) error = %v, want %v", keys, err, ErrTreeConflict)
+		}
+	}
+}
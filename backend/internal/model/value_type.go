@@ -0,0 +1,159 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSpec = errors.New("invalid value type")
+
+type ValueType string
+
+const (
+	TypeString   ValueType = "string"
+	TypeInt      ValueType = "int"
+	TypeFloat    ValueType = "float"
+	TypeBool     ValueType = "bool"
+	TypeDuration ValueType = "duration"
+	TypeURL      ValueType = "url"
+	TypeJSON     ValueType = "json"
+	TypeEnum     ValueType = "enum"
+)
+
+// ValueSpec declares the type of a config value. The zero spec means the key
+// is untyped and accepts any string, which is how keys created before typed
+// values existed behave. Secret values are encrypted at rest and masked in
+// responses.
+type ValueSpec struct {
+	Type   ValueType       `json:"type,omitempty"`
+	Enum   []string        `json:"enum,omitempty"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+	Secret bool            `json:"secret,omitempty"`
+}
+
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValueError reports why a value does not conform to its ValueSpec.
+// It matches ErrInvalidValue with errors.Is.
+type ValueError struct {
+	Type       ValueType   `json:"type"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValueError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Path + ": " + v.Message
+	}
+	return fmt.Sprintf("invalid %s value: %s", e.Type, strings.Join(messages, "; "))
+}
+
+func (e *ValueError) Unwrap() error {
+	return ErrInvalidValue
+}
+
+func (s ValueSpec) IsZero() bool {
+	return s.Type == "" && len(s.Enum) == 0 && len(s.Schema) == 0 && !s.Secret
+}
+
+func (s ValueSpec) Equal(other ValueSpec) bool {
+	return s.Type == other.Type && slices.Equal(s.Enum, other.Enum) && bytes.Equal(s.Schema, other.Schema) &&
+		s.Secret == other.Secret
+}
+
+func (s ValueSpec) Validate() error {
+	switch s.Type {
+	case "", TypeString, TypeInt, TypeFloat, TypeBool, TypeDuration, TypeURL:
+		if len(s.Enum) > 0 || len(s.Schema) > 0 {
+			return fmt.Errorf("%w: enum and schema are not allowed for type %q", ErrInvalidSpec, s.Type)
+		}
+	case TypeEnum:
+		if len(s.Enum) == 0 || len(s.Schema) > 0 {
+			return fmt.Errorf("%w: enum type requires a non-empty enum list", ErrInvalidSpec)
+		}
+		for i, value := range s.Enum {
+			if slices.Contains(s.Enum[:i], value) {
+				return fmt.Errorf("%w: duplicate enum value %q", ErrInvalidSpec, value)
+			}
+		}
+	case TypeJSON:
+		if len(s.Enum) > 0 {
+			return fmt.Errorf("%w: enum is not allowed for type %q", ErrInvalidSpec, s.Type)
+		}
+		if len(s.Schema) > 0 {
+			if _, err := compileSchema(s.Schema); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidSpec, s.Type)
+	}
+	return nil
+}
+
+// Check returns a *ValueError when value does not conform to the spec.
+func (s ValueSpec) Check(value string) error {
+	var violations []Violation
+	switch s.Type {
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			violations = rootViolation("must be an integer")
+		}
+	case TypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			violations = rootViolation("must be a number")
+		}
+	case TypeBool:
+		if value != "true" && value != "false" {
+			violations = rootViolation("must be true or false")
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			violations = rootViolation("must be a duration such as 30s or 1h30m")
+		}
+	case TypeURL:
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			violations = rootViolation("must be an absolute URL")
+		}
+	case TypeEnum:
+		if !slices.Contains(s.Enum, value) {
+			violations = rootViolation("must be one of " + strings.Join(s.Enum, ", "))
+		}
+	case TypeJSON:
+		violations = checkJSON(value, s.Schema)
+	}
+
+	if len(violations) > 0 {
+		return &ValueError{Type: s.Type, Violations: violations}
+	}
+	return nil
+}
+
+func checkJSON(value string, rawSchema json.RawMessage) []Violation {
+	var document any
+	if err := json.Unmarshal([]byte(value), &document); err != nil {
+		return rootViolation("must be valid JSON")
+	}
+	if len(rawSchema) == 0 {
+		return nil
+	}
+	schema, err := compileSchema(rawSchema)
+	if err != nil {
+		return rootViolation("schema is invalid: " + err.Error())
+	}
+	return schema.validate("$", document)
+}
+
+func rootViolation(message string) []Violation {
+	return []Violation{{Path: "$", Message: message}}
+}
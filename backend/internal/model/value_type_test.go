@@ -0,0 +1,147 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValueSpecCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    ValueSpec
+		value   string
+		wantErr bool
+	}{
+		{name: "untyped accepts anything", spec: ValueSpec{}, value: "tru"},
+		{name: "string", spec: ValueSpec{Type: TypeString}, value: "anything"},
+		{name: "int", spec: ValueSpec{Type: TypeInt}, value: "-42"},
+		{name: "int rejects float", spec: ValueSpec{Type: TypeInt}, value: "4.2", wantErr: true},
+		{name: "float", spec: ValueSpec{Type: TypeFloat}, value: "0.75"},
+		{name: "float rejects text", spec: ValueSpec{Type: TypeFloat}, value: "three", wantErr: true},
+		{name: "bool", spec: ValueSpec{Type: TypeBool}, value: "false"},
+		{name: "bool rejects typo", spec: ValueSpec{Type: TypeBool}, value: "tru", wantErr: true},
+		{name: "bool rejects numeric", spec: ValueSpec{Type: TypeBool}, value: "1", wantErr: true},
+		{name: "duration", spec: ValueSpec{Type: TypeDuration}, value: "1h30m"},
+		{name: "duration rejects missing unit", spec: ValueSpec{Type: TypeDuration}, value: "30", wantErr: true},
+		{name: "url", spec: ValueSpec{Type: TypeURL}, value: "postgres://localhost:5432/db"},
+		{name: "url rejects relative", spec: ValueSpec{Type: TypeURL}, value: "/path", wantErr: true},
+		{name: "enum", spec: ValueSpec{Type: TypeEnum, Enum: []string{"debug", "info"}}, value: "info"},
+		{name: "enum rejects unknown", spec: ValueSpec{Type: TypeEnum, Enum: []string{"debug", "info"}}, value: "trace", wantErr: true},
+		{name: "json", spec: ValueSpec{Type: TypeJSON}, value: `{"a":[1,2]}`},
+		{name: "json rejects malformed", spec: ValueSpec{Type: TypeJSON}, value: `{"a":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Check(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidValue) {
+				t.Fatalf("Check() error = %v, want it to match ErrInvalidValue", err)
+			}
+		})
+	}
+}
+
+func TestValueSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    ValueSpec
+		wantErr bool
+	}{
+		{name: "zero", spec: ValueSpec{}},
+		{name: "enum", spec: ValueSpec{Type: TypeEnum, Enum: []string{"a", "b"}}},
+		{name: "json with schema", spec: ValueSpec{Type: TypeJSON, Schema: json.RawMessage(`{"type":"object"}`)}},
+		{name: "unknown type", spec: ValueSpec{Type: "uuid"}, wantErr: true},
+		{name: "enum without values", spec: ValueSpec{Type: TypeEnum}, wantErr: true},
+		{name: "enum with duplicates", spec: ValueSpec{Type: TypeEnum, Enum: []string{"a", "a"}}, wantErr: true},
+		{name: "enum values on int", spec: ValueSpec{Type: TypeInt, Enum: []string{"1"}}, wantErr: true},
+		{name: "schema on string", spec: ValueSpec{Type: TypeString, Schema: json.RawMessage(`{}`)}, wantErr: true},
+		{name: "invalid schema", spec: ValueSpec{Type: TypeJSON, Schema: json.RawMessage(`{"type":"thing"}`)}, wantErr: true},
+		{name: "invalid schema pattern", spec: ValueSpec{Type: TypeJSON, Schema: json.RawMessage(`{"pattern":"("}`)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSpec) {
+				t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidSpec)
+			}
+		})
+	}
+}
+
+func TestValueSpecCheckJSONSchema(t *testing.T) {
+	spec := ValueSpec{Type: TypeJSON, Schema: json.RawMessage(`{
+		"type": "object",
+		"required": ["host", "port"],
+		"additionalProperties": false,
+		"properties": {
+			"host": {"type": "string", "minLength": 1},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "pattern": "^[a-z]+$"}},
+			"mode": {"enum": ["primary", "replica"]}
+		}
+	}`)}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if err := spec.Check(`{"host":"db","port":5432,"tags":["main"],"mode":"primary"}`); err != nil {
+		t.Fatalf("Check() valid document error = %v", err)
+	}
+
+	err := spec.Check(`{"port":70000.5,"tags":["ok","Bad","x"],"mode":"standby","extra":true}`)
+	var valueErr *ValueError
+	if !errors.As(err, &valueErr) {
+		t.Fatalf("Check() error = %v, want *ValueError", err)
+	}
+	want := map[string]bool{
+		"$":         false,
+		"$.port":    false,
+		"$.tags":    false,
+		"$.tags[1]": false,
+		"$.mode":    false,
+		"$.extra":   false,
+	}
+	for _, violation := range valueErr.Violations {
+		if _, ok := want[violation.Path]; !ok {
+			t.Fatalf("unexpected violation %#v", violation)
+		}
+		want[violation.Path] = true
+	}
+	for path, seen := range want {
+		if !seen {
+			t.Fatalf("missing violation for %s in %#v", path, valueErr.Violations)
+		}
+	}
+}
+
+func TestTypedConfigUpdates(t *testing.T) {
+	config, err := NewTypedConfig("prod", "enabled", "true", ValueSpec{Type: TypeBool})
+	if err != nil {
+		t.Fatalf("NewTypedConfig() error = %v", err)
+	}
+	if _, err := NewTypedConfig("prod", "enabled", "tru", ValueSpec{Type: TypeBool}); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("NewTypedConfig() error = %v, want %v", err, ErrInvalidValue)
+	}
+
+	if err := config.UpdateValue("tru"); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("UpdateValue() error = %v, want %v", err, ErrInvalidValue)
+	}
+	if config.Value != "true" {
+		t.Fatalf("rejected update changed value to %q", config.Value)
+	}
+
+	if err := config.UpdateTypedValue("0.5", ValueSpec{Type: TypeFloat}); err != nil {
+		t.Fatalf("UpdateTypedValue() error = %v", err)
+	}
+	if config.Type != TypeFloat || config.Value != "0.5" {
+		t.Fatalf("config = %#v, want float 0.5", config)
+	}
+}
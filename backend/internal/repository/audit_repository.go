@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+)
+
+// AuditRepository is append-only.
+type AuditRepository interface {
+	// AppendAudit sets the ID and creation time of the entry.
+	AppendAudit(ctx context.Context, entry *model.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
@@ -2,19 +2,47 @@ package repository
 
 import (
 	"config-service/backend/internal/model"
+	"context"
 	"errors"
+	"time"
 )
 
 var (
 	ErrConfigNotFound      = errors.New("config not found")
 	ErrConfigAlreadyExists = errors.New("config already exists")
+	ErrVersionConflict     = errors.New("config version conflict")
 )
 
 type ConfigRepository interface {
-	Create(config *model.Config) error
-	Get(environment, key string) (*model.Config, error)
-	GetAll(environment string) ([]*model.Config, error)
-	Update(config *model.Config) error
-	Delete(environment, key string) error
-	Exists(environment, key string) (bool, error)
+	EnvironmentRepository
+	AuditRepository
+
+	Create(ctx context.Context, config *model.Config) (*model.Revision, error)
+	Get(ctx context.Context, environment, key string) (*model.Config, error)
+	GetAll(ctx context.Context, environment string) ([]*model.Config, error)
+	// ListConfigs returns the configs matching query in its sort order, at
+	// most query.Limit of them unless the limit is zero.
+	ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) ([]*model.Config, error)
+	// CountConfigs counts the configs matching query, ignoring its cursor
+	// and limit.
+	CountConfigs(ctx context.Context, environment string, query model.ConfigQuery) (int, error)
+	// Update succeeds only while the stored version equals config.Version
+	// and increments the version on success.
+	Update(ctx context.Context, config *model.Config) (*model.Revision, error)
+	// Delete removes the key if its stored version equals version;
+	// a zero version deletes unconditionally.
+	Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error)
+	Exists(ctx context.Context, environment, key string) (bool, error)
+	GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error)
+	GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error)
+	GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error)
+	// GetRevisionsSince returns up to limit revisions of the environment newer
+	// than since, oldest first. Revisions of an environment must become
+	// visible in the order of their numbers, so that a revision is never
+	// committed below one a reader has already seen.
+	GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error)
+	GetRevision(ctx context.Context, revision int64) (*model.Revision, error)
+	// WithTx runs fn against a repository bound to a single transaction.
+	// The transaction is committed when fn returns nil and rolled back otherwise.
+	WithTx(ctx context.Context, fn func(repo ConfigRepository) error) error
 }
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+)
+
+var (
+	ErrEnvironmentNotFound      = errors.New("environment not found")
+	ErrEnvironmentAlreadyExists = errors.New("environment already exists")
+	ErrEnvironmentInUse         = errors.New("environment is still referenced")
+)
+
+type EnvironmentRepository interface {
+	CreateEnvironment(ctx context.Context, environment *model.Environment) error
+	// GetEnvironment and GetEnvironments fill in KeyCount.
+	GetEnvironment(ctx context.Context, name string) (*model.Environment, error)
+	GetEnvironments(ctx context.Context) ([]*model.Environment, error)
+	UpdateEnvironment(ctx context.Context, environment *model.Environment) error
+	// DeleteEnvironment fails with ErrEnvironmentInUse while configs or child
+	// environments still reference the environment.
+	DeleteEnvironment(ctx context.Context, name string) error
+}
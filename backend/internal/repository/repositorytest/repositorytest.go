@@ -0,0 +1,625 @@
+// Package repositorytest is a conformance suite that every storage driver's
+// config repository, token repository and secret store must pass.
+package repositorytest
+
+import (
+	"cmp"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Factory returns an empty repository for a single test.
+type Factory func(t *testing.T) repository.ConfigRepository
+
+// RunConfigRepository runs the suite against repositories from newRepository.
+func RunConfigRepository(t *testing.T, newRepository Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.ConfigRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"UpdateVersions", testUpdateVersions},
+		{"Delete", testDelete},
+		{"History", testHistory},
+		{"PointInTime", testPointInTime},
+		{"ListConfigs", testListConfigs},
+		{"ListConfigsByUpdatedAt", testListConfigsByUpdatedAt},
+		{"Environments", testEnvironments},
+		{"Audit", testAudit},
+		{"WithTx", testWithTx},
+		{"RevisionsCommitInOrder", testRevisionsCommitInOrder},
+		{"ConcurrentWrites", testConcurrentWrites},
+		{"CanceledContext", testCanceledContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepository(t))
+		})
+	}
+}
+
+// baseTime has no sub-microsecond part, which Postgres would drop.
+var baseTime = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func createEnvironment(t *testing.T, repo repository.ConfigRepository, name, parent string) {
+	t.Helper()
+	environment := &model.Environment{Name: name, EnvironmentAttributes: model.EnvironmentAttributes{Parent: parent}, CreatedAt: baseTime}
+	if err := repo.CreateEnvironment(context.Background(), environment); err != nil {
+		t.Fatalf("CreateEnvironment(%s) error = %v", name, err)
+	}
+}
+
+func createConfig(t *testing.T, repo repository.ConfigRepository, config *model.Config) *model.Revision {
+	t.Helper()
+	if config.UpdatedAt.IsZero() {
+		config.UpdatedAt = baseTime
+	}
+	revision, err := repo.Create(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Create(%s/%s) error = %v", config.Environment, config.Key, err)
+	}
+	return revision
+}
+
+func keys(configs []*model.Config) []string {
+	result := []string{}
+	for _, config := range configs {
+		result = append(result, config.Key)
+	}
+	return result
+}
+
+func testCreateAndGet(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+
+	config := &model.Config{
+		Environment: "dev",
+		Key:         "db.port",
+		Value:       "5432",
+		UpdatedBy:   "alice",
+		ValueSpec:   model.ValueSpec{Type: model.TypeInt},
+	}
+	revision := createConfig(t, repo, config)
+	if revision.Revision == 0 || revision.Operation != model.OperationCreate || revision.Actor != "alice" || revision.Value != "5432" || revision.CreatedAt.IsZero() {
+		t.Fatalf("Create() revision = %#v", revision)
+	}
+	if config.Revision != revision.Revision || config.Version != 1 {
+		t.Fatalf("Create() left revision %d and version %d on the config", config.Revision, config.Version)
+	}
+
+	got, err := repo.Get(ctx, "dev", "db.port")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Value != "5432" || got.Type != model.TypeInt || got.Version != 1 || got.Revision != revision.Revision || got.UpdatedBy != "alice" || !got.UpdatedAt.Equal(baseTime) {
+		t.Fatalf("Get() = %#v", got)
+	}
+	got.Value = "changed by caller"
+	if again, _ := repo.Get(ctx, "dev", "db.port"); again.Value != "5432" {
+		t.Fatal("Get() returned a config shared with the repository")
+	}
+
+	if _, err := repo.Create(ctx, &model.Config{Environment: "dev", Key: "db.port", Value: "1", UpdatedAt: baseTime}); !errors.Is(err, repository.ErrConfigAlreadyExists) {
+		t.Fatalf("Create() duplicate error = %v", err)
+	}
+	if _, err := repo.Create(ctx, &model.Config{Environment: "missing", Key: "db.port", Value: "1", UpdatedAt: baseTime}); !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("Create() in a missing environment error = %v", err)
+	}
+	if _, err := repo.Get(ctx, "dev", "missing"); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("Get() missing error = %v", err)
+	}
+	if exists, err := repo.Exists(ctx, "dev", "db.port"); err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v", exists, err)
+	}
+	if exists, err := repo.Exists(ctx, "dev", "missing"); err != nil || exists {
+		t.Fatalf("Exists() missing = %v, %v", exists, err)
+	}
+}
+
+func testUpdateVersions(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	created := createConfig(t, repo, &model.Config{Environment: "dev", Key: "mode", Value: "a"})
+
+	config := &model.Config{Environment: "dev", Key: "mode", Value: "b", UpdatedAt: baseTime.Add(time.Minute), UpdatedBy: "bob", Version: 1}
+	revision, err := repo.Update(ctx, config)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if revision.Revision <= created.Revision || revision.Operation != model.OperationUpdate || revision.Actor != "bob" || revision.Value != "b" {
+		t.Fatalf("Update() revision = %#v", revision)
+	}
+	if config.Version != 2 || config.Revision != revision.Revision {
+		t.Fatalf("Update() left version %d and revision %d on the config", config.Version, config.Revision)
+	}
+	got, err := repo.Get(ctx, "dev", "mode")
+	if err != nil || got.Value != "b" || got.Version != 2 || got.UpdatedBy != "bob" || !got.UpdatedAt.Equal(baseTime.Add(time.Minute)) {
+		t.Fatalf("Get() after Update() = %#v, %v", got, err)
+	}
+
+	stale := &model.Config{Environment: "dev", Key: "mode", Value: "c", UpdatedAt: baseTime, Version: 1}
+	if _, err := repo.Update(ctx, stale); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Update() stale version error = %v", err)
+	}
+	missing := &model.Config{Environment: "dev", Key: "missing", Value: "c", UpdatedAt: baseTime, Version: 1}
+	if _, err := repo.Update(ctx, missing); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("Update() missing error = %v", err)
+	}
+}
+
+func testDelete(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	createConfig(t, repo, &model.Config{Environment: "dev", Key: "a", Value: "1"})
+	createConfig(t, repo, &model.Config{Environment: "dev", Key: "b", Value: "2"})
+
+	if _, err := repo.Delete(ctx, "dev", "a", "carol", 2); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Delete() stale version error = %v", err)
+	}
+	revision, err := repo.Delete(ctx, "dev", "a", "carol", 1)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if revision.Operation != model.OperationDelete || revision.Actor != "carol" || revision.Value != "1" {
+		t.Fatalf("Delete() revision = %#v", revision)
+	}
+	if _, err := repo.Get(ctx, "dev", "a"); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("Get() deleted error = %v", err)
+	}
+	if _, err := repo.Delete(ctx, "dev", "b", "carol", 0); err != nil {
+		t.Fatalf("Delete() without version error = %v", err)
+	}
+	for _, version := range []int64{0, 1} {
+		if _, err := repo.Delete(ctx, "dev", "a", "carol", version); !errors.Is(err, repository.ErrConfigNotFound) {
+			t.Fatalf("Delete() missing with version %d error = %v", version, err)
+		}
+	}
+}
+
+func testHistory(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	createEnvironment(t, repo, "prod", "")
+	first := createConfig(t, repo, &model.Config{Environment: "dev", Key: "k", Value: "1"})
+	createConfig(t, repo, &model.Config{Environment: "prod", Key: "k", Value: "p"})
+	spec := model.ValueSpec{Type: model.TypeInt}
+	second, err := repo.Update(ctx, &model.Config{Environment: "dev", Key: "k", Value: "2", ValueSpec: spec, UpdatedAt: baseTime, Version: 1})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	third, err := repo.Delete(ctx, "dev", "k", "", 0)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	history, err := repo.GetHistory(ctx, "dev", "k")
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	var numbers []int64
+	for _, revision := range history {
+		numbers = append(numbers, revision.Revision)
+	}
+	if !slices.Equal(numbers, []int64{third.Revision, second.Revision, first.Revision}) {
+		t.Fatalf("GetHistory() revisions = %v, want newest first", numbers)
+	}
+	// Each revision keeps the type the key had after it, the deletion too.
+	if !history[0].ValueSpec.Equal(spec) || !history[1].ValueSpec.Equal(spec) || !history[2].ValueSpec.IsZero() {
+		t.Fatalf("GetHistory() specs = %v, %v, %v", history[0].ValueSpec, history[1].ValueSpec, history[2].ValueSpec)
+	}
+	if history, err := repo.GetHistory(ctx, "dev", "missing"); err != nil || len(history) != 0 {
+		t.Fatalf("GetHistory() missing = %v, %v", history, err)
+	}
+
+	since, err := repo.GetRevisionsSince(ctx, "dev", first.Revision, 1)
+	if err != nil || len(since) != 1 || since[0].Revision != second.Revision {
+		t.Fatalf("GetRevisionsSince() = %v, %v, want only revision %d", since, err, second.Revision)
+	}
+	if since, err := repo.GetRevisionsSince(ctx, "dev", 0, 10); err != nil || len(since) != 3 || since[0].Revision != first.Revision {
+		t.Fatalf("GetRevisionsSince() = %v, %v, want all three oldest first", since, err)
+	}
+
+	got, err := repo.GetRevision(ctx, second.Revision)
+	if err != nil || got.Value != "2" || got.Operation != model.OperationUpdate || got.Environment != "dev" || got.Type != model.TypeInt {
+		t.Fatalf("GetRevision() = %#v, %v", got, err)
+	}
+	if _, err := repo.GetRevision(ctx, third.Revision+1000); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("GetRevision() missing error = %v", err)
+	}
+}
+
+func testPointInTime(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	created := createConfig(t, repo, &model.Config{Environment: "dev", Key: "a", Value: "1"})
+	createConfig(t, repo, &model.Config{Environment: "dev", Key: "b", Value: "2"})
+	// Revision times come from the store's clock; tests only compare them
+	// with each other.
+	deleted, err := repo.Delete(ctx, "dev", "a", "", 0)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := repo.GetAt(ctx, "dev", "a", created.CreatedAt.Add(-time.Hour)); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("GetAt() before creation error = %v", err)
+	}
+	if _, err := repo.GetAt(ctx, "dev", "a", deleted.CreatedAt); !errors.Is(err, repository.ErrConfigNotFound) {
+		t.Fatalf("GetAt() after deletion error = %v", err)
+	}
+	got, err := repo.GetAt(ctx, "dev", "a", created.CreatedAt)
+	if err != nil || got.Value != "1" || got.Revision != created.Revision {
+		t.Fatalf("GetAt() = %#v, %v", got, err)
+	}
+
+	all, err := repo.GetAllAt(ctx, "dev", deleted.CreatedAt)
+	if err != nil || !slices.Equal(keys(all), []string{"b"}) {
+		t.Fatalf("GetAllAt() after deletion = %v, %v", keys(all), err)
+	}
+	if all, err := repo.GetAllAt(ctx, "dev", created.CreatedAt.Add(-time.Hour)); err != nil || len(all) != 0 {
+		t.Fatalf("GetAllAt() before creation = %v, %v", keys(all), err)
+	}
+}
+
+func testListConfigs(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	createEnvironment(t, repo, "prod", "")
+	for _, config := range []*model.Config{
+		{Environment: "dev", Key: "db.host", Value: "Primary"},
+		{Environment: "dev", Key: "db.port", Value: "5432"},
+		{Environment: "dev", Key: "db_x", Value: "literal underscore"},
+		{Environment: "dev", Key: "dbz", Value: "matches db_ only as a pattern"},
+		{Environment: "dev", Key: "api.token", Value: "primary-secret", ValueSpec: model.ValueSpec{Secret: true}},
+		{Environment: "dev", Key: "db", Value: "tree root"},
+		{Environment: "prod", Key: "db.host", Value: "other environment"},
+	} {
+		createConfig(t, repo, config)
+	}
+
+	tests := []struct {
+		name  string
+		query model.ConfigQuery
+		want  []string
+	}{
+		{name: "all by key", want: []string{"api.token", "db", "db.host", "db.port", "db_x", "dbz"}},
+		{name: "literal prefix", query: model.ConfigQuery{Prefix: "db_"}, want: []string{"db_x"}},
+		{name: "tree", query: model.ConfigQuery{Tree: "db"}, want: []string{"db", "db.host", "db.port"}},
+		{name: "search ignores case and secret values", query: model.ConfigQuery{Search: "PRIMARY"}, want: []string{"db.host"}},
+		{name: "search matches keys", query: model.ConfigQuery{Search: "TOKEN"}, want: []string{"api.token"}},
+		{name: "allowed prefixes", query: model.ConfigQuery{AllowedPrefixes: []string{"api.", "db."}}, want: []string{"api.token", "db.host", "db.port"}},
+		{name: "no allowed prefixes", query: model.ConfigQuery{AllowedPrefixes: []string{}}, want: []string{}},
+		{name: "limit", query: model.ConfigQuery{Limit: 2}, want: []string{"api.token", "db"}},
+		{name: "after cursor", query: model.ConfigQuery{After: &model.ConfigCursor{Sort: model.SortByKey, Key: "db.port"}, Limit: 10}, want: []string{"db_x", "dbz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, err := repo.ListConfigs(ctx, "dev", tt.query)
+			if err != nil || !slices.Equal(keys(configs), tt.want) {
+				t.Fatalf("ListConfigs() = %v, %v, want %v", keys(configs), err, tt.want)
+			}
+
+			count, err := repo.CountConfigs(ctx, "dev", tt.query)
+			want := len(tt.want)
+			if tt.query.Limit > 0 || tt.query.After != nil {
+				want = 6
+			}
+			if err != nil || count != want {
+				t.Fatalf("CountConfigs() = %d, %v, want %d ignoring the page", count, err, want)
+			}
+		})
+	}
+
+	all, err := repo.GetAll(ctx, "dev")
+	if err != nil || len(all) != 6 || all[0].Key != "api.token" || !all[0].Secret {
+		t.Fatalf("GetAll() = %v, %v", keys(all), err)
+	}
+	if all, err := repo.GetAll(ctx, "missing"); err != nil || len(all) != 0 {
+		t.Fatalf("GetAll() missing environment = %v, %v", keys(all), err)
+	}
+}
+
+func testListConfigsByUpdatedAt(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	for _, config := range []*model.Config{
+		{Environment: "dev", Key: "old", Value: "1", UpdatedAt: baseTime},
+		{Environment: "dev", Key: "b", Value: "2", UpdatedAt: baseTime.Add(time.Hour)},
+		{Environment: "dev", Key: "a", Value: "3", UpdatedAt: baseTime.Add(time.Hour)},
+		{Environment: "dev", Key: "new", Value: "4", UpdatedAt: baseTime.Add(2 * time.Hour)},
+	} {
+		createConfig(t, repo, config)
+	}
+
+	query := model.ConfigQuery{Sort: model.SortByUpdatedAt}
+	configs, err := repo.ListConfigs(ctx, "dev", query)
+	if err != nil || !slices.Equal(keys(configs), []string{"new", "a", "b", "old"}) {
+		t.Fatalf("ListConfigs() = %v, %v, want newest first and keys breaking ties", keys(configs), err)
+	}
+
+	query.After = model.CursorAfter(configs[1], model.SortByUpdatedAt)
+	query.Limit = 2
+	if page, err := repo.ListConfigs(ctx, "dev", query); err != nil || !slices.Equal(keys(page), []string{"b", "old"}) {
+		t.Fatalf("ListConfigs() after %s = %v, %v", configs[1].Key, keys(page), err)
+	}
+
+	since := model.ConfigQuery{UpdatedSince: baseTime.Add(time.Hour)}
+	if count, err := repo.CountConfigs(ctx, "dev", since); err != nil || count != 3 {
+		t.Fatalf("CountConfigs() updated since = %d, %v, want 3", count, err)
+	}
+}
+
+func testEnvironments(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "base", "")
+	createEnvironment(t, repo, "prod", "base")
+	createConfig(t, repo, &model.Config{Environment: "prod", Key: "a", Value: "1"})
+	createConfig(t, repo, &model.Config{Environment: "prod", Key: "b", Value: "2"})
+
+	if err := repo.CreateEnvironment(ctx, &model.Environment{Name: "prod", CreatedAt: baseTime}); !errors.Is(err, repository.ErrEnvironmentAlreadyExists) {
+		t.Fatalf("CreateEnvironment() duplicate error = %v", err)
+	}
+	orphan := &model.Environment{Name: "orphan", EnvironmentAttributes: model.EnvironmentAttributes{Parent: "missing"}, CreatedAt: baseTime}
+	if err := repo.CreateEnvironment(ctx, orphan); !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("CreateEnvironment() with a missing parent error = %v", err)
+	}
+
+	prod, err := repo.GetEnvironment(ctx, "prod")
+	if err != nil || prod.Parent != "base" || prod.KeyCount != 2 || !prod.CreatedAt.Equal(baseTime) {
+		t.Fatalf("GetEnvironment() = %#v, %v", prod, err)
+	}
+	if _, err := repo.GetEnvironment(ctx, "missing"); !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("GetEnvironment() missing error = %v", err)
+	}
+	environments, err := repo.GetEnvironments(ctx)
+	if err != nil || len(environments) != 2 || environments[0].Name != "base" || environments[0].KeyCount != 0 || environments[1].KeyCount != 2 {
+		t.Fatalf("GetEnvironments() = %v, %v", environments, err)
+	}
+
+	prod.EnvironmentAttributes = model.EnvironmentAttributes{Description: "production", Owner: "ops", Protected: true}
+	if err := repo.UpdateEnvironment(ctx, prod); err != nil {
+		t.Fatalf("UpdateEnvironment() error = %v", err)
+	}
+	if got, _ := repo.GetEnvironment(ctx, "prod"); got.Parent != "" || got.Description != "production" || got.Owner != "ops" || !got.Protected {
+		t.Fatalf("GetEnvironment() after update = %#v", got)
+	}
+	prod.Parent = "missing"
+	if err := repo.UpdateEnvironment(ctx, prod); !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("UpdateEnvironment() with a missing parent error = %v", err)
+	}
+	if err := repo.UpdateEnvironment(ctx, &model.Environment{Name: "missing"}); !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("UpdateEnvironment() missing error = %v", err)
+	}
+
+	createEnvironment(t, repo, "child", "base")
+	if err := repo.DeleteEnvironment(ctx, "base"); !errors.Is(err, repository.ErrEnvironmentInUse) {
+		t.Fatalf("DeleteEnvironment() with a child error = %v", err)
+	}
+	if err := repo.DeleteEnvironment(ctx, "prod"); !errors.Is(err, repository.ErrEnvironmentInUse) {
+		t.Fatalf("DeleteEnvironment() with configs error = %v", err)
+	}
+	if err := repo.DeleteEnvironment(ctx, "child"); err != nil {
+		t.Fatalf("DeleteEnvironment() error = %v", err)
+	}
+	if err := repo.DeleteEnvironment(ctx, "child"); !errors.Is(err, repository.ErrEnvironmentNotFound) {
+		t.Fatalf("DeleteEnvironment() missing error = %v", err)
+	}
+}
+
+func testAudit(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	value := "v"
+	var ids []int64
+	for _, entry := range []*model.AuditEntry{
+		{Actor: "alice", Operation: model.AuditConfigCreate, Environment: "dev", Key: "a", NewValue: &value},
+		{Actor: "bob", Operation: model.AuditConfigDelete, Environment: "dev", Key: "a", OldValue: &value},
+		{Actor: "alice", Operation: model.AuditEnvironmentCreate, Environment: "prod", Secret: true},
+	} {
+		if err := repo.AppendAudit(ctx, entry); err != nil {
+			t.Fatalf("AppendAudit() error = %v", err)
+		}
+		if entry.ID == 0 || entry.CreatedAt.IsZero() {
+			t.Fatalf("AppendAudit() left ID %d and time %v", entry.ID, entry.CreatedAt)
+		}
+		ids = append(ids, entry.ID)
+	}
+	first, err := repo.GetAuditEntries(ctx, model.AuditFilter{Limit: 1})
+	if err != nil || len(first) != 1 {
+		t.Fatalf("GetAuditEntries() = %v, %v", first, err)
+	}
+	from := first[0].CreatedAt
+
+	tests := []struct {
+		name   string
+		filter model.AuditFilter
+		want   []int64
+	}{
+		{name: "newest first", filter: model.AuditFilter{Limit: 10}, want: []int64{ids[2], ids[1], ids[0]}},
+		{name: "limit", filter: model.AuditFilter{Limit: 2}, want: []int64{ids[2], ids[1]}},
+		{name: "cursor", filter: model.AuditFilter{Cursor: ids[2], Limit: 10}, want: []int64{ids[1], ids[0]}},
+		{name: "environment and key", filter: model.AuditFilter{Environment: "dev", Key: "a", Limit: 10}, want: []int64{ids[1], ids[0]}},
+		{name: "actor", filter: model.AuditFilter{Actor: "alice", Limit: 10}, want: []int64{ids[2], ids[0]}},
+		{name: "to", filter: model.AuditFilter{To: from.Add(-time.Hour), Limit: 10}, want: nil},
+		{name: "from is inclusive", filter: model.AuditFilter{From: from, Environment: "prod", Limit: 10}, want: []int64{ids[2]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := repo.GetAuditEntries(ctx, tt.filter)
+			var got []int64
+			for _, entry := range entries {
+				got = append(got, entry.ID)
+			}
+			if err != nil || !slices.Equal(got, tt.want) {
+				t.Fatalf("GetAuditEntries() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	entries, _ := repo.GetAuditEntries(ctx, model.AuditFilter{Limit: 10})
+	if entries[1].OldValue == nil || *entries[1].OldValue != "v" || entries[1].NewValue != nil || !entries[0].Secret {
+		t.Fatalf("GetAuditEntries() did not keep values: %#v", entries)
+	}
+}
+
+func testWithTx(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	errRollback := errors.New("rollback")
+
+	err := repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		createConfig(t, tx, &model.Config{Environment: "dev", Key: "rolled.back", Value: "1"})
+		if _, err := tx.Get(ctx, "dev", "rolled.back"); err != nil {
+			t.Fatalf("Get() in transaction error = %v", err)
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want the error of fn", err)
+	}
+	if exists, err := repo.Exists(ctx, "dev", "rolled.back"); err != nil || exists {
+		t.Fatalf("Exists() after rollback = %v, %v", exists, err)
+	}
+
+	err = repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+		createConfig(t, tx, &model.Config{Environment: "dev", Key: "committed", Value: "1"})
+		return tx.AppendAudit(ctx, &model.AuditEntry{Operation: model.AuditConfigCreate, Environment: "dev", Key: "committed"})
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if exists, err := repo.Exists(ctx, "dev", "committed"); err != nil || !exists {
+		t.Fatalf("Exists() after commit = %v, %v", exists, err)
+	}
+	if entries, err := repo.GetAuditEntries(ctx, model.AuditFilter{Key: "committed", Limit: 10}); err != nil || len(entries) != 1 {
+		t.Fatalf("GetAuditEntries() after commit = %v, %v", entries, err)
+	}
+}
+
+// testRevisionsCommitInOrder interleaves a transaction with a write to the
+// same environment. Watchers follow revision > since, so a revision must not
+// become visible below one that has already been read.
+func testRevisionsCommitInOrder(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+
+	written := make(chan struct{})
+	release := make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+			_, err := tx.Create(ctx, &model.Config{Environment: "dev", Key: "in.tx", Value: "1", UpdatedAt: baseTime})
+			close(written)
+			<-release
+			return err
+		})
+	}()
+	<-written
+
+	// The write and the read either wait for the transaction or finish while
+	// it is open; either way the transaction commits after them.
+	var writeErr error
+	writeDone := make(chan struct{})
+	go func() {
+		_, writeErr = repo.Create(ctx, &model.Config{Environment: "dev", Key: "outside", Value: "1", UpdatedAt: baseTime})
+		close(writeDone)
+	}()
+	select {
+	case <-writeDone:
+	case <-time.After(100 * time.Millisecond):
+	}
+	var seen []*model.Revision
+	var readErr error
+	readDone := make(chan struct{})
+	go func() {
+		seen, readErr = repo.GetRevisionsSince(ctx, "dev", 0, 10)
+		close(readDone)
+	}()
+	select {
+	case <-readDone:
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-txDone; err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	<-writeDone
+	if writeErr != nil {
+		t.Fatalf("Create() error = %v", writeErr)
+	}
+	<-readDone
+	if readErr != nil {
+		t.Fatalf("GetRevisionsSince() during the transaction error = %v", readErr)
+	}
+
+	all, err := repo.GetRevisionsSince(ctx, "dev", 0, 10)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetRevisionsSince() = %v, %v, want both writes", all, err)
+	}
+	var last int64
+	for _, revision := range seen {
+		last = max(last, revision.Revision)
+	}
+	for _, revision := range all {
+		if !slices.ContainsFunc(seen, func(r *model.Revision) bool { return r.Revision == revision.Revision }) && revision.Revision < last {
+			t.Fatalf("revision %d of %s became visible after revision %d was read", revision.Revision, revision.Key, last)
+		}
+	}
+}
+
+func testConcurrentWrites(t *testing.T, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("key%d", i)
+			errs <- repo.WithTx(ctx, func(tx repository.ConfigRepository) error {
+				_, err := tx.Create(ctx, &model.Config{Environment: "dev", Key: key, Value: "v", UpdatedAt: baseTime})
+				return err
+			})
+			_, _ = repo.ListConfigs(ctx, "dev", model.ConfigQuery{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("WithTx() error = %v", err)
+		}
+	}
+
+	if count, err := repo.CountConfigs(ctx, "dev", model.ConfigQuery{}); err != nil || count != 20 {
+		t.Fatalf("CountConfigs() = %d, %v, want 20", count, err)
+	}
+	revisions, err := repo.GetRevisionsSince(ctx, "dev", 0, 100)
+	if err != nil || len(revisions) != 20 {
+		t.Fatalf("GetRevisionsSince() = %d revisions, %v, want 20", len(revisions), err)
+	}
+	if !slices.IsSortedFunc(revisions, func(a, b *model.Revision) int { return cmp.Compare(a.Revision, b.Revision) }) {
+		t.Fatal("GetRevisionsSince() must return revisions in order")
+	}
+}
+
+func testCanceledContext(t *testing.T, repo repository.ConfigRepository) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.Get(ctx, "dev", "key"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Get() error = %v, want context.Canceled", err)
+	}
+	if err := repo.CreateEnvironment(ctx, &model.Environment{Name: "dev", CreatedAt: baseTime}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("CreateEnvironment() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,105 @@
+package repositorytest
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// SecretStoreFactory returns an empty secret store for a single test, along
+// with a config repository over the same storage.
+type SecretStoreFactory func(t *testing.T) (repository.SecretStore, repository.ConfigRepository)
+
+// RunSecretStore runs the secret store checks against stores from newStore.
+func RunSecretStore(t *testing.T, newStore SecretStoreFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, store repository.SecretStore, repo repository.ConfigRepository)
+	}{
+		{"DataKeys", testDataKeys},
+		{"ReplaceSecrets", testReplaceSecrets},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, repo := newStore(t)
+			tt.run(t, store, repo)
+		})
+	}
+}
+
+func testDataKeys(t *testing.T, store repository.SecretStore, _ repository.ConfigRepository) {
+	ctx := context.Background()
+	first := &repository.DataKey{MasterKey: "k1", Wrapped: []byte("wrapped-1"), CreatedAt: baseTime}
+	second := &repository.DataKey{MasterKey: "k1", Wrapped: []byte("wrapped-2"), CreatedAt: baseTime}
+	for _, key := range []*repository.DataKey{first, second} {
+		if err := store.CreateDataKey(ctx, key); err != nil {
+			t.Fatalf("CreateDataKey() error = %v", err)
+		}
+	}
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("CreateDataKey() ids = %d, %d", first.ID, second.ID)
+	}
+
+	if err := store.RewrapDataKey(ctx, &repository.DataKey{ID: first.ID, MasterKey: "k2", Wrapped: []byte("rewrapped")}); err != nil {
+		t.Fatalf("RewrapDataKey() error = %v", err)
+	}
+	got, err := store.GetDataKey(ctx, first.ID)
+	if err != nil || got.MasterKey != "k2" || string(got.Wrapped) != "rewrapped" || !got.CreatedAt.Equal(baseTime) {
+		t.Fatalf("GetDataKey() = %#v, %v", got, err)
+	}
+	keys, err := store.GetDataKeys(ctx)
+	if err != nil || len(keys) != 2 || keys[0].ID != first.ID || keys[1].MasterKey != "k1" {
+		t.Fatalf("GetDataKeys() = %v, %v", keys, err)
+	}
+
+	if _, err := store.GetDataKey(ctx, second.ID+1); !errors.Is(err, repository.ErrDataKeyNotFound) {
+		t.Fatalf("GetDataKey() missing error = %v", err)
+	}
+	if err := store.RewrapDataKey(ctx, &repository.DataKey{ID: second.ID + 1, MasterKey: "k2"}); !errors.Is(err, repository.ErrDataKeyNotFound) {
+		t.Fatalf("RewrapDataKey() missing error = %v", err)
+	}
+}
+
+func testReplaceSecrets(t *testing.T, store repository.SecretStore, repo repository.ConfigRepository) {
+	ctx := context.Background()
+	createEnvironment(t, repo, "dev", "")
+	createEnvironment(t, repo, "prod", "")
+	for _, config := range []*model.Config{
+		{Environment: "dev", Key: "a", Value: "enc:v1:1:old"},
+		{Environment: "prod", Key: "a", Value: "enc:v1:1:old"},
+		{Environment: "dev", Key: "b", Value: "enc:v1:1:other"},
+		{Environment: "dev", Key: "c", Value: "enc:v1:2:current"},
+		{Environment: "dev", Key: "d", Value: "plain"},
+	} {
+		createConfig(t, repo, config)
+	}
+
+	stale, err := store.GetStaleSecrets(ctx, "enc:v1:", "enc:v1:2:", 10)
+	slices.Sort(stale)
+	if want := []string{"enc:v1:1:old", "enc:v1:1:other"}; err != nil || !slices.Equal(stale, want) {
+		t.Fatalf("GetStaleSecrets() = %v, %v, want %v", stale, err, want)
+	}
+	if stale, err := store.GetStaleSecrets(ctx, "enc:v1:", "enc:v1:2:", 1); err != nil || len(stale) != 1 {
+		t.Fatalf("GetStaleSecrets() with limit 1 = %v, %v", stale, err)
+	}
+
+	if replaced, err := store.ReplaceSecret(ctx, "enc:v1:1:old", "enc:v1:2:new"); err != nil || replaced != 2 {
+		t.Fatalf("ReplaceSecret() = %d, %v, want 2", replaced, err)
+	}
+	config, err := repo.Get(ctx, "prod", "a")
+	if err != nil || config.Value != "enc:v1:2:new" || config.Version != 1 {
+		t.Fatalf("Get() after ReplaceSecret() = %#v, %v", config, err)
+	}
+	if history, err := repo.GetHistory(ctx, "prod", "a"); err != nil || len(history) != 1 || history[0].Value != "enc:v1:1:old" {
+		t.Fatalf("GetHistory() after ReplaceSecret() = %v, %v, want the revision unchanged", history, err)
+	}
+	if replaced, err := store.ReplaceSecret(ctx, "enc:v1:1:old", "enc:v1:2:newer"); err != nil || replaced != 0 {
+		t.Fatalf("second ReplaceSecret() = %d, %v, want 0", replaced, err)
+	}
+	if stale, err := store.GetStaleSecrets(ctx, "enc:v1:", "enc:v1:2:", 10); err != nil || !slices.Equal(stale, []string{"enc:v1:1:other"}) {
+		t.Fatalf("GetStaleSecrets() after ReplaceSecret() = %v, %v", stale, err)
+	}
+}
@@ -0,0 +1,55 @@
+package repositorytest
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TokenFactory returns an empty token repository for a single test.
+type TokenFactory func(t *testing.T) repository.TokenRepository
+
+// RunTokenRepository runs the token checks against repositories from
+// newRepository.
+func RunTokenRepository(t *testing.T, newRepository TokenFactory) {
+	t.Run("Tokens", func(t *testing.T) {
+		testTokens(t, newRepository(t))
+	})
+}
+
+func testTokens(t *testing.T, repo repository.TokenRepository) {
+	ctx := context.Background()
+	expires := baseTime.Add(365 * 24 * time.Hour)
+	first := &model.Token{Name: "deploy", Grants: []model.Grant{{Environment: "*", Role: model.RoleReader}}, CreatedAt: baseTime, ExpiresAt: &expires}
+	if err := repo.CreateToken(ctx, first, "hash1"); err != nil || first.ID == 0 {
+		t.Fatalf("CreateToken() = %d, %v", first.ID, err)
+	}
+	for _, duplicate := range []struct{ name, hash string }{{"deploy", "hash2"}, {"other", "hash1"}} {
+		if err := repo.CreateToken(ctx, &model.Token{Name: duplicate.name, CreatedAt: baseTime}, duplicate.hash); !errors.Is(err, repository.ErrTokenAlreadyExists) {
+			t.Fatalf("CreateToken(%s, %s) error = %v", duplicate.name, duplicate.hash, err)
+		}
+	}
+	if err := repo.CreateToken(ctx, &model.Token{Name: "ci", CreatedAt: baseTime}, "hash3"); err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	token, err := repo.GetTokenByHash(ctx, "hash1")
+	if err != nil || token.Name != "deploy" || len(token.Grants) != 1 || token.ExpiresAt == nil || !token.ExpiresAt.Equal(expires) {
+		t.Fatalf("GetTokenByHash() = %#v, %v", token, err)
+	}
+	if tokens, err := repo.GetTokens(ctx); err != nil || len(tokens) != 2 || tokens[0].Name != "ci" || tokens[0].ExpiresAt != nil {
+		t.Fatalf("GetTokens() = %v, %v, want sorted by name", tokens, err)
+	}
+	if err := repo.DeleteToken(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteToken() error = %v", err)
+	}
+	if _, err := repo.GetTokenByHash(ctx, "hash1"); !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Fatalf("GetTokenByHash() deleted error = %v", err)
+	}
+	if err := repo.DeleteToken(ctx, first.ID); !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Fatalf("DeleteToken() missing error = %v", err)
+	}
+}
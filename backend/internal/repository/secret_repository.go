@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSecretsUnavailable = errors.New("no master key configured for secret values")
+	ErrDataKeyNotFound    = errors.New("data key not found")
+)
+
+// DataKey is a key secret values are encrypted with, stored wrapped with the
+// master key MasterKey.
+type DataKey struct {
+	ID        int64     `json:"id"`
+	MasterKey string    `json:"master_key"`
+	Wrapped   []byte    `json:"wrapped"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SecretStore keeps the data keys of secret values. Values only refer to
+// their data key by ID, so rotating a master key re-wraps data keys; current
+// values are then re-encrypted with a fresh data key, while revisions keep
+// the values they were written with.
+type SecretStore interface {
+	// CreateDataKey sets the ID of the key.
+	CreateDataKey(ctx context.Context, key *DataKey) error
+	GetDataKey(ctx context.Context, id int64) (*DataKey, error)
+	// GetDataKeys returns every data key ordered by ID.
+	GetDataKeys(ctx context.Context) ([]*DataKey, error)
+	// RewrapDataKey replaces the master key and wrapped bytes of a data key.
+	RewrapDataKey(ctx context.Context, key *DataKey) error
+	// GetStaleSecrets returns up to limit distinct current config values
+	// that start with prefix but not with current.
+	GetStaleSecrets(ctx context.Context, prefix, current string, limit int) ([]string, error)
+	// ReplaceSecret swaps a current config value without creating a revision
+	// or bumping the version, and returns the number of configs changed.
+	ReplaceSecret(ctx context.Context, old, replacement string) (int64, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+)
+
+var (
+	ErrTokenNotFound      = errors.New("token not found")
+	ErrTokenAlreadyExists = errors.New("token already exists")
+)
+
+// TokenRepository stores API tokens by the SHA-256 hash of their secret.
+type TokenRepository interface {
+	// CreateToken sets the ID of the token.
+	CreateToken(ctx context.Context, token *model.Token, hash string) error
+	GetTokenByHash(ctx context.Context, hash string) (*model.Token, error)
+	GetTokens(ctx context.Context) ([]*model.Token, error)
+	DeleteToken(ctx context.Context, id int64) error
+}
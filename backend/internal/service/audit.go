@@ -0,0 +1,235 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"encoding/json"
+)
+
+type AuditService interface {
+	// GetAuditLog returns a page of entries, newest first.
+	GetAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditPage, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) GetAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// One extra entry tells whether another page exists.
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.repo.GetAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = page.Entries[limit-1].ID
+	}
+	if page.Entries == nil {
+		page.Entries = []*model.AuditEntry{}
+	}
+	return page, nil
+}
+
+// Audit returns svc with the writes it makes attributed to request in the
+// audit log. Services that do not write the audit log are returned
+// unchanged.
+func Audit(svc ConfigService, request model.AuditRequest) ConfigService {
+	if scoped, ok := svc.(interface {
+		forRequest(model.AuditRequest) ConfigService
+	}); ok {
+		return scoped.forRequest(request)
+	}
+	return svc
+}
+
+func AuditEnvironments(svc EnvironmentService, request model.AuditRequest) EnvironmentService {
+	if scoped, ok := svc.(interface {
+		forRequest(model.AuditRequest) EnvironmentService
+	}); ok {
+		return scoped.forRequest(request)
+	}
+	return svc
+}
+
+// auditRepository appends an audit entry for every config and environment
+// write, in the same transaction as the write, so that a change cannot be
+// committed without its entry.
+type auditRepository struct {
+	repository.ConfigRepository
+	request model.AuditRequest
+}
+
+func newAuditRepository(repo repository.ConfigRepository) *auditRepository {
+	return &auditRepository{ConfigRepository: repo}
+}
+
+func (r *auditRepository) forRequest(request model.AuditRequest) *auditRepository {
+	return &auditRepository{ConfigRepository: r.ConfigRepository, request: request}
+}
+
+func (r *auditRepository) WithTx(ctx context.Context, fn func(repo repository.ConfigRepository) error) error {
+	return r.ConfigRepository.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		return fn(&auditRepository{ConfigRepository: repo, request: r.request})
+	})
+}
+
+// record runs write and appends the entry it returns in one transaction.
+// Inside WithTx the wrapped repository is already bound to a transaction and
+// runs write directly.
+func (r *auditRepository) record(ctx context.Context, write func(repo repository.ConfigRepository) (*model.AuditEntry, error)) error {
+	return r.ConfigRepository.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		entry, err := write(repo)
+		if err != nil {
+			return err
+		}
+		entry.SourceIP = r.request.SourceIP
+		entry.RequestID = r.request.RequestID
+		return repo.AppendAudit(ctx, entry)
+	})
+}
+
+func (r *auditRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	var revision *model.Revision
+	err := r.record(ctx, func(repo repository.ConfigRepository) (*model.AuditEntry, error) {
+		var err error
+		if revision, err = repo.Create(ctx, config); err != nil {
+			return nil, err
+		}
+		return configEntry(model.AuditConfigCreate, revision, nil, &revision.Value, revision.Secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (r *auditRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
+	var revision *model.Revision
+	err := r.record(ctx, func(repo repository.ConfigRepository) (*model.AuditEntry, error) {
+		old, err := repo.Get(ctx, config.Environment, config.Key)
+		if err != nil {
+			return nil, err
+		}
+		if revision, err = repo.Update(ctx, config); err != nil {
+			return nil, err
+		}
+		return configEntry(model.AuditConfigUpdate, revision, &old.Value, &revision.Value, old.Secret || revision.Secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (r *auditRepository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
+	var revision *model.Revision
+	err := r.record(ctx, func(repo repository.ConfigRepository) (*model.AuditEntry, error) {
+		var err error
+		if revision, err = repo.Delete(ctx, environment, key, actor, version); err != nil {
+			return nil, err
+		}
+		// A delete revision keeps the value the key had.
+		return configEntry(model.AuditConfigDelete, revision, &revision.Value, nil, revision.Secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (r *auditRepository) CreateEnvironment(ctx context.Context, environment *model.Environment) error {
+	return r.record(ctx, func(repo repository.ConfigRepository) (*model.AuditEntry, error) {
+		if err := repo.CreateEnvironment(ctx, environment); err != nil {
+			return nil, err
+		}
+		return r.environmentEntry(model.AuditEnvironmentCreate, environment.Name, nil, environment)
+	})
+}
+
+func (r *auditRepository) UpdateEnvironment(ctx context.Context, environment *model.Environment) error {
+	return r.record(ctx, func(repo repository.ConfigRepository) (*model.AuditEntry, error) {
+		old, err := repo.GetEnvironment(ctx, environment.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.UpdateEnvironment(ctx, environment); err != nil {
+			return nil, err
+		}
+		return r.environmentEntry(model.AuditEnvironmentUpdate, environment.Name, old, environment)
+	})
+}
+
+func (r *auditRepository) DeleteEnvironment(ctx context.Context, name string) error {
+	return r.record(ctx, func(repo repository.ConfigRepository) (*model.AuditEntry, error) {
+		old, err := repo.GetEnvironment(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.DeleteEnvironment(ctx, name); err != nil {
+			return nil, err
+		}
+		return r.environmentEntry(model.AuditEnvironmentDelete, name, old, nil)
+	})
+}
+
+// configEntry takes the actor from the revision, which already names the
+// authenticated caller.
+func configEntry(operation model.AuditOperation, revision *model.Revision, oldValue, newValue *string, secret bool) *model.AuditEntry {
+	entry := &model.AuditEntry{
+		Actor:       revision.Actor,
+		Operation:   operation,
+		Environment: revision.Environment,
+		Key:         revision.Key,
+		Revision:    revision.Revision,
+		Secret:      secret,
+	}
+	if !secret {
+		entry.OldValue, entry.NewValue = oldValue, newValue
+	}
+	return entry
+}
+
+// environmentEntry stores the attributes of the environment before and after
+// the change as JSON.
+func (r *auditRepository) environmentEntry(operation model.AuditOperation, name string, old, updated *model.Environment) (*model.AuditEntry, error) {
+	entry := &model.AuditEntry{
+		Actor:       r.request.Actor,
+		Operation:   operation,
+		Environment: name,
+	}
+	var err error
+	if old != nil {
+		if entry.OldValue, err = encodeAttributes(old.EnvironmentAttributes); err != nil {
+			return nil, err
+		}
+	}
+	if updated != nil {
+		if entry.NewValue, err = encodeAttributes(updated.EnvironmentAttributes); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+func encodeAttributes(attributes model.EnvironmentAttributes) (*string, error) {
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	value := string(data)
+	return &value, nil
+}
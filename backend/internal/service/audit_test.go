@@ -0,0 +1,175 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"testing"
+)
+
+var testRequest = model.AuditRequest{Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1"}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return "<nil>"
+	}
+	return *value
+}
+
+func TestAudit_RecordsConfigWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := Audit(NewConfigService(repo, NewBroker()), testRequest)
+
+	if err := svc.CreateConfig(ctx, "prod", "db.host", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "db.host", "v2", model.ValueSpec{}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if err := svc.DeleteConfig(ctx, "prod", "db.host", "alice", 0); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+
+	want := []struct {
+		operation model.AuditOperation
+		oldValue  string
+		newValue  string
+	}{
+		{operation: model.AuditConfigCreate, oldValue: "<nil>", newValue: "v1"},
+		{operation: model.AuditConfigUpdate, oldValue: "v1", newValue: "v2"},
+		{operation: model.AuditConfigDelete, oldValue: "v2", newValue: "<nil>"},
+	}
+	if len(repo.audit) != len(want) {
+		t.Fatalf("audit = %#v, want %d entries", repo.audit, len(want))
+	}
+	for i, entry := range repo.audit {
+		if entry.Operation != want[i].operation || stringValue(entry.OldValue) != want[i].oldValue || stringValue(entry.NewValue) != want[i].newValue {
+			t.Fatalf("entry %d = %s %s -> %s, want %#v", i, entry.Operation, stringValue(entry.OldValue), stringValue(entry.NewValue), want[i])
+		}
+		if entry.Actor != "alice" || entry.SourceIP != "10.0.0.7" || entry.RequestID != "req-1" ||
+			entry.Environment != "prod" || entry.Key != "db.host" || entry.Revision != int64(i+1) {
+			t.Fatalf("entry %d = %#v", i, entry)
+		}
+	}
+}
+
+func TestAudit_OmitsSecretValues(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := Audit(NewConfigService(repo, NewBroker()), testRequest)
+
+	if err := svc.CreateConfig(ctx, "prod", "db.password", "s3cr3t", model.ValueSpec{Secret: true}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "db.password", "n3w", model.ValueSpec{}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if _, err := svc.RollbackConfig(ctx, "prod", "db.password", 1, "alice"); err != nil {
+		t.Fatalf("RollbackConfig() error = %v", err)
+	}
+
+	if len(repo.audit) != 3 {
+		t.Fatalf("audit = %#v, want 3 entries", repo.audit)
+	}
+	for _, entry := range repo.audit {
+		if !entry.Secret || entry.OldValue != nil || entry.NewValue != nil {
+			t.Fatalf("secret entry = %#v, old %s, new %s", entry, stringValue(entry.OldValue), stringValue(entry.NewValue))
+		}
+	}
+}
+
+func TestAudit_FailedEntryRollsBackWrite(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	repo.auditErr = errors.New("disk full")
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "db.host", "v1", model.ValueSpec{}, "alice"); !errors.Is(err, repo.auditErr) {
+		t.Fatalf("CreateConfig() error = %v, want %v", err, repo.auditErr)
+	}
+	if exists, _ := repo.Exists(ctx, "prod", "db.host"); exists || len(repo.revisions) != 0 {
+		t.Fatal("the write must be rolled back with its audit entry")
+	}
+}
+
+func TestAudit_RecordsEveryBatchOperation(t *testing.T) {
+	repo := newMockRepository()
+	svc := Audit(NewConfigService(repo, NewBroker()), testRequest)
+
+	_, err := svc.ApplyBatch(context.Background(), "prod", []model.BatchOperation{
+		{Op: model.OperationCreate, Key: "a", Value: "1"},
+		{Op: model.OperationCreate, Key: "b", Value: "2"},
+	}, "alice")
+	if err != nil {
+		t.Fatalf("ApplyBatch() error = %v", err)
+	}
+	if len(repo.audit) != 2 || repo.audit[0].Key != "a" || repo.audit[1].Key != "b" || repo.audit[1].RequestID != "req-1" {
+		t.Fatalf("audit = %#v", repo.audit)
+	}
+}
+
+func TestAuditEnvironments_RecordsEnvironmentWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := AuditEnvironments(NewEnvironmentService(repo, NewBroker()), testRequest)
+
+	if _, err := svc.CreateEnvironment(ctx, "dev", model.EnvironmentAttributes{Owner: "team-a"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	if _, err := svc.PutEnvironment(ctx, "dev", model.EnvironmentAttributes{Owner: "team-b"}); err != nil {
+		t.Fatalf("PutEnvironment() error = %v", err)
+	}
+	if err := svc.DeleteEnvironment(ctx, "dev", "alice", false); err != nil {
+		t.Fatalf("DeleteEnvironment() error = %v", err)
+	}
+
+	want := []struct {
+		operation model.AuditOperation
+		oldValue  string
+		newValue  string
+	}{
+		{operation: model.AuditEnvironmentCreate, oldValue: "<nil>", newValue: `{"owner":"team-a","protected":false}`},
+		{operation: model.AuditEnvironmentUpdate, oldValue: `{"owner":"team-a","protected":false}`, newValue: `{"owner":"team-b","protected":false}`},
+		{operation: model.AuditEnvironmentDelete, oldValue: `{"owner":"team-b","protected":false}`, newValue: "<nil>"},
+	}
+	if len(repo.audit) != len(want) {
+		t.Fatalf("audit = %#v, want %d entries", repo.audit, len(want))
+	}
+	for i, entry := range repo.audit {
+		if entry.Operation != want[i].operation || stringValue(entry.OldValue) != want[i].oldValue || stringValue(entry.NewValue) != want[i].newValue {
+			t.Fatalf("entry %d = %s %s -> %s, want %#v", i, entry.Operation, stringValue(entry.OldValue), stringValue(entry.NewValue), want[i])
+		}
+		if entry.Actor != "alice" || entry.Environment != "dev" || entry.Key != "" || entry.SourceIP != "10.0.0.7" {
+			t.Fatalf("entry %d = %#v", i, entry)
+		}
+	}
+}
+
+func TestAuditService_GetAuditLog(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	for _, key := range []string{"a", "b", "c"} {
+		if err := svc.CreateConfig(ctx, "prod", key, "v", model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+	}
+	audit := NewAuditService(repo)
+
+	page, err := audit.GetAuditLog(ctx, model.AuditFilter{Environment: "prod", Limit: 2})
+	if err != nil || len(page.Entries) != 2 || page.Entries[0].Key != "c" || page.NextCursor != page.Entries[1].ID {
+		t.Fatalf("first page = %#v, %v", page, err)
+	}
+	page, err = audit.GetAuditLog(ctx, model.AuditFilter{Environment: "prod", Cursor: page.NextCursor, Limit: 2})
+	if err != nil || len(page.Entries) != 1 || page.Entries[0].Key != "a" || page.NextCursor != 0 {
+		t.Fatalf("last page = %#v, %v", page, err)
+	}
+	page, err = audit.GetAuditLog(ctx, model.AuditFilter{Actor: "bob", Limit: 2})
+	if err != nil || page.Entries == nil || len(page.Entries) != 0 {
+		t.Fatalf("empty page = %#v, %v", page, err)
+	}
+	if _, err := audit.GetAuditLog(ctx, model.AuditFilter{}); !errors.Is(err, model.ErrInvalidAuditFilter) {
+		t.Fatalf("GetAuditLog() without limit error = %v, want %v", err, model.ErrInvalidAuditFilter)
+	}
+}
@@ -0,0 +1,452 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrForbidden is returned when the caller's grants do not cover the
+// request.
+var ErrForbidden = errors.New("permission denied")
+
+// Authorize returns a ConfigService that acts on behalf of caller: requests
+// outside the caller's grants fail with ErrForbidden, reads spanning an
+// environment only return keys the caller may read, and writes are
+// recorded with the caller's name as actor. A nil caller means
+// authentication is disabled and svc is returned unchanged.
+func Authorize(svc ConfigService, caller *model.Identity) ConfigService {
+	if caller == nil {
+		return svc
+	}
+	return &authorizedConfigService{next: svc, caller: caller}
+}
+
+type authorizedConfigService struct {
+	next   ConfigService
+	caller *model.Identity
+}
+
+func (s *authorizedConfigService) require(role model.Role, environment, key string) error {
+	if !s.caller.Allows(role, environment, key) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *authorizedConfigService) requireAny(role model.Role, environment string) error {
+	if !s.caller.AllowsAny(role, environment) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *authorizedConfigService) requireEnvironment(role model.Role, environment string) error {
+	if !s.caller.AllowsEnvironment(role, environment) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *authorizedConfigService) CreateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, _ string) error {
+	if err := s.require(model.RoleWriter, environment, key); err != nil {
+		return err
+	}
+	return s.next.CreateConfig(ctx, environment, key, value, spec, s.caller.Name)
+}
+
+func (s *authorizedConfigService) GetConfig(ctx context.Context, environment, key string) (*model.Config, error) {
+	if err := s.require(model.RoleReader, environment, key); err != nil {
+		return nil, err
+	}
+	return s.next.GetConfig(ctx, environment, key)
+}
+
+func (s *authorizedConfigService) RevealConfig(ctx context.Context, environment, key string) (*model.Config, error) {
+	if err := s.require(model.RoleWriter, environment, key); err != nil {
+		return nil, err
+	}
+	return s.next.RevealConfig(ctx, environment, key)
+}
+
+func (s *authorizedConfigService) GetConfigAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	if err := s.require(model.RoleReader, environment, key); err != nil {
+		return nil, err
+	}
+	return s.next.GetConfigAt(ctx, environment, key, asOf)
+}
+
+func (s *authorizedConfigService) GetAllConfigs(ctx context.Context, environment string) ([]*model.Config, error) {
+	if err := s.requireAny(model.RoleReader, environment); err != nil {
+		return nil, err
+	}
+	configs, err := s.next.GetAllConfigs(ctx, environment)
+	return s.readableConfigs(environment, configs), err
+}
+
+func (s *authorizedConfigService) GetAllConfigsAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	if err := s.requireAny(model.RoleReader, environment); err != nil {
+		return nil, err
+	}
+	configs, err := s.next.GetAllConfigsAt(ctx, environment, asOf)
+	return s.readableConfigs(environment, configs), err
+}
+
+// ListConfigs filters in the query rather than after it, so that pages and
+// totals only count keys the caller may read.
+func (s *authorizedConfigService) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) (*model.ConfigPage, error) {
+	if err := s.requireAny(model.RoleReader, environment); err != nil {
+		return nil, err
+	}
+	if !s.caller.AllowsEnvironment(model.RoleReader, environment) {
+		query.AllowedPrefixes = s.caller.KeyPrefixes(model.RoleReader, environment)
+	}
+	return s.next.ListConfigs(ctx, environment, query)
+}
+
+func (s *authorizedConfigService) CompareEnvironments(ctx context.Context, left, right model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+	if err := s.requireAny(model.RoleReader, left.Name); err != nil {
+		return nil, err
+	}
+	if err := s.requireAny(model.RoleReader, right.Name); err != nil {
+		return nil, err
+	}
+	diff, err := s.next.CompareEnvironments(ctx, left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	diff.OnlyInLeft = s.readableConfigs(left.Name, diff.OnlyInLeft)
+	diff.OnlyInRight = s.readableConfigs(right.Name, diff.OnlyInRight)
+	changed := diff.Changed[:0]
+	for _, change := range diff.Changed {
+		if s.caller.Allows(model.RoleReader, left.Name, change.Key) && s.caller.Allows(model.RoleReader, right.Name, change.Key) {
+			changed = append(changed, change)
+		}
+	}
+	diff.Changed = changed
+	return diff, nil
+}
+
+// ResolveConfigs returns an inherited value only when the caller may read the
+// key in the environment it came from as well.
+func (s *authorizedConfigService) ResolveConfigs(ctx context.Context, environment string) ([]*model.ResolvedConfig, error) {
+	if err := s.requireAny(model.RoleReader, environment); err != nil {
+		return nil, err
+	}
+	resolved, err := s.next.ResolveConfigs(ctx, environment)
+	if err != nil {
+		return nil, err
+	}
+	readable := resolved[:0]
+	for _, entry := range resolved {
+		if s.caller.Allows(model.RoleReader, environment, entry.Key) && s.caller.Allows(model.RoleReader, entry.Source, entry.Key) {
+			readable = append(readable, entry)
+		}
+	}
+	return readable, nil
+}
+
+func (s *authorizedConfigService) GetConfigHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	if err := s.require(model.RoleReader, environment, key); err != nil {
+		return nil, err
+	}
+	return s.next.GetConfigHistory(ctx, environment, key)
+}
+
+func (s *authorizedConfigService) UpdateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, _ string, version int64) error {
+	if err := s.require(model.RoleWriter, environment, key); err != nil {
+		return err
+	}
+	return s.next.UpdateConfig(ctx, environment, key, value, spec, s.caller.Name, version)
+}
+
+func (s *authorizedConfigService) DeleteConfig(ctx context.Context, environment, key, _ string, version int64) error {
+	if err := s.require(model.RoleWriter, environment, key); err != nil {
+		return err
+	}
+	return s.next.DeleteConfig(ctx, environment, key, s.caller.Name, version)
+}
+
+// DeleteTree needs a grant covering tree, which then covers every key below
+// it as well.
+func (s *authorizedConfigService) DeleteTree(ctx context.Context, environment, tree, _ string) ([]*model.Revision, error) {
+	if err := s.require(model.RoleWriter, environment, tree); err != nil {
+		return nil, err
+	}
+	return s.next.DeleteTree(ctx, environment, tree, s.caller.Name)
+}
+
+func (s *authorizedConfigService) RollbackConfig(ctx context.Context, environment, key string, revision int64, _ string) ([]*model.Revision, error) {
+	if err := s.require(model.RoleWriter, environment, key); err != nil {
+		return nil, err
+	}
+	return s.next.RollbackConfig(ctx, environment, key, revision, s.caller.Name)
+}
+
+// RollbackEnvironment may touch any key, so it needs a grant on the whole
+// environment.
+func (s *authorizedConfigService) RollbackEnvironment(ctx context.Context, environment string, asOf time.Time, _ string) ([]*model.Revision, error) {
+	if err := s.requireEnvironment(model.RoleWriter, environment); err != nil {
+		return nil, err
+	}
+	return s.next.RollbackEnvironment(ctx, environment, asOf, s.caller.Name)
+}
+
+func (s *authorizedConfigService) ApplyBatch(ctx context.Context, environment string, operations []model.BatchOperation, _ string) ([]model.BatchResult, error) {
+	for _, operation := range operations {
+		if err := s.require(model.RoleWriter, environment, operation.Key); err != nil {
+			return nil, err
+		}
+	}
+	return s.next.ApplyBatch(ctx, environment, operations, s.caller.Name)
+}
+
+// ImportConfigs in replace mode deletes keys missing from the document, so
+// it needs a grant on the whole environment.
+func (s *authorizedConfigService) ImportConfigs(ctx context.Context, environment string, values []model.KeyValue, mode model.ImportMode, _ string, dryRun bool) (*model.ImportReport, error) {
+	if mode == model.ImportReplace {
+		if err := s.requireEnvironment(model.RoleWriter, environment); err != nil {
+			return nil, err
+		}
+	}
+	for _, value := range values {
+		if err := s.require(model.RoleWriter, environment, value.Key); err != nil {
+			return nil, err
+		}
+	}
+	return s.next.ImportConfigs(ctx, environment, values, mode, s.caller.Name, dryRun)
+}
+
+func (s *authorizedConfigService) WatchConfigs(ctx context.Context, environment string) (<-chan *model.Revision, func(), error) {
+	if err := s.requireAny(model.RoleReader, environment); err != nil {
+		return nil, nil, err
+	}
+	changes, cancel, err := s.next.WatchConfigs(ctx, environment)
+	if err != nil || s.caller.AllowsEnvironment(model.RoleReader, environment) {
+		return changes, cancel, err
+	}
+
+	readable := make(chan *model.Revision, subscriberBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(readable)
+		for revision := range changes {
+			if !s.caller.Allows(model.RoleReader, environment, revision.Key) {
+				continue
+			}
+			select {
+			case readable <- revision:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return readable, func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}, nil
+}
+
+// GetChangesSince keeps reading while whole pages are filtered out, so that
+// a short page still means the caller has caught up.
+func (s *authorizedConfigService) GetChangesSince(ctx context.Context, environment string, since int64) ([]*model.Revision, error) {
+	if err := s.requireAny(model.RoleReader, environment); err != nil {
+		return nil, err
+	}
+	if s.caller.AllowsEnvironment(model.RoleReader, environment) {
+		return s.next.GetChangesSince(ctx, environment, since)
+	}
+
+	var readable []*model.Revision
+	for {
+		revisions, err := s.next.GetChangesSince(ctx, environment, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, revision := range revisions {
+			if s.caller.Allows(model.RoleReader, environment, revision.Key) {
+				readable = append(readable, revision)
+			}
+		}
+		if len(revisions) < MaxChangesPerRequest || len(readable) >= MaxChangesPerRequest {
+			break
+		}
+		since = revisions[len(revisions)-1].Revision
+	}
+	if len(readable) > MaxChangesPerRequest {
+		readable = readable[:MaxChangesPerRequest]
+	}
+	return readable, nil
+}
+
+func (s *authorizedConfigService) readableConfigs(environment string, configs []*model.Config) []*model.Config {
+	if configs == nil || s.caller.AllowsEnvironment(model.RoleReader, environment) {
+		return configs
+	}
+	readable := make([]*model.Config, 0, len(configs))
+	for _, config := range configs {
+		if s.caller.Allows(model.RoleReader, environment, config.Key) {
+			readable = append(readable, config)
+		}
+	}
+	return readable
+}
+
+// AuthorizeEnvironments is Authorize for EnvironmentService. Managing an
+// environment needs admin on it; listing only returns environments the
+// caller can read.
+func AuthorizeEnvironments(svc EnvironmentService, caller *model.Identity) EnvironmentService {
+	if caller == nil {
+		return svc
+	}
+	return &authorizedEnvironmentService{next: svc, caller: caller}
+}
+
+type authorizedEnvironmentService struct {
+	next   EnvironmentService
+	caller *model.Identity
+}
+
+func (s *authorizedEnvironmentService) requireEnvironment(role model.Role, environment string) error {
+	if !s.caller.AllowsEnvironment(role, environment) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *authorizedEnvironmentService) ListEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	environments, err := s.next.ListEnvironments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readable := environments[:0]
+	for _, environment := range environments {
+		if s.caller.AllowsAny(model.RoleReader, environment.Name) {
+			readable = append(readable, environment)
+		}
+	}
+	return readable, nil
+}
+
+func (s *authorizedEnvironmentService) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	if !s.caller.AllowsAny(model.RoleReader, name) {
+		return nil, ErrForbidden
+	}
+	return s.next.GetEnvironment(ctx, name)
+}
+
+func (s *authorizedEnvironmentService) CreateEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	if err := s.requireEnvironment(model.RoleAdmin, name); err != nil {
+		return nil, err
+	}
+	return s.next.CreateEnvironment(ctx, name, attributes)
+}
+
+func (s *authorizedEnvironmentService) PutEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	if err := s.requireEnvironment(model.RoleAdmin, name); err != nil {
+		return nil, err
+	}
+	return s.next.PutEnvironment(ctx, name, attributes)
+}
+
+func (s *authorizedEnvironmentService) DeleteEnvironment(ctx context.Context, name, _ string, force bool) error {
+	if err := s.requireEnvironment(model.RoleAdmin, name); err != nil {
+		return err
+	}
+	return s.next.DeleteEnvironment(ctx, name, s.caller.Name, force)
+}
+
+// PromoteEnvironment needs read access to both environments and, unless it
+// is a dry run, write access to the whole target.
+func (s *authorizedEnvironmentService) PromoteEnvironment(ctx context.Context, source, target string, filter model.KeyFilter, _ string, dryRun bool) (*model.Promotion, error) {
+	if err := s.requireEnvironment(model.RoleReader, source); err != nil {
+		return nil, err
+	}
+	role := model.RoleWriter
+	if dryRun {
+		role = model.RoleReader
+	}
+	if err := s.requireEnvironment(role, target); err != nil {
+		return nil, err
+	}
+	return s.next.PromoteEnvironment(ctx, source, target, filter, s.caller.Name, dryRun)
+}
+
+// AuthorizeTokens is Authorize for TokenService: managing tokens needs
+// admin on every environment, since a token can be given any grant.
+func AuthorizeTokens(svc TokenService, caller *model.Identity) TokenService {
+	if caller == nil {
+		return svc
+	}
+	return &authorizedTokenService{next: svc, caller: caller}
+}
+
+type authorizedTokenService struct {
+	next   TokenService
+	caller *model.Identity
+}
+
+func (s *authorizedTokenService) requireAdmin() error {
+	if !s.caller.AllowsEnvironment(model.RoleAdmin, model.AllEnvironments) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *authorizedTokenService) Authenticate(ctx context.Context, token string) (*model.Identity, error) {
+	return s.next.Authenticate(ctx, token)
+}
+
+func (s *authorizedTokenService) ListTokens(ctx context.Context) ([]*model.Token, error) {
+	if err := s.requireAdmin(); err != nil {
+		return nil, err
+	}
+	return s.next.ListTokens(ctx)
+}
+
+func (s *authorizedTokenService) CreateToken(ctx context.Context, name string, grants []model.Grant, expiresAt *time.Time, _ string) (*model.Token, string, error) {
+	if err := s.requireAdmin(); err != nil {
+		return nil, "", err
+	}
+	return s.next.CreateToken(ctx, name, grants, expiresAt, s.caller.Name)
+}
+
+func (s *authorizedTokenService) DeleteToken(ctx context.Context, id int64) error {
+	if err := s.requireAdmin(); err != nil {
+		return err
+	}
+	return s.next.DeleteToken(ctx, id)
+}
+
+// AuthorizeAudit is Authorize for AuditService: the audit log of an
+// environment needs admin on it, the log of all environments admin on every
+// environment.
+func AuthorizeAudit(svc AuditService, caller *model.Identity) AuditService {
+	if caller == nil {
+		return svc
+	}
+	return &authorizedAuditService{next: svc, caller: caller}
+}
+
+type authorizedAuditService struct {
+	next   AuditService
+	caller *model.Identity
+}
+
+func (s *authorizedAuditService) GetAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditPage, error) {
+	environment := filter.Environment
+	if environment == "" {
+		environment = model.AllEnvironments
+	}
+	if !s.caller.AllowsEnvironment(model.RoleAdmin, environment) {
+		return nil, ErrForbidden
+	}
+	return s.next.GetAuditLog(ctx, filter)
+}
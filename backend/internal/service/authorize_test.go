@@ -0,0 +1,258 @@
+package service
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func prefixWriter() *model.Identity {
+	return &model.Identity{Name: "billing-ci", Grants: []model.Grant{
+		{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleWriter},
+		{Environment: "staging", Role: model.RoleReader},
+	}}
+}
+
+func TestAuthorize_NilCallerIsUnrestricted(t *testing.T) {
+	svc := NewConfigService(newMockRepository(), NewBroker())
+
+	if Authorize(svc, nil) != svc {
+		t.Fatal("Authorize() with a nil caller must return the service unchanged")
+	}
+}
+
+func TestAuthorize_ConfigPermissions(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	inner := NewConfigService(repo, NewBroker())
+	for _, key := range []string{"billing.rate", "db.host"} {
+		if err := inner.CreateConfig(ctx, "prod", key, "v1", model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+	}
+	svc := Authorize(inner, prefixWriter())
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{name: "read prefixed key", call: func() error { _, err := svc.GetConfig(ctx, "prod", "billing.rate"); return err }},
+		{name: "read other key", call: func() error { _, err := svc.GetConfig(ctx, "prod", "db.host"); return err }, wantErr: ErrForbidden},
+		{name: "reveal prefixed key", call: func() error { _, err := svc.RevealConfig(ctx, "prod", "billing.rate"); return err }},
+		{name: "reveal with reader grant", call: func() error { _, err := svc.RevealConfig(ctx, "staging", "db.host"); return err }, wantErr: ErrForbidden},
+		{name: "update prefixed key", call: func() error {
+			return svc.UpdateConfig(ctx, "prod", "billing.rate", "v2", model.ValueSpec{}, "mallory", 0)
+		}},
+		{name: "update other key", call: func() error {
+			return svc.UpdateConfig(ctx, "prod", "db.host", "v2", model.ValueSpec{}, "mallory", 0)
+		}, wantErr: ErrForbidden},
+		{name: "write with reader grant", call: func() error {
+			return svc.CreateConfig(ctx, "staging", "key", "v", model.ValueSpec{}, "mallory")
+		}, wantErr: ErrForbidden},
+		{name: "delete tree inside grant", call: func() error {
+			_, err := svc.DeleteTree(ctx, "prod", "billing.missing", "mallory")
+			return err
+		}, wantErr: ErrConfigNotFound},
+		{name: "delete tree above grant", call: func() error { _, err := svc.DeleteTree(ctx, "prod", "billing", "mallory"); return err }, wantErr: ErrForbidden},
+		{name: "read unknown environment", call: func() error { _, err := svc.GetAllConfigs(ctx, "dev"); return err }, wantErr: ErrForbidden},
+		{name: "rollback whole environment", call: func() error {
+			_, err := svc.RollbackEnvironment(ctx, "prod", time.Now(), "mallory")
+			return err
+		}, wantErr: ErrForbidden},
+		{name: "batch with one foreign key", call: func() error {
+			_, err := svc.ApplyBatch(ctx, "prod", []model.BatchOperation{
+				{Op: model.OperationUpdate, Key: "billing.rate", Value: "v3"},
+				{Op: model.OperationDelete, Key: "db.host"},
+			}, "mallory")
+			return err
+		}, wantErr: ErrForbidden},
+		{name: "import replace", call: func() error {
+			_, err := svc.ImportConfigs(ctx, "prod", []model.KeyValue{{Key: "billing.rate", Value: "v4"}}, model.ImportReplace, "mallory", true)
+			return err
+		}, wantErr: ErrForbidden},
+		{name: "watch unknown environment", call: func() error { _, _, err := svc.WatchConfigs(ctx, "dev"); return err }, wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if stored, _ := repo.Get(ctx, "prod", "billing.rate"); stored.UpdatedBy != "billing-ci" {
+		t.Fatalf("updated_by = %q, want the caller instead of the requested actor", stored.UpdatedBy)
+	}
+	if stored, _ := repo.Get(ctx, "prod", "db.host"); stored.Value != "v1" {
+		t.Fatalf("forbidden batch changed db.host to %q", stored.Value)
+	}
+}
+
+func TestAuthorize_FiltersEnvironmentReads(t *testing.T) {
+	ctx := context.Background()
+	inner := NewConfigService(newMockRepository(), NewBroker())
+	for _, key := range []string{"billing.rate", "db.host"} {
+		if err := inner.CreateConfig(ctx, "prod", key, "v1", model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+	}
+	svc := Authorize(inner, prefixWriter())
+
+	configs, err := svc.GetAllConfigs(ctx, "prod")
+	if err != nil || len(configs) != 1 || configs[0].Key != "billing.rate" {
+		t.Fatalf("GetAllConfigs() = %#v, %v", configs, err)
+	}
+	page, err := svc.ListConfigs(ctx, "prod", model.ConfigQuery{Limit: 10})
+	if err != nil || page.Total != 1 || len(page.Configs) != 1 || page.Configs[0].Key != "billing.rate" {
+		t.Fatalf("ListConfigs() = %#v, %v", page, err)
+	}
+	resolved, err := svc.ResolveConfigs(ctx, "prod")
+	if err != nil || len(resolved) != 1 || resolved[0].Key != "billing.rate" {
+		t.Fatalf("ResolveConfigs() = %#v, %v", resolved, err)
+	}
+	changes, err := svc.GetChangesSince(ctx, "prod", 0)
+	if err != nil || len(changes) != 1 || changes[0].Key != "billing.rate" {
+		t.Fatalf("GetChangesSince() = %#v, %v", changes, err)
+	}
+	diff, err := svc.CompareEnvironments(ctx, model.EnvironmentRef{Name: "prod"}, model.EnvironmentRef{Name: "staging"})
+	if err != nil || len(diff.OnlyInLeft) != 1 || diff.OnlyInLeft[0].Key != "billing.rate" {
+		t.Fatalf("CompareEnvironments() = %#v, %v", diff, err)
+	}
+}
+
+func TestAuthorize_ResolveChecksSourceEnvironment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	environments := NewEnvironmentService(repo, NewBroker())
+	inner := NewConfigService(repo, NewBroker())
+	for _, env := range [][2]string{{"prod", ""}, {"dev", "prod"}} {
+		if _, err := environments.PutEnvironment(ctx, env[0], inherits(env[1])); err != nil {
+			t.Fatalf("PutEnvironment(%s) error = %v", env[0], err)
+		}
+	}
+	for _, config := range [][3]string{
+		{"prod", "db.password", "p"},
+		{"prod", "billing.rate", "10"},
+		{"dev", "db.host", "localhost"},
+	} {
+		if err := inner.CreateConfig(ctx, config[0], config[1], config[2], model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%v) error = %v", config, err)
+		}
+	}
+	svc := Authorize(inner, &model.Identity{Name: "dev-ci", Grants: []model.Grant{
+		{Environment: "dev", Role: model.RoleReader},
+		{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleReader},
+	}})
+
+	resolved, err := svc.ResolveConfigs(ctx, "dev")
+	if err != nil {
+		t.Fatalf("ResolveConfigs() error = %v", err)
+	}
+	var keys []string
+	for _, entry := range resolved {
+		keys = append(keys, entry.Source+"/"+entry.Key)
+	}
+	if want := []string{"prod/billing.rate", "dev/db.host"}; !slices.Equal(keys, want) {
+		t.Fatalf("ResolveConfigs() = %v, want %v", keys, want)
+	}
+}
+
+func TestAuthorize_WatchFiltersKeys(t *testing.T) {
+	ctx := context.Background()
+	inner := NewConfigService(newMockRepository(), NewBroker())
+	svc := Authorize(inner, prefixWriter())
+
+	changes, cancel, err := svc.WatchConfigs(ctx, "prod")
+	if err != nil {
+		t.Fatalf("WatchConfigs() error = %v", err)
+	}
+	defer cancel()
+
+	for _, key := range []string{"db.host", "billing.rate"} {
+		if err := inner.CreateConfig(ctx, "prod", key, "v1", model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+	}
+
+	select {
+	case revision := <-changes:
+		if revision.Key != "billing.rate" {
+			t.Fatalf("received %q, want only readable keys", revision.Key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no change received")
+	}
+
+	cancel()
+	if _, ok := <-changes; ok {
+		t.Fatal("channel must be closed after cancel")
+	}
+}
+
+func TestAuthorizeEnvironments(t *testing.T) {
+	ctx := context.Background()
+	svc := AuthorizeEnvironments(NewEnvironmentService(newMockRepository(), NewBroker()), prefixWriter())
+
+	environments, err := svc.ListEnvironments(ctx)
+	if err != nil || len(environments) != 2 {
+		t.Fatalf("ListEnvironments() = %#v, %v", environments, err)
+	}
+	if _, err := svc.CreateEnvironment(ctx, "dev", model.EnvironmentAttributes{}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("CreateEnvironment() error = %v, want %v", err, ErrForbidden)
+	}
+	if _, err := svc.PromoteEnvironment(ctx, "staging", "prod", model.KeyFilter{}, "mallory", false); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("PromoteEnvironment() into a prefix grant error = %v, want %v", err, ErrForbidden)
+	}
+	if _, err := svc.PromoteEnvironment(ctx, "prod", "staging", model.KeyFilter{}, "mallory", true); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("PromoteEnvironment() dry run from a prefix grant error = %v, want %v", err, ErrForbidden)
+	}
+
+	admin := AuthorizeEnvironments(NewEnvironmentService(newMockRepository(), NewBroker()), &model.Identity{
+		Name:   "ops",
+		Grants: []model.Grant{{Environment: "dev", Role: model.RoleAdmin}},
+	})
+	if _, err := admin.CreateEnvironment(ctx, "dev", model.EnvironmentAttributes{}); err != nil {
+		t.Fatalf("CreateEnvironment() as admin error = %v", err)
+	}
+}
+
+func TestAuthorizeTokens(t *testing.T) {
+	ctx := context.Background()
+	inner := NewTokenService(newMemoryTokenRepository(), &config.Config{})
+	grants := []model.Grant{{Environment: "prod", Role: model.RoleReader}}
+
+	envAdmin := AuthorizeTokens(inner, &model.Identity{Name: "ops", Grants: []model.Grant{{Environment: "prod", Role: model.RoleAdmin}}})
+	if _, _, err := envAdmin.CreateToken(ctx, "ci", grants, nil, "ops"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("CreateToken() as environment admin error = %v, want %v", err, ErrForbidden)
+	}
+
+	admin := AuthorizeTokens(inner, &model.Identity{Name: "root", Grants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}}})
+	token, _, err := admin.CreateToken(ctx, "ci", grants, nil, "someone-else")
+	if err != nil || token.CreatedBy != "root" {
+		t.Fatalf("CreateToken() as global admin = %#v, %v", token, err)
+	}
+}
+
+func TestAuthorizeAudit(t *testing.T) {
+	ctx := context.Background()
+	inner := NewAuditService(newMockRepository())
+	filter := model.AuditFilter{Environment: "prod", Limit: 10}
+
+	envAdmin := AuthorizeAudit(inner, &model.Identity{Name: "ops", Grants: []model.Grant{{Environment: "prod", Role: model.RoleAdmin}}})
+	if _, err := envAdmin.GetAuditLog(ctx, filter); err != nil {
+		t.Fatalf("GetAuditLog() of an administered environment error = %v", err)
+	}
+	if _, err := envAdmin.GetAuditLog(ctx, model.AuditFilter{Limit: 10}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("GetAuditLog() of all environments error = %v, want %v", err, ErrForbidden)
+	}
+
+	writer := AuthorizeAudit(inner, prefixWriter())
+	if _, err := writer.GetAuditLog(ctx, filter); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("GetAuditLog() as writer error = %v, want %v", err, ErrForbidden)
+	}
+}
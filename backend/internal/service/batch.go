@@ -0,0 +1,140 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+)
+
+const MaxBatchOperations = 500
+
+var (
+	ErrBatchInvalid = errors.New("invalid batch")
+	ErrBatchFailed  = errors.New("batch failed")
+)
+
+// ApplyBatch validates every operation up front and then applies them in
+// order inside one transaction. Results are returned even when the batch
+// fails so callers can see which operation caused it; in that case nothing
+// is written.
+func (s *configService) ApplyBatch(ctx context.Context, environment string, operations []model.BatchOperation, actor string) ([]model.BatchResult, error) {
+	results := newBatchResults(operations)
+
+	if len(operations) == 0 || len(operations) > MaxBatchOperations {
+		return results, ErrBatchInvalid
+	}
+	if !validateBatch(environment, operations, results) {
+		return results, ErrBatchInvalid
+	}
+
+	var applied []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		var err error
+		applied, err = applyOperations(ctx, repo, environment, operations, results, actor)
+		return err
+	})
+	if err != nil {
+		abortResults(results)
+		if isOperationError(err) {
+			return results, ErrBatchFailed
+		}
+		return results, err
+	}
+
+	s.publish(applied)
+	return results, nil
+}
+
+// applyOperations applies operations in order and records the outcome of
+// each one in results. It stops at the first failure.
+func applyOperations(ctx context.Context, repo repository.ConfigRepository, environment string, operations []model.BatchOperation, results []model.BatchResult, actor string) ([]*model.Revision, error) {
+	applied := make([]*model.Revision, 0, len(operations))
+	for i, op := range operations {
+		revision, err := applyOperation(ctx, repo, environment, op, actor)
+		if err != nil {
+			setFailure(&results[i], err)
+			return nil, err
+		}
+		results[i].Status = model.BatchStatusApplied
+		results[i].Revision = revision.Revision
+		applied = append(applied, revision)
+	}
+	return applied, nil
+}
+
+// abortResults marks applied operations as aborted after the transaction
+// was rolled back.
+func abortResults(results []model.BatchResult) {
+	for i := range results {
+		if results[i].Status == model.BatchStatusApplied {
+			results[i].Status = model.BatchStatusAborted
+			results[i].Revision = 0
+		}
+	}
+}
+
+func newBatchResults(operations []model.BatchOperation) []model.BatchResult {
+	results := make([]model.BatchResult, len(operations))
+	for i, op := range operations {
+		results[i] = model.BatchResult{Op: op.Op, Key: op.Key, Status: model.BatchStatusAborted}
+	}
+	return results
+}
+
+func validateBatch(environment string, operations []model.BatchOperation, results []model.BatchResult) bool {
+	valid := true
+	seen := make(map[string]bool, len(operations))
+	for i, op := range operations {
+		err := op.Validate(environment)
+		if err == nil && seen[op.Key] {
+			err = model.ErrDuplicateKey
+		}
+		seen[op.Key] = true
+
+		if err != nil {
+			setFailure(&results[i], err)
+			valid = false
+		}
+	}
+	return valid
+}
+
+func applyOperation(ctx context.Context, repo repository.ConfigRepository, environment string, op model.BatchOperation, actor string) (*model.Revision, error) {
+	switch op.Op {
+	case model.OperationCreate:
+		return createConfig(ctx, repo, environment, op.Key, op.Value, op.ValueSpec, actor)
+	case model.OperationUpdate:
+		return updateConfig(ctx, repo, environment, op.Key, op.Value, op.ValueSpec, actor, op.Version)
+	case model.OperationDelete:
+		return deleteConfig(ctx, repo, environment, op.Key, actor, op.Version)
+	case model.OperationUpsert:
+		revision, err := updateConfig(ctx, repo, environment, op.Key, op.Value, op.ValueSpec, actor, op.Version)
+		if errors.Is(err, ErrConfigNotFound) && op.Version == 0 {
+			return createConfig(ctx, repo, environment, op.Key, op.Value, op.ValueSpec, actor)
+		}
+		return revision, err
+	default:
+		return nil, model.ErrInvalidOperation
+	}
+}
+
+func setFailure(result *model.BatchResult, err error) {
+	result.Status = model.BatchStatusFailed
+	result.Error = err.Error()
+
+	var valueErr *model.ValueError
+	if errors.As(err, &valueErr) {
+		result.Violations = valueErr.Violations
+	}
+}
+
+func isOperationError(err error) bool {
+	return errors.Is(err, ErrConfigNotFound) ||
+		errors.Is(err, ErrConfigExists) ||
+		errors.Is(err, ErrVersionMismatch) ||
+		errors.Is(err, ErrEnvironmentNotFound) ||
+		errors.Is(err, ErrSecretsUnavailable) ||
+		errors.Is(err, model.ErrInvalidOperation) ||
+		errors.Is(err, model.ErrInvalidValue)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConfigService_ApplyBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	for key, value := range map[string]string{"update": "old", "delete": "old", "upsert-existing": "old"} {
+		if err := svc.CreateConfig(ctx, "prod", key, value, model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig() error = %v", err)
+		}
+	}
+
+	results, err := svc.ApplyBatch(ctx, "prod", []model.BatchOperation{
+		{Op: model.OperationCreate, Key: "create", Value: "new"},
+		{Op: model.OperationUpdate, Key: "update", Value: "new", Version: 1},
+		{Op: model.OperationDelete, Key: "delete"},
+		{Op: model.OperationUpsert, Key: "upsert-existing", Value: "new"},
+		{Op: model.OperationUpsert, Key: "upsert-missing", Value: "new"},
+	}, "bob")
+	if err != nil {
+		t.Fatalf("ApplyBatch() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Status != model.BatchStatusApplied || result.Revision == 0 {
+			t.Fatalf("ApplyBatch() result = %#v", result)
+		}
+	}
+
+	for _, key := range []string{"create", "update", "upsert-existing", "upsert-missing"} {
+		config, err := svc.GetConfig(ctx, "prod", key)
+		if err != nil || config.Value != "new" {
+			t.Fatalf("GetConfig(%q) = %#v, %v", key, config, err)
+		}
+	}
+	if _, err := svc.GetConfig(ctx, "prod", "delete"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("deleted key error = %v, want %v", err, ErrConfigNotFound)
+	}
+}
+
+func TestConfigService_ApplyBatchValidatesUpFront(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	results, err := svc.ApplyBatch(ctx, "prod", []model.BatchOperation{
+		{Op: model.OperationCreate, Key: "a", Value: "1"},
+		{Op: "rename", Key: "b"},
+		{Op: model.OperationCreate, Key: "a", Value: "2"},
+		{Op: model.OperationCreate, Key: "c", Value: string(make([]byte, 10001))},
+	}, "bob")
+	if !errors.Is(err, ErrBatchInvalid) {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, ErrBatchInvalid)
+	}
+
+	wantStatuses := []model.BatchStatus{
+		model.BatchStatusAborted,
+		model.BatchStatusFailed,
+		model.BatchStatusFailed,
+		model.BatchStatusFailed,
+	}
+	for i, result := range results {
+		if result.Status != wantStatuses[i] {
+			t.Fatalf("results[%d] = %#v, want status %s", i, result, wantStatuses[i])
+		}
+	}
+	if results[2].Error != model.ErrDuplicateKey.Error() {
+		t.Fatalf("duplicate key error = %q", results[2].Error)
+	}
+	if len(repo.configs) != 0 || len(repo.revisions) != 0 {
+		t.Fatal("nothing should be written when validation fails")
+	}
+
+	if _, err := svc.ApplyBatch(ctx, "prod", nil, "bob"); !errors.Is(err, ErrBatchInvalid) {
+		t.Fatalf("ApplyBatch() empty error = %v, want %v", err, ErrBatchInvalid)
+	}
+}
+
+func TestConfigService_ApplyBatchRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "existing", "old", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	changes, cancel, err := svc.WatchConfigs(ctx, "prod")
+	if err != nil {
+		t.Fatalf("WatchConfigs() error = %v", err)
+	}
+	defer cancel()
+
+	results, err := svc.ApplyBatch(ctx, "prod", []model.BatchOperation{
+		{Op: model.OperationUpdate, Key: "existing", Value: "new"},
+		{Op: model.OperationCreate, Key: "fresh", Value: "new"},
+		{Op: model.OperationDelete, Key: "missing"},
+		{Op: model.OperationCreate, Key: "never", Value: "new"},
+	}, "bob")
+	if !errors.Is(err, ErrBatchFailed) {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, ErrBatchFailed)
+	}
+
+	wantStatuses := []model.BatchStatus{
+		model.BatchStatusAborted,
+		model.BatchStatusAborted,
+		model.BatchStatusFailed,
+		model.BatchStatusAborted,
+	}
+	for i, result := range results {
+		if result.Status != wantStatuses[i] || (result.Status != model.BatchStatusApplied && result.Revision != 0) {
+			t.Fatalf("results[%d] = %#v, want status %s", i, result, wantStatuses[i])
+		}
+	}
+	if results[2].Error != ErrConfigNotFound.Error() {
+		t.Fatalf("failed operation error = %q", results[2].Error)
+	}
+
+	config, err := svc.GetConfig(ctx, "prod", "existing")
+	if err != nil || config.Value != "old" {
+		t.Fatalf("existing = %#v, %v; want old value after rollback", config, err)
+	}
+	if _, err := svc.GetConfig(ctx, "prod", "fresh"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("fresh should not exist after rollback, error = %v", err)
+	}
+	select {
+	case revision := <-changes:
+		t.Fatalf("rolled back batch published revision %#v", revision)
+	default:
+	}
+}
+
+func TestConfigService_ApplyBatchReturnsRepositoryError(t *testing.T) {
+	wantErr := errors.New("db down")
+	repo := &controllableRepository{existsErr: wantErr}
+
+	results, err := NewConfigService(repo, NewBroker()).ApplyBatch(context.Background(), "prod", []model.BatchOperation{
+		{Op: model.OperationCreate, Key: "a", Value: "1"},
+	}, "bob")
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, wantErr)
+	}
+	if len(results) != 1 || results[0].Status != model.BatchStatusFailed {
+		t.Fatalf("ApplyBatch() results = %#v", results)
+	}
+}
+
+func TestConfigService_ApplyBatchReportsTypeViolations(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	if err := svc.CreateConfig(ctx, "prod", "port", "8080", model.ValueSpec{Type: model.TypeInt}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+
+	results, err := svc.ApplyBatch(ctx, "prod", []model.BatchOperation{
+		{Op: model.OperationCreate, Key: "enabled", Value: "tru", ValueSpec: model.ValueSpec{Type: model.TypeBool}},
+	}, "bob")
+	if !errors.Is(err, ErrBatchInvalid) {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, ErrBatchInvalid)
+	}
+	if len(results[0].Violations) != 1 || results[0].Violations[0].Path != "$" {
+		t.Fatalf("results[0] = %#v, want a violation", results[0])
+	}
+
+	results, err = svc.ApplyBatch(ctx, "prod", []model.BatchOperation{
+		{Op: model.OperationUpdate, Key: "port", Value: "http"},
+	}, "bob")
+	if !errors.Is(err, ErrBatchFailed) {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, ErrBatchFailed)
+	}
+	if results[0].Status != model.BatchStatusFailed || len(results[0].Violations) != 1 {
+		t.Fatalf("results[0] = %#v, want failed with violations", results[0])
+	}
+}
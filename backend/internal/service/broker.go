@@ -0,0 +1,96 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"sync"
+)
+
+const (
+	subscriberBuffer = 64
+	recentRevisions  = 4096
+)
+
+// Broker fans out config revisions to watchers of an environment.
+// The same revision may be published twice, once by the local write and
+// once by the database notification, so recently seen revisions are dropped.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan *model.Revision]struct{}
+	seen        map[int64]struct{}
+	order       []int64
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan *model.Revision]struct{}),
+		seen:        make(map[int64]struct{}),
+	}
+}
+
+// Subscribe returns a channel of revisions for the environment and a
+// function that cancels the subscription. The channel is closed when the
+// subscription is cancelled or when the subscriber falls too far behind.
+func (b *Broker) Subscribe(environment string) (<-chan *model.Revision, func()) {
+	ch := make(chan *model.Revision, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[environment] == nil {
+		b.subscribers[environment] = make(map[chan *model.Revision]struct{})
+	}
+	b.subscribers[environment][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.remove(environment, ch)
+		})
+	}
+}
+
+// Publish masks secret values before they reach any subscriber.
+func (b *Broker) Publish(revision *model.Revision) {
+	if b == nil || revision == nil {
+		return
+	}
+	revision = revision.Masked()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.seen[revision.Revision]; ok {
+		return
+	}
+	b.remember(revision.Revision)
+
+	for ch := range b.subscribers[revision.Environment] {
+		select {
+		case ch <- revision:
+		default:
+			b.remove(revision.Environment, ch)
+		}
+	}
+}
+
+func (b *Broker) remember(revision int64) {
+	b.seen[revision] = struct{}{}
+	b.order = append(b.order, revision)
+	if len(b.order) > recentRevisions {
+		delete(b.seen, b.order[0])
+		b.order = b.order[1:]
+	}
+}
+
+func (b *Broker) remove(environment string, ch chan *model.Revision) {
+	subscribers := b.subscribers[environment]
+	if _, ok := subscribers[ch]; !ok {
+		return
+	}
+	delete(subscribers, ch)
+	close(ch)
+	if len(subscribers) == 0 {
+		delete(b.subscribers, environment)
+	}
+}
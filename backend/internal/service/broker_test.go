@@ -0,0 +1,72 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"testing"
+)
+
+func TestBroker_PublishDeliversToEnvironmentSubscribers(t *testing.T) {
+	broker := NewBroker()
+	prod, cancelProd := broker.Subscribe("prod")
+	defer cancelProd()
+	dev, cancelDev := broker.Subscribe("dev")
+	defer cancelDev()
+
+	broker.Publish(&model.Revision{Revision: 1, Environment: "prod", Key: "key"})
+
+	if revision := <-prod; revision.Revision != 1 {
+		t.Fatalf("prod received revision %d, want 1", revision.Revision)
+	}
+	select {
+	case revision := <-dev:
+		t.Fatalf("dev received unexpected revision %d", revision.Revision)
+	default:
+	}
+}
+
+func TestBroker_DropsDuplicateRevisions(t *testing.T) {
+	broker := NewBroker()
+	changes, cancel := broker.Subscribe("prod")
+	defer cancel()
+
+	revision := &model.Revision{Revision: 5, Environment: "prod", Key: "key"}
+	broker.Publish(revision)
+	broker.Publish(revision)
+
+	<-changes
+	select {
+	case <-changes:
+		t.Fatal("duplicate revision was delivered twice")
+	default:
+	}
+}
+
+func TestBroker_CancelClosesChannel(t *testing.T) {
+	broker := NewBroker()
+	changes, cancel := broker.Subscribe("prod")
+	cancel()
+	cancel()
+
+	if _, ok := <-changes; ok {
+		t.Fatal("channel should be closed after cancel")
+	}
+	broker.Publish(&model.Revision{Revision: 1, Environment: "prod"})
+}
+
+func TestBroker_SlowSubscriberIsDisconnected(t *testing.T) {
+	broker := NewBroker()
+	changes, cancel := broker.Subscribe("prod")
+	defer cancel()
+
+	for i := 1; i <= subscriberBuffer+1; i++ {
+		broker.Publish(&model.Revision{Revision: int64(i), Environment: "prod"})
+	}
+
+	received := 0
+	for range changes {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("received %d revisions before disconnect, want %d", received, subscriberBuffer)
+	}
+}
@@ -3,107 +3,500 @@ package service
 import (
 	"config-service/backend/internal/model"
 	"config-service/backend/internal/repository"
+	"context"
 	"errors"
+	"sort"
+	"time"
 )
 
 var (
-	ErrConfigNotFound = errors.New("config not found")
-	ErrConfigExists   = errors.New("config already exists")
+	ErrConfigNotFound   = errors.New("config not found")
+	ErrConfigExists     = errors.New("config already exists")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrVersionMismatch  = errors.New("config version mismatch")
+	// ErrSecretsUnavailable is returned for writes to secret keys while no
+	// master key is configured.
+	ErrSecretsUnavailable = errors.New("secret values require a master key")
 )
 
 type ConfigService interface {
-	CreateConfig(environment, key, value string) error
-	GetConfig(environment, key string) (*model.Config, error)
-	GetAllConfigs(environment string) ([]*model.Config, error)
-	UpdateConfig(environment, key, value string) error
-	DeleteConfig(environment, key string) error
+	// CreateConfig stores an untyped key when spec is zero.
+	CreateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, actor string) error
+	// Reads mask the values of secret keys with model.SecretMask; only
+	// RevealConfig returns them in plaintext.
+	GetConfig(ctx context.Context, environment, key string) (*model.Config, error)
+	RevealConfig(ctx context.Context, environment, key string) (*model.Config, error)
+	GetConfigAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error)
+	GetAllConfigs(ctx context.Context, environment string) ([]*model.Config, error)
+	GetAllConfigsAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error)
+	// ListConfigs returns a page of the configs matching query; NextCursor
+	// is set while more pages follow.
+	ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) (*model.ConfigPage, error)
+	CompareEnvironments(ctx context.Context, left, right model.EnvironmentRef) (*model.EnvironmentDiff, error)
+	// ResolveConfigs merges the configs of the environment and its parent
+	// chain; the nearest environment defining a key wins.
+	ResolveConfigs(ctx context.Context, environment string) ([]*model.ResolvedConfig, error)
+	GetConfigHistory(ctx context.Context, environment, key string) ([]*model.Revision, error)
+	// UpdateConfig and DeleteConfig fail with ErrVersionMismatch when version
+	// is non-zero and differs from the stored one. UpdateConfig keeps the
+	// stored type when spec is zero.
+	UpdateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, actor string, version int64) error
+	DeleteConfig(ctx context.Context, environment, key, actor string, version int64) error
+	// DeleteTree deletes the key tree and every key nested below it in one
+	// transaction; it fails with ErrConfigNotFound when there are none.
+	DeleteTree(ctx context.Context, environment, tree, actor string) ([]*model.Revision, error)
+	RollbackConfig(ctx context.Context, environment, key string, revision int64, actor string) ([]*model.Revision, error)
+	RollbackEnvironment(ctx context.Context, environment string, asOf time.Time, actor string) ([]*model.Revision, error)
+	ApplyBatch(ctx context.Context, environment string, operations []model.BatchOperation, actor string) ([]model.BatchResult, error)
+	ImportConfigs(ctx context.Context, environment string, values []model.KeyValue, mode model.ImportMode, actor string, dryRun bool) (*model.ImportReport, error)
+	// WatchConfigs subscribes to revisions written to the environment after
+	// the call. The returned function cancels the subscription.
+	WatchConfigs(ctx context.Context, environment string) (<-chan *model.Revision, func(), error)
+	GetChangesSince(ctx context.Context, environment string, since int64) ([]*model.Revision, error)
 }
 
+const MaxChangesPerRequest = 1000
+
 type configService struct {
-	repo repository.ConfigRepository
+	repo   *auditRepository
+	broker *Broker
+}
+
+func NewConfigService(repo repository.ConfigRepository, broker *Broker) ConfigService {
+	return &configService{repo: newAuditRepository(repo), broker: broker}
 }
 
-func NewConfigService(repo repository.ConfigRepository) ConfigService {
-	return &configService{repo: repo}
+func (s *configService) forRequest(request model.AuditRequest) ConfigService {
+	return &configService{repo: s.repo.forRequest(request), broker: s.broker}
 }
 
-func (s *configService) CreateConfig(environment, key, value string) error {
-	exists, err := s.repo.Exists(environment, key)
+func (s *configService) CreateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, actor string) error {
+	revision, err := createConfig(ctx, s.repo, environment, key, value, spec, actor)
 	if err != nil {
 		return err
 	}
-	if exists {
-		return ErrConfigExists
-	}
+	s.broker.Publish(revision)
+	return nil
+}
 
-	config, err := model.NewConfig(environment, key, value)
+func (s *configService) GetConfig(ctx context.Context, environment, key string) (*model.Config, error) {
+	config, err := s.RevealConfig(ctx, environment, key)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return config.Masked(), nil
+}
 
-	if err := s.repo.Create(config); err != nil {
-		if errors.Is(err, repository.ErrConfigAlreadyExists) {
-			return ErrConfigExists
+func (s *configService) RevealConfig(ctx context.Context, environment, key string) (*model.Config, error) {
+	config, err := s.repo.Get(ctx, environment, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrConfigNotFound) {
+			return nil, ErrConfigNotFound
 		}
-		return err
+		return nil, err
 	}
-
-	return nil
+	return config, nil
 }
 
-func (s *configService) GetConfig(environment, key string) (*model.Config, error) {
-	config, err := s.repo.Get(environment, key)
+func (s *configService) GetConfigAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	config, err := s.repo.GetAt(ctx, environment, key, asOf)
 	if err != nil {
 		if errors.Is(err, repository.ErrConfigNotFound) {
 			return nil, ErrConfigNotFound
 		}
 		return nil, err
 	}
-	return config, nil
+	return config.Masked(), nil
+}
+
+func (s *configService) GetAllConfigs(ctx context.Context, environment string) ([]*model.Config, error) {
+	return maskConfigs(s.repo.GetAll(ctx, environment))
+}
+
+func (s *configService) GetAllConfigsAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	return maskConfigs(s.repo.GetAllAt(ctx, environment, asOf))
 }
 
-func (s *configService) GetAllConfigs(environment string) ([]*model.Config, error) {
-	return s.repo.GetAll(environment)
+func (s *configService) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) (*model.ConfigPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	// One extra config tells whether another page exists.
+	limit := query.Limit
+	if limit > 0 {
+		query.Limit++
+	}
+	configs, err := maskConfigs(s.repo.ListConfigs(ctx, environment, query))
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountConfigs(ctx, environment, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.ConfigPage{Configs: configs, Total: total}
+	if limit > 0 && len(configs) > limit {
+		page.Configs = configs[:limit]
+		page.NextCursor = model.CursorAfter(page.Configs[limit-1], query.SortOrDefault())
+	}
+	return page, nil
 }
 
-func (s *configService) UpdateConfig(environment, key, value string) error {
-	config, err := s.repo.Get(environment, key)
+func (s *configService) ResolveConfigs(ctx context.Context, environment string) ([]*model.ResolvedConfig, error) {
+	chain, err := environmentChain(ctx, s.repo, environment)
 	if err != nil {
-		if errors.Is(err, repository.ErrConfigNotFound) {
-			return ErrConfigNotFound
+		return nil, err
+	}
+
+	resolved := make(map[string]*model.ResolvedConfig)
+	for i := len(chain) - 1; i >= 0; i-- {
+		configs, err := s.repo.GetAll(ctx, chain[i])
+		if err != nil {
+			return nil, err
 		}
+		for _, config := range configs {
+			entry := &model.ResolvedConfig{Config: *config.Masked(), Source: chain[i]}
+			entry.Environment = environment
+			resolved[config.Key] = entry
+		}
+	}
+
+	result := make([]*model.ResolvedConfig, 0, len(resolved))
+	for _, entry := range resolved {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+func (s *configService) GetConfigHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	revisions, err := s.repo.GetHistory(ctx, environment, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrConfigNotFound
+	}
+	return maskRevisions(revisions, nil)
+}
+
+func (s *configService) UpdateConfig(ctx context.Context, environment, key, value string, spec model.ValueSpec, actor string, version int64) error {
+	revision, err := updateConfig(ctx, s.repo, environment, key, value, spec, actor, version)
+	if err != nil {
 		return err
 	}
+	s.broker.Publish(revision)
+	return nil
+}
 
-	if err := config.UpdateValue(value); err != nil {
+func (s *configService) DeleteConfig(ctx context.Context, environment, key, actor string, version int64) error {
+	revision, err := deleteConfig(ctx, s.repo, environment, key, actor, version)
+	if err != nil {
 		return err
 	}
+	s.broker.Publish(revision)
+	return nil
+}
 
-	if err := s.repo.Update(config); err != nil {
-		if errors.Is(err, repository.ErrConfigNotFound) {
+func (s *configService) DeleteTree(ctx context.Context, environment, tree, actor string) ([]*model.Revision, error) {
+	if tree == "" {
+		return nil, model.ErrInvalidKey
+	}
+
+	var deleted []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		configs, err := repo.ListConfigs(ctx, environment, model.ConfigQuery{Tree: tree})
+		if err != nil {
+			return err
+		}
+		if len(configs) == 0 {
 			return ErrConfigNotFound
 		}
-		return err
+		for _, config := range configs {
+			revision, err := deleteConfig(ctx, repo, environment, config.Key, actor, config.Version)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, revision)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.publish(deleted)
+	return maskRevisions(deleted, nil)
+}
 
-	return nil
+func (s *configService) WatchConfigs(ctx context.Context, environment string) (<-chan *model.Revision, func(), error) {
+	changes, cancel := s.broker.Subscribe(environment)
+	return changes, cancel, nil
 }
 
-func (s *configService) DeleteConfig(environment, key string) error {
-	exists, err := s.repo.Exists(environment, key)
+func (s *configService) GetChangesSince(ctx context.Context, environment string, since int64) ([]*model.Revision, error) {
+	return maskRevisions(s.repo.GetRevisionsSince(ctx, environment, since, MaxChangesPerRequest))
+}
+
+func (s *configService) publish(revisions []*model.Revision) {
+	for _, revision := range revisions {
+		s.broker.Publish(revision)
+	}
+}
+
+func createConfig(ctx context.Context, repo repository.ConfigRepository, environment, key, value string, spec model.ValueSpec, actor string) (*model.Revision, error) {
+	if _, err := repo.GetEnvironment(ctx, environment); err != nil {
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return nil, ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+
+	exists, err := repo.Exists(ctx, environment, key)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if exists {
+		return nil, ErrConfigExists
+	}
+
+	config, err := model.NewTypedConfig(environment, key, value, spec)
+	if err != nil {
+		return nil, err
+	}
+	config.UpdatedBy = actor
+
+	revision, err := repo.Create(ctx, config)
+	if err != nil {
+		if errors.Is(err, repository.ErrConfigAlreadyExists) {
+			return nil, ErrConfigExists
+		}
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return nil, ErrEnvironmentNotFound
+		}
+		if errors.Is(err, repository.ErrSecretsUnavailable) {
+			return nil, ErrSecretsUnavailable
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func updateConfig(ctx context.Context, repo repository.ConfigRepository, environment, key, value string, spec model.ValueSpec, actor string, version int64) (*model.Revision, error) {
+	config, err := repo.Get(ctx, environment, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrConfigNotFound) {
+			return nil, ErrConfigNotFound
+		}
+		return nil, err
+	}
+	if version != 0 && config.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	if spec.IsZero() {
+		spec = config.ValueSpec
+	}
+	if err := config.UpdateTypedValue(value, spec); err != nil {
+		return nil, err
+	}
+	config.UpdatedBy = actor
+
+	revision, err := repo.Update(ctx, config)
+	if err != nil {
+		if errors.Is(err, repository.ErrConfigNotFound) {
+			return nil, ErrConfigNotFound
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrSecretsUnavailable) {
+			return nil, ErrSecretsUnavailable
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func deleteConfig(ctx context.Context, repo repository.ConfigRepository, environment, key, actor string, version int64) (*model.Revision, error) {
+	exists, err := repo.Exists(ctx, environment, key)
+	if err != nil {
+		return nil, err
 	}
 	if !exists {
-		return ErrConfigNotFound
+		return nil, ErrConfigNotFound
 	}
 
-	if err := s.repo.Delete(environment, key); err != nil {
+	revision, err := repo.Delete(ctx, environment, key, actor, version)
+	if err != nil {
 		if errors.Is(err, repository.ErrConfigNotFound) {
-			return ErrConfigNotFound
+			return nil, ErrConfigNotFound
 		}
-		return err
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ErrVersionMismatch
+		}
+		return nil, err
 	}
 
-	return nil
+	return revision, nil
+}
+
+func (s *configService) RollbackConfig(ctx context.Context, environment, key string, revision int64, actor string) ([]*model.Revision, error) {
+	var applied []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		history, err := repo.GetHistory(ctx, environment, key)
+		if err != nil {
+			return err
+		}
+
+		var target *model.Revision
+		for _, candidate := range history {
+			if candidate.Revision == revision {
+				target = candidate
+				break
+			}
+		}
+		if target == nil {
+			return ErrRevisionNotFound
+		}
+
+		var state *model.Config
+		if target.Operation != model.OperationDelete {
+			state = target.Config()
+		}
+
+		change, err := restoreConfig(ctx, repo, environment, key, state, actor)
+		if err != nil {
+			return err
+		}
+		if change != nil {
+			applied = append(applied, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(applied)
+	return maskRevisions(applied, nil)
+}
+
+func (s *configService) RollbackEnvironment(ctx context.Context, environment string, asOf time.Time, actor string) ([]*model.Revision, error) {
+	var applied []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		target, err := repo.GetAllAt(ctx, environment, asOf)
+		if err != nil {
+			return err
+		}
+		current, err := repo.GetAll(ctx, environment)
+		if err != nil {
+			return err
+		}
+
+		states := make(map[string]*model.Config, len(target))
+		for _, config := range target {
+			states[config.Key] = config
+		}
+		keys := make([]string, 0, len(target)+len(current))
+		for _, config := range target {
+			keys = append(keys, config.Key)
+		}
+		for _, config := range current {
+			if _, ok := states[config.Key]; !ok {
+				keys = append(keys, config.Key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			change, err := restoreConfig(ctx, repo, environment, key, states[key], actor)
+			if err != nil {
+				return err
+			}
+			if change != nil {
+				applied = append(applied, change)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(applied)
+	return maskRevisions(applied, nil)
+}
+
+// restoreConfig brings a single key to the given state using the same
+// create/update/delete rules as the public write methods. A nil state means
+// the key must not exist. It returns nil when the key already matches.
+// The key gets the type it had in the restored state, and a value that is
+// secret now or was secret in the restored state stays secret.
+func restoreConfig(ctx context.Context, repo repository.ConfigRepository, environment, key string, state *model.Config, actor string) (*model.Revision, error) {
+	current, err := repo.Get(ctx, environment, key)
+	if err != nil && !errors.Is(err, repository.ErrConfigNotFound) {
+		return nil, err
+	}
+
+	switch {
+	case state == nil && current == nil:
+		return nil, nil
+	case state == nil:
+		return repo.Delete(ctx, environment, key, actor, current.Version)
+	case current == nil:
+		config, err := model.NewTypedConfig(environment, key, state.Value, state.ValueSpec)
+		if err != nil {
+			return nil, err
+		}
+		config.UpdatedBy = actor
+		revision, err := repo.Create(ctx, config)
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return nil, ErrEnvironmentNotFound
+		}
+		if errors.Is(err, repository.ErrSecretsUnavailable) {
+			return nil, ErrSecretsUnavailable
+		}
+		return revision, err
+	}
+
+	spec := state.ValueSpec
+	spec.Secret = spec.Secret || current.Secret
+	if current.Value == state.Value && current.ValueSpec.Equal(spec) {
+		return nil, nil
+	}
+	if err := current.UpdateTypedValue(state.Value, spec); err != nil {
+		return nil, err
+	}
+	current.UpdatedBy = actor
+	revision, err := repo.Update(ctx, current)
+	if errors.Is(err, repository.ErrSecretsUnavailable) {
+		return nil, ErrSecretsUnavailable
+	}
+	return revision, err
+}
+
+func maskConfigs(configs []*model.Config, err error) ([]*model.Config, error) {
+	if err != nil {
+		return nil, err
+	}
+	masked := make([]*model.Config, len(configs))
+	for i, config := range configs {
+		masked[i] = config.Masked()
+	}
+	return masked, nil
+}
+
+func maskRevisions(revisions []*model.Revision, err error) ([]*model.Revision, error) {
+	if err != nil {
+		return nil, err
+	}
+	masked := make([]*model.Revision, len(revisions))
+	for i, revision := range revisions {
+		masked[i] = revision.Masked()
+	}
+	return masked, nil
 }
@@ -3,20 +3,24 @@ package service
 import (
 	"config-service/backend/internal/model"
 	"config-service/backend/internal/repository"
+	"context"
 	"errors"
 	"testing"
+	"time"
 )
 
 type controllableRepository struct {
-	createErr error
-	getConfig *model.Config
-	getErr    error
-	getAll    []*model.Config
-	getAllErr error
-	updateErr error
-	deleteErr error
-	exists    bool
-	existsErr error
+	createErr  error
+	getConfig  *model.Config
+	getErr     error
+	getAll     []*model.Config
+	getAllErr  error
+	updateErr  error
+	deleteErr  error
+	exists     bool
+	existsErr  error
+	history    []*model.Revision
+	historyErr error
 
 	created    *model.Config
 	updated    *model.Config
@@ -27,40 +31,114 @@ type controllableRepository struct {
 	getAllEnv  string
 }
 
-func (r *controllableRepository) Create(config *model.Config) error {
+func (r *controllableRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
 	r.created = config
-	return r.createErr
+	if r.createErr != nil {
+		return nil, r.createErr
+	}
+	return &model.Revision{Environment: config.Environment, Key: config.Key, Operation: model.OperationCreate}, nil
 }
 
-func (r *controllableRepository) Get(environment, key string) (*model.Config, error) {
+func (r *controllableRepository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
 	if r.getErr != nil {
 		return nil, r.getErr
 	}
 	return r.getConfig, nil
 }
 
-func (r *controllableRepository) GetAll(environment string) ([]*model.Config, error) {
+func (r *controllableRepository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
+	r.getAllEnv = environment
+	return r.getAll, r.getAllErr
+}
+
+func (r *controllableRepository) ListConfigs(ctx context.Context, environment string, _ model.ConfigQuery) ([]*model.Config, error) {
 	r.getAllEnv = environment
 	return r.getAll, r.getAllErr
 }
 
-func (r *controllableRepository) Update(config *model.Config) error {
+func (r *controllableRepository) CountConfigs(context.Context, string, model.ConfigQuery) (int, error) {
+	return len(r.getAll), r.getAllErr
+}
+
+func (r *controllableRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
 	r.updated = config
-	return r.updateErr
+	if r.updateErr != nil {
+		return nil, r.updateErr
+	}
+	return &model.Revision{Environment: config.Environment, Key: config.Key, Operation: model.OperationUpdate}, nil
 }
 
-func (r *controllableRepository) Delete(environment, key string) error {
+func (r *controllableRepository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
 	r.deletedEnv = environment
 	r.deletedKey = key
-	return r.deleteErr
+	if r.deleteErr != nil {
+		return nil, r.deleteErr
+	}
+	return &model.Revision{Environment: environment, Key: key, Operation: model.OperationDelete, Actor: actor}, nil
 }
 
-func (r *controllableRepository) Exists(environment, key string) (bool, error) {
+func (r *controllableRepository) Exists(ctx context.Context, environment, key string) (bool, error) {
 	r.existsEnv = environment
 	r.existsKey = key
 	return r.exists, r.existsErr
 }
 
+func (r *controllableRepository) GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	return r.getConfig, nil
+}
+
+func (r *controllableRepository) GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	r.getAllEnv = environment
+	return r.getAll, r.getAllErr
+}
+
+func (r *controllableRepository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	return r.history, r.historyErr
+}
+
+func (r *controllableRepository) GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error) {
+	return r.history, r.historyErr
+}
+
+func (r *controllableRepository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	return nil, repository.ErrConfigNotFound
+}
+
+func (r *controllableRepository) CreateEnvironment(context.Context, *model.Environment) error {
+	return nil
+}
+
+func (r *controllableRepository) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	return &model.Environment{Name: name}, nil
+}
+
+func (r *controllableRepository) GetEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	return nil, nil
+}
+
+func (r *controllableRepository) UpdateEnvironment(context.Context, *model.Environment) error {
+	return nil
+}
+
+func (r *controllableRepository) DeleteEnvironment(context.Context, string) error {
+	return nil
+}
+
+func (r *controllableRepository) WithTx(ctx context.Context, fn func(repository.ConfigRepository) error) error {
+	return fn(r)
+}
+
+func (r *controllableRepository) AppendAudit(context.Context, *model.AuditEntry) error {
+	return nil
+}
+
+func (r *controllableRepository) GetAuditEntries(context.Context, model.AuditFilter) ([]*model.AuditEntry, error) {
+	return nil, nil
+}
+
 func TestConfigService_CreateConfigRepositoryErrors(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -86,7 +164,7 @@ func TestConfigService_CreateConfigRepositoryErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := NewConfigService(tt.repo).CreateConfig("prod", "key", "value")
+			err := NewConfigService(tt.repo, NewBroker()).CreateConfig(context.Background(), "prod", "key", "value", model.ValueSpec{}, "alice")
 			if err == nil {
 				t.Fatal("expected error")
 			}
@@ -107,7 +185,7 @@ func TestConfigService_GetConfigReturnsDatabaseError(t *testing.T) {
 	wantErr := errors.New("select failed")
 	repo := &controllableRepository{getErr: wantErr}
 
-	_, err := NewConfigService(repo).GetConfig("prod", "key")
+	_, err := NewConfigService(repo, NewBroker()).GetConfig(context.Background(), "prod", "key")
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("GetConfig() error = %v, want %v", err, wantErr)
 	}
@@ -120,7 +198,7 @@ func TestConfigService_GetAllConfigs(t *testing.T) {
 	}
 
 	repo := &controllableRepository{getAll: []*model.Config{config}}
-	got, err := NewConfigService(repo).GetAllConfigs("prod")
+	got, err := NewConfigService(repo, NewBroker()).GetAllConfigs(context.Background(), "prod")
 	if err != nil {
 		t.Fatalf("GetAllConfigs() error = %v", err)
 	}
@@ -136,7 +214,7 @@ func TestConfigService_GetAllConfigsReturnsRepositoryError(t *testing.T) {
 	wantErr := errors.New("select failed")
 	repo := &controllableRepository{getAllErr: wantErr}
 
-	_, err := NewConfigService(repo).GetAllConfigs("prod")
+	_, err := NewConfigService(repo, NewBroker()).GetAllConfigs(context.Background(), "prod")
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("GetAllConfigs() error = %v, want %v", err, wantErr)
 	}
@@ -176,7 +254,7 @@ func TestConfigService_UpdateConfigErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := NewConfigService(tt.repo).UpdateConfig("prod", "key", tt.value)
+			err := NewConfigService(tt.repo, NewBroker()).UpdateConfig(context.Background(), "prod", "key", tt.value, model.ValueSpec{}, "alice", 0)
 			if err == nil {
 				t.Fatal("expected error")
 			}
@@ -200,7 +278,7 @@ func TestConfigService_UpdateConfigReturnsGetDatabaseError(t *testing.T) {
 	wantErr := errors.New("select failed")
 	repo := &controllableRepository{getErr: wantErr}
 
-	err := NewConfigService(repo).UpdateConfig("prod", "key", "value")
+	err := NewConfigService(repo, NewBroker()).UpdateConfig(context.Background(), "prod", "key", "value", model.ValueSpec{}, "alice", 0)
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("UpdateConfig() error = %v, want %v", err, wantErr)
 	}
@@ -234,7 +312,7 @@ func TestConfigService_DeleteConfigErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := NewConfigService(tt.repo).DeleteConfig("prod", "key")
+			err := NewConfigService(tt.repo, NewBroker()).DeleteConfig(context.Background(), "prod", "key", "alice", 0)
 			if err == nil {
 				t.Fatal("expected error")
 			}
@@ -250,3 +328,63 @@ func TestConfigService_DeleteConfigErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigService_WritesRecordActor(t *testing.T) {
+	ctx := context.Background()
+	config, err := model.NewConfig("prod", "key", "old")
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	repo := &controllableRepository{}
+	if err := NewConfigService(repo, NewBroker()).CreateConfig(ctx, "prod", "key", "value", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if repo.created == nil || repo.created.UpdatedBy != "alice" {
+		t.Fatalf("Create called with %#v, want UpdatedBy alice", repo.created)
+	}
+
+	repo = &controllableRepository{getConfig: config}
+	if err := NewConfigService(repo, NewBroker()).UpdateConfig(ctx, "prod", "key", "new", model.ValueSpec{}, "bob", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if repo.updated == nil || repo.updated.UpdatedBy != "bob" {
+		t.Fatalf("Update called with %#v, want UpdatedBy bob", repo.updated)
+	}
+}
+
+func TestConfigService_UpdateConfigMapsVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	config, err := model.NewConfig("prod", "key", "old")
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	config.Version = 4
+
+	repo := &controllableRepository{getConfig: config}
+	if err := NewConfigService(repo, NewBroker()).UpdateConfig(ctx, "prod", "key", "new", model.ValueSpec{}, "alice", 3); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("UpdateConfig() stale version error = %v, want %v", err, ErrVersionMismatch)
+	}
+	if repo.updated != nil {
+		t.Fatal("repository Update should not be called for a stale version")
+	}
+
+	repo = &controllableRepository{getConfig: config, updateErr: repository.ErrVersionConflict}
+	if err := NewConfigService(repo, NewBroker()).UpdateConfig(ctx, "prod", "key", "new", model.ValueSpec{}, "alice", 0); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("UpdateConfig() concurrent write error = %v, want %v", err, ErrVersionMismatch)
+	}
+
+	repo = &controllableRepository{exists: true, deleteErr: repository.ErrVersionConflict}
+	if err := NewConfigService(repo, NewBroker()).DeleteConfig(ctx, "prod", "key", "alice", 3); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("DeleteConfig() stale version error = %v, want %v", err, ErrVersionMismatch)
+	}
+}
+
+func TestConfigService_GetConfigHistoryReturnsRepositoryError(t *testing.T) {
+	wantErr := errors.New("select failed")
+	repo := &controllableRepository{historyErr: wantErr}
+
+	_, err := NewConfigService(repo, NewBroker()).GetConfigHistory(context.Background(), "prod", "key")
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("GetConfigHistory() error = %v, want %v", err, wantErr)
+	}
+}
@@ -3,72 +3,329 @@ package service
 import (
 	"config-service/backend/internal/model"
 	"config-service/backend/internal/repository"
+	"context"
 	"errors"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockRepository struct {
-	configs map[string]*model.Config
+	configs      map[string]*model.Config
+	revisions    []*model.Revision
+	environments map[string]*model.Environment
+	audit        []*model.AuditEntry
+	auditErr     error
 }
 
 func newMockRepository() *mockRepository {
 	return &mockRepository{
 		configs: make(map[string]*model.Config),
+		environments: map[string]*model.Environment{
+			"prod":    {Name: "prod"},
+			"staging": {Name: "staging"},
+		},
+	}
+}
+
+func (m *mockRepository) CreateEnvironment(ctx context.Context, environment *model.Environment) error {
+	if _, exists := m.environments[environment.Name]; exists {
+		return repository.ErrEnvironmentAlreadyExists
+	}
+	stored := *environment
+	m.environments[environment.Name] = &stored
+	return nil
+}
+
+func (m *mockRepository) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	environment, exists := m.environments[name]
+	if !exists {
+		return nil, repository.ErrEnvironmentNotFound
+	}
+	result := *environment
+	return &result, nil
+}
+
+func (m *mockRepository) GetEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	names := make([]string, 0, len(m.environments))
+	for name := range m.environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	environments := make([]*model.Environment, 0, len(names))
+	for _, name := range names {
+		environment, _ := m.GetEnvironment(ctx, name)
+		environments = append(environments, environment)
+	}
+	return environments, nil
+}
+
+func (m *mockRepository) UpdateEnvironment(ctx context.Context, environment *model.Environment) error {
+	if _, exists := m.environments[environment.Name]; !exists {
+		return repository.ErrEnvironmentNotFound
+	}
+	stored := *environment
+	m.environments[environment.Name] = &stored
+	return nil
+}
+
+func (m *mockRepository) DeleteEnvironment(ctx context.Context, name string) error {
+	if _, exists := m.environments[name]; !exists {
+		return repository.ErrEnvironmentNotFound
 	}
+	delete(m.environments, name)
+	return nil
 }
 
-func (m *mockRepository) Create(config *model.Config) error {
+func (m *mockRepository) record(config *model.Config, operation model.Operation, actor string) *model.Revision {
+	revision := &model.Revision{
+		Revision:    int64(len(m.revisions) + 1),
+		Environment: config.Environment,
+		Key:         config.Key,
+		Value:       config.Value,
+		Operation:   operation,
+		Actor:       actor,
+		CreatedAt:   time.Now(),
+		ValueSpec:   config.ValueSpec,
+	}
+	m.revisions = append(m.revisions, revision)
+	config.Revision = revision.Revision
+	return revision
+}
+
+func (m *mockRepository) Create(ctx context.Context, config *model.Config) (*model.Revision, error) {
 	key := config.Environment + ":" + config.Key
 	if _, exists := m.configs[key]; exists {
-		return errors.New("already exists")
+		return nil, errors.New("already exists")
 	}
-	m.configs[key] = config
-	return nil
+	revision := m.record(config, model.OperationCreate, config.UpdatedBy)
+	config.Version = 1
+	stored := *config
+	m.configs[key] = &stored
+	return revision, nil
 }
 
-func (m *mockRepository) Get(environment, key string) (*model.Config, error) {
+func (m *mockRepository) Get(ctx context.Context, environment, key string) (*model.Config, error) {
 	lookupKey := environment + ":" + key
 	config, exists := m.configs[lookupKey]
 	if !exists {
 		return nil, repository.ErrConfigNotFound
 	}
-	return config, nil
+	result := *config
+	return &result, nil
 }
 
-func (m *mockRepository) GetAll(environment string) ([]*model.Config, error) {
+func (m *mockRepository) GetAll(ctx context.Context, environment string) ([]*model.Config, error) {
 	var result []*model.Config
-	for key, config := range m.configs {
-		if len(key) > len(environment)+1 && key[:len(environment)] == environment {
+	for _, config := range m.configs {
+		if config.Environment == environment {
 			result = append(result, config)
 		}
 	}
 	return result, nil
 }
 
-func (m *mockRepository) Update(config *model.Config) error {
+func (m *mockRepository) ListConfigs(ctx context.Context, environment string, query model.ConfigQuery) ([]*model.Config, error) {
+	matches := m.matchConfigs(environment, query)
+	if query.SortOrDefault() == model.SortByUpdatedAt {
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].UpdatedAt.After(matches[j].UpdatedAt) })
+	}
+
+	result := make([]*model.Config, 0, len(matches))
+	for _, config := range matches {
+		if after := query.After; after != nil {
+			if after.Sort == model.SortByUpdatedAt && (config.UpdatedAt.After(after.UpdatedAt) ||
+				config.UpdatedAt.Equal(after.UpdatedAt) && config.Key <= after.Key) {
+				continue
+			}
+			if after.Sort == model.SortByKey && config.Key <= after.Key {
+				continue
+			}
+		}
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+		result = append(result, config)
+	}
+	return result, nil
+}
+
+func (m *mockRepository) CountConfigs(ctx context.Context, environment string, query model.ConfigQuery) (int, error) {
+	return len(m.matchConfigs(environment, query)), nil
+}
+
+// matchConfigs applies the filters of query and sorts the matches by key.
+func (m *mockRepository) matchConfigs(environment string, query model.ConfigQuery) []*model.Config {
+	var matches []*model.Config
+	for _, config := range m.configs {
+		search := strings.ToLower(query.Search)
+		switch {
+		case config.Environment != environment,
+			!strings.HasPrefix(config.Key, query.Prefix),
+			!model.InTree(config.Key, query.Tree),
+			!strings.Contains(strings.ToLower(config.Key), search) &&
+				(config.Secret || !strings.Contains(strings.ToLower(config.Value), search)),
+			config.UpdatedAt.Before(query.UpdatedSince):
+			continue
+		}
+		if query.AllowedPrefixes != nil && !slices.ContainsFunc(query.AllowedPrefixes, func(prefix string) bool {
+			return strings.HasPrefix(config.Key, prefix)
+		}) {
+			continue
+		}
+		matches = append(matches, config)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Key < matches[j].Key })
+	return matches
+}
+
+func (m *mockRepository) Update(ctx context.Context, config *model.Config) (*model.Revision, error) {
 	key := config.Environment + ":" + config.Key
-	if _, exists := m.configs[key]; !exists {
-		return repository.ErrConfigNotFound
+	stored, exists := m.configs[key]
+	if !exists {
+		return nil, repository.ErrConfigNotFound
 	}
-	m.configs[key] = config
-	return nil
+	if stored.Version != config.Version {
+		return nil, repository.ErrVersionConflict
+	}
+	revision := m.record(config, model.OperationUpdate, config.UpdatedBy)
+	config.Version++
+	updated := *config
+	m.configs[key] = &updated
+	return revision, nil
 }
 
-func (m *mockRepository) Delete(environment, key string) error {
+func (m *mockRepository) Delete(ctx context.Context, environment, key, actor string, version int64) (*model.Revision, error) {
 	lookupKey := environment + ":" + key
-	if _, exists := m.configs[lookupKey]; !exists {
-		return repository.ErrConfigNotFound
+	config, exists := m.configs[lookupKey]
+	if !exists {
+		return nil, repository.ErrConfigNotFound
+	}
+	if version != 0 && config.Version != version {
+		return nil, repository.ErrVersionConflict
 	}
 	delete(m.configs, lookupKey)
-	return nil
+	return m.record(config, model.OperationDelete, actor), nil
 }
 
-func (m *mockRepository) Exists(environment, key string) (bool, error) {
+func (m *mockRepository) Exists(ctx context.Context, environment, key string) (bool, error) {
 	lookupKey := environment + ":" + key
 	_, exists := m.configs[lookupKey]
 	return exists, nil
 }
 
+func (m *mockRepository) GetAt(ctx context.Context, environment, key string, asOf time.Time) (*model.Config, error) {
+	configs, _ := m.GetAllAt(ctx, environment, asOf)
+	for _, config := range configs {
+		if config.Key == key {
+			return config, nil
+		}
+	}
+	return nil, repository.ErrConfigNotFound
+}
+
+func (m *mockRepository) GetAllAt(ctx context.Context, environment string, asOf time.Time) ([]*model.Config, error) {
+	latest := make(map[string]*model.Revision)
+	var keys []string
+	for _, revision := range m.revisions {
+		if revision.Environment != environment || revision.CreatedAt.After(asOf) {
+			continue
+		}
+		if _, seen := latest[revision.Key]; !seen {
+			keys = append(keys, revision.Key)
+		}
+		latest[revision.Key] = revision
+	}
+
+	var result []*model.Config
+	for _, key := range keys {
+		if latest[key].Operation != model.OperationDelete {
+			result = append(result, latest[key].Config())
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	var result []*model.Revision
+	for i := len(m.revisions) - 1; i >= 0; i-- {
+		if m.revisions[i].Environment == environment && m.revisions[i].Key == key {
+			result = append(result, m.revisions[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetRevisionsSince(ctx context.Context, environment string, since int64, limit int) ([]*model.Revision, error) {
+	var result []*model.Revision
+	for _, revision := range m.revisions {
+		if revision.Environment == environment && revision.Revision > since && len(result) < limit {
+			result = append(result, revision)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetRevision(ctx context.Context, revision int64) (*model.Revision, error) {
+	for _, candidate := range m.revisions {
+		if candidate.Revision == revision {
+			return candidate, nil
+		}
+	}
+	return nil, repository.ErrConfigNotFound
+}
+
+func (m *mockRepository) WithTx(ctx context.Context, fn func(repository.ConfigRepository) error) error {
+	configs := make(map[string]*model.Config, len(m.configs))
+	for key, config := range m.configs {
+		snapshot := *config
+		configs[key] = &snapshot
+	}
+	environments := make(map[string]*model.Environment, len(m.environments))
+	for name, environment := range m.environments {
+		snapshot := *environment
+		environments[name] = &snapshot
+	}
+	revisions := len(m.revisions)
+	audit := len(m.audit)
+
+	if err := fn(m); err != nil {
+		m.configs = configs
+		m.environments = environments
+		m.revisions = m.revisions[:revisions]
+		m.audit = m.audit[:audit]
+		return err
+	}
+	return nil
+}
+
+func (m *mockRepository) AppendAudit(ctx context.Context, entry *model.AuditEntry) error {
+	if m.auditErr != nil {
+		return m.auditErr
+	}
+	entry.ID = int64(len(m.audit) + 1)
+	entry.CreatedAt = time.Now()
+	m.audit = append(m.audit, entry)
+	return nil
+}
+
+func (m *mockRepository) GetAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	var result []*model.AuditEntry
+	for i := len(m.audit) - 1; i >= 0 && len(result) < filter.Limit; i-- {
+		entry := m.audit[i]
+		if (filter.Environment == "" || entry.Environment == filter.Environment) &&
+			(filter.Key == "" || entry.Key == filter.Key) &&
+			(filter.Actor == "" || entry.Actor == filter.Actor) &&
+			(filter.Cursor == 0 || entry.ID < filter.Cursor) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
 func TestConfigService_CreateConfig(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -107,15 +364,24 @@ func TestConfigService_CreateConfig(t *testing.T) {
 			setup:       func(*mockRepository) {},
 			wantErr:     true,
 		},
+		{
+			name:        "key named after an environment action",
+			environment: "prod",
+			key:         "export",
+			value:       "value1",
+			setup:       func(*mockRepository) {},
+			wantErr:     true,
+			errType:     model.ErrInvalidKey,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockRepository()
 			tt.setup(repo)
-			svc := NewConfigService(repo)
+			svc := NewConfigService(repo, NewBroker())
 
-			err := svc.CreateConfig(tt.environment, tt.key, tt.value)
+			err := svc.CreateConfig(context.Background(), tt.environment, tt.key, tt.value, model.ValueSpec{}, "alice")
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("expected error but got none")
@@ -138,7 +404,7 @@ func TestConfigService_GetConfig(t *testing.T) {
 	config, _ := model.NewConfig("prod", "key1", "value1")
 	repo.configs["prod:key1"] = config
 
-	svc := NewConfigService(repo)
+	svc := NewConfigService(repo, NewBroker())
 
 	tests := []struct {
 		name        string
@@ -162,7 +428,7 @@ func TestConfigService_GetConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config, err := svc.GetConfig(tt.environment, tt.key)
+			config, err := svc.GetConfig(context.Background(), tt.environment, tt.key)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("expected error but got none")
@@ -187,7 +453,7 @@ func TestConfigService_UpdateConfig(t *testing.T) {
 	config, _ := model.NewConfig("prod", "key1", "old_value")
 	repo.configs["prod:key1"] = config
 
-	svc := NewConfigService(repo)
+	svc := NewConfigService(repo, NewBroker())
 
 	tests := []struct {
 		name        string
@@ -214,7 +480,7 @@ func TestConfigService_UpdateConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := svc.UpdateConfig(tt.environment, tt.key, tt.value)
+			err := svc.UpdateConfig(context.Background(), tt.environment, tt.key, tt.value, model.ValueSpec{}, "alice", 0)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("expected error but got none")
@@ -233,7 +499,7 @@ func TestConfigService_DeleteConfig(t *testing.T) {
 	config, _ := model.NewConfig("prod", "key1", "value1")
 	repo.configs["prod:key1"] = config
 
-	svc := NewConfigService(repo)
+	svc := NewConfigService(repo, NewBroker())
 
 	tests := []struct {
 		name        string
@@ -257,7 +523,7 @@ func TestConfigService_DeleteConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := svc.DeleteConfig(tt.environment, tt.key)
+			err := svc.DeleteConfig(context.Background(), tt.environment, tt.key, "alice", 0)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("expected error but got none")
@@ -270,3 +536,481 @@ func TestConfigService_DeleteConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigService_HistoryAndPointInTimeReads(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "key1", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "key1", "v2", model.ValueSpec{}, "bob", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if err := svc.DeleteConfig(ctx, "prod", "key1", "carol", 0); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+
+	history, err := svc.GetConfigHistory(ctx, "prod", "key1")
+	if err != nil {
+		t.Fatalf("GetConfigHistory() error = %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("GetConfigHistory() returned %d revisions, want 3", len(history))
+	}
+	wantOps := []model.Operation{model.OperationDelete, model.OperationUpdate, model.OperationCreate}
+	wantActors := []string{"carol", "bob", "alice"}
+	for i, revision := range history {
+		if revision.Operation != wantOps[i] || revision.Actor != wantActors[i] {
+			t.Fatalf("history[%d] = %s by %s, want %s by %s", i, revision.Operation, revision.Actor, wantOps[i], wantActors[i])
+		}
+	}
+
+	repo.revisions[0].CreatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.revisions[1].CreatedAt = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo.revisions[2].CreatedAt = time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	config, err := svc.GetConfigAt(ctx, "prod", "key1", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetConfigAt() error = %v", err)
+	}
+	if config.Value != "v1" {
+		t.Fatalf("GetConfigAt() value = %q, want v1", config.Value)
+	}
+
+	configs, err := svc.GetAllConfigsAt(ctx, "prod", time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetAllConfigsAt() error = %v", err)
+	}
+	if len(configs) != 1 || configs[0].Value != "v2" {
+		t.Fatalf("GetAllConfigsAt() = %#v", configs)
+	}
+
+	if _, err := svc.GetConfigAt(ctx, "prod", "key1", time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("GetConfigAt() after delete error = %v, want %v", err, ErrConfigNotFound)
+	}
+	if _, err := svc.GetConfigHistory(ctx, "prod", "missing"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("GetConfigHistory() missing error = %v, want %v", err, ErrConfigNotFound)
+	}
+}
+
+func TestConfigService_ListConfigs(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	for i, key := range []string{"db.host", "db.password", "db.port", "feature.flag"} {
+		spec := model.ValueSpec{Secret: key == "db.password"}
+		if err := svc.CreateConfig(ctx, "prod", key, "host-"+key, spec, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+		repo.configs["prod:"+key].UpdatedAt = time.Date(2026, 1, 1+i%2, 0, 0, 0, 0, time.UTC)
+	}
+
+	var keys []string
+	query := model.ConfigQuery{Prefix: "db.", Limit: 2}
+	for {
+		page, err := svc.ListConfigs(ctx, "prod", query)
+		if err != nil || page.Total != 3 {
+			t.Fatalf("ListConfigs(%#v) = %#v, %v", query, page, err)
+		}
+		for _, config := range page.Configs {
+			keys = append(keys, config.Key)
+			if config.Key == "db.password" && config.Value != model.SecretMask {
+				t.Fatalf("secret value = %q, want it masked", config.Value)
+			}
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		query.After = page.NextCursor
+	}
+	if strings.Join(keys, ",") != "db.host,db.password,db.port" {
+		t.Fatalf("pages by key = %v", keys)
+	}
+
+	page, err := svc.ListConfigs(ctx, "prod", model.ConfigQuery{Sort: model.SortByUpdatedAt, Limit: 3})
+	if err != nil || len(page.Configs) != 3 || page.Configs[0].Key != "db.password" || page.Configs[1].Key != "feature.flag" || page.NextCursor == nil {
+		t.Fatalf("ListConfigs(updated_at) = %#v, %v", page, err)
+	}
+	page, err = svc.ListConfigs(ctx, "prod", model.ConfigQuery{Sort: model.SortByUpdatedAt, After: page.NextCursor})
+	if err != nil || len(page.Configs) != 1 || page.Configs[0].Key != "db.port" || page.NextCursor != nil {
+		t.Fatalf("ListConfigs(updated_at) last page = %#v, %v", page, err)
+	}
+
+	page, err = svc.ListConfigs(ctx, "prod", model.ConfigQuery{Search: "HOST-DB"})
+	if err != nil || page.Total != 2 || page.Configs[0].Key != "db.host" || page.Configs[1].Key != "db.port" {
+		t.Fatalf("search must skip secret values, got %#v, %v", page, err)
+	}
+	if _, err := svc.ListConfigs(ctx, "prod", model.ConfigQuery{Limit: model.MaxConfigPageSize + 1}); !errors.Is(err, model.ErrInvalidConfigQuery) {
+		t.Fatalf("ListConfigs() error = %v, want %v", err, model.ErrInvalidConfigQuery)
+	}
+}
+
+func TestConfigService_DeleteTree(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	for _, key := range []string{"payments.stripe", "payments.stripe.timeout", "payments.stripe_v2.timeout", "payments.currency"} {
+		if err := svc.CreateConfig(ctx, "prod", key, "v", model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+	}
+
+	repo.auditErr = errors.New("disk full")
+	if _, err := svc.DeleteTree(ctx, "prod", "payments.stripe", "bob"); !errors.Is(err, repo.auditErr) {
+		t.Fatalf("DeleteTree() error = %v, want %v", err, repo.auditErr)
+	}
+	if len(repo.configs) != 4 {
+		t.Fatalf("failed DeleteTree() left %d keys, want all 4", len(repo.configs))
+	}
+	repo.auditErr = nil
+
+	deleted, err := svc.DeleteTree(ctx, "prod", "payments.stripe", "bob")
+	if err != nil || len(deleted) != 2 || deleted[0].Key != "payments.stripe" || deleted[1].Key != "payments.stripe.timeout" {
+		t.Fatalf("DeleteTree() = %#v, %v", deleted, err)
+	}
+	if deleted[0].Operation != model.OperationDelete || deleted[0].Actor != "bob" {
+		t.Fatalf("revision = %#v", deleted[0])
+	}
+	if exists, _ := repo.Exists(ctx, "prod", "payments.stripe_v2.timeout"); !exists || len(repo.configs) != 2 {
+		t.Fatalf("DeleteTree() removed keys outside the tree: %v", repo.configs)
+	}
+
+	if _, err := svc.DeleteTree(ctx, "prod", "payments.stripe", "bob"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("DeleteTree() of an empty tree error = %v, want %v", err, ErrConfigNotFound)
+	}
+	if _, err := svc.DeleteTree(ctx, "prod", "", "bob"); !errors.Is(err, model.ErrInvalidKey) {
+		t.Fatalf("DeleteTree() without tree error = %v, want %v", err, model.ErrInvalidKey)
+	}
+}
+
+func TestConfigService_RollbackConfig(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "key1", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "key1", "v2", model.ValueSpec{}, "bob", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+
+	applied, err := svc.RollbackConfig(ctx, "prod", "key1", 1, "carol")
+	if err != nil {
+		t.Fatalf("RollbackConfig() error = %v", err)
+	}
+	if len(applied) != 1 || applied[0].Operation != model.OperationUpdate || applied[0].Value != "v1" || applied[0].Actor != "carol" {
+		t.Fatalf("RollbackConfig() = %#v", applied)
+	}
+
+	applied, err = svc.RollbackConfig(ctx, "prod", "key1", 1, "carol")
+	if err != nil {
+		t.Fatalf("RollbackConfig() repeated error = %v", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("RollbackConfig() to current value applied %#v", applied)
+	}
+
+	if err := svc.DeleteConfig(ctx, "prod", "key1", "bob", 0); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+	applied, err = svc.RollbackConfig(ctx, "prod", "key1", 2, "carol")
+	if err != nil {
+		t.Fatalf("RollbackConfig() restore error = %v", err)
+	}
+	if len(applied) != 1 || applied[0].Operation != model.OperationCreate || applied[0].Value != "v2" {
+		t.Fatalf("RollbackConfig() restore = %#v", applied)
+	}
+
+	deleteRevision := repo.revisions[3].Revision
+	applied, err = svc.RollbackConfig(ctx, "prod", "key1", deleteRevision, "carol")
+	if err != nil {
+		t.Fatalf("RollbackConfig() to deletion error = %v", err)
+	}
+	if len(applied) != 1 || applied[0].Operation != model.OperationDelete {
+		t.Fatalf("RollbackConfig() to deletion = %#v", applied)
+	}
+
+	if _, err := svc.RollbackConfig(ctx, "prod", "key1", 999, "carol"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatalf("RollbackConfig() unknown revision error = %v, want %v", err, ErrRevisionNotFound)
+	}
+}
+
+func TestConfigService_RollbackRestoresTypedKey(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	spec := model.ValueSpec{Type: model.TypeEnum, Enum: []string{"debug", "info"}}
+
+	if err := svc.CreateConfig(ctx, "prod", "log.level", "info", spec, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.DeleteConfig(ctx, "prod", "log.level", "bob", 0); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+	applied, err := svc.RollbackConfig(ctx, "prod", "log.level", 1, "carol")
+	if err != nil || len(applied) != 1 || applied[0].Operation != model.OperationCreate {
+		t.Fatalf("RollbackConfig() = %#v, %v", applied, err)
+	}
+
+	restored, err := svc.GetConfig(ctx, "prod", "log.level")
+	if err != nil || restored.Value != "info" || !restored.ValueSpec.Equal(spec) {
+		t.Fatalf("GetConfig() after rollback = %#v, %v, want spec %v", restored, err, spec)
+	}
+	var valueErr *model.ValueError
+	if err := svc.UpdateConfig(ctx, "prod", "log.level", "verbose", model.ValueSpec{}, "bob", 0); !errors.As(err, &valueErr) {
+		t.Fatalf("UpdateConfig() with a value outside the enum error = %v, want a ValueError", err)
+	}
+}
+
+func TestConfigService_RollbackRestoresTypeChange(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	intSpec := model.ValueSpec{Type: model.TypeInt}
+
+	if err := svc.CreateConfig(ctx, "prod", "pool.size", "10", intSpec, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "pool.size", "10", model.ValueSpec{Type: model.TypeString}, "bob", 0); err != nil {
+		t.Fatalf("UpdateConfig() to string with the same value error = %v", err)
+	}
+	applied, err := svc.RollbackConfig(ctx, "prod", "pool.size", 1, "carol")
+	if err != nil || len(applied) != 1 || applied[0].Operation != model.OperationUpdate {
+		t.Fatalf("RollbackConfig() with the same value = %#v, %v", applied, err)
+	}
+	restored, err := svc.GetConfig(ctx, "prod", "pool.size")
+	if err != nil || !restored.ValueSpec.Equal(intSpec) {
+		t.Fatalf("GetConfig() after rollback = %#v, %v, want spec %v", restored, err, intSpec)
+	}
+
+	if err := svc.UpdateConfig(ctx, "prod", "pool.size", "auto", model.ValueSpec{Type: model.TypeString}, "bob", 0); err != nil {
+		t.Fatalf("UpdateConfig() to string error = %v", err)
+	}
+	if _, err := svc.RollbackConfig(ctx, "prod", "pool.size", 1, "carol"); err != nil {
+		t.Fatalf("RollbackConfig() to a value outside the current type error = %v", err)
+	}
+	restored, err = svc.GetConfig(ctx, "prod", "pool.size")
+	if err != nil || restored.Value != "10" || !restored.ValueSpec.Equal(intSpec) {
+		t.Fatalf("GetConfig() after rollback = %#v, %v, want 10 with spec %v", restored, err, intSpec)
+	}
+}
+
+func TestConfigService_RollbackEnvironment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	target := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, step := range []func() error{
+		func() error {
+			return svc.CreateConfig(ctx, "prod", "kept", "same", model.ValueSpec{}, "alice")
+		},
+		func() error {
+			return svc.CreateConfig(ctx, "prod", "changed", "v1", model.ValueSpec{}, "alice")
+		},
+		func() error {
+			return svc.CreateConfig(ctx, "prod", "removed", "v1", model.ValueSpec{}, "alice")
+		},
+		func() error {
+			return svc.UpdateConfig(ctx, "prod", "changed", "v2", model.ValueSpec{}, "bob", 0)
+		},
+		func() error { return svc.DeleteConfig(ctx, "prod", "removed", "bob", 0) },
+		func() error {
+			return svc.CreateConfig(ctx, "prod", "added", "v1", model.ValueSpec{}, "bob")
+		},
+		func() error {
+			return svc.CreateConfig(ctx, "staging", "other", "v1", model.ValueSpec{}, "bob")
+		},
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("setup error = %v", err)
+		}
+	}
+	for i, revision := range repo.revisions {
+		revision.CreatedAt = target.Add(time.Duration(i-2) * time.Hour)
+	}
+
+	applied, err := svc.RollbackEnvironment(ctx, "prod", target, "carol")
+	if err != nil {
+		t.Fatalf("RollbackEnvironment() error = %v", err)
+	}
+
+	got := make(map[string]model.Operation)
+	for _, revision := range applied {
+		got[revision.Key] = revision.Operation
+	}
+	want := map[string]model.Operation{
+		"added":   model.OperationDelete,
+		"changed": model.OperationUpdate,
+		"removed": model.OperationCreate,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("RollbackEnvironment() applied %v, want %v", got, want)
+	}
+	for key, operation := range want {
+		if got[key] != operation {
+			t.Fatalf("RollbackEnvironment() applied %v, want %v", got, want)
+		}
+	}
+
+	if config, _ := svc.GetConfig(ctx, "prod", "changed"); config == nil || config.Value != "v1" {
+		t.Fatalf("changed = %#v, want v1", config)
+	}
+	if _, err := svc.GetConfig(ctx, "prod", "added"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("added should be removed, got error %v", err)
+	}
+	if _, err := svc.GetConfig(ctx, "staging", "other"); err != nil {
+		t.Fatalf("other environments must not change: %v", err)
+	}
+}
+
+func TestConfigService_RollbackEnvironmentIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	target := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := svc.CreateConfig(ctx, "prod", "a", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "a", "v2", model.ValueSpec{}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	repo.revisions[0].CreatedAt = target.Add(-time.Hour)
+	repo.revisions[1].CreatedAt = target.Add(time.Hour)
+	repo.revisions = append(repo.revisions, &model.Revision{
+		Revision:    3,
+		Environment: "prod",
+		Key:         "b",
+		Value:       string(make([]byte, 10001)),
+		Operation:   model.OperationCreate,
+		CreatedAt:   target.Add(-time.Hour),
+	})
+
+	if _, err := svc.RollbackEnvironment(ctx, "prod", target, "carol"); !errors.Is(err, model.ErrInvalidValue) {
+		t.Fatalf("RollbackEnvironment() error = %v, want %v", err, model.ErrInvalidValue)
+	}
+	if config, _ := svc.GetConfig(ctx, "prod", "a"); config == nil || config.Value != "v2" {
+		t.Fatalf("a = %#v, want v2 after failed rollback", config)
+	}
+	if len(repo.revisions) != 3 {
+		t.Fatalf("revisions = %d, want 3 after failed rollback", len(repo.revisions))
+	}
+}
+
+func TestConfigService_VersionPreconditions(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "key1", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "key1", "v2", model.ValueSpec{}, "alice", 1); err != nil {
+		t.Fatalf("UpdateConfig() with current version error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "key1", "v3", model.ValueSpec{}, "bob", 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("UpdateConfig() with stale version error = %v, want %v", err, ErrVersionMismatch)
+	}
+
+	config, err := svc.GetConfig(ctx, "prod", "key1")
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	if config.Value != "v2" || config.Version != 2 {
+		t.Fatalf("GetConfig() = %#v, want v2 at version 2", config)
+	}
+
+	if err := svc.DeleteConfig(ctx, "prod", "key1", "bob", 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("DeleteConfig() with stale version error = %v, want %v", err, ErrVersionMismatch)
+	}
+	if err := svc.DeleteConfig(ctx, "prod", "key1", "bob", 2); err != nil {
+		t.Fatalf("DeleteConfig() with current version error = %v", err)
+	}
+}
+
+func TestConfigService_WatchReceivesCommittedWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	changes, cancel, err := svc.WatchConfigs(ctx, "prod")
+	if err != nil {
+		t.Fatalf("WatchConfigs() error = %v", err)
+	}
+	defer cancel()
+
+	if err := svc.CreateConfig(ctx, "prod", "key", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.CreateConfig(ctx, "staging", "key", "v1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "key", "v2", model.ValueSpec{}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "key", "v3", model.ValueSpec{}, "alice", 99); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("UpdateConfig() error = %v, want ErrVersionMismatch", err)
+	}
+	if err := svc.DeleteConfig(ctx, "prod", "key", "alice", 0); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+
+	want := []model.Operation{model.OperationCreate, model.OperationUpdate, model.OperationDelete}
+	for _, operation := range want {
+		revision := <-changes
+		if revision.Operation != operation || revision.Environment != "prod" {
+			t.Fatalf("received %s in %s, want %s in prod", revision.Operation, revision.Environment, operation)
+		}
+	}
+	select {
+	case revision := <-changes:
+		t.Fatalf("unexpected revision %#v", revision)
+	default:
+	}
+
+	since, err := svc.GetChangesSince(ctx, "prod", 1)
+	if err != nil {
+		t.Fatalf("GetChangesSince() error = %v", err)
+	}
+	if len(since) != 2 || since[0].Revision != 3 || since[1].Revision != 4 {
+		t.Fatalf("GetChangesSince() = %#v, want revisions 3 and 4", since)
+	}
+}
+
+func TestConfigService_TypedValues(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	boolSpec := model.ValueSpec{Type: model.TypeBool}
+
+	if err := svc.CreateConfig(ctx, "prod", "flag", "tru", boolSpec, "alice"); !errors.Is(err, model.ErrInvalidValue) {
+		t.Fatalf("CreateConfig() error = %v, want %v", err, model.ErrInvalidValue)
+	}
+	if err := svc.CreateConfig(ctx, "prod", "flag", "true", boolSpec, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "flag", "yes", model.ValueSpec{}, "alice", 0); !errors.Is(err, model.ErrInvalidValue) {
+		t.Fatalf("UpdateConfig() error = %v, want stored type to be enforced", err)
+	}
+	if err := svc.UpdateConfig(ctx, "prod", "flag", "false", model.ValueSpec{}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+
+	config, err := svc.GetConfig(ctx, "prod", "flag")
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	if config.Type != model.TypeBool || config.Value != "false" {
+		t.Fatalf("config = %#v, want bool false", config)
+	}
+
+	if err := svc.UpdateConfig(ctx, "prod", "flag", "yes", model.ValueSpec{Type: model.TypeString}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() changing type error = %v", err)
+	}
+	if err := svc.CreateConfig(ctx, "prod", "level", "info", model.ValueSpec{Type: model.TypeEnum}, "alice"); !errors.Is(err, model.ErrInvalidSpec) {
+		t.Fatalf("CreateConfig() error = %v, want %v", err, model.ErrInvalidSpec)
+	}
+}
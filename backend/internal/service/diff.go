@@ -0,0 +1,36 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+)
+
+// CompareEnvironments compares plaintext values, so changed secrets are
+// reported, but model.CompareConfigs masks them. Only current references
+// must name an existing environment; history outlives deleted ones.
+func (s *configService) CompareEnvironments(ctx context.Context, left, right model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+	leftConfigs, err := s.snapshot(ctx, left)
+	if err != nil {
+		return nil, err
+	}
+	rightConfigs, err := s.snapshot(ctx, right)
+	if err != nil {
+		return nil, err
+	}
+	return model.CompareConfigs(left, right, leftConfigs, rightConfigs), nil
+}
+
+func (s *configService) snapshot(ctx context.Context, ref model.EnvironmentRef) ([]*model.Config, error) {
+	if !ref.AsOf.IsZero() {
+		return s.repo.GetAllAt(ctx, ref.Name, ref.AsOf)
+	}
+	if _, err := s.repo.GetEnvironment(ctx, ref.Name); err != nil {
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return nil, ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+	return s.repo.GetAll(ctx, ref.Name)
+}
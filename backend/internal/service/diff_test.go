@@ -0,0 +1,49 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigService_CompareEnvironments(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	for _, config := range [][3]string{
+		{"staging", "db.host", "db-2"},
+		{"prod", "db.host", "db-1"},
+		{"prod", "legacy", "on"},
+	} {
+		if err := svc.CreateConfig(ctx, config[0], config[1], config[2], model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%v) error = %v", config, err)
+		}
+	}
+	before := time.Now()
+	if err := svc.UpdateConfig(ctx, "prod", "db.host", "db-2", model.ValueSpec{}, "alice", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+
+	diff, err := svc.CompareEnvironments(ctx, model.EnvironmentRef{Name: "staging"}, model.EnvironmentRef{Name: "prod"})
+	if err != nil {
+		t.Fatalf("CompareEnvironments() error = %v", err)
+	}
+	if len(diff.Changed) != 0 || len(diff.OnlyInRight) != 1 || diff.OnlyInRight[0].Key != "legacy" {
+		t.Fatalf("CompareEnvironments() = %#v", diff)
+	}
+
+	diff, err = svc.CompareEnvironments(ctx, model.EnvironmentRef{Name: "prod", AsOf: before}, model.EnvironmentRef{Name: "prod"})
+	if err != nil {
+		t.Fatalf("CompareEnvironments(as of) error = %v", err)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Left.Value != "db-1" || diff.Changed[0].Right.Value != "db-2" {
+		t.Fatalf("CompareEnvironments(as of) = %#v", diff)
+	}
+
+	if _, err := svc.CompareEnvironments(ctx, model.EnvironmentRef{Name: "missing"}, model.EnvironmentRef{Name: "prod"}); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("CompareEnvironments(missing) error = %v, want %v", err, ErrEnvironmentNotFound)
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxInheritanceDepth bounds the parent chain of an environment, including
+// the environment itself.
+const MaxInheritanceDepth = 16
+
+var (
+	ErrEnvironmentNotFound  = errors.New("environment not found")
+	ErrEnvironmentExists    = errors.New("environment already exists")
+	ErrEnvironmentProtected = errors.New("environment is protected")
+	ErrEnvironmentNotEmpty  = errors.New("environment still has configs")
+	ErrEnvironmentInUse     = errors.New("environment has child environments")
+)
+
+type EnvironmentService interface {
+	ListEnvironments(ctx context.Context) ([]*model.Environment, error)
+	GetEnvironment(ctx context.Context, name string) (*model.Environment, error)
+	CreateEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error)
+	// PutEnvironment creates the environment or replaces its attributes.
+	PutEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error)
+	// DeleteEnvironment fails with ErrEnvironmentNotEmpty while the
+	// environment has configs, unless force is set, in which case the configs
+	// are deleted in the same transaction.
+	DeleteEnvironment(ctx context.Context, name, actor string, force bool) error
+	// PromoteEnvironment makes target match source for the keys accepted by
+	// filter. With dryRun set it only reports the diff.
+	PromoteEnvironment(ctx context.Context, source, target string, filter model.KeyFilter, actor string, dryRun bool) (*model.Promotion, error)
+}
+
+type environmentService struct {
+	repo   *auditRepository
+	broker *Broker
+}
+
+func NewEnvironmentService(repo repository.ConfigRepository, broker *Broker) EnvironmentService {
+	return &environmentService{repo: newAuditRepository(repo), broker: broker}
+}
+
+func (s *environmentService) forRequest(request model.AuditRequest) EnvironmentService {
+	return &environmentService{repo: s.repo.forRequest(request), broker: s.broker}
+}
+
+func (s *environmentService) ListEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	return s.repo.GetEnvironments(ctx)
+}
+
+func (s *environmentService) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	environment, err := s.repo.GetEnvironment(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return nil, ErrEnvironmentNotFound
+		}
+		return nil, err
+	}
+	return environment, nil
+}
+
+func (s *environmentService) CreateEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	var result *model.Environment
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		if err := checkParent(ctx, repo, name, attributes.Parent); err != nil {
+			return err
+		}
+
+		environment, err := model.NewEnvironment(name, attributes)
+		if err != nil {
+			return err
+		}
+		if err := repo.CreateEnvironment(ctx, environment); err != nil {
+			if errors.Is(err, repository.ErrEnvironmentAlreadyExists) {
+				return ErrEnvironmentExists
+			}
+			return err
+		}
+
+		result = environment
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *environmentService) PutEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	var result *model.Environment
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		if err := checkParent(ctx, repo, name, attributes.Parent); err != nil {
+			return err
+		}
+
+		environment, err := repo.GetEnvironment(ctx, name)
+		switch {
+		case errors.Is(err, repository.ErrEnvironmentNotFound):
+			environment, err = model.NewEnvironment(name, attributes)
+			if err != nil {
+				return err
+			}
+			err = repo.CreateEnvironment(ctx, environment)
+		case err != nil:
+			return err
+		default:
+			if err := environment.Update(attributes); err != nil {
+				return err
+			}
+			err = repo.UpdateEnvironment(ctx, environment)
+		}
+		if err != nil {
+			return err
+		}
+
+		result = environment
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *environmentService) DeleteEnvironment(ctx context.Context, name, actor string, force bool) error {
+	var deleted []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		environment, err := repo.GetEnvironment(ctx, name)
+		if err != nil {
+			if errors.Is(err, repository.ErrEnvironmentNotFound) {
+				return ErrEnvironmentNotFound
+			}
+			return err
+		}
+		if environment.Protected {
+			return ErrEnvironmentProtected
+		}
+
+		environments, err := repo.GetEnvironments(ctx)
+		if err != nil {
+			return err
+		}
+		for _, candidate := range environments {
+			if candidate.Parent == name {
+				return ErrEnvironmentInUse
+			}
+		}
+
+		configs, err := repo.GetAll(ctx, name)
+		if err != nil {
+			return err
+		}
+		if len(configs) > 0 && !force {
+			return ErrEnvironmentNotEmpty
+		}
+		for _, config := range configs {
+			revision, err := repo.Delete(ctx, name, config.Key, actor, 0)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, revision)
+		}
+
+		if err := repo.DeleteEnvironment(ctx, name); err != nil {
+			if errors.Is(err, repository.ErrEnvironmentInUse) {
+				return ErrEnvironmentInUse
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, revision := range deleted {
+		s.broker.Publish(revision)
+	}
+	return nil
+}
+
+func checkParent(ctx context.Context, repo repository.ConfigRepository, name, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	if parent == name {
+		return fmt.Errorf("%w: %q cannot inherit from itself", model.ErrInvalidParent, name)
+	}
+	if _, err := repo.GetEnvironment(ctx, parent); err != nil {
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return fmt.Errorf("%w: %q does not exist", model.ErrInvalidParent, parent)
+		}
+		return err
+	}
+
+	chain, err := environmentChain(ctx, repo, parent)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(chain, name) {
+		return fmt.Errorf("%w: %q already inherits from %q", model.ErrInvalidParent, parent, name)
+	}
+	if len(chain) >= MaxInheritanceDepth {
+		return fmt.Errorf("%w: inheritance is deeper than %d environments", model.ErrInvalidParent, MaxInheritanceDepth)
+	}
+	return nil
+}
+
+// environmentChain returns the environment followed by its ancestors,
+// nearest first. Environments without a record have no parent.
+func environmentChain(ctx context.Context, repo repository.ConfigRepository, name string) ([]string, error) {
+	chain := []string{name}
+	for current := name; ; {
+		environment, err := repo.GetEnvironment(ctx, current)
+		if errors.Is(err, repository.ErrEnvironmentNotFound) {
+			return chain, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if environment.Parent == "" {
+			return chain, nil
+		}
+		if slices.Contains(chain, environment.Parent) {
+			return nil, fmt.Errorf("%w: inheritance cycle through %q", model.ErrInvalidParent, environment.Parent)
+		}
+		if len(chain) >= MaxInheritanceDepth {
+			return nil, fmt.Errorf("%w: inheritance is deeper than %d environments", model.ErrInvalidParent, MaxInheritanceDepth)
+		}
+		chain = append(chain, environment.Parent)
+		current = environment.Parent
+	}
+}
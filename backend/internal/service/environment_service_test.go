@@ -0,0 +1,224 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentService_PutEnvironment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewEnvironmentService(repo, NewBroker())
+
+	if _, err := svc.PutEnvironment(ctx, "production", inherits("base")); !errors.Is(err, model.ErrInvalidParent) {
+		t.Fatalf("PutEnvironment() with missing parent error = %v, want %v", err, model.ErrInvalidParent)
+	}
+	if _, err := svc.GetEnvironment(ctx, "production"); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("failed PutEnvironment() must not create the environment, error = %v", err)
+	}
+
+	if _, err := svc.PutEnvironment(ctx, "base", model.EnvironmentAttributes{}); err != nil {
+		t.Fatalf("PutEnvironment(base) error = %v", err)
+	}
+	if _, err := svc.PutEnvironment(ctx, "staging", inherits("base")); err != nil {
+		t.Fatalf("PutEnvironment(staging) error = %v", err)
+	}
+	environment, err := svc.PutEnvironment(ctx, "production", inherits("staging"))
+	if err != nil {
+		t.Fatalf("PutEnvironment(production) error = %v", err)
+	}
+	if environment.Name != "production" || environment.Parent != "staging" {
+		t.Fatalf("PutEnvironment() = %#v", environment)
+	}
+
+	environment, err = svc.PutEnvironment(ctx, "production", inherits("base"))
+	if err != nil || environment.Parent != "base" {
+		t.Fatalf("PutEnvironment() reparent = %#v, %v", environment, err)
+	}
+	if stored, _ := svc.GetEnvironment(ctx, "production"); stored.Parent != "base" {
+		t.Fatalf("stored parent = %q, want base", stored.Parent)
+	}
+
+	if _, err := svc.PutEnvironment(ctx, "base", inherits("staging")); !errors.Is(err, model.ErrInvalidParent) {
+		t.Fatalf("PutEnvironment() cycle error = %v, want %v", err, model.ErrInvalidParent)
+	}
+	if _, err := svc.PutEnvironment(ctx, "base", inherits("base")); !errors.Is(err, model.ErrInvalidParent) {
+		t.Fatalf("PutEnvironment() self parent error = %v, want %v", err, model.ErrInvalidParent)
+	}
+}
+
+func TestEnvironmentService_PutEnvironmentLimitsDepth(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewEnvironmentService(repo, NewBroker())
+
+	parent := ""
+	for i := 0; i < MaxInheritanceDepth; i++ {
+		name := fmt.Sprintf("env%d", i)
+		if _, err := svc.PutEnvironment(ctx, name, inherits(parent)); err != nil {
+			t.Fatalf("PutEnvironment(%s) error = %v", name, err)
+		}
+		parent = name
+	}
+
+	if _, err := svc.PutEnvironment(ctx, "too-deep", inherits(parent)); !errors.Is(err, model.ErrInvalidParent) {
+		t.Fatalf("PutEnvironment() error = %v, want %v", err, model.ErrInvalidParent)
+	}
+}
+
+func TestConfigService_ResolveConfigs(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	environments := NewEnvironmentService(repo, NewBroker())
+	svc := NewConfigService(repo, NewBroker())
+
+	for _, env := range [][2]string{{"base", ""}, {"staging", "base"}, {"production", "staging"}} {
+		if _, err := environments.PutEnvironment(ctx, env[0], inherits(env[1])); err != nil {
+			t.Fatalf("PutEnvironment(%s) error = %v", env[0], err)
+		}
+	}
+	for _, config := range [][3]string{
+		{"base", "timeout", "30s"},
+		{"base", "replicas", "1"},
+		{"base", "log_level", "debug"},
+		{"staging", "replicas", "2"},
+		{"production", "log_level", "warn"},
+		{"prod", "timeout", "5s"},
+	} {
+		if err := svc.CreateConfig(ctx, config[0], config[1], config[2], model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%v) error = %v", config, err)
+		}
+	}
+
+	resolved, err := svc.ResolveConfigs(ctx, "production")
+	if err != nil {
+		t.Fatalf("ResolveConfigs() error = %v", err)
+	}
+	want := []struct{ key, value, source string }{
+		{"log_level", "warn", "production"},
+		{"replicas", "2", "staging"},
+		{"timeout", "30s", "base"},
+	}
+	if len(resolved) != len(want) {
+		t.Fatalf("ResolveConfigs() returned %d entries, want %d", len(resolved), len(want))
+	}
+	for i, w := range want {
+		got := resolved[i]
+		if got.Key != w.key || got.Value != w.value || got.Source != w.source || got.Environment != "production" {
+			t.Fatalf("resolved[%d] = %#v, want %v", i, got, w)
+		}
+	}
+
+	resolved, err = svc.ResolveConfigs(ctx, "prod")
+	if err != nil || len(resolved) != 1 || resolved[0].Source != "prod" {
+		t.Fatalf("ResolveConfigs() without parent = %#v, %v", resolved, err)
+	}
+}
+
+func TestEnvironmentService_CreateEnvironment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewEnvironmentService(repo, NewBroker())
+
+	environment, err := svc.CreateEnvironment(ctx, "qa", model.EnvironmentAttributes{Parent: "staging", Owner: "team-qa"})
+	if err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	if environment.Parent != "staging" || environment.Owner != "team-qa" {
+		t.Fatalf("CreateEnvironment() = %#v", environment)
+	}
+
+	tests := []struct {
+		name       string
+		env        string
+		attributes model.EnvironmentAttributes
+		wantErr    error
+	}{
+		{name: "existing", env: "qa", wantErr: ErrEnvironmentExists},
+		{name: "invalid name", env: "QA", wantErr: model.ErrInvalidEnvironmentName},
+		{name: "missing parent", env: "uat", attributes: inherits("missing"), wantErr: model.ErrInvalidParent},
+		{name: "long owner", env: "uat", attributes: model.EnvironmentAttributes{Owner: strings.Repeat("o", 256)}, wantErr: model.ErrInvalidAttributes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateEnvironment(ctx, tt.env, tt.attributes); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateEnvironment() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	environments, err := svc.ListEnvironments(ctx)
+	if err != nil || len(environments) != 3 || environments[1].Name != "qa" {
+		t.Fatalf("ListEnvironments() = %#v, %v", environments, err)
+	}
+}
+
+func TestEnvironmentService_DeleteEnvironment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	broker := NewBroker()
+	environments := NewEnvironmentService(repo, broker)
+	configs := NewConfigService(repo, broker)
+
+	if _, err := environments.CreateEnvironment(ctx, "qa", inherits("staging")); err != nil {
+		t.Fatalf("CreateEnvironment(qa) error = %v", err)
+	}
+	if _, err := environments.PutEnvironment(ctx, "prod", model.EnvironmentAttributes{Protected: true}); err != nil {
+		t.Fatalf("PutEnvironment(prod) error = %v", err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := configs.CreateConfig(ctx, "qa", key, "1", model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", key, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		force   bool
+		wantErr error
+	}{
+		{name: "missing", env: "missing", wantErr: ErrEnvironmentNotFound},
+		{name: "protected", env: "prod", force: true, wantErr: ErrEnvironmentProtected},
+		{name: "has children", env: "staging", force: true, wantErr: ErrEnvironmentInUse},
+		{name: "has configs", env: "qa", wantErr: ErrEnvironmentNotEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := environments.DeleteEnvironment(ctx, tt.env, "bob", tt.force); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteEnvironment() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	events, cancel := broker.Subscribe("qa")
+	defer cancel()
+
+	if err := environments.DeleteEnvironment(ctx, "qa", "bob", true); err != nil {
+		t.Fatalf("DeleteEnvironment(force) error = %v", err)
+	}
+	if _, err := environments.GetEnvironment(ctx, "qa"); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("GetEnvironment() after delete error = %v", err)
+	}
+	if remaining, _ := repo.GetAll(ctx, "qa"); len(remaining) != 0 {
+		t.Fatalf("configs left after forced delete: %d", len(remaining))
+	}
+	for i := 0; i < 2; i++ {
+		revision := <-events
+		if revision.Operation != model.OperationDelete || revision.Actor != "bob" {
+			t.Fatalf("published revision = %#v", revision)
+		}
+	}
+
+	if err := configs.CreateConfig(ctx, "qa", "a", "1", model.ValueSpec{}, "alice"); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("CreateConfig() in deleted environment error = %v, want %v", err, ErrEnvironmentNotFound)
+	}
+}
+
+func inherits(parent string) model.EnvironmentAttributes {
+	return model.EnvironmentAttributes{Parent: parent}
+}
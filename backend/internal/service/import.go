@@ -0,0 +1,136 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"sort"
+)
+
+const MaxImportKeys = 5000
+
+var ErrImportTooLarge = errors.New("too many keys in import")
+
+// errDryRun rolls back a dry-run import after every operation was applied.
+var errDryRun = errors.New("dry run")
+
+// ImportConfigs turns the document into batch operations according to mode
+// and applies them in one transaction. A dry run applies them as well and
+// then rolls back, so the report reflects type checks against stored keys.
+func (s *configService) ImportConfigs(ctx context.Context, environment string, values []model.KeyValue, mode model.ImportMode, actor string, dryRun bool) (*model.ImportReport, error) {
+	if err := mode.Validate(); err != nil {
+		return nil, err
+	}
+	if len(values) > MaxImportKeys {
+		return nil, ErrImportTooLarge
+	}
+
+	report := &model.ImportReport{
+		Environment: environment,
+		Mode:        mode,
+		DryRun:      dryRun,
+		Created:     []string{},
+		Updated:     []string{},
+		Deleted:     []string{},
+		Unchanged:   []string{},
+		Skipped:     []string{},
+		Masked:      []string{},
+	}
+	var results []model.BatchResult
+	var applied []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		if _, err := repo.GetEnvironment(ctx, environment); err != nil {
+			if errors.Is(err, repository.ErrEnvironmentNotFound) {
+				return ErrEnvironmentNotFound
+			}
+			return err
+		}
+		configs, err := repo.GetAll(ctx, environment)
+		if err != nil {
+			return err
+		}
+
+		operations := importOperations(values, configs, mode, report)
+		results = newBatchResults(operations)
+		if !validateBatch(environment, operations, results) {
+			return ErrBatchInvalid
+		}
+		applied, err = applyOperations(ctx, repo, environment, operations, results, actor)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	switch {
+	case err == nil:
+		s.publish(applied)
+		return report, nil
+	case errors.Is(err, errDryRun):
+		return report, nil
+	case results == nil:
+		return nil, err
+	}
+
+	abortResults(results)
+	report.Results = results
+	if errors.Is(err, ErrBatchInvalid) {
+		return report, err
+	}
+	if isOperationError(err) {
+		return report, ErrBatchFailed
+	}
+	return report, err
+}
+
+// importOperations leaves a secret key unchanged when the document holds
+// model.SecretMask for it, so an export without revealed values can be
+// imported back. The mask is not a value, so for any other key it is
+// reported as masked and nothing is written.
+func importOperations(values []model.KeyValue, configs []*model.Config, mode model.ImportMode, report *model.ImportReport) []model.BatchOperation {
+	current := make(map[string]*model.Config, len(configs))
+	for _, config := range configs {
+		current[config.Key] = config
+	}
+
+	var operations []model.BatchOperation
+	imported := make(map[string]bool, len(values))
+	for _, value := range values {
+		imported[value.Key] = true
+		existing, ok := current[value.Key]
+		switch {
+		case !ok && value.Value == model.SecretMask:
+			report.Masked = append(report.Masked, value.Key)
+		case !ok:
+			operations = append(operations, model.BatchOperation{Op: model.OperationCreate, Key: value.Key, Value: value.Value})
+			report.Created = append(report.Created, value.Key)
+		case mode == model.ImportSkipExisting:
+			report.Skipped = append(report.Skipped, value.Key)
+		case existing.Value == value.Value, existing.Secret && value.Value == model.SecretMask:
+			report.Unchanged = append(report.Unchanged, value.Key)
+		case value.Value == model.SecretMask:
+			report.Masked = append(report.Masked, value.Key)
+		default:
+			operations = append(operations, model.BatchOperation{Op: model.OperationUpdate, Key: value.Key, Value: value.Value, Version: existing.Version})
+			report.Updated = append(report.Updated, value.Key)
+		}
+	}
+
+	if mode == model.ImportReplace {
+		var missing []string
+		for key := range current {
+			if !imported[key] {
+				missing = append(missing, key)
+			}
+		}
+		sort.Strings(missing)
+		for _, key := range missing {
+			operations = append(operations, model.BatchOperation{Op: model.OperationDelete, Key: key, Version: current[key].Version})
+			report.Deleted = append(report.Deleted, key)
+		}
+	}
+	return operations
+}
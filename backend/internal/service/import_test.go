@@ -0,0 +1,133 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfigService_ImportConfigs(t *testing.T) {
+	ctx := context.Background()
+	values := []model.KeyValue{
+		{Key: "db.host", Value: "db-2"},
+		{Key: "db.port", Value: "5432"},
+		{Key: "feature.new", Value: "true"},
+	}
+
+	tests := []struct {
+		name      string
+		mode      model.ImportMode
+		dryRun    bool
+		want      map[string][]string
+		wantState map[string]string
+	}{
+		{
+			name: "merge",
+			mode: model.ImportMerge,
+			want: map[string][]string{"created": {"feature.new"}, "updated": {"db.host"}, "unchanged": {"db.port"}},
+			wantState: map[string]string{
+				"db.host": "db-2", "db.port": "5432", "feature.new": "true", "legacy": "on",
+			},
+		},
+		{
+			name: "replace",
+			mode: model.ImportReplace,
+			want: map[string][]string{"created": {"feature.new"}, "updated": {"db.host"}, "unchanged": {"db.port"}, "deleted": {"legacy"}},
+			wantState: map[string]string{
+				"db.host": "db-2", "db.port": "5432", "feature.new": "true",
+			},
+		},
+		{
+			name: "skip existing",
+			mode: model.ImportSkipExisting,
+			want: map[string][]string{"created": {"feature.new"}, "skipped": {"db.host", "db.port"}},
+			wantState: map[string]string{
+				"db.host": "db-1", "db.port": "5432", "feature.new": "true", "legacy": "on",
+			},
+		},
+		{
+			name:   "dry run",
+			mode:   model.ImportReplace,
+			dryRun: true,
+			want:   map[string][]string{"created": {"feature.new"}, "updated": {"db.host"}, "unchanged": {"db.port"}, "deleted": {"legacy"}},
+			wantState: map[string]string{
+				"db.host": "db-1", "db.port": "5432", "legacy": "on",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			svc := NewConfigService(repo, NewBroker())
+			for _, kv := range [][2]string{{"db.host", "db-1"}, {"db.port", "5432"}, {"legacy", "on"}} {
+				if err := svc.CreateConfig(ctx, "prod", kv[0], kv[1], model.ValueSpec{}, "alice"); err != nil {
+					t.Fatalf("CreateConfig(%s) error = %v", kv[0], err)
+				}
+			}
+
+			report, err := svc.ImportConfigs(ctx, "prod", values, tt.mode, "bob", tt.dryRun)
+			if err != nil {
+				t.Fatalf("ImportConfigs() error = %v", err)
+			}
+			got := map[string][]string{
+				"created": report.Created, "updated": report.Updated, "deleted": report.Deleted,
+				"unchanged": report.Unchanged, "skipped": report.Skipped,
+			}
+			for outcome, keys := range got {
+				if len(keys) == 0 && len(tt.want[outcome]) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(keys, tt.want[outcome]) {
+					t.Fatalf("%s = %v, want %v", outcome, keys, tt.want[outcome])
+				}
+			}
+			if report.DryRun != tt.dryRun || report.Results != nil {
+				t.Fatalf("report = %#v", report)
+			}
+
+			state := map[string]string{}
+			configs, _ := repo.GetAll(ctx, "prod")
+			for _, config := range configs {
+				state[config.Key] = config.Value
+			}
+			if !reflect.DeepEqual(state, tt.wantState) {
+				t.Fatalf("state = %v, want %v", state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestConfigService_ImportConfigsErrors(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+	if err := svc.CreateConfig(ctx, "prod", "replicas", "2", model.ValueSpec{Type: model.TypeInt}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+
+	if _, err := svc.ImportConfigs(ctx, "prod", nil, "overwrite", "bob", false); !errors.Is(err, model.ErrInvalidImportMode) {
+		t.Fatalf("ImportConfigs() mode error = %v, want %v", err, model.ErrInvalidImportMode)
+	}
+	if _, err := svc.ImportConfigs(ctx, "missing", nil, model.ImportMerge, "bob", false); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("ImportConfigs() environment error = %v, want %v", err, ErrEnvironmentNotFound)
+	}
+	if _, err := svc.ImportConfigs(ctx, "prod", make([]model.KeyValue, MaxImportKeys+1), model.ImportMerge, "bob", false); !errors.Is(err, ErrImportTooLarge) {
+		t.Fatalf("ImportConfigs() size error = %v, want %v", err, ErrImportTooLarge)
+	}
+
+	report, err := svc.ImportConfigs(ctx, "prod", []model.KeyValue{{Key: "", Value: "x"}, {Key: "ok", Value: "1"}}, model.ImportMerge, "bob", false)
+	if !errors.Is(err, ErrBatchInvalid) || report == nil || report.Results[0].Status != model.BatchStatusFailed {
+		t.Fatalf("ImportConfigs() invalid key = %#v, %v", report, err)
+	}
+
+	report, err = svc.ImportConfigs(ctx, "prod", []model.KeyValue{{Key: "new", Value: "1"}, {Key: "replicas", Value: "many"}}, model.ImportMerge, "bob", true)
+	if !errors.Is(err, ErrBatchFailed) || report.Results[0].Status != model.BatchStatusAborted || len(report.Results[1].Violations) == 0 {
+		t.Fatalf("ImportConfigs() typed value = %#v, %v", report, err)
+	}
+	if exists, _ := repo.Exists(ctx, "prod", "new"); exists {
+		t.Fatal("failed import must not create keys")
+	}
+}
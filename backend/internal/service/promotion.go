@@ -0,0 +1,117 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+)
+
+var ErrSamePromotionTarget = errors.New("source and target environments must differ")
+
+// PromoteEnvironment diffs both environments inside one transaction and,
+// unless dryRun is set, applies the diff with batch semantics: every
+// operation is validated first and a failure rolls back the whole promotion.
+func (s *environmentService) PromoteEnvironment(ctx context.Context, source, target string, filter model.KeyFilter, actor string, dryRun bool) (*model.Promotion, error) {
+	if source == target {
+		return nil, ErrSamePromotionTarget
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	promotion := &model.Promotion{Source: source, Target: target, DryRun: dryRun}
+	var applied []*model.Revision
+	err := s.repo.WithTx(ctx, func(repo repository.ConfigRepository) error {
+		for _, name := range []string{source, target} {
+			if _, err := repo.GetEnvironment(ctx, name); err != nil {
+				if errors.Is(err, repository.ErrEnvironmentNotFound) {
+					return ErrEnvironmentNotFound
+				}
+				return err
+			}
+		}
+
+		desired, err := repo.GetAll(ctx, source)
+		if err != nil {
+			return err
+		}
+		current, err := repo.GetAll(ctx, target)
+		if err != nil {
+			return err
+		}
+
+		promotion.ConfigDiff = model.DiffConfigs(desired, current, filter)
+		if dryRun {
+			return nil
+		}
+
+		operations := promotionOperations(desired, current, promotion.ConfigDiff)
+		promotion.Results = newBatchResults(operations)
+		if !validateBatch(target, operations, promotion.Results) {
+			return ErrBatchInvalid
+		}
+		applied, err = applyOperations(ctx, repo, target, operations, promotion.Results, actor)
+		return err
+	})
+	if err != nil {
+		if promotion.Results == nil {
+			return nil, err
+		}
+		abortResults(promotion.Results)
+		if errors.Is(err, ErrBatchInvalid) {
+			return promotion, err
+		}
+		if isOperationError(err) {
+			return promotion, ErrBatchFailed
+		}
+		return promotion, err
+	}
+
+	for _, revision := range applied {
+		s.broker.Publish(revision)
+	}
+	return promotion, nil
+}
+
+// promotionOperations turns a diff into batch operations for the target.
+// Updates and deletes carry the target version seen while diffing.
+func promotionOperations(source, target []*model.Config, diff model.ConfigDiff) []model.BatchOperation {
+	desired := make(map[string]*model.Config, len(source))
+	for _, config := range source {
+		desired[config.Key] = config
+	}
+	current := make(map[string]*model.Config, len(target))
+	for _, config := range target {
+		current[config.Key] = config
+	}
+
+	operations := make([]model.BatchOperation, 0, len(diff.Added)+len(diff.Changed)+len(diff.Removed))
+	for _, change := range diff.Added {
+		config := desired[change.Key]
+		operations = append(operations, model.BatchOperation{
+			Op:        model.OperationCreate,
+			Key:       change.Key,
+			Value:     config.Value,
+			ValueSpec: config.ValueSpec,
+		})
+	}
+	for _, change := range diff.Changed {
+		config := desired[change.Key]
+		operations = append(operations, model.BatchOperation{
+			Op:        model.OperationUpdate,
+			Key:       change.Key,
+			Value:     config.Value,
+			Version:   current[change.Key].Version,
+			ValueSpec: config.ValueSpec,
+		})
+	}
+	for _, change := range diff.Removed {
+		operations = append(operations, model.BatchOperation{
+			Op:      model.OperationDelete,
+			Key:     change.Key,
+			Version: current[change.Key].Version,
+		})
+	}
+	return operations
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnvironmentService_PromoteEnvironment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	broker := NewBroker()
+	environments := NewEnvironmentService(repo, broker)
+	configs := NewConfigService(repo, broker)
+
+	for _, config := range [][3]string{
+		{"staging", "db.host", "db-2"},
+		{"staging", "db.port", "5432"},
+		{"staging", "feature.new", "true"},
+		{"staging", "secret.token", "staging-token"},
+		{"prod", "db.host", "db-1"},
+		{"prod", "db.port", "5432"},
+		{"prod", "feature.old", "false"},
+		{"prod", "secret.token", "prod-token"},
+	} {
+		if err := configs.CreateConfig(ctx, config[0], config[1], config[2], model.ValueSpec{}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%v) error = %v", config, err)
+		}
+	}
+	filter := model.KeyFilter{Exclude: []string{"secret.*"}}
+
+	preview, err := environments.PromoteEnvironment(ctx, "staging", "prod", filter, "bob", true)
+	if err != nil {
+		t.Fatalf("PromoteEnvironment(dry run) error = %v", err)
+	}
+	if !preview.DryRun || len(preview.Added) != 1 || len(preview.Changed) != 1 || len(preview.Removed) != 1 || preview.Results != nil {
+		t.Fatalf("PromoteEnvironment(dry run) = %#v", preview)
+	}
+	if config, _ := repo.Get(ctx, "prod", "db.host"); config.Value != "db-1" {
+		t.Fatalf("dry run changed prod: db.host = %q", config.Value)
+	}
+
+	events, cancel := broker.Subscribe("prod")
+	defer cancel()
+
+	promotion, err := environments.PromoteEnvironment(ctx, "staging", "prod", filter, "bob", false)
+	if err != nil {
+		t.Fatalf("PromoteEnvironment() error = %v", err)
+	}
+	if len(promotion.Results) != 3 {
+		t.Fatalf("PromoteEnvironment() results = %#v", promotion.Results)
+	}
+	for _, result := range promotion.Results {
+		if result.Status != model.BatchStatusApplied {
+			t.Fatalf("result = %#v, want applied", result)
+		}
+		<-events
+	}
+
+	got, _ := repo.GetAll(ctx, "prod")
+	want := map[string]string{"db.host": "db-2", "db.port": "5432", "feature.new": "true", "secret.token": "prod-token"}
+	if len(got) != len(want) {
+		t.Fatalf("prod has %d keys, want %d", len(got), len(want))
+	}
+	for _, config := range got {
+		if want[config.Key] != config.Value {
+			t.Fatalf("prod %s = %q, want %q", config.Key, config.Value, want[config.Key])
+		}
+	}
+
+	again, err := environments.PromoteEnvironment(ctx, "staging", "prod", filter, "bob", true)
+	if err != nil || !again.IsEmpty() {
+		t.Fatalf("PromoteEnvironment() after promotion = %#v, %v", again, err)
+	}
+}
+
+func TestEnvironmentService_PromoteEnvironmentErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		target  string
+		filter  model.KeyFilter
+		wantErr error
+	}{
+		{name: "same environment", source: "prod", target: "prod", wantErr: ErrSamePromotionTarget},
+		{name: "invalid filter", source: "staging", target: "prod", filter: model.KeyFilter{Include: []string{"["}}, wantErr: model.ErrInvalidFilter},
+		{name: "missing source", source: "missing", target: "prod", wantErr: ErrEnvironmentNotFound},
+		{name: "missing target", source: "staging", target: "missing", wantErr: ErrEnvironmentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewEnvironmentService(newMockRepository(), NewBroker())
+			promotion, err := svc.PromoteEnvironment(context.Background(), tt.source, tt.target, tt.filter, "bob", false)
+			if !errors.Is(err, tt.wantErr) || promotion != nil {
+				t.Fatalf("PromoteEnvironment() = %#v, %v, want %v", promotion, err, tt.wantErr)
+			}
+		})
+	}
+}
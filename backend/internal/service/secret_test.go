@@ -0,0 +1,197 @@
+package service
+
+import (
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigService_MasksSecretValues(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	broker := NewBroker()
+	svc := NewConfigService(repo, broker)
+
+	events, cancel := broker.Subscribe("prod")
+	defer cancel()
+
+	if err := svc.CreateConfig(ctx, "prod", "db.password", "hunter2", model.ValueSpec{Secret: true}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := svc.CreateConfig(ctx, "prod", "db.host", "db-1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if revision := <-events; revision.Value != model.SecretMask || !revision.Secret {
+		t.Fatalf("published revision = %#v, want masked", revision)
+	}
+
+	config, err := svc.GetConfig(ctx, "prod", "db.password")
+	if err != nil || config.Value != model.SecretMask || !config.Secret {
+		t.Fatalf("GetConfig() = %#v, %v", config, err)
+	}
+	config, err = svc.RevealConfig(ctx, "prod", "db.password")
+	if err != nil || config.Value != "hunter2" {
+		t.Fatalf("RevealConfig() = %#v, %v", config, err)
+	}
+	if stored, _ := repo.Get(ctx, "prod", "db.password"); stored.Value != "hunter2" {
+		t.Fatalf("masking changed the stored value to %q", stored.Value)
+	}
+
+	configs, err := svc.GetAllConfigs(ctx, "prod")
+	if err != nil {
+		t.Fatalf("GetAllConfigs() error = %v", err)
+	}
+	values := make(map[string]string)
+	for _, config := range configs {
+		values[config.Key] = config.Value
+	}
+	if values["db.password"] != model.SecretMask || values["db.host"] != "db-1" {
+		t.Fatalf("GetAllConfigs() values = %v", values)
+	}
+
+	if err := svc.UpdateConfig(ctx, "prod", "db.password", "hunter3", model.ValueSpec{}, "bob", 0); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if config, _ := svc.GetConfig(ctx, "prod", "db.password"); !config.Secret {
+		t.Fatal("UpdateConfig() with a zero spec dropped the secret flag")
+	}
+
+	history, err := svc.GetConfigHistory(ctx, "prod", "db.password")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("GetConfigHistory() = %#v, %v", history, err)
+	}
+	for _, revision := range history {
+		if revision.Value != model.SecretMask {
+			t.Fatalf("history revision %d value = %q, want masked", revision.Revision, revision.Value)
+		}
+	}
+
+	resolved, err := svc.ResolveConfigs(ctx, "prod")
+	if err != nil {
+		t.Fatalf("ResolveConfigs() error = %v", err)
+	}
+	for _, entry := range resolved {
+		if entry.Key == "db.password" && entry.Value != model.SecretMask {
+			t.Fatalf("ResolveConfigs() value = %q, want masked", entry.Value)
+		}
+	}
+
+	changes, err := svc.GetChangesSince(ctx, "prod", 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince() error = %v", err)
+	}
+	for _, revision := range changes {
+		if revision.Key == "db.password" && revision.Value != model.SecretMask {
+			t.Fatalf("GetChangesSince() value = %q, want masked", revision.Value)
+		}
+	}
+}
+
+func TestConfigService_RollbackKeepsSecretFlag(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "token", "t-1", model.ValueSpec{Secret: true}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	history, _ := svc.GetConfigHistory(ctx, "prod", "token")
+	if err := svc.DeleteConfig(ctx, "prod", "token", "alice", 0); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+
+	applied, err := svc.RollbackConfig(ctx, "prod", "token", history[0].Revision, "bob")
+	if err != nil || len(applied) != 1 || applied[0].Value != model.SecretMask {
+		t.Fatalf("RollbackConfig() = %#v, %v", applied, err)
+	}
+	restored, err := repo.Get(ctx, "prod", "token")
+	if err != nil || restored.Value != "t-1" || !restored.Secret {
+		t.Fatalf("restored config = %#v, %v", restored, err)
+	}
+}
+
+func TestConfigService_CompareEnvironmentsMasksSecrets(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	for _, env := range []string{"prod", "staging"} {
+		if err := svc.CreateConfig(ctx, env, "token", "t-"+env, model.ValueSpec{Secret: true}, "alice"); err != nil {
+			t.Fatalf("CreateConfig(%s) error = %v", env, err)
+		}
+	}
+
+	diff, err := svc.CompareEnvironments(ctx, model.EnvironmentRef{Name: "prod"}, model.EnvironmentRef{Name: "staging"})
+	if err != nil {
+		t.Fatalf("CompareEnvironments() error = %v", err)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("CompareEnvironments() changed = %#v, want the secret reported as changed", diff.Changed)
+	}
+	if change := diff.Changed[0]; change.Left.Value != model.SecretMask || change.Right.Value != model.SecretMask {
+		t.Fatalf("CompareEnvironments() change = %#v, want masked values", change)
+	}
+}
+
+func TestConfigService_ImportKeepsMaskedSecrets(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "token", "t-1", model.ValueSpec{Secret: true}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+
+	if err := svc.CreateConfig(ctx, "prod", "plain", "p-1", model.ValueSpec{}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+
+	values := []model.KeyValue{
+		{Key: "token", Value: model.SecretMask},
+		{Key: "plain", Value: model.SecretMask},
+		{Key: "new.token", Value: model.SecretMask},
+	}
+	report, err := svc.ImportConfigs(ctx, "prod", values, model.ImportReplace, "bob", false)
+	if err != nil || !reflect.DeepEqual(report.Unchanged, []string{"token"}) || !reflect.DeepEqual(report.Masked, []string{"plain", "new.token"}) ||
+		len(report.Created) != 0 || len(report.Updated) != 0 || len(report.Deleted) != 0 {
+		t.Fatalf("ImportConfigs() = %#v, %v", report, err)
+	}
+	if stored, _ := repo.Get(ctx, "prod", "token"); stored.Value != "t-1" {
+		t.Fatalf("stored value = %q, want t-1", stored.Value)
+	}
+	if stored, _ := repo.Get(ctx, "prod", "plain"); stored.Value != "p-1" {
+		t.Fatalf("stored value = %q, want p-1", stored.Value)
+	}
+	if exists, _ := repo.Exists(ctx, "prod", "new.token"); exists {
+		t.Fatal("a masked value must not create a key")
+	}
+}
+
+func TestConfigService_SecretsUnavailable(t *testing.T) {
+	repo := &controllableRepository{createErr: repository.ErrSecretsUnavailable}
+	svc := NewConfigService(repo, NewBroker())
+
+	err := svc.CreateConfig(context.Background(), "prod", "token", "t-1", model.ValueSpec{Secret: true}, "alice")
+	if !errors.Is(err, ErrSecretsUnavailable) {
+		t.Fatalf("CreateConfig() error = %v, want %v", err, ErrSecretsUnavailable)
+	}
+}
+
+func TestConfigService_GetConfigAtMasksSecrets(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepository()
+	svc := NewConfigService(repo, NewBroker())
+
+	if err := svc.CreateConfig(ctx, "prod", "token", "t-1", model.ValueSpec{Secret: true}, "alice"); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+
+	config, err := svc.GetConfigAt(ctx, "prod", "token", time.Now())
+	if err != nil || config.Value != model.SecretMask {
+		t.Fatalf("GetConfigAt() = %#v, %v", config, err)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrTokenExists   = errors.New("token already exists")
+)
+
+// TokenPrefix starts every generated API token so that leaked tokens are
+// easy to find with secret scanners.
+const TokenPrefix = "cfg_"
+
+// adminIdentity is the caller name of the bootstrap admin token.
+const adminIdentity = "admin"
+
+type TokenService interface {
+	// Authenticate returns nil without an error for unknown and expired
+	// tokens.
+	Authenticate(ctx context.Context, token string) (*model.Identity, error)
+	ListTokens(ctx context.Context) ([]*model.Token, error)
+	// CreateToken returns the token and its secret, which is not stored and
+	// cannot be retrieved later.
+	CreateToken(ctx context.Context, name string, grants []model.Grant, expiresAt *time.Time, actor string) (*model.Token, string, error)
+	DeleteToken(ctx context.Context, id int64) error
+}
+
+type tokenService struct {
+	repo      repository.TokenRepository
+	adminHash []byte
+}
+
+func NewTokenService(repo repository.TokenRepository, cfg *config.Config) TokenService {
+	s := &tokenService{repo: repo}
+	if cfg.Auth.AdminToken != "" {
+		hash := sha256.Sum256([]byte(cfg.Auth.AdminToken))
+		s.adminHash = hash[:]
+	}
+	return s
+}
+
+func (s *tokenService) Authenticate(ctx context.Context, token string) (*model.Identity, error) {
+	hash := sha256.Sum256([]byte(token))
+	if s.adminHash != nil && subtle.ConstantTimeCompare(hash[:], s.adminHash) == 1 {
+		return &model.Identity{
+			Name:   adminIdentity,
+			Source: model.IdentitySourceAdminToken,
+			Grants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}},
+		}, nil
+	}
+
+	stored, err := s.repo.GetTokenByHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if stored.Expired(time.Now()) {
+		return nil, nil
+	}
+	return &model.Identity{
+		Name:      stored.Name,
+		Source:    model.IdentitySourceToken,
+		Grants:    stored.Grants,
+		ExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+func (s *tokenService) ListTokens(ctx context.Context) ([]*model.Token, error) {
+	return s.repo.GetTokens(ctx)
+}
+
+func (s *tokenService) CreateToken(ctx context.Context, name string, grants []model.Grant, expiresAt *time.Time, actor string) (*model.Token, string, error) {
+	token, err := model.NewToken(name, grants, expiresAt, actor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := TokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+	hash := sha256.Sum256([]byte(secret))
+
+	if err := s.repo.CreateToken(ctx, token, hex.EncodeToString(hash[:])); err != nil {
+		if errors.Is(err, repository.ErrTokenAlreadyExists) {
+			return nil, "", ErrTokenExists
+		}
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+func (s *tokenService) DeleteToken(ctx context.Context, id int64) error {
+	if err := s.repo.DeleteToken(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return ErrTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memoryTokenRepository struct {
+	tokens map[string]*model.Token
+	nextID int64
+}
+
+func newMemoryTokenRepository() *memoryTokenRepository {
+	return &memoryTokenRepository{tokens: make(map[string]*model.Token)}
+}
+
+func (r *memoryTokenRepository) CreateToken(ctx context.Context, token *model.Token, hash string) error {
+	for _, existing := range r.tokens {
+		if existing.Name == token.Name {
+			return repository.ErrTokenAlreadyExists
+		}
+	}
+	r.nextID++
+	token.ID = r.nextID
+	r.tokens[hash] = token
+	return nil
+}
+
+func (r *memoryTokenRepository) GetTokenByHash(ctx context.Context, hash string) (*model.Token, error) {
+	token, ok := r.tokens[hash]
+	if !ok {
+		return nil, repository.ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (r *memoryTokenRepository) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	var tokens []*model.Token
+	for _, token := range r.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (r *memoryTokenRepository) DeleteToken(ctx context.Context, id int64) error {
+	for hash, token := range r.tokens {
+		if token.ID == id {
+			delete(r.tokens, hash)
+			return nil
+		}
+	}
+	return repository.ErrTokenNotFound
+}
+
+func TestTokenService_CreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTokenRepository()
+	svc := NewTokenService(repo, &config.Config{})
+	grants := []model.Grant{{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleWriter}}
+
+	token, secret, err := svc.CreateToken(ctx, "ci", grants, nil, "alice")
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, TokenPrefix) || token.ID == 0 || token.CreatedBy != "alice" {
+		t.Fatalf("CreateToken() = %#v, %q", token, secret)
+	}
+	for hash := range repo.tokens {
+		if strings.Contains(hash, secret) {
+			t.Fatal("the token secret must not be stored")
+		}
+	}
+
+	identity, err := svc.Authenticate(ctx, secret)
+	if err != nil || identity == nil || identity.Name != "ci" || !identity.Allows(model.RoleWriter, "prod", "billing.rate") {
+		t.Fatalf("Authenticate() = %#v, %v", identity, err)
+	}
+	if identity, err := svc.Authenticate(ctx, secret+"x"); identity != nil || err != nil {
+		t.Fatalf("Authenticate() of an unknown token = %#v, %v", identity, err)
+	}
+
+	if _, _, err := svc.CreateToken(ctx, "ci", grants, nil, "alice"); !errors.Is(err, ErrTokenExists) {
+		t.Fatalf("CreateToken() duplicate error = %v, want %v", err, ErrTokenExists)
+	}
+	if _, _, err := svc.CreateToken(ctx, "ci-2", nil, nil, "alice"); !errors.Is(err, model.ErrInvalidGrant) {
+		t.Fatalf("CreateToken() without grants error = %v, want %v", err, model.ErrInvalidGrant)
+	}
+
+	if err := svc.DeleteToken(ctx, token.ID); err != nil {
+		t.Fatalf("DeleteToken() error = %v", err)
+	}
+	if identity, _ := svc.Authenticate(ctx, secret); identity != nil {
+		t.Fatal("deleted token still authenticates")
+	}
+	if err := svc.DeleteToken(ctx, token.ID); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("DeleteToken() missing error = %v, want %v", err, ErrTokenNotFound)
+	}
+}
+
+func TestTokenService_ExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryTokenRepository()
+	svc := NewTokenService(repo, &config.Config{})
+
+	expiresAt := time.Now().Add(time.Hour)
+	_, secret, err := svc.CreateToken(ctx, "temp", []model.Grant{{Environment: "prod", Role: model.RoleReader}}, &expiresAt, "alice")
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	for _, token := range repo.tokens {
+		past := time.Now().Add(-time.Minute)
+		token.ExpiresAt = &past
+	}
+
+	if identity, err := svc.Authenticate(ctx, secret); identity != nil || err != nil {
+		t.Fatalf("Authenticate() of an expired token = %#v, %v", identity, err)
+	}
+}
+
+func TestTokenService_AdminToken(t *testing.T) {
+	ctx := context.Background()
+	svc := NewTokenService(newMemoryTokenRepository(), &config.Config{Auth: config.AuthConfig{AdminToken: "bootstrap"}})
+
+	identity, err := svc.Authenticate(ctx, "bootstrap")
+	if err != nil || identity == nil || !identity.AllowsEnvironment(model.RoleAdmin, model.AllEnvironments) {
+		t.Fatalf("Authenticate() admin token = %#v, %v", identity, err)
+	}
+
+	empty := NewTokenService(newMemoryTokenRepository(), &config.Config{})
+	if identity, _ := empty.Authenticate(ctx, ""); identity != nil {
+		t.Fatal("an empty token must not authenticate when no admin token is configured")
+	}
+}
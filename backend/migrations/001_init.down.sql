@@ -0,0 +1,5 @@
+-- Migration: Drop configs table
+-- Description: Откатывает 001_init.sql
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS configs;
@@ -1,6 +1,6 @@
 -- Migration: Create configs table
 -- Description: Создает таблицу для хранения конфигураций различных окружений
--- Run: Автоматически при первом запуске PostgreSQL через docker-compose
+-- Run: Автоматически при запуске сервиса или командой migrate up
 
 CREATE TABLE IF NOT EXISTS configs (
     env TEXT NOT NULL,
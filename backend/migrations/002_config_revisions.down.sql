@@ -0,0 +1,9 @@
+-- Migration: Drop config_revisions table
+-- Description: Откатывает 002_config_revisions.sql; история изменений теряется
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS config_revisions;
+DROP SEQUENCE IF EXISTS config_revision_seq;
+
+ALTER TABLE configs DROP COLUMN IF EXISTS revision;
+ALTER TABLE configs DROP COLUMN IF EXISTS updated_by;
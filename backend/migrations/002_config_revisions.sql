@@ -0,0 +1,45 @@
+-- Migration: Create config_revisions table
+-- Description: Хранит неизменяемую историю всех изменений конфигураций
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+CREATE SEQUENCE IF NOT EXISTS config_revision_seq;
+
+ALTER TABLE configs ADD COLUMN IF NOT EXISTS updated_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE configs ADD COLUMN IF NOT EXISTS revision BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS config_revisions (
+    revision BIGINT PRIMARY KEY DEFAULT nextval('config_revision_seq'),
+    env TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Индекс для чтения истории ключа и состояния на момент времени
+CREATE INDEX IF NOT EXISTS idx_config_revisions_env_key ON config_revisions(env, key, revision DESC);
+CREATE INDEX IF NOT EXISTS idx_config_revisions_env_created_at ON config_revisions(env, created_at);
+
+-- Существующие ключи получают начальную ревизию
+WITH seeded AS (
+    INSERT INTO config_revisions (env, key, value, operation, created_at)
+    SELECT c.env, c.key, c.value, 'create', c.updated_at
+    FROM configs c
+    WHERE NOT EXISTS (
+        SELECT 1
+        FROM config_revisions r
+        WHERE r.env = c.env AND r.key = c.key
+    )
+    RETURNING env, key, revision
+)
+UPDATE configs
+SET revision = seeded.revision
+FROM seeded
+WHERE configs.env = seeded.env AND configs.key = seeded.key;
+
+COMMENT ON TABLE config_revisions IS 'История изменений конфигураций';
+COMMENT ON COLUMN config_revisions.revision IS 'Глобальный номер ревизии';
+COMMENT ON COLUMN config_revisions.operation IS 'Тип изменения (create, update, delete)';
+COMMENT ON COLUMN config_revisions.actor IS 'Автор изменения';
+COMMENT ON COLUMN config_revisions.created_at IS 'Время изменения';
@@ -0,0 +1,5 @@
+-- Migration: Drop version column from configs
+-- Description: Откатывает 003_config_version.sql
+-- Run: Командой migrate down
+
+ALTER TABLE configs DROP COLUMN IF EXISTS version;
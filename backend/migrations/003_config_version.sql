@@ -0,0 +1,7 @@
+-- Migration: Add version column to configs
+-- Description: Счетчик версий строки для оптимистичной блокировки (ETag / If-Match)
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+ALTER TABLE configs ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN configs.version IS 'Версия строки, увеличивается при каждом обновлении';
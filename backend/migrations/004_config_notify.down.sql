@@ -0,0 +1,6 @@
+-- Migration: Stop notifying listeners about new config revisions
+-- Description: Откатывает 004_config_notify.sql
+-- Run: Командой migrate down
+
+DROP TRIGGER IF EXISTS config_revisions_notify ON config_revisions;
+DROP FUNCTION IF EXISTS notify_config_change();
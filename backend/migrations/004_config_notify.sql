@@ -0,0 +1,21 @@
+-- Migration: Notify listeners about new config revisions
+-- Description: Триггер отправляет NOTIFY в канал config_changes при каждой новой ревизии, чтобы все реплики могли разослать события подписчикам watch
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- Значение не передается в payload: NOTIFY ограничен 8000 байт, реплики читают ревизию из таблицы по номеру
+CREATE OR REPLACE FUNCTION notify_config_change() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify(
+        'config_changes',
+        json_build_object('revision', NEW.revision, 'env', NEW.env)::text
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS config_revisions_notify ON config_revisions;
+
+CREATE TRIGGER config_revisions_notify
+    AFTER INSERT ON config_revisions
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_config_change();
@@ -0,0 +1,5 @@
+-- Migration: Drop value types from configs
+-- Description: Откатывает 005_config_value_types.sql; ключи становятся нетипизированными
+-- Run: Командой migrate down
+
+ALTER TABLE configs DROP COLUMN IF EXISTS value_spec;
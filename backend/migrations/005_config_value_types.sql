@@ -0,0 +1,8 @@
+-- Migration: Add value types to configs
+-- Description: Необязательный тип значения ключа (string, int, float, bool, duration, url, json, enum) и JSON Schema для json
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- NULL означает нетипизированный ключ: так продолжают работать ключи, созданные до появления типов
+ALTER TABLE configs ADD COLUMN IF NOT EXISTS value_spec JSONB;
+
+COMMENT ON COLUMN configs.value_spec IS 'Тип значения: {"type": ..., "enum": [...], "schema": {...}}';
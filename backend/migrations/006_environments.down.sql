@@ -0,0 +1,5 @@
+-- Migration: Drop environments table
+-- Description: Откатывает 006_environments.sql; окружения снова существуют только как значения configs.env
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS environments;
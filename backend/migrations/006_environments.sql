@@ -0,0 +1,20 @@
+-- Migration: Create environments table
+-- Description: Окружения становятся отдельными записями с необязательным родителем для наследования ключей (например production -> base)
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+CREATE TABLE IF NOT EXISTS environments (
+    name TEXT PRIMARY KEY,
+    parent TEXT REFERENCES environments(name),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT environments_parent_not_self CHECK (parent IS NULL OR parent <> name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_environments_parent ON environments(parent);
+
+-- Окружения, которые до этого существовали только как значения столбца configs.env
+INSERT INTO environments (name)
+SELECT DISTINCT env FROM configs
+ON CONFLICT (name) DO NOTHING;
+
+COMMENT ON TABLE environments IS 'Окружения конфигурации';
+COMMENT ON COLUMN environments.parent IS 'Родительское окружение, ключи которого наследуются';
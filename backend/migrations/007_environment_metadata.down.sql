@@ -0,0 +1,9 @@
+-- Migration: Drop environment metadata and referential integrity
+-- Description: Откатывает 007_environment_metadata.sql
+-- Run: Командой migrate down
+
+ALTER TABLE configs DROP CONSTRAINT IF EXISTS configs_env_fkey;
+
+ALTER TABLE environments DROP COLUMN IF EXISTS protected;
+ALTER TABLE environments DROP COLUMN IF EXISTS owner;
+ALTER TABLE environments DROP COLUMN IF EXISTS description;
@@ -0,0 +1,19 @@
+-- Migration: Environment metadata and referential integrity
+-- Description: Описание, владелец и флаг защиты окружения; запись конфигураций возможна только в существующие окружения
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+ALTER TABLE environments ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE environments ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE environments ADD COLUMN IF NOT EXISTS protected BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Окружения, в которые писали до появления ограничения
+INSERT INTO environments (name)
+SELECT DISTINCT env FROM configs
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE configs DROP CONSTRAINT IF EXISTS configs_env_fkey;
+ALTER TABLE configs ADD CONSTRAINT configs_env_fkey FOREIGN KEY (env) REFERENCES environments(name);
+
+COMMENT ON COLUMN environments.description IS 'Описание окружения';
+COMMENT ON COLUMN environments.owner IS 'Владелец окружения';
+COMMENT ON COLUMN environments.protected IS 'Защищенное окружение нельзя удалить';
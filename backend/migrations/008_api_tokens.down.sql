@@ -0,0 +1,5 @@
+-- Migration: Drop api_tokens table
+-- Description: Откатывает 008_api_tokens.sql; все API-токены удаляются
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,18 @@
+-- Migration: Create api_tokens table
+-- Description: API-токены для аутентификации по Bearer; хранится только SHA-256 хеш токена и список прав по окружениям
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    token_hash TEXT NOT NULL UNIQUE,
+    grants JSONB NOT NULL DEFAULT '[]',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ
+);
+
+COMMENT ON TABLE api_tokens IS 'API-токены';
+COMMENT ON COLUMN api_tokens.token_hash IS 'SHA-256 хеш токена в шестнадцатеричном виде';
+COMMENT ON COLUMN api_tokens.grants IS 'Права: окружение (* для всех), необязательный префикс ключа и роль reader, writer или admin';
+COMMENT ON COLUMN api_tokens.expires_at IS 'Срок действия; NULL для бессрочных токенов';
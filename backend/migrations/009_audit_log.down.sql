@@ -0,0 +1,6 @@
+-- Migration: Drop audit_log table
+-- Description: Откатывает 009_audit_log.sql; журнал изменений удаляется
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS reject_audit_log_change();
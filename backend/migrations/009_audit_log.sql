@@ -0,0 +1,44 @@
+-- Migration: Create audit_log table
+-- Description: Журнал всех изменений конфигураций и окружений: кто, откуда и что изменил. Запись добавляется в той же транзакции, что и изменение
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL DEFAULT '',
+    source_ip TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    operation TEXT NOT NULL,
+    env TEXT NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    revision BIGINT,
+    old_value TEXT,
+    new_value TEXT,
+    secret BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Индексы для фильтров GET /api/audit; страницы читаются от новых записей к старым по id
+CREATE INDEX IF NOT EXISTS idx_audit_log_env_key ON audit_log(env, key, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_log_change();
+
+COMMENT ON TABLE audit_log IS 'Журнал изменений';
+COMMENT ON COLUMN audit_log.operation IS 'Операция: config.create, config.update, config.delete, environment.create, environment.update, environment.delete';
+COMMENT ON COLUMN audit_log.revision IS 'Ревизия конфигурации из config_revisions; NULL для операций с окружениями';
+COMMENT ON COLUMN audit_log.old_value IS 'Значение до изменения; NULL для секретных ключей';
+COMMENT ON COLUMN audit_log.new_value IS 'Значение после изменения; NULL для секретных ключей';
+COMMENT ON COLUMN audit_log.secret IS 'Ключ секретный, значения не записываются';
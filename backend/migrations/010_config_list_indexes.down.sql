@@ -0,0 +1,6 @@
+-- Migration: Drop indexes for paged config listing
+-- Description: Откатывает 010_config_list_indexes.sql
+-- Run: Командой migrate down
+
+DROP INDEX IF EXISTS idx_configs_env_updated_at;
+DROP INDEX IF EXISTS idx_configs_env_key_pattern;
@@ -0,0 +1,9 @@
+-- Migration: Indexes for paged config listing
+-- Description: Постраничный вывод GET /api/configs/{env} с фильтром по префиксу ключа и сортировкой по времени изменения
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- Первичный ключ (env, key) не используется для LIKE 'prefix%' при сортировке, отличной от C
+CREATE INDEX IF NOT EXISTS idx_configs_env_key_pattern ON configs(env, key text_pattern_ops);
+
+-- Сортировка sort=updated_at: сначала недавно измененные, ключ разрешает совпадения времени
+CREATE INDEX IF NOT EXISTS idx_configs_env_updated_at ON configs(env, updated_at DESC, key);
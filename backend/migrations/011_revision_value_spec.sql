@@ -0,0 +1,14 @@
+-- Migration: Add value types to config_revisions
+-- Description: Ревизия хранит тип значения ключа, чтобы откат удаленного ключа восстанавливал его тип
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- NULL означает нетипизированный ключ, в том числе у ревизий, записанных до этой миграции
+ALTER TABLE config_revisions ADD COLUMN IF NOT EXISTS value_spec JSONB;
+
+-- Текущие ревизии существующих ключей получают их тип
+UPDATE config_revisions r
+SET value_spec = c.value_spec
+FROM configs c
+WHERE r.env = c.env AND r.key = c.key AND r.revision = c.revision AND r.value_spec IS NULL;
+
+COMMENT ON COLUMN config_revisions.value_spec IS 'Тип значения после изменения: {"type": ..., "enum": [...], "schema": {...}}';
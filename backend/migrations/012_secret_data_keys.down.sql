@@ -0,0 +1,5 @@
+-- Migration: Drop secret_data_keys table
+-- Description: Откатывает 012_secret_data_keys.sql; секретные значения становятся нечитаемыми
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS secret_data_keys;
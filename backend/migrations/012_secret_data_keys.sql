@@ -0,0 +1,15 @@
+-- Migration: Create secret_data_keys table
+-- Description: Ключи данных секретных значений, обернутые мастер-ключом; значение ссылается на ключ данных по id
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- Ротация мастер-ключа переоборачивает только строки этой таблицы: configs и config_revisions не изменяются
+CREATE TABLE IF NOT EXISTS secret_data_keys (
+    id BIGSERIAL PRIMARY KEY,
+    master_key TEXT NOT NULL,
+    wrapped BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE secret_data_keys IS 'Ключи данных секретных значений';
+COMMENT ON COLUMN secret_data_keys.master_key IS 'Идентификатор мастер-ключа, которым обернут ключ данных';
+COMMENT ON COLUMN secret_data_keys.wrapped IS 'Ключ данных, зашифрованный мастер-ключом (AES-256-GCM, nonce в начале)';
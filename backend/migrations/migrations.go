@@ -0,0 +1,21 @@
+// Package migrations embeds the versioned schema migrations into the binary.
+// NNN_name.sql upgrades the schema to version NNN and the optional
+// NNN_name.down.sql reverts it.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the Postgres migrations.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite holds the migrations of the embedded SQLite storage, which starts
+// from the current Postgres schema.
+var SQLite, _ = fs.Sub(sqliteFS, "sqlite")
@@ -0,0 +1,9 @@
+-- Migration: Drop schema for SQLite storage
+-- Description: Откатывает 001_init.sql; все данные удаляются
+-- Run: Командой migrate down
+
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS config_revisions;
+DROP TABLE IF EXISTS configs;
+DROP TABLE IF EXISTS environments;
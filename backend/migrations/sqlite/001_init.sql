@@ -0,0 +1,90 @@
+-- Migration: Create schema for SQLite storage
+-- Description: Схема встроенного хранилища SQLite, соответствует схеме PostgreSQL после 010_config_list_indexes.sql
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- Время хранится текстом в UTC с наносекундами фиксированной ширины (2006-01-02T15:04:05.000000000Z),
+-- поэтому строки сравниваются и сортируются в хронологическом порядке
+
+CREATE TABLE IF NOT EXISTS environments (
+    name TEXT PRIMARY KEY,
+    parent TEXT REFERENCES environments(name),
+    description TEXT NOT NULL DEFAULT '',
+    owner TEXT NOT NULL DEFAULT '',
+    protected BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT environments_parent_not_self CHECK (parent IS NULL OR parent <> name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_environments_parent ON environments(parent);
+
+CREATE TABLE IF NOT EXISTS configs (
+    env TEXT NOT NULL REFERENCES environments(name),
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    updated_by TEXT NOT NULL DEFAULT '',
+    revision INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    -- NULL означает нетипизированный ключ
+    value_spec TEXT,
+    PRIMARY KEY (env, key)
+);
+
+-- Сортировка sort=updated_at: сначала недавно измененные, ключ разрешает совпадения времени
+CREATE INDEX IF NOT EXISTS idx_configs_env_updated_at ON configs(env, updated_at DESC, key);
+
+-- AUTOINCREMENT не выдает номера повторно, поэтому номера ревизий только растут
+CREATE TABLE IF NOT EXISTS config_revisions (
+    revision INTEGER PRIMARY KEY AUTOINCREMENT,
+    env TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_revisions_env_key ON config_revisions(env, key, revision DESC);
+CREATE INDEX IF NOT EXISTS idx_config_revisions_env_created_at ON config_revisions(env, created_at);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    token_hash TEXT NOT NULL UNIQUE,
+    grants TEXT NOT NULL DEFAULT '[]',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL DEFAULT '',
+    source_ip TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    operation TEXT NOT NULL,
+    env TEXT NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    revision INTEGER,
+    old_value TEXT,
+    new_value TEXT,
+    secret BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_env_key ON audit_log(env, key, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
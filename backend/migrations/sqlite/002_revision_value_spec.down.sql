@@ -0,0 +1,5 @@
+-- Migration: Drop value types from config_revisions
+-- Description: Откатывает 002_revision_value_spec.sql
+-- Run: Командой migrate down
+
+ALTER TABLE config_revisions DROP COLUMN value_spec;
@@ -0,0 +1,15 @@
+-- Migration: Add value types to config_revisions
+-- Description: Соответствует 011_revision_value_spec.sql схемы PostgreSQL
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+-- NULL означает нетипизированный ключ, в том числе у ревизий, записанных до этой миграции
+ALTER TABLE config_revisions ADD COLUMN value_spec TEXT;
+
+-- Текущие ревизии существующих ключей получают их тип
+UPDATE config_revisions
+SET value_spec = (
+    SELECT c.value_spec
+    FROM configs c
+    WHERE c.env = config_revisions.env AND c.key = config_revisions.key AND c.revision = config_revisions.revision
+)
+WHERE value_spec IS NULL;
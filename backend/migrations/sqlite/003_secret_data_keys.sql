@@ -0,0 +1,10 @@
+-- Migration: Create secret_data_keys table
+-- Description: Соответствует 012_secret_data_keys.sql схемы PostgreSQL
+-- Run: Автоматически при запуске сервиса или командой migrate up
+
+CREATE TABLE IF NOT EXISTS secret_data_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    master_key TEXT NOT NULL,
+    wrapped BLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,302 @@
+// Package client is the Go SDK of the config service. Client calls the HTTP
+// API and retries failed idempotent requests with backoff. Store keeps a
+// local snapshot of one environment up to date and serves typed values from
+// it, so services keep working while the config service is unavailable.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetries    = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+
+	// maxErrorBody bounds how much of an error response is read.
+	maxErrorBody = 64 << 10
+)
+
+var (
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrVersionMismatch = errors.New("version mismatch")
+	ErrInvalid         = errors.New("invalid request")
+	ErrUnavailable     = errors.New("service unavailable")
+)
+
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// APIError is a response with an error status. It matches the Err values of
+// this package with errors.Is by status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Violations []Violation
+
+	// body is kept for endpoints that describe a failure with a report.
+	body []byte
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("config service: %d %s", e.StatusCode, e.Message)
+	for _, v := range e.Violations {
+		message += "; " + v.Path + ": " + v.Message
+	}
+	return message
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrVersionMismatch:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusServiceUnavailable || e.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+type Options struct {
+	// Token is sent as a bearer token when set.
+	Token string
+	// HTTPClient defaults to http.DefaultClient. Its timeout must exceed the
+	// poll timeout of stores that watch for changes.
+	HTTPClient *http.Client
+	// Retries is how many times a failed idempotent request is repeated,
+	// 3 by default; a negative value disables retries.
+	Retries int
+	// MinBackoff and MaxBackoff bound the exponential delay between
+	// retries, 100ms and 10s by default.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// New returns a client of the service at baseURL, such as
+// http://config-service:8080.
+func New(baseURL string, opts Options) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+
+	c := &Client{
+		baseURL:    parsed,
+		token:      opts.Token,
+		httpClient: opts.HTTPClient,
+		retries:    opts.Retries,
+		minBackoff: opts.MinBackoff,
+		maxBackoff: opts.MaxBackoff,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	switch {
+	case c.retries == 0:
+		c.retries = defaultRetries
+	case c.retries < 0:
+		c.retries = 0
+	}
+	if c.minBackoff <= 0 {
+		c.minBackoff = defaultMinBackoff
+	}
+	if c.maxBackoff <= 0 {
+		c.maxBackoff = defaultMaxBackoff
+	}
+	c.maxBackoff = max(c.maxBackoff, c.minBackoff)
+	return c, nil
+}
+
+// configPath joins unescaped segments; send escapes the path as a whole.
+func configPath(segments ...string) string {
+	return "/api/configs/" + strings.Join(segments, "/")
+}
+
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+}
+
+// do sends req and decodes a successful JSON response into out. Transport
+// errors and statuses that signal a transient failure are retried for
+// methods that are safe to repeat.
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	var body []byte
+	switch b := req.body.(type) {
+	case nil:
+	case []byte:
+		body = b
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, body)
+		if attempt < c.retries && idempotent(req.method) && retryable(resp, err) && ctx.Err() == nil {
+			if resp != nil {
+				_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+				_ = resp.Body.Close()
+			}
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return nil, readError(resp)
+		}
+		switch out := out.(type) {
+		case nil:
+		case *[]byte:
+			if *out, err = io.ReadAll(resp.Body); err != nil {
+				return nil, err
+			}
+		default:
+			if resp.StatusCode == http.StatusNoContent {
+				break
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return nil, fmt.Errorf("invalid response: %w", err)
+			}
+		}
+		return resp.Header, nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	target := *c.baseURL
+	target.Path += req.path
+	target.RawPath = ""
+	target.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if body != nil && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// backoff doubles the delay with every attempt and picks it at random from
+// its upper half, so that clients failing together do not retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.maxBackoff
+	if attempt < 32 {
+		delay = min(c.minBackoff<<attempt, c.maxBackoff)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readError turns an error response into an APIError. Validation errors
+// come as JSON, everything else as plain text.
+func readError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data)), body: data}
+
+	var body struct {
+		Error      string      `json:"error"`
+		Violations []Violation `json:"violations"`
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		apiErr.Message = ""
+		if json.Unmarshal(data, &body) == nil {
+			apiErr.Message = body.Error
+			apiErr.Violations = body.Violations
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"config-service/backend/internal/handler"
+	"config-service/backend/internal/infrastructure/memory"
+	"config-service/backend/internal/model"
+	"config-service/backend/internal/service"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServer serves the real config API over an in-memory repository. Its
+// failures field makes the next requests fail with 503, and down fails all
+// of them.
+type testServer struct {
+	*httptest.Server
+	service  service.ConfigService
+	requests atomic.Int32
+	failures atomic.Int32
+	down     atomic.Bool
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	repo, err := memory.NewRepository("")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if err := repo.CreateEnvironment(context.Background(), &model.Environment{Name: "dev"}); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+
+	s := &testServer{service: service.NewConfigService(repo, service.NewBroker())}
+	mux := http.NewServeMux()
+	handler.NewConfigHandler(s.service).RegisterRoutes(mux)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.down.Load() || s.failures.Add(-1) >= 0 {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) set(t *testing.T, key, value string) {
+	t.Helper()
+	ctx := context.Background()
+	config, err := s.service.GetConfig(ctx, "dev", key)
+	switch {
+	case errors.Is(err, service.ErrConfigNotFound):
+		err = s.service.CreateConfig(ctx, "dev", key, value, model.ValueSpec{}, "test")
+	case err == nil:
+		err = s.service.UpdateConfig(ctx, "dev", key, value, config.ValueSpec, "test", config.Version)
+	}
+	if err != nil {
+		t.Fatalf("setting %s: %v", key, err)
+	}
+}
+
+func newTestClient(t *testing.T, server *testServer, opts Options) *Client {
+	t.Helper()
+	if opts.MinBackoff == 0 {
+		opts.MinBackoff, opts.MaxBackoff = time.Millisecond, 5*time.Millisecond
+	}
+	c, err := New(server.URL, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestNewRejectsInvalidURL(t *testing.T) {
+	for _, baseURL := range []string{"", "config-service:8080", "ftp://config-service", "http://"} {
+		if _, err := New(baseURL, Options{}); err == nil {
+			t.Errorf("New(%q) error = nil", baseURL)
+		}
+	}
+}
+
+func TestClientGetConfig(t *testing.T) {
+	server := newTestServer(t)
+	server.set(t, "db.host", "localhost")
+	c := newTestClient(t, server, Options{})
+
+	config, err := c.GetConfig(context.Background(), "dev", "db.host")
+	if err != nil || config.Value != "localhost" || config.Version != 1 || config.Revision == 0 {
+		t.Fatalf("GetConfig() = %#v, %v", config, err)
+	}
+
+	_, err = c.GetConfig(context.Background(), "dev", "missing")
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message != "config not found" {
+		t.Fatalf("GetConfig() missing error = %v", err)
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Fatal("a 404 must not match ErrConflict")
+	}
+}
+
+func TestClientListConfigsAndChanges(t *testing.T) {
+	server := newTestServer(t)
+	server.set(t, "b", "2")
+	server.set(t, "a", "1")
+	c := newTestClient(t, server, Options{})
+	ctx := context.Background()
+
+	configs, err := c.ListConfigs(ctx, "dev", ListOptions{})
+	if err != nil || len(configs) != 2 || configs[0].Key != "a" || configs[1].Value != "2" {
+		t.Fatalf("ListConfigs() = %v, %v", configs, err)
+	}
+
+	revisions, err := c.Changes(ctx, "dev", configs[1].Revision, time.Second)
+	if err != nil || len(revisions) != 1 || revisions[0].Key != "a" || revisions[0].Operation != "create" {
+		t.Fatalf("Changes() = %v, %v", revisions, err)
+	}
+
+	start := time.Now()
+	revisions, err = c.Changes(ctx, "dev", configs[0].Revision, 50*time.Millisecond)
+	if err != nil || len(revisions) != 0 || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("Changes() with nothing new = %v, %v after %v, want an empty list after the timeout", revisions, err, time.Since(start))
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		failures     int32
+		wantErr      error
+		wantRequests int32
+	}{
+		{name: "recovers", retries: 3, failures: 2, wantRequests: 3},
+		{name: "gives up", retries: 2, failures: 5, wantErr: ErrUnavailable, wantRequests: 3},
+		{name: "disabled", retries: -1, failures: 1, wantErr: ErrUnavailable, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			server.set(t, "key", "value")
+			server.failures.Store(tt.failures)
+			c := newTestClient(t, server, Options{Retries: tt.retries})
+
+			_, err := c.GetConfig(context.Background(), "dev", "key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetConfig() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := server.requests.Load(); got != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientSendsToken(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error":"invalid value","type":"int","violations":[{"path":"","message":"not an integer"}]}`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL+"/", Options{Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err = c.GetConfig(context.Background(), "dev", "port")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrInvalid) || apiErr.Message != "invalid value" || len(apiErr.Violations) != 1 {
+		t.Fatalf("GetConfig() error = %#v", err)
+	}
+	if header != "Bearer secret" {
+		t.Fatalf("Authorization = %q", header)
+	}
+}
+
+func TestClientWrites(t *testing.T) {
+	server := newTestServer(t)
+	c := newTestClient(t, server, Options{})
+	ctx := context.Background()
+
+	if err := c.CreateConfig(ctx, "dev", "port", "8080", ValueSpec{Type: "int"}); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	if err := c.CreateConfig(ctx, "dev", "port", "8081", ValueSpec{}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("CreateConfig() existing error = %v", err)
+	}
+	if err := c.UpdateConfig(ctx, "dev", "port", "abc", ValueSpec{}, 0); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("UpdateConfig() invalid int error = %v", err)
+	}
+	if err := c.UpdateConfig(ctx, "dev", "port", "9090", ValueSpec{}, 1); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if err := c.UpdateConfig(ctx, "dev", "port", "9091", ValueSpec{}, 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("UpdateConfig() stale error = %v", err)
+	}
+	config, err := c.GetConfig(ctx, "dev", "port")
+	if err != nil || config.Value != "9090" || config.Type != "int" || config.Version != 2 {
+		t.Fatalf("GetConfig() = %#v, %v, want the type kept", config, err)
+	}
+
+	history, err := c.History(ctx, "dev", "port")
+	if err != nil || len(history) != 2 || history[0].Value != "9090" {
+		t.Fatalf("History() = %v, %v", history, err)
+	}
+	if err := c.DeleteConfig(ctx, "dev", "port", 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("DeleteConfig() stale error = %v", err)
+	}
+	if err := c.DeleteConfig(ctx, "dev", "port", 2); err != nil {
+		t.Fatalf("DeleteConfig() error = %v", err)
+	}
+
+	server.set(t, "db", "x")
+	server.set(t, "db.host", "localhost")
+	revisions, err := c.DeleteTree(ctx, "dev", "db")
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("DeleteTree() = %v, %v", revisions, err)
+	}
+}
+
+func TestClientExportImport(t *testing.T) {
+	server := newTestServer(t)
+	server.set(t, "a", "1")
+	c := newTestClient(t, server, Options{})
+	ctx := context.Background()
+
+	document, err := c.Export(ctx, "dev", "dotenv", "")
+	if err != nil || string(document) != "a=\"1\"\n" {
+		t.Fatalf("Export() = %q, %v", document, err)
+	}
+
+	report, err := c.Import(ctx, "dev", []byte("a=2\nb=3\n"), ImportOptions{Format: "dotenv", DryRun: true})
+	if err != nil || !report.DryRun || len(report.Created) != 1 || len(report.Updated) != 1 {
+		t.Fatalf("Import() dry run = %#v, %v", report, err)
+	}
+	if _, err := c.Import(ctx, "dev", []byte("{"), ImportOptions{}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Import() malformed error = %v", err)
+	}
+	if err := c.CreateConfig(ctx, "dev", "port", "8080", ValueSpec{Type: "int"}); err != nil {
+		t.Fatalf("CreateConfig() error = %v", err)
+	}
+	report, err = c.Import(ctx, "dev", []byte(`{"port": "abc"}`), ImportOptions{})
+	if !errors.Is(err, ErrConflict) || report == nil || len(report.Results) == 0 {
+		t.Fatalf("Import() rejected = %#v, %v, want the report with the error", report, err)
+	}
+
+	diff, err := c.Compare(ctx, "dev", "dev")
+	if err != nil || diff.Left != "dev" || len(diff.Changed) != 0 {
+		t.Fatalf("Compare() = %#v, %v", diff, err)
+	}
+}
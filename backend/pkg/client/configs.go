@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Config is a config key as the API returns it. Secret values are masked.
+type Config struct {
+	Environment string    `json:"env"`
+	Key         string    `json:"key"`
+	Value       string    `json:"value"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	Revision    int64     `json:"revision,omitempty"`
+	Version     int64     `json:"version,omitempty"`
+	ValueSpec
+}
+
+// ValueSpec declares the type of a value. Writing a value with the zero spec
+// keeps the type the key already has.
+type ValueSpec struct {
+	Type   string          `json:"type,omitempty"`
+	Enum   []string        `json:"enum,omitempty"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+	Secret bool            `json:"secret,omitempty"`
+}
+
+// Revision records one write to a config key. Operation is create, update
+// or delete; a delete carries the last value of the key.
+type Revision struct {
+	Revision    int64     `json:"revision"`
+	Environment string    `json:"env"`
+	Key         string    `json:"key"`
+	Value       string    `json:"value"`
+	Operation   string    `json:"operation"`
+	Actor       string    `json:"actor"`
+	CreatedAt   time.Time `json:"created_at"`
+	Secret      bool      `json:"secret,omitempty"`
+}
+
+// ListOptions filters ListConfigs. Tree selects a key and the keys nested
+// below it; Search matches keys and values case-insensitively.
+type ListOptions struct {
+	Prefix string
+	Tree   string
+	Search string
+}
+
+// Diff compares two environments key by key.
+type Diff struct {
+	Left        string         `json:"left"`
+	Right       string         `json:"right"`
+	OnlyInLeft  []*Config      `json:"only_in_left"`
+	OnlyInRight []*Config      `json:"only_in_right"`
+	Changed     []ConfigChange `json:"changed"`
+}
+
+type ConfigChange struct {
+	Key   string  `json:"key"`
+	Left  *Config `json:"left"`
+	Right *Config `json:"right"`
+}
+
+// ImportOptions describes an imported document. Format is json, yaml,
+// dotenv or properties; Mode is merge, replace or skip-existing. The server
+// defaults to json and merge.
+type ImportOptions struct {
+	Format string
+	Mode   string
+	DryRun bool
+}
+
+// ImportReport lists the imported keys per outcome. Results explain which
+// operation made a failed import fail.
+type ImportReport struct {
+	Environment string        `json:"env"`
+	Mode        string        `json:"mode"`
+	DryRun      bool          `json:"dry_run"`
+	Created     []string      `json:"created"`
+	Updated     []string      `json:"updated"`
+	Deleted     []string      `json:"deleted"`
+	Unchanged   []string      `json:"unchanged"`
+	Skipped     []string      `json:"skipped"`
+	Masked      []string      `json:"masked"`
+	Results     []BatchResult `json:"results,omitempty"`
+}
+
+type BatchResult struct {
+	Op         string      `json:"op"`
+	Key        string      `json:"key"`
+	Status     string      `json:"status"`
+	Revision   int64       `json:"revision,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+func (c *Client) GetConfig(ctx context.Context, environment, key string) (*Config, error) {
+	var config Config
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: configPath(environment, key)}, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// ListConfigs returns the keys of the environment that match opts, sorted
+// by key.
+func (c *Client) ListConfigs(ctx context.Context, environment string, opts ListOptions) ([]*Config, error) {
+	query := url.Values{}
+	for name, value := range map[string]string{"prefix": opts.Prefix, "tree": opts.Tree, "search": opts.Search} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	var configs []*Config
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: configPath(environment), query: query}, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// CreateConfig fails with ErrConflict when the key exists.
+func (c *Client) CreateConfig(ctx context.Context, environment, key, value string, spec ValueSpec) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: configPath(environment, key), body: valueBody(value, spec)}, nil)
+	return err
+}
+
+// UpdateConfig fails with ErrVersionMismatch when version is not zero and
+// the key has moved past it.
+func (c *Client) UpdateConfig(ctx context.Context, environment, key, value string, spec ValueSpec, version int64) error {
+	_, err := c.do(ctx, request{method: http.MethodPut, path: configPath(environment, key), header: ifMatch(version), body: valueBody(value, spec)}, nil)
+	return err
+}
+
+// DeleteConfig deletes the key if it is still at version, unless version
+// is zero.
+func (c *Client) DeleteConfig(ctx context.Context, environment, key string, version int64) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: configPath(environment, key), header: ifMatch(version)}, nil)
+	return err
+}
+
+// DeleteTree deletes a key and the keys nested below it in one transaction.
+func (c *Client) DeleteTree(ctx context.Context, environment, tree string) ([]*Revision, error) {
+	var revisions []*Revision
+	req := request{method: http.MethodDelete, path: configPath(environment), query: url.Values{"tree": {tree}}}
+	if _, err := c.do(ctx, req, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// History returns the revisions of the key, newest first.
+func (c *Client) History(ctx context.Context, environment, key string) ([]*Revision, error) {
+	var revisions []*Revision
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: configPath(environment, key, "history")}, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// Compare diffs two environments. Either may be pinned to a point in time
+// as env@<RFC 3339 time>.
+func (c *Client) Compare(ctx context.Context, left, right string) (*Diff, error) {
+	var diff Diff
+	req := request{method: http.MethodGet, path: "/api/diff", query: url.Values{"left": {left}, "right": {right}}}
+	if _, err := c.do(ctx, req, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// Export returns the keys of the environment under tree, or all of them,
+// as a document in format.
+func (c *Client) Export(ctx context.Context, environment, format, tree string) ([]byte, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	if tree != "" {
+		query.Set("tree", tree)
+	}
+	var document []byte
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: configPath(environment, "export"), query: query}, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// Import writes the keys of document to the environment. When the import is
+// rejected as a whole, the report is returned along with the error.
+func (c *Client) Import(ctx context.Context, environment string, document []byte, opts ImportOptions) (*ImportReport, error) {
+	query := url.Values{}
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+	if opts.Mode != "" {
+		query.Set("mode", opts.Mode)
+	}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	req := request{
+		method: http.MethodPost,
+		path:   configPath(environment, "import"),
+		query:  query,
+		header: http.Header{"Content-Type": {"application/octet-stream"}},
+		body:   document,
+	}
+
+	var report ImportReport
+	_, err := c.do(ctx, req, &report)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusConflict || apiErr.StatusCode == http.StatusUnprocessableEntity) {
+		if json.Unmarshal(apiErr.body, &report) == nil && report.Environment != "" {
+			return &report, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Changes returns the revisions of the environment after since, in order.
+// When there are none it waits up to timeout for the next change and may
+// return an empty list; the server caps the wait at a minute.
+func (c *Client) Changes(ctx context.Context, environment string, since int64, timeout time.Duration) ([]*Revision, error) {
+	query := url.Values{"since": {strconv.FormatInt(since, 10)}}
+	if timeout > 0 {
+		query.Set("timeout", timeout.String())
+	}
+	var revisions []*Revision
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: configPath(environment, "watch"), query: query}, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func valueBody(value string, spec ValueSpec) any {
+	return struct {
+		Value string `json:"value"`
+		ValueSpec
+	}{value, spec}
+}
+
+// ifMatch makes a write conditional on the version of the key.
+func ifMatch(version int64) http.Header {
+	if version == 0 {
+		return nil
+	}
+	return http.Header{"If-Match": {`"` + strconv.FormatInt(version, 10) + `"`}}
+}
@@ -0,0 +1,367 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultPollTimeout = 30 * time.Second
+
+type StoreOptions struct {
+	// CacheFile keeps the last snapshot on disk. Start falls back to it when
+	// the service is unreachable, so a restart during an outage still has
+	// values.
+	CacheFile string
+	// RefreshInterval, when set, reloads the whole environment on that
+	// interval instead of watching it for changes.
+	RefreshInterval time.Duration
+	// PollTimeout is how long each watch request waits for a change,
+	// 30s by default.
+	PollTimeout time.Duration
+	// OnError receives the errors of background updates, which the store
+	// keeps retrying with backoff.
+	OnError func(error)
+}
+
+// Change describes how one key differs after an update of the snapshot.
+type Change struct {
+	Key      string
+	Value    string
+	OldValue string
+	Deleted  bool
+}
+
+// Store serves the values of one environment from a local snapshot that a
+// background goroutine keeps up to date. Reads never wait on the network:
+// while the service is unreachable they return the last values seen.
+type Store struct {
+	client      *Client
+	environment string
+	opts        StoreOptions
+
+	mu        sync.RWMutex
+	values    map[string]string
+	revision  int64
+	callbacks []func(Change)
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func NewStore(c *Client, environment string, opts StoreOptions) *Store {
+	if opts.PollTimeout <= 0 {
+		opts.PollTimeout = defaultPollTimeout
+	}
+	return &Store{
+		client:      c,
+		environment: environment,
+		opts:        opts,
+		values:      make(map[string]string),
+	}
+}
+
+// Start loads the snapshot and starts keeping it up to date until Stop. The
+// snapshot comes from the service, or from the cache file when the service
+// cannot be reached; Start fails only when neither works.
+func (s *Store) Start(ctx context.Context) error {
+	if s.done != nil {
+		return errors.New("store already started")
+	}
+	if err := s.load(ctx); err != nil {
+		cached, cacheErr := s.readCache()
+		if cacheErr != nil {
+			return err
+		}
+		s.replace(cached)
+		s.report(fmt.Errorf("serving cached configs: %w", err))
+	}
+
+	ctx, s.stop = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+	go s.run(ctx)
+	return nil
+}
+
+// Stop ends background updates and waits for a running callback to return.
+func (s *Store) Stop() {
+	if s.done == nil {
+		return
+	}
+	s.stop()
+	<-s.done
+}
+
+// OnChange registers fn to be called for every key that changes after Start.
+// Callbacks run one at a time on the goroutine that updates the store.
+func (s *Store) OnChange(fn func(Change)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks = append(s.callbacks, fn)
+}
+
+func (s *Store) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Values returns a copy of the snapshot.
+func (s *Store) Values() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make(map[string]string, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Revision is the newest change of the environment the snapshot reflects.
+func (s *Store) Revision() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
+}
+
+// String returns the value of key, or fallback when the key does not exist.
+// The other getters also return fallback when the value does not parse.
+func (s *Store) String(key, fallback string) string {
+	if value, ok := s.Lookup(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func (s *Store) Int(key string, fallback int) int {
+	return lookupAs(s, key, fallback, strconv.Atoi)
+}
+
+func (s *Store) Bool(key string, fallback bool) bool {
+	return lookupAs(s, key, fallback, strconv.ParseBool)
+}
+
+func (s *Store) Duration(key string, fallback time.Duration) time.Duration {
+	return lookupAs(s, key, fallback, time.ParseDuration)
+}
+
+func lookupAs[T any](s *Store, key string, fallback T, parse func(string) (T, error)) T {
+	value, ok := s.Lookup(key)
+	if !ok {
+		return fallback
+	}
+	parsed, err := parse(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+// snapshot is the state of the store, as saved to the cache file.
+type snapshot struct {
+	Environment string            `json:"env"`
+	Revision    int64             `json:"revision"`
+	Values      map[string]string `json:"values"`
+}
+
+func (s *Store) run(ctx context.Context) {
+	defer close(s.done)
+
+	update := s.watch
+	if s.opts.RefreshInterval > 0 {
+		update = s.refresh
+	}
+	failures := 0
+	for ctx.Err() == nil {
+		err := update(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			failures = 0
+			continue
+		}
+		s.report(err)
+		_ = sleep(ctx, s.client.backoff(failures))
+		failures++
+	}
+}
+
+// load replaces the snapshot with the current keys of the environment. The
+// newest revision among them is where watching resumes: a later revision
+// can only belong to a key that has since been deleted, so replaying it
+// changes nothing.
+func (s *Store) load(ctx context.Context) error {
+	next, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	s.replace(next)
+	s.saveCache(next)
+	return nil
+}
+
+func (s *Store) fetch(ctx context.Context) (*snapshot, error) {
+	configs, err := s.client.ListConfigs(ctx, s.environment, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	next := &snapshot{Environment: s.environment, Values: make(map[string]string, len(configs))}
+	for _, config := range configs {
+		next.Values[config.Key] = config.Value
+		next.Revision = max(next.Revision, config.Revision)
+	}
+	return next, nil
+}
+
+// refresh waits for the refresh interval and then reloads the environment,
+// reporting the keys that differ.
+func (s *Store) refresh(ctx context.Context) error {
+	if err := sleep(ctx, s.opts.RefreshInterval); err != nil {
+		return err
+	}
+	next, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	var changes []Change
+	for key, value := range next.Values {
+		if old, ok := s.values[key]; !ok || old != value {
+			changes = append(changes, Change{Key: key, Value: value, OldValue: old})
+		}
+	}
+	for key, old := range s.values {
+		if _, ok := next.Values[key]; !ok {
+			changes = append(changes, Change{Key: key, OldValue: old, Deleted: true})
+		}
+	}
+	s.values, s.revision = next.Values, next.Revision
+	s.mu.Unlock()
+
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Key, b.Key) })
+	s.publish(changes)
+	return nil
+}
+
+// watch waits for the changes after the snapshot revision and applies them.
+func (s *Store) watch(ctx context.Context) error {
+	revisions, err := s.client.Changes(ctx, s.environment, s.Revision(), s.opts.PollTimeout)
+	if err != nil || len(revisions) == 0 {
+		return err
+	}
+
+	s.mu.Lock()
+	var changes []Change
+	for _, revision := range revisions {
+		old, ok := s.values[revision.Key]
+		switch {
+		case revision.Operation == "delete":
+			if ok {
+				delete(s.values, revision.Key)
+				changes = append(changes, Change{Key: revision.Key, OldValue: old, Deleted: true})
+			}
+		case !ok || old != revision.Value:
+			s.values[revision.Key] = revision.Value
+			changes = append(changes, Change{Key: revision.Key, Value: revision.Value, OldValue: old})
+		}
+		s.revision = max(s.revision, revision.Revision)
+	}
+	s.mu.Unlock()
+
+	s.publish(changes)
+	return nil
+}
+
+func (s *Store) replace(next *snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values, s.revision = next.Values, next.Revision
+}
+
+// publish saves the snapshot and passes changes to the callbacks.
+func (s *Store) publish(changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	s.mu.RLock()
+	current := &snapshot{Environment: s.environment, Revision: s.revision, Values: s.values}
+	s.saveCache(current)
+	callbacks := slices.Clone(s.callbacks)
+	s.mu.RUnlock()
+
+	for _, change := range changes {
+		for _, fn := range callbacks {
+			fn(change)
+		}
+	}
+}
+
+func (s *Store) report(err error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(err)
+	}
+}
+
+func (s *Store) readCache() (*snapshot, error) {
+	if s.opts.CacheFile == "" {
+		return nil, errors.New("no cache file")
+	}
+	data, err := os.ReadFile(s.opts.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+	var cached snapshot
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("invalid cache file %s: %w", s.opts.CacheFile, err)
+	}
+	if cached.Environment != s.environment {
+		return nil, fmt.Errorf("cache file %s holds environment %q", s.opts.CacheFile, cached.Environment)
+	}
+	if cached.Values == nil {
+		cached.Values = make(map[string]string)
+	}
+	return &cached, nil
+}
+
+// saveCache writes the snapshot to a temporary file and renames it over the
+// cache file, so a crash never leaves a partial snapshot behind.
+func (s *Store) saveCache(current *snapshot) {
+	if s.opts.CacheFile == "" {
+		return
+	}
+	if err := writeFileAtomic(s.opts.CacheFile, current); err != nil {
+		s.report(fmt.Errorf("saving cache file: %w", err))
+	}
+}
+
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func startTestStore(t *testing.T, c *Client, opts StoreOptions) *Store {
+	t.Helper()
+	store := NewStore(c, "dev", opts)
+	if err := store.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(store.Stop)
+	return store
+}
+
+// nextChange waits for the store to report a change.
+func nextChange(t *testing.T, changes <-chan Change) Change {
+	t.Helper()
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a change")
+		return Change{}
+	}
+}
+
+func TestStoreTypedGetters(t *testing.T) {
+	server := newTestServer(t)
+	for key, value := range map[string]string{
+		"name":    "api",
+		"port":    "8080",
+		"debug":   "true",
+		"timeout": "1m30s",
+		"broken":  "not a number",
+	} {
+		server.set(t, key, value)
+	}
+	store := startTestStore(t, newTestClient(t, server, Options{}), StoreOptions{})
+
+	if got := store.String("name", "x"); got != "api" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := store.String("missing", "x"); got != "x" {
+		t.Errorf("String() missing = %q", got)
+	}
+	if got := store.Int("port", 0); got != 8080 {
+		t.Errorf("Int() = %d", got)
+	}
+	if got := store.Int("broken", 42); got != 42 {
+		t.Errorf("Int() unparsable = %d, want the fallback", got)
+	}
+	if got := store.Bool("debug", false); !got {
+		t.Errorf("Bool() = %v", got)
+	}
+	if got := store.Duration("timeout", 0); got != 90*time.Second {
+		t.Errorf("Duration() = %v", got)
+	}
+	if got := store.Duration("missing", time.Second); got != time.Second {
+		t.Errorf("Duration() missing = %v", got)
+	}
+	if values := store.Values(); len(values) != 5 {
+		t.Errorf("Values() = %v", values)
+	}
+}
+
+func TestStoreFollowsChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		opts StoreOptions
+	}{
+		{name: "watch", opts: StoreOptions{PollTimeout: time.Second}},
+		{name: "refresh", opts: StoreOptions{RefreshInterval: 10 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			server.set(t, "a", "1")
+			server.set(t, "b", "1")
+			store := startTestStore(t, newTestClient(t, server, Options{}), tt.opts)
+			changes := make(chan Change, 10)
+			store.OnChange(func(change Change) { changes <- change })
+
+			server.set(t, "a", "2")
+			if change := nextChange(t, changes); change != (Change{Key: "a", Value: "2", OldValue: "1"}) {
+				t.Fatalf("change = %+v", change)
+			}
+			if got := store.Int("a", 0); got != 2 {
+				t.Fatalf("Int() after change = %d", got)
+			}
+
+			if err := server.service.DeleteConfig(context.Background(), "dev", "b", "test", 0); err != nil {
+				t.Fatalf("DeleteConfig() error = %v", err)
+			}
+			if change := nextChange(t, changes); change != (Change{Key: "b", OldValue: "1", Deleted: true}) {
+				t.Fatalf("change = %+v", change)
+			}
+			if _, ok := store.Lookup("b"); ok {
+				t.Fatal("deleted key is still in the store")
+			}
+		})
+	}
+}
+
+func TestStoreSurvivesOutage(t *testing.T) {
+	server := newTestServer(t)
+	server.set(t, "port", "8080")
+	c := newTestClient(t, server, Options{Retries: -1})
+	cacheFile := filepath.Join(t.TempDir(), "dev.json")
+
+	first := startTestStore(t, c, StoreOptions{CacheFile: cacheFile, PollTimeout: time.Second})
+	first.Stop()
+
+	server.down.Store(true)
+	if err := NewStore(c, "dev", StoreOptions{}).Start(context.Background()); err == nil {
+		t.Fatal("Start() without a cache file must fail while the service is down")
+	}
+	errs := make(chan error, 100)
+	store := startTestStore(t, c, StoreOptions{
+		CacheFile:   cacheFile,
+		PollTimeout: time.Second,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	if got := store.Int("port", 0); got != 8080 {
+		t.Fatalf("Int() from the cache file = %d", got)
+	}
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the outage was not reported")
+	}
+
+	changes := make(chan Change, 10)
+	store.OnChange(func(change Change) { changes <- change })
+	server.set(t, "port", "9090")
+	server.down.Store(false)
+	if change := nextChange(t, changes); change.Key != "port" || change.Value != "9090" {
+		t.Fatalf("change after the outage = %+v", change)
+	}
+
+	other := NewStore(c, "staging", StoreOptions{CacheFile: cacheFile})
+	server.down.Store(true)
+	if err := other.Start(context.Background()); err == nil {
+		t.Fatal("Start() must not serve the cache file of another environment")
+	}
+}
@@ -9,6 +9,8 @@ type Metrics struct {
 	HTTPRequestDuration *prometheus.HistogramVec
 	DBQueriesTotal      *prometheus.CounterVec
 	DBQueryDuration     *prometheus.HistogramVec
+	CacheRequestsTotal  *prometheus.CounterVec
+	CacheEntries        prometheus.Gauge
 }
 
 func NewMetrics() *Metrics {
@@ -46,6 +48,21 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"operation"},
 		),
+
+		CacheRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_requests_total",
+				Help: "Config cache lookups by result (hit or miss)",
+			},
+			[]string{"result"},
+		),
+
+		CacheEntries: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "cache_entries",
+				Help: "Number of configs held in the cache",
+			},
+		),
 	}
 
 	prometheus.MustRegister(
@@ -53,6 +70,8 @@ func NewMetrics() *Metrics {
 		m.HTTPRequestDuration,
 		m.DBQueriesTotal,
 		m.DBQueryDuration,
+		m.CacheRequestsTotal,
+		m.CacheEntries,
 	)
 
 	return m
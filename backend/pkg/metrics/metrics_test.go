@@ -21,7 +21,9 @@ func TestNewMetricsRegistersCollectors(t *testing.T) {
 	if m.HTTPRequestsTotal == nil ||
 		m.HTTPRequestDuration == nil ||
 		m.DBQueriesTotal == nil ||
-		m.DBQueryDuration == nil {
+		m.DBQueryDuration == nil ||
+		m.CacheRequestsTotal == nil ||
+		m.CacheEntries == nil {
 		t.Fatal("expected all metric collectors to be initialized")
 	}
 
@@ -29,6 +31,8 @@ func TestNewMetricsRegistersCollectors(t *testing.T) {
 	m.HTTPRequestDuration.WithLabelValues("/api/configs/prod", "GET").Observe(0.01)
 	m.DBQueriesTotal.WithLabelValues("get").Inc()
 	m.DBQueryDuration.WithLabelValues("get").Observe(0.02)
+	m.CacheRequestsTotal.WithLabelValues("hit").Inc()
+	m.CacheEntries.Set(1)
 
 	gathered, err := registry.Gather()
 	if err != nil {
@@ -44,6 +48,8 @@ func TestNewMetricsRegistersCollectors(t *testing.T) {
 		"http_request_duration_seconds",
 		"db_queries_total",
 		"db_query_duration_seconds",
+		"cache_requests_total",
+		"cache_entries",
 	} {
 		if !names[name] {
 			t.Fatalf("metric %q was not registered", name)
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"config-service/backend/internal/model"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Authenticator resolves a bearer token to the caller. It returns nil
+// without an error for tokens it does not accept.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*model.Identity, error)
+}
+
+type AuthMiddleware struct {
+	authenticator Authenticator
+}
+
+func NewAuthMiddleware(authenticator Authenticator) *AuthMiddleware {
+	return &AuthMiddleware{authenticator: authenticator}
+}
+
+// Handler rejects requests without a valid bearer token and stores the
+// caller in the request context for model.IdentityFromContext.
+func (mw *AuthMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w)
+			return
+		}
+		identity, err := mw.authenticator.Authenticate(r.Context(), token)
+		if err != nil {
+			log.Printf("authentication failed: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if identity == nil {
+			unauthorized(w)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(model.ContextWithIdentity(r.Context(), identity)))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="config-service"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// isPublicPath lists the endpoints probes, Prometheus and API docs reach
+// without a token.
+func isPublicPath(path string) bool {
+	return shouldSkipMetrics(path)
+}
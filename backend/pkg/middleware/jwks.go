@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"config-service/backend/config"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	maxJWKSSize = 1 << 20
+	// minJWKSRefetch limits fetches caused by tokens with an unknown key ID,
+	// which anyone can send.
+	minJWKSRefetch = time.Minute
+)
+
+// keySet holds the JWKS used to verify tokens. Keys from a URL are
+// re-fetched periodically and when a token names a key that is not known
+// yet, which is how providers roll keys.
+type keySet struct {
+	url     string
+	file    string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+	// fetching is closed when the fetch in flight, if any, completes;
+	// fetchErr is the outcome of the last fetch.
+	fetching chan struct{}
+	fetchErr error
+	now      func() time.Time
+}
+
+func newKeySet(cfg config.OIDCConfig) *keySet {
+	return &keySet{
+		url:     cfg.JWKSURL,
+		file:    cfg.JWKSFile,
+		refresh: cfg.JWKSRefresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+	}
+}
+
+func (s *keySet) loadFile() error {
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("invalid JWKS file %s: %w", s.file, err)
+	}
+	s.keys = keys
+	return nil
+}
+
+// lookup returns the keys to try for a token: the key with the given ID, or
+// every key when the token has no key ID.
+func (s *keySet) lookup(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	if s.url != "" {
+		if err := s.update(ctx, kid); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kid == "" {
+		return s.keys.Keys, nil
+	}
+	return s.keys.Key(kid), nil
+}
+
+// update starts a fetch when the keys are stale or do not contain kid, and
+// waits for it only when the current keys cannot answer the lookup. A
+// single fetch runs at a time and mu is not held during it, so a slow
+// provider or tokens with made-up key IDs do not hold up lookups of known
+// keys.
+func (s *keySet) update(ctx context.Context, kid string) error {
+	s.mu.Lock()
+	now := s.now()
+	stale := s.fetchedAt.IsZero() || now.Sub(s.fetchedAt) >= s.refresh
+	unknown := kid != "" && len(s.keys.Key(kid)) == 0
+	done := s.fetching
+	if done == nil && (stale || (unknown && now.Sub(s.fetchedAt) >= minJWKSRefetch)) {
+		// Failed fetches count too, so that a broken provider is not
+		// hammered on every request.
+		s.fetchedAt = now
+		done = make(chan struct{})
+		s.fetching = done
+		// The fetch serves every waiting lookup, so it must not end with
+		// the request that started it; the client timeout bounds it.
+		go s.fetch(context.WithoutCancel(ctx), done)
+	}
+	missing := len(s.keys.Keys) == 0 || unknown
+	s.mu.Unlock()
+
+	if done == nil || !missing {
+		return nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Keep verifying with the previous keys while the provider is
+	// unreachable.
+	if len(s.keys.Keys) == 0 {
+		return s.fetchErr
+	}
+	return nil
+}
+
+func (s *keySet) fetch(ctx context.Context, done chan struct{}) {
+	keys, err := s.download(ctx)
+
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+	}
+	s.fetchErr = err
+	s.fetching = nil
+	s.mu.Unlock()
+	close(done)
+}
+
+func (s *keySet) download(ctx context.Context) (jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return jose.JSONWebKeySet{}, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("invalid JWKS from %s: %w", s.url, err)
+	}
+	return keys, nil
+}
+
+func parseJWKS(data []byte) (jose.JSONWebKeySet, error) {
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return jose.JSONWebKeySet{}, err
+	}
+	if len(keys.Keys) == 0 {
+		return jose.JSONWebKeySet{}, fmt.Errorf("no keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTAuthenticator accepts JWTs issued by an OIDC provider and maps the
+// caller's groups to grants.
+type JWTAuthenticator struct {
+	cfg    config.OIDCConfig
+	grants map[string][]model.Grant
+	keys   *keySet
+	now    func() time.Time
+}
+
+// NewJWTAuthenticator returns nil when no JWKS is configured. A JWKS file is
+// read right away; a JWKS URL is fetched on first use.
+func NewJWTAuthenticator(cfg *config.Config) (*JWTAuthenticator, error) {
+	oidc := cfg.Auth.OIDC
+	if !oidc.Enabled() {
+		return nil, nil
+	}
+
+	grants := make(map[string][]model.Grant, len(oidc.GroupGrants))
+	for group, groupGrants := range oidc.GroupGrants {
+		for _, g := range groupGrants {
+			grant := model.Grant{Environment: g.Environment, KeyPrefix: g.KeyPrefix, Role: model.Role(g.Role)}
+			if err := grant.Validate(); err != nil {
+				return nil, fmt.Errorf("OIDC_GROUP_GRANTS for group %q: %w", group, err)
+			}
+			grants[group] = append(grants[group], grant)
+		}
+	}
+
+	keys := newKeySet(oidc)
+	if oidc.JWKSFile != "" {
+		if err := keys.loadFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &JWTAuthenticator{cfg: oidc, grants: grants, keys: keys, now: time.Now}, nil
+}
+
+// Authenticate returns nil for anything that is not a valid JWT from the
+// configured issuer, so that other authenticators can try the token. It only
+// fails when the keys cannot be fetched.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, raw string) (*model.Identity, error) {
+	if strings.Count(raw, ".") != 2 {
+		return nil, nil
+	}
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return nil, nil
+	}
+
+	candidates, err := a.keys.lookup(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+	var claims jwt.Claims
+	var extra map[string]any
+	verified := false
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if err := token.Claims(key.Key, &claims, &extra); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified || claims.Expiry == nil {
+		return nil, nil
+	}
+	expected := jwt.Expected{
+		Issuer:      a.cfg.Issuer,
+		AnyAudience: jwt.Audience{a.cfg.Audience},
+		Time:        a.now(),
+	}
+	if err := claims.ValidateWithLeeway(expected, a.cfg.ClockSkew); err != nil {
+		return nil, nil
+	}
+
+	name, _ := extra[a.cfg.NameClaim].(string)
+	if name == "" {
+		name = claims.Subject
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	groups := stringsClaim(extra[a.cfg.GroupsClaim])
+	identity := &model.Identity{
+		Name:   name,
+		Source: model.IdentitySourceOIDC,
+		Groups: groups,
+		Grants: []model.Grant{},
+	}
+	for _, group := range groups {
+		identity.Grants = append(identity.Grants, a.grants[group]...)
+	}
+	expiresAt := claims.Expiry.Time()
+	identity.ExpiresAt = &expiresAt
+	return identity, nil
+}
+
+// stringsClaim accepts both a list of strings and a single string, which
+// some providers use for one-element claims.
+func stringsClaim(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// ChainAuthenticators tries each authenticator in order and returns the
+// first identity.
+func ChainAuthenticators(authenticators ...Authenticator) Authenticator {
+	return authenticatorChain(authenticators)
+}
+
+type authenticatorChain []Authenticator
+
+func (c authenticatorChain) Authenticate(ctx context.Context, token string) (*model.Identity, error) {
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(ctx, token)
+		if err != nil || identity != nil {
+			return identity, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,414 @@
+package middleware
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/model"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "config-service"
+)
+
+var testNow = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type testKey struct {
+	private any
+	public  jose.JSONWebKey
+	alg     jose.SignatureAlgorithm
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	return testKey{
+		private: key,
+		public:  jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: string(jose.RS256), Use: "sig"},
+		alg:     jose.RS256,
+	}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	return testKey{
+		private: key,
+		public:  jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: string(jose.ES256), Use: "sig"},
+		alg:     jose.ES256,
+	}
+}
+
+func (k testKey) sign(t *testing.T, claims ...any) string {
+	t.Helper()
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+	if k.public.KeyID != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), k.public.KeyID)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: k.alg, Key: k.private}, opts)
+	if err != nil {
+		t.Fatalf("jose.NewSigner() error = %v", err)
+	}
+	builder := jwt.Signed(signer)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+	token, err := builder.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	return token
+}
+
+func writeJWKS(t *testing.T, keys ...testKey) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, keys...), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func jwksJSON(t *testing.T, keys ...testKey) []byte {
+	t.Helper()
+	set := jose.JSONWebKeySet{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.public)
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return data
+}
+
+func testOIDCConfig() config.OIDCConfig {
+	return config.OIDCConfig{
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		ClockSkew:   time.Minute,
+		JWKSRefresh: 15 * time.Minute,
+		NameClaim:   "email",
+		GroupsClaim: "groups",
+		GroupGrants: map[string][]config.GroupGrant{
+			"platform": {{Environment: "*", Role: "admin"}},
+			"billing":  {{Environment: "prod", KeyPrefix: "billing.", Role: "writer"}, {Environment: "staging", Role: "reader"}},
+		},
+	}
+}
+
+func newTestJWTAuthenticator(t *testing.T, oidc config.OIDCConfig) *JWTAuthenticator {
+	t.Helper()
+	a, err := NewJWTAuthenticator(&config.Config{Auth: config.AuthConfig{Enabled: true, OIDC: oidc}})
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator() error = %v", err)
+	}
+	a.now = func() time.Time { return testNow }
+	a.keys.now = a.now
+	return a
+}
+
+func validClaims() jwt.Claims {
+	return jwt.Claims{
+		Issuer:   testIssuer,
+		Subject:  "user-42",
+		Audience: jwt.Audience{testAudience},
+		IssuedAt: jwt.NewNumericDate(testNow.Add(-time.Minute)),
+		Expiry:   jwt.NewNumericDate(testNow.Add(time.Hour)),
+	}
+}
+
+func TestNewJWTAuthenticator(t *testing.T) {
+	a, err := NewJWTAuthenticator(&config.Config{})
+	if err != nil || a != nil {
+		t.Fatalf("NewJWTAuthenticator() without a JWKS = %v, %v; want nil, nil", a, err)
+	}
+
+	oidc := testOIDCConfig()
+	oidc.JWKSFile = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := NewJWTAuthenticator(&config.Config{Auth: config.AuthConfig{OIDC: oidc}}); err == nil {
+		t.Fatal("NewJWTAuthenticator() with a missing JWKS file must fail")
+	}
+
+	oidc = testOIDCConfig()
+	oidc.JWKSFile = writeJWKS(t, newECKey(t, "k1"))
+	oidc.GroupGrants = map[string][]config.GroupGrant{"ops": {{Environment: "prod", Role: "root"}}}
+	if _, err := NewJWTAuthenticator(&config.Config{Auth: config.AuthConfig{OIDC: oidc}}); err == nil {
+		t.Fatal("NewJWTAuthenticator() with an invalid group role must fail")
+	}
+}
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	stranger := newRSAKey(t, "rsa-1")
+	oidc := testOIDCConfig()
+	oidc.JWKSFile = writeJWKS(t, key, ecKey)
+	a := newTestJWTAuthenticator(t, oidc)
+
+	withClaims := func(change func(*jwt.Claims)) jwt.Claims {
+		c := validClaims()
+		change(&c)
+		return c
+	}
+	profile := map[string]any{"email": "alice@example.com", "groups": []string{"billing", "unknown"}}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantName  string
+		wantNoErr bool
+	}{
+		{name: "valid RSA token", token: key.sign(t, validClaims(), profile), wantName: "alice@example.com"},
+		{name: "valid EC token", token: ecKey.sign(t, validClaims(), profile), wantName: "alice@example.com"},
+		{name: "subject without name claim", token: key.sign(t, validClaims()), wantName: "user-42"},
+		{name: "wrong issuer", token: key.sign(t, withClaims(func(c *jwt.Claims) { c.Issuer = "https://evil.example.com" }))},
+		{name: "wrong audience", token: key.sign(t, withClaims(func(c *jwt.Claims) { c.Audience = jwt.Audience{"other"} }))},
+		{name: "expired within skew", token: key.sign(t, withClaims(func(c *jwt.Claims) {
+			c.Expiry = jwt.NewNumericDate(testNow.Add(-30 * time.Second))
+		})), wantName: "user-42"},
+		{name: "expired beyond skew", token: key.sign(t, withClaims(func(c *jwt.Claims) {
+			c.Expiry = jwt.NewNumericDate(testNow.Add(-2 * time.Minute))
+		}))},
+		{name: "not valid yet", token: key.sign(t, withClaims(func(c *jwt.Claims) {
+			c.NotBefore = jwt.NewNumericDate(testNow.Add(5 * time.Minute))
+		}))},
+		{name: "without expiry", token: key.sign(t, withClaims(func(c *jwt.Claims) { c.Expiry = nil }))},
+		{name: "signed by another key", token: stranger.sign(t, validClaims())},
+		{name: "API token", token: "cfg_abc"},
+		{name: "malformed JWT", token: "a.b.c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := a.Authenticate(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if tt.wantName == "" {
+				if identity != nil {
+					t.Fatalf("Authenticate() = %#v, want nil", identity)
+				}
+				return
+			}
+			if identity == nil || identity.Name != tt.wantName || identity.Source != model.IdentitySourceOIDC {
+				t.Fatalf("Authenticate() = %#v, want %q from OIDC", identity, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator_MapsGroups(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	oidc := testOIDCConfig()
+	oidc.JWKSFile = writeJWKS(t, key)
+	oidc.NameClaim = "preferred_username"
+	oidc.GroupsClaim = "roles"
+	a := newTestJWTAuthenticator(t, oidc)
+
+	tests := []struct {
+		name       string
+		extra      map[string]any
+		wantGroups []string
+		wantGrants []model.Grant
+	}{
+		{
+			name:       "group list",
+			extra:      map[string]any{"preferred_username": "alice", "roles": []string{"billing", "unknown"}},
+			wantGroups: []string{"billing", "unknown"},
+			wantGrants: []model.Grant{
+				{Environment: "prod", KeyPrefix: "billing.", Role: model.RoleWriter},
+				{Environment: "staging", Role: model.RoleReader},
+			},
+		},
+		{
+			name:       "single group string",
+			extra:      map[string]any{"preferred_username": "bob", "roles": "platform"},
+			wantGroups: []string{"platform"},
+			wantGrants: []model.Grant{{Environment: model.AllEnvironments, Role: model.RoleAdmin}},
+		},
+		{
+			name:       "no groups",
+			extra:      map[string]any{"preferred_username": "carol"},
+			wantGrants: []model.Grant{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := a.Authenticate(context.Background(), key.sign(t, validClaims(), tt.extra))
+			if err != nil || identity == nil {
+				t.Fatalf("Authenticate() = %#v, %v", identity, err)
+			}
+			if !reflect.DeepEqual(identity.Groups, tt.wantGroups) {
+				t.Fatalf("groups = %#v, want %#v", identity.Groups, tt.wantGroups)
+			}
+			if !reflect.DeepEqual(identity.Grants, tt.wantGrants) {
+				t.Fatalf("grants = %#v, want %#v", identity.Grants, tt.wantGrants)
+			}
+			if identity.ExpiresAt == nil || !identity.ExpiresAt.Equal(testNow.Add(time.Hour)) {
+				t.Fatalf("expires_at = %v, want the token expiry", identity.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator_FetchesRotatedKeys(t *testing.T) {
+	ctx := context.Background()
+	oldKey := newRSAKey(t, "old")
+	newKey := newECKey(t, "new")
+	var published atomic.Value
+	published.Store(jwksJSON(t, oldKey))
+	var fetches atomic.Int32
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(published.Load().([]byte))
+	}))
+	defer jwks.Close()
+
+	oidc := testOIDCConfig()
+	oidc.JWKSURL = jwks.URL
+	a := newTestJWTAuthenticator(t, oidc)
+	now := testNow
+	a.now = func() time.Time { return now }
+	a.keys.now = a.now
+
+	if identity, err := a.Authenticate(ctx, oldKey.sign(t, validClaims())); err != nil || identity == nil {
+		t.Fatalf("Authenticate() with the published key = %#v, %v", identity, err)
+	}
+
+	published.Store(jwksJSON(t, oldKey, newKey))
+	if identity, _ := a.Authenticate(ctx, newKey.sign(t, validClaims())); identity != nil {
+		t.Fatal("unknown keys must not be re-fetched more than once a minute")
+	}
+
+	now = now.Add(minJWKSRefetch)
+	if identity, err := a.Authenticate(ctx, newKey.sign(t, validClaims())); err != nil || identity == nil {
+		t.Fatalf("Authenticate() with a rotated key = %#v, %v", identity, err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", got)
+	}
+
+	jwks.Close()
+	now = now.Add(oidc.JWKSRefresh)
+	if identity, err := a.Authenticate(ctx, oldKey.sign(t, validClaims())); err != nil || identity == nil {
+		t.Fatalf("Authenticate() with an unreachable JWKS = %#v, %v; want the cached keys", identity, err)
+	}
+}
+
+func TestJWTAuthenticator_SlowJWKSDoesNotBlockKnownKeys(t *testing.T) {
+	ctx := context.Background()
+	key := newRSAKey(t, "known")
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		_, _ = w.Write(jwksJSON(t, key))
+	}))
+	defer jwks.Close()
+	defer close(release)
+
+	oidc := testOIDCConfig()
+	oidc.JWKSURL = jwks.URL
+	a := newTestJWTAuthenticator(t, oidc)
+	if identity, err := a.Authenticate(ctx, key.sign(t, validClaims())); err != nil || identity == nil {
+		t.Fatalf("Authenticate() = %#v, %v", identity, err)
+	}
+	now := testNow.Add(minJWKSRefetch)
+	a.now = func() time.Time { return now }
+	a.keys.now = a.now
+
+	// Tokens with unknown key IDs wait for one shared fetch until their
+	// requests end.
+	var waiting sync.WaitGroup
+	for _, kid := range []string{"forged-1", "forged-2"} {
+		waiting.Add(1)
+		go func() {
+			defer waiting.Done()
+			ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancel()
+			if identity, _ := a.Authenticate(ctx, newRSAKey(t, kid).sign(t, validClaims())); identity != nil {
+				t.Errorf("Authenticate() with key %q = %#v, want an error", kid, identity)
+			}
+		}()
+	}
+
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if identity, err := a.Authenticate(ctx, key.sign(t, validClaims())); err != nil || identity == nil {
+		t.Fatalf("Authenticate() with a known key during a fetch = %#v, %v", identity, err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("Authenticate() with a known key took %v while the JWKS fetch was stuck", elapsed)
+	}
+	waiting.Wait()
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", got)
+	}
+}
+
+func TestJWTAuthenticator_FailsWithoutKeys(t *testing.T) {
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+
+	oidc := testOIDCConfig()
+	oidc.JWKSURL = jwks.URL
+	a := newTestJWTAuthenticator(t, oidc)
+
+	if _, err := a.Authenticate(context.Background(), newRSAKey(t, "k").sign(t, validClaims())); err == nil {
+		t.Fatal("Authenticate() must fail when no keys could be fetched")
+	}
+}
+
+func TestChainAuthenticators(t *testing.T) {
+	first := stubAuthenticator{"a": {Name: "first"}}
+	second := stubAuthenticator{"a": {Name: "second"}, "b": {Name: "second"}}
+	chain := ChainAuthenticators(first, second)
+
+	tests := []struct {
+		token    string
+		wantName string
+	}{
+		{token: "a", wantName: "first"},
+		{token: "b", wantName: "second"},
+		{token: "c"},
+	}
+	for _, tt := range tests {
+		identity, err := chain.Authenticate(context.Background(), tt.token)
+		if err != nil {
+			t.Fatalf("Authenticate(%q) error = %v", tt.token, err)
+		}
+		if (identity == nil) != (tt.wantName == "") || identity != nil && identity.Name != tt.wantName {
+			t.Fatalf("Authenticate(%q) = %#v, want %q", tt.token, identity, tt.wantName)
+		}
+	}
+}
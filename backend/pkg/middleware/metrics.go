@@ -51,6 +51,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// streaming responses need for flushing.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func shouldSkipMetrics(path string) bool {
 	return path == "/metrics" ||
 		path == "/health" ||
@@ -1,10 +1,15 @@
 package middleware
 
 import (
+	"config-service/backend/internal/model"
 	"config-service/backend/pkg/metrics"
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -109,3 +114,145 @@ func TestShouldSkipMetrics(t *testing.T) {
 		}
 	}
 }
+
+func TestMetricsMiddlewareSupportsFlush(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/configs/prod/watch", nil)
+
+	NewMetricsMiddleware(testMetrics()).Handler(next).ServeHTTP(rr, req)
+
+	if !rr.Flushed {
+		t.Fatal("expected response to be flushed")
+	}
+}
+
+type stubAuthenticator map[string]*model.Identity
+
+func (a stubAuthenticator) Authenticate(ctx context.Context, token string) (*model.Identity, error) {
+	if token == "broken" {
+		return nil, errors.New("database unavailable")
+	}
+	return a[token], nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	authenticator := stubAuthenticator{"cfg_valid": {Name: "ci"}}
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantIdentity  string
+	}{
+		{name: "valid token", path: "/api/configs/prod", authorization: "Bearer cfg_valid", wantStatus: http.StatusOK, wantIdentity: "ci"},
+		{name: "lowercase scheme", path: "/api/configs/prod", authorization: "bearer cfg_valid", wantStatus: http.StatusOK, wantIdentity: "ci"},
+		{name: "missing header", path: "/api/configs/prod", wantStatus: http.StatusUnauthorized},
+		{name: "basic scheme", path: "/api/configs/prod", authorization: "Basic cfg_valid", wantStatus: http.StatusUnauthorized},
+		{name: "empty token", path: "/api/configs/prod", authorization: "Bearer ", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", path: "/api/configs/prod", authorization: "Bearer cfg_other", wantStatus: http.StatusUnauthorized},
+		{name: "authenticator error", path: "/api/configs/prod", authorization: "Bearer broken", wantStatus: http.StatusInternalServerError},
+		{name: "health is public", path: "/health", wantStatus: http.StatusOK},
+		{name: "docs are public", path: "/doc.json", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var identity *model.Identity
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				identity = model.IdentityFromContext(r.Context())
+			})
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			NewAuthMiddleware(authenticator).Handler(next).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if rr.Code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("401 response without WWW-Authenticate")
+			}
+			if tt.wantIdentity != "" && (identity == nil || identity.Name != tt.wantIdentity) {
+				t.Fatalf("identity = %#v, want %q", identity, tt.wantIdentity)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{name: "keeps client id", header: "req-123", wantSame: true},
+		{name: "generates missing id"},
+		{name: "replaces id with spaces", header: "req 123"},
+		{name: "replaces long id", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotID = model.RequestIDFromContext(r.Context())
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/configs/prod", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+
+			RequestIDMiddleware(next).ServeHTTP(rr, req)
+
+			if gotID == "" || rr.Header().Get(RequestIDHeader) != gotID {
+				t.Fatalf("context id = %q, header = %q", gotID, rr.Header().Get(RequestIDHeader))
+			}
+			if (gotID == tt.header) != tt.wantSame {
+				t.Fatalf("request id = %q, header %q, want same %v", gotID, tt.header, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	exempt := func(r *http.Request) bool { return r.URL.Path == "/stream" }
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		path         string
+		wantDeadline bool
+	}{
+		{name: "bounds requests", timeout: time.Minute, path: "/api/configs/prod", wantDeadline: true},
+		{name: "skips exempt requests", timeout: time.Minute, path: "/stream"},
+		{name: "disabled", path: "/api/configs/prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline time.Time
+			var hasDeadline bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadline, hasDeadline = r.Context().Deadline()
+			})
+			start := time.Now()
+
+			NewTimeoutMiddleware(tt.timeout, exempt).Handler(next).
+				ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if hasDeadline != tt.wantDeadline {
+				t.Fatalf("deadline set = %v, want %v", hasDeadline, tt.wantDeadline)
+			}
+			if hasDeadline && (deadline.Before(start.Add(tt.timeout)) || deadline.After(time.Now().Add(tt.timeout))) {
+				t.Fatalf("deadline = %v, want %v after the request started", deadline, tt.timeout)
+			}
+		})
+	}
+}
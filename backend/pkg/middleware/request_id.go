@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"config-service/backend/internal/model"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestIDMiddleware keeps the X-Request-ID sent by the client or a proxy,
+// or generates one, echoes it in the response and stores it in the request
+// context for model.RequestIDFromContext.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := ValidOrNewRequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(model.ContextWithRequestID(r.Context(), requestID)))
+	})
+}
+
+// ValidOrNewRequestID returns requestID, or a new ID when the client sent
+// none or an unusable one.
+func ValidOrNewRequestID(requestID string) string {
+	if !validRequestID(requestID) {
+		return newRequestID()
+	}
+	return requestID
+}
+
+// validRequestID accepts printable ASCII only, since the ID ends up in the
+// audit log and in response headers.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return hex.EncodeToString(random)
+}
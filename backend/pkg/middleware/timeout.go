@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TimeoutMiddleware puts a deadline on the request context, so that the
+// queries a request runs are canceled once it is exceeded. Handlers answer
+// for the deadline themselves; the middleware does not write a response.
+type TimeoutMiddleware struct {
+	timeout time.Duration
+	exempt  func(*http.Request) bool
+}
+
+// NewTimeoutMiddleware skips requests for which exempt returns true, such as
+// change streams that stay open on purpose. A zero timeout disables the
+// deadline.
+func NewTimeoutMiddleware(timeout time.Duration, exempt func(*http.Request) bool) *TimeoutMiddleware {
+	return &TimeoutMiddleware{timeout: timeout, exempt: exempt}
+}
+
+func (mw *TimeoutMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mw.timeout <= 0 || (mw.exempt != nil && mw.exempt(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), mw.timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/grpcapi"
+	"config-service/backend/internal/service"
+	"config-service/backend/pkg/middleware"
+	"context"
+	"errors"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCServer serves the gRPC API on its own port, with the same
+// authentication as the HTTP API.
+type GRPCServer struct {
+	grpcServer *grpc.Server
+	addr       string
+}
+
+func NewGRPCServer(
+	cfg *config.Config,
+	configs *grpcapi.ConfigServer,
+	tokens service.TokenService,
+	jwt *middleware.JWTAuthenticator,
+) *GRPCServer {
+	grpcServer := grpc.NewServer(grpcapi.Interceptors(provideAuthenticator(cfg, tokens, jwt))...)
+	configs.Register(grpcServer)
+	reflection.Register(grpcServer)
+
+	return &GRPCServer{grpcServer: grpcServer, addr: ":" + cfg.GRPC.Port}
+}
+
+func (s *GRPCServer) Start() <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		listener, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			log.Fatalf("gRPC server error: %v", err)
+		}
+		log.Println("gRPC server started on", s.addr)
+		if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Fatalf("gRPC server error: %v", err)
+		}
+		close(done)
+	}()
+
+	return done
+}
+
+// Stop waits for calls to finish until ctx ends, then cancels the remaining
+// ones, such as open watches.
+func (s *GRPCServer) Stop(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+	log.Println("gRPC server stopped")
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"config-service/backend/config"
+	"config-service/backend/internal/grpcapi"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewGRPCServer(t *testing.T) {
+	cfg := &config.Config{GRPC: config.GRPCConfig{Port: "0"}, Auth: config.AuthConfig{Enabled: true}}
+	srv := NewGRPCServer(cfg, grpcapi.NewConfigServer(serverStubService{}), serverStubTokenService{}, nil)
+	if srv.addr != ":0" {
+		t.Fatalf("server addr = %q", srv.addr)
+	}
+	services := srv.grpcServer.GetServiceInfo()
+	for _, name := range []string{"config.v1.ConfigService", "grpc.reflection.v1.ServerReflection"} {
+		if _, ok := services[name]; !ok {
+			t.Fatalf("services = %v, want %s", services, name)
+		}
+	}
+
+	done := srv.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	srv.Stop(ctx)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop")
+	}
+}
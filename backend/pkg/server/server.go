@@ -3,15 +3,12 @@ package server
 import (
 	"config-service/backend/config"
 	"config-service/backend/internal/handler"
+	"config-service/backend/internal/service"
 	"config-service/backend/pkg/metrics"
 	"config-service/backend/pkg/middleware"
 	"context"
 	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -24,11 +21,19 @@ type Server struct {
 func provideHTTPServer(
 	cfg *config.Config,
 	h *handler.ConfigHandler,
+	eh *handler.EnvironmentHandler,
+	th *handler.TokenHandler,
+	ah *handler.AuditHandler,
+	tokens service.TokenService,
+	jwt *middleware.JWTAuthenticator,
 	m *metrics.Metrics,
 ) *http.Server {
 
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
+	eh.RegisterRoutes(mux)
+	th.RegisterRoutes(mux)
+	ah.RegisterRoutes(mux)
 
 	mux.Handle(
 		"/swagger/",
@@ -40,7 +45,12 @@ func provideHTTPServer(
 	metricsMw := middleware.NewMetricsMiddleware(m)
 
 	var handler http.Handler = mux
+	if authenticator := provideAuthenticator(cfg, tokens, jwt); authenticator != nil {
+		handler = middleware.NewAuthMiddleware(authenticator).Handler(handler)
+	}
+	handler = middleware.NewTimeoutMiddleware(cfg.HTTP.RequestTimeout, h.IsWatchRequest).Handler(handler)
 	handler = metricsMw.Handler(handler)
+	handler = middleware.RequestIDMiddleware(handler)
 
 	return &http.Server{
 		Addr:    ":" + cfg.HTTP.Port,
@@ -48,13 +58,29 @@ func provideHTTPServer(
 	}
 }
 
+// provideAuthenticator returns nil when authentication is disabled.
+func provideAuthenticator(cfg *config.Config, tokens service.TokenService, jwt *middleware.JWTAuthenticator) middleware.Authenticator {
+	if !cfg.Auth.Enabled {
+		return nil
+	}
+	if jwt != nil {
+		return middleware.ChainAuthenticators(jwt, tokens)
+	}
+	return tokens
+}
+
 func NewServer(
 	cfg *config.Config,
 	h *handler.ConfigHandler,
+	eh *handler.EnvironmentHandler,
+	th *handler.TokenHandler,
+	ah *handler.AuditHandler,
+	tokens service.TokenService,
+	jwt *middleware.JWTAuthenticator,
 	m *metrics.Metrics,
 ) *Server {
 	return &Server{
-		httpServer: provideHTTPServer(cfg, h, m),
+		httpServer: provideHTTPServer(cfg, h, eh, th, ah, tokens, jwt, m),
 	}
 }
 
@@ -72,19 +98,13 @@ func (s *Server) Start() <-chan struct{} {
 	return done
 }
 
-func (s *Server) GracefulShutdown(timeout time.Duration) {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-
-	<-quit
-	log.Println("Shutdown signal received, stopping server...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
+// Shutdown waits for requests to finish until ctx ends, then closes the
+// remaining connections, such as open change streams.
+func (s *Server) Shutdown(ctx context.Context) error {
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		_ = s.httpServer.Close()
+		return err
 	}
-
 	log.Println("Server stopped gracefully")
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"config-service/backend/internal/handler"
 	"config-service/backend/internal/model"
 	"config-service/backend/pkg/metrics"
+	"config-service/backend/pkg/middleware"
 	"context"
 	"net/http"
 	"net/http/httptest"
@@ -16,23 +17,132 @@ import (
 
 type serverStubService struct{}
 
-func (serverStubService) CreateConfig(string, string, string) error {
+func (serverStubService) CreateConfig(context.Context, string, string, string, model.ValueSpec, string) error {
 	return nil
 }
 
-func (serverStubService) GetConfig(environment, key string) (*model.Config, error) {
+func (serverStubService) GetConfig(ctx context.Context, environment, key string) (*model.Config, error) {
 	return &model.Config{Environment: environment, Key: key, Value: "value"}, nil
 }
 
-func (serverStubService) GetAllConfigs(environment string) ([]*model.Config, error) {
+func (serverStubService) RevealConfig(ctx context.Context, environment, key string) (*model.Config, error) {
+	return &model.Config{Environment: environment, Key: key, Value: "value"}, nil
+}
+
+func (serverStubService) GetAllConfigs(ctx context.Context, environment string) ([]*model.Config, error) {
+	return []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, nil
+}
+
+func (serverStubService) ListConfigs(ctx context.Context, environment string, _ model.ConfigQuery) (*model.ConfigPage, error) {
+	return &model.ConfigPage{Configs: []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, Total: 1}, nil
+}
+
+func (serverStubService) GetConfigAt(ctx context.Context, environment, key string, _ time.Time) (*model.Config, error) {
+	return &model.Config{Environment: environment, Key: key, Value: "value"}, nil
+}
+
+func (serverStubService) ImportConfigs(ctx context.Context, environment string, _ []model.KeyValue, mode model.ImportMode, _ string, dryRun bool) (*model.ImportReport, error) {
+	return &model.ImportReport{Environment: environment, Mode: mode, DryRun: dryRun}, nil
+}
+
+func (serverStubService) CompareEnvironments(ctx context.Context, left, right model.EnvironmentRef) (*model.EnvironmentDiff, error) {
+	return model.CompareConfigs(left, right, nil, nil), nil
+}
+
+func (serverStubService) GetAllConfigsAt(ctx context.Context, environment string, _ time.Time) ([]*model.Config, error) {
 	return []*model.Config{{Environment: environment, Key: "key", Value: "value"}}, nil
 }
 
-func (serverStubService) UpdateConfig(string, string, string) error {
+func (serverStubService) GetConfigHistory(ctx context.Context, environment, key string) ([]*model.Revision, error) {
+	return []*model.Revision{{Environment: environment, Key: key, Value: "value"}}, nil
+}
+
+func (serverStubService) UpdateConfig(context.Context, string, string, string, model.ValueSpec, string, int64) error {
 	return nil
 }
 
-func (serverStubService) DeleteConfig(string, string) error {
+func (serverStubService) DeleteConfig(context.Context, string, string, string, int64) error {
+	return nil
+}
+
+func (serverStubService) DeleteTree(context.Context, string, string, string) ([]*model.Revision, error) {
+	return nil, nil
+}
+
+func (serverStubService) RollbackConfig(context.Context, string, string, int64, string) ([]*model.Revision, error) {
+	return nil, nil
+}
+
+func (serverStubService) RollbackEnvironment(context.Context, string, time.Time, string) ([]*model.Revision, error) {
+	return nil, nil
+}
+
+func (serverStubService) ApplyBatch(context.Context, string, []model.BatchOperation, string) ([]model.BatchResult, error) {
+	return nil, nil
+}
+
+func (serverStubService) ResolveConfigs(context.Context, string) ([]*model.ResolvedConfig, error) {
+	return nil, nil
+}
+
+func (serverStubService) WatchConfigs(context.Context, string) (<-chan *model.Revision, func(), error) {
+	return nil, func() {}, nil
+}
+
+func (serverStubService) GetChangesSince(context.Context, string, int64) ([]*model.Revision, error) {
+	return nil, nil
+}
+
+type serverStubEnvironmentService struct{}
+
+func (serverStubEnvironmentService) ListEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	return []*model.Environment{{Name: "prod"}}, nil
+}
+
+func (serverStubEnvironmentService) GetEnvironment(ctx context.Context, name string) (*model.Environment, error) {
+	return &model.Environment{Name: name}, nil
+}
+
+func (serverStubEnvironmentService) CreateEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	return &model.Environment{Name: name, EnvironmentAttributes: attributes}, nil
+}
+
+func (serverStubEnvironmentService) PutEnvironment(ctx context.Context, name string, attributes model.EnvironmentAttributes) (*model.Environment, error) {
+	return &model.Environment{Name: name, EnvironmentAttributes: attributes}, nil
+}
+
+func (serverStubEnvironmentService) DeleteEnvironment(context.Context, string, string, bool) error {
+	return nil
+}
+
+func (serverStubEnvironmentService) PromoteEnvironment(ctx context.Context, source, target string, _ model.KeyFilter, _ string, dryRun bool) (*model.Promotion, error) {
+	return &model.Promotion{Source: source, Target: target, DryRun: dryRun}, nil
+}
+
+type serverStubAuditService struct{}
+
+func (serverStubAuditService) GetAuditLog(context.Context, model.AuditFilter) (*model.AuditPage, error) {
+	return &model.AuditPage{Entries: []*model.AuditEntry{}}, nil
+}
+
+type serverStubTokenService struct{}
+
+func (serverStubTokenService) Authenticate(ctx context.Context, token string) (*model.Identity, error) {
+	if token != "cfg_valid" {
+		return nil, nil
+	}
+	return &model.Identity{Name: "ci", Grants: []model.Grant{{Environment: "prod", Role: model.RoleReader}}}, nil
+}
+
+func (serverStubTokenService) ListTokens(ctx context.Context) ([]*model.Token, error) {
+	return nil, nil
+}
+
+func (serverStubTokenService) CreateToken(ctx context.Context, name string, grants []model.Grant, _ *time.Time, _ string) (*model.Token, string, error) {
+	return &model.Token{Name: name, Grants: grants}, "cfg_secret", nil
+}
+
+func (serverStubTokenService) DeleteToken(context.Context, int64) error {
 	return nil
 }
 
@@ -60,8 +170,12 @@ func serverTestMetrics() *metrics.Metrics {
 func TestNewServer(t *testing.T) {
 	cfg := &config.Config{HTTP: config.HTTPConfig{Port: "18080"}}
 	h := handler.NewConfigHandler(serverStubService{})
+	eh := handler.NewEnvironmentHandler(serverStubEnvironmentService{})
 
-	srv := NewServer(cfg, h, serverTestMetrics())
+	th := handler.NewTokenHandler(serverStubTokenService{})
+	ah := handler.NewAuditHandler(serverStubAuditService{})
+
+	srv := NewServer(cfg, h, eh, th, ah, serverStubTokenService{}, nil, serverTestMetrics())
 	if srv == nil || srv.httpServer == nil {
 		t.Fatal("server was not initialized")
 	}
@@ -75,20 +189,68 @@ func TestNewServer(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Fatalf("health status = %d, want %d", rr.Code, http.StatusOK)
 	}
+	if rr.Header().Get(middleware.RequestIDHeader) == "" {
+		t.Fatal("response has no request id")
+	}
 }
 
 func TestProvideHTTPServerHandlesAPIRequest(t *testing.T) {
 	cfg := &config.Config{HTTP: config.HTTPConfig{Port: "8081"}}
 	h := handler.NewConfigHandler(serverStubService{})
-	httpServer := provideHTTPServer(cfg, h, serverTestMetrics())
+	eh := handler.NewEnvironmentHandler(serverStubEnvironmentService{})
+	th := handler.NewTokenHandler(serverStubTokenService{})
+	ah := handler.NewAuditHandler(serverStubAuditService{})
+	httpServer := provideHTTPServer(cfg, h, eh, th, ah, serverStubTokenService{}, nil, serverTestMetrics())
 
-	rr := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/api/configs/prod/key", nil)
+	for _, path := range []string{"/api/configs/prod/key", "/api/environments", "/api/environments/prod", "/api/tokens", "/api/me", "/api/audit"} {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
 
-	httpServer.Handler.ServeHTTP(rr, req)
+		httpServer.Handler.ServeHTTP(rr, req)
 
-	if rr.Code != http.StatusOK {
-		t.Fatalf("status = %d, want %d; body=%q", rr.Code, http.StatusOK, rr.Body.String())
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, want %d; body=%q", path, rr.Code, http.StatusOK, rr.Body.String())
+		}
+	}
+}
+
+func TestProvideHTTPServerRequiresToken(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{Port: "8081"}, Auth: config.AuthConfig{Enabled: true}}
+	h := handler.NewConfigHandler(serverStubService{})
+	eh := handler.NewEnvironmentHandler(serverStubEnvironmentService{})
+	th := handler.NewTokenHandler(serverStubTokenService{})
+	ah := handler.NewAuditHandler(serverStubAuditService{})
+	httpServer := provideHTTPServer(cfg, h, eh, th, ah, serverStubTokenService{}, nil, serverTestMetrics())
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "health without token", path: "/health", wantStatus: http.StatusOK},
+		{name: "config without token", path: "/api/configs/prod/key", wantStatus: http.StatusUnauthorized},
+		{name: "config with token", path: "/api/configs/prod/key", token: "cfg_valid", wantStatus: http.StatusOK},
+		{name: "config outside grants", path: "/api/configs/staging/key", token: "cfg_valid", wantStatus: http.StatusForbidden},
+		{name: "tokens need global admin", path: "/api/tokens", token: "cfg_valid", wantStatus: http.StatusForbidden},
+		{name: "me with token", path: "/api/me", token: "cfg_valid", wantStatus: http.StatusOK},
+		{name: "me without token", path: "/api/me", wantStatus: http.StatusUnauthorized},
+		{name: "audit needs admin", path: "/api/audit", token: "cfg_valid", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			httpServer.Handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+		})
 	}
 }
 
@@ -104,7 +266,7 @@ func TestServerStartCanBeShutdown(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	if err := srv.httpServer.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(ctx); err != nil {
 		t.Fatalf("Shutdown() error = %v", err)
 	}
 
@@ -70,6 +70,12 @@ export function createConfigApi({ baseUrl = DEFAULT_BASE_URL, fetcher = fetch }
   return {
     baseUrl: root || "same-origin (/)",
     health: () => request("/health"),
+    createEnvironment: (name) =>
+      request("/api/environments", {
+        method: "POST",
+        body: { name },
+        parse: "none"
+      }),
     listConfigs: (env) => request(`/api/configs/${encodeSegment(env)}`),
     getConfig: (env, key) =>
       request(`/api/configs/${encodeSegment(env)}/${encodeSegment(key)}`),
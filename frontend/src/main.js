@@ -187,6 +187,26 @@ async function loadConfigs({ silent = false } = {}) {
   }
 }
 
+// Keys can only be created in an existing environment, so the first key of
+// a new environment creates the environment too.
+async function createConfig(env, key, value) {
+  try {
+    await api.createConfig(env, key, value);
+  } catch (error) {
+    if (error.status !== 404) {
+      throw error;
+    }
+    try {
+      await api.createEnvironment(env);
+    } catch (envError) {
+      if (envError.status !== 409) {
+        throw envError;
+      }
+    }
+    await api.createConfig(env, key, value);
+  }
+}
+
 async function checkBackend() {
   setStatus("loading", "Checking API...");
   try {
@@ -235,7 +255,7 @@ createBtn.addEventListener("click", async () => {
   setStatus("loading", "Creating...");
 
   try {
-    await api.createConfig(env, key, value);
+    await createConfig(env, key, value);
     state.selectedKey = key;
     await loadConfigs({ silent: true });
     setStatus("success", `Created ${key}.`);
@@ -80,6 +80,21 @@ describe("createConfigApi", () => {
     expect(options.method).toBe("DELETE");
   });
 
+  it("creates environments with POST", async () => {
+    const fetcher = vi.fn().mockResolvedValue(
+      new Response("", { status: 201 })
+    );
+
+    const api = createConfigApi({ baseUrl: "http://api", fetcher });
+
+    await api.createEnvironment("stage");
+    const [url, options] = fetcher.mock.calls[0];
+
+    expect(url).toBe("http://api/api/environments");
+    expect(options.method).toBe("POST");
+    expect(JSON.parse(options.body)).toEqual({ name: "stage" });
+  });
+
   it("throws when the server responds with an error", async () => {
     const fetcher = vi.fn().mockResolvedValue(
       new Response("boom", { status: 500 })
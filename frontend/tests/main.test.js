@@ -334,6 +334,47 @@ describe("main UI flow", () => {
     expect(document.byId("status").textContent).toBe("missing");
   });
 
+  it("creates the environment with its first key", async () => {
+    let envExists = false;
+    const fetcher = vi.fn(async (url, options = {}) => {
+      const method = options.method || "GET";
+      if (url === "/health") {
+        return jsonResponse({ status: "ok" });
+      }
+      if (String(url).startsWith("http://localhost:9091")) {
+        return new Response("", { status: 202 });
+      }
+      if (url === "/api/environments" && method === "POST") {
+        envExists = true;
+        return new Response("", { status: 201 });
+      }
+      if (url === "/api/configs/dev/token" && method === "POST") {
+        return envExists
+          ? new Response("", { status: 201 })
+          : new Response("environment not found", { status: 404 });
+      }
+      if (url === "/api/configs/dev" && method === "GET") {
+        return jsonResponse([{ key: "token", value: "secret" }]);
+      }
+      throw new Error(`Unexpected request ${method} ${url}`);
+    });
+
+    const { document } = await importMainWithDom({ fetcher });
+
+    document.byId("env-input").value = "dev";
+    document.byId("key-input").value = "token";
+    document.byId("value-input").value = "secret";
+    document.byId("create-btn").click();
+    await flushPromises();
+
+    expect(fetcher).toHaveBeenCalledWith(
+      "/api/environments",
+      expect.objectContaining({ method: "POST", body: JSON.stringify({ name: "dev" }) })
+    );
+    expect(document.byId("status").textContent).toBe("Created token.");
+    expect(document.byId("count-label").textContent).toBe("1 entries");
+  });
+
   it("handles empty lists, unchanged refreshes, delete cancellation, and delete failures", async () => {
     const configs = [
       {